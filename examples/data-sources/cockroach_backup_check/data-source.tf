@@ -0,0 +1,9 @@
+# Fails the plan when the latest backup of the collection is missing or corrupt
+data "cockroach_backup_check" "latest" {
+  collection = "s3://backups/crdb?AUTH=implicit"
+  kms_uris   = ["aws:///arn:aws:kms:us-east-1:123456789012:key/backups?AUTH=implicit&REGION=us-east-1"]
+}
+
+output "latest_backup_end_time" {
+  value = data.cockroach_backup_check.latest.end_time
+}
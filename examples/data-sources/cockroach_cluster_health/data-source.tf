@@ -0,0 +1,13 @@
+# requires the http_api block of the provider
+data "cockroach_cluster_health" "example" {
+}
+
+# fails the plan while the cluster isn't healthy
+resource "null_resource" "deploy" {
+  lifecycle {
+    precondition {
+      condition     = data.cockroach_cluster_health.example.healthy
+      error_message = join(", ", data.cockroach_cluster_health.example.reasons)
+    }
+  }
+}
@@ -0,0 +1,7 @@
+data "cockroach_collations" "german" {
+  prefix = "de"
+}
+
+output "german_collations" {
+  value = data.cockroach_collations.german.collations
+}
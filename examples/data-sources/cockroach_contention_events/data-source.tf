@@ -0,0 +1,15 @@
+data "cockroach_contention_events" "example" {
+  since        = "30m"
+  min_duration = "100ms"
+  limit        = 10
+}
+
+# blocks the deployment when the transactions waited for more than a minute
+resource "null_resource" "deploy" {
+  lifecycle {
+    precondition {
+      condition     = data.cockroach_contention_events.example.total_duration_ms < 60000
+      error_message = "The contention is too high to deploy."
+    }
+  }
+}
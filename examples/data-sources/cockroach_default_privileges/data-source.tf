@@ -0,0 +1,17 @@
+data "cockroach_default_privileges" "example" {
+  database    = "example"
+  role        = "migrator"
+  object_type = "tables"
+}
+
+# fails the plan until the tables created by the migrator are readable by the application
+resource "null_resource" "cutover" {
+  lifecycle {
+    precondition {
+      condition = anytrue([
+        for p in data.cockroach_default_privileges.example.default_privileges : p.grantee == "app" && contains(p.privileges, "SELECT")
+      ])
+      error_message = "The tables created by the migrator won't be readable by the application."
+    }
+  }
+}
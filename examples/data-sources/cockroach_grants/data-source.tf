@@ -0,0 +1,13 @@
+data "cockroach_grants" "example" {
+  database    = "example_database"
+  object_type = "table"
+  objects     = ["orders", "customers"]
+}
+
+# Bring the existing grants under Terraform, run
+# `terraform plan -generate-config-out=grants.tf` to generate their configuration.
+import {
+  for_each = { for grant in data.cockroach_grants.example.grants : grant.import_id => grant }
+  to       = cockroach_grant.imported[each.key]
+  id       = each.value.import_id
+}
@@ -0,0 +1,9 @@
+# requires the http_api block of the provider
+data "cockroach_health_check" "example" {
+  max_cpu_percent   = 80
+  fail_on_violation = false
+}
+
+output "violations" {
+  value = data.cockroach_health_check.example.violations
+}
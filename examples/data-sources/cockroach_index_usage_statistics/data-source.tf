@@ -0,0 +1,9 @@
+# the secondary indexes of the public schema which weren't read for 30 days
+data "cockroach_index_usage_statistics" "example" {
+  database   = "example"
+  unused_for = "720h"
+}
+
+output "unused_indexes" {
+  value = [for i in data.cockroach_index_usage_statistics.example.indexes : "${i.table}@${i.index}" if !i.is_unique]
+}
@@ -0,0 +1,27 @@
+data "cockroach_locality_map" "example" {
+}
+
+locals {
+  regions = one([for tier in data.cockroach_locality_map.example.tiers : tier.values if tier.key == "region"])
+}
+
+# a partition per region of the cluster, with its replicas and leaseholder in it
+resource "cockroach_table_partitioning" "users_by_region" {
+  database = "example"
+  table    = "users"
+  by       = "LIST"
+  columns  = ["region"]
+
+  dynamic "partition" {
+    for_each = local.regions
+    content {
+      name   = partition.value
+      values = ["'${partition.value}'"]
+
+      zone_config = {
+        constraints       = "[+region=${partition.value}]"
+        lease_preferences = "[[+region=${partition.value}]]"
+      }
+    }
+  }
+}
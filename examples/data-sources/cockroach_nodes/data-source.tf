@@ -0,0 +1,14 @@
+# requires the http_api block of the provider
+data "cockroach_nodes" "example" {
+  metric_names = ["sys.cpu.combined.percent-normalized"]
+}
+
+# fails the plan while a node is being decommissioned
+resource "null_resource" "deploy" {
+  lifecycle {
+    precondition {
+      condition     = alltrue([for node in data.cockroach_nodes.example.nodes : node.liveness_status != "decommissioning"])
+      error_message = "A node is being decommissioned."
+    }
+  }
+}
@@ -0,0 +1,7 @@
+# requires the http_api block of the provider
+data "cockroach_problem_ranges" "example" {
+}
+
+output "unavailable_ranges" {
+  value = data.cockroach_problem_ranges.example.unavailable_range_ids
+}
@@ -0,0 +1,14 @@
+data "cockroach_query" "tenants" {
+  database = "app"
+  query    = "SELECT name, plan FROM tenants WHERE active AND region = $1 ORDER BY name"
+
+  parameters = [var.region]
+}
+
+# a database per active tenant
+resource "cockroach_database" "tenant" {
+  for_each = { for row in data.cockroach_query.tenants.rows : row.name => row }
+
+  name       = "tenant_${each.key}"
+  local_port = "26258"
+}
@@ -0,0 +1,11 @@
+data "cockroach_sequence_value" "legacy_invoice_ids" {
+  database = "legacy"
+  name     = "invoice_ids"
+}
+
+# the new sequence goes on after the legacy one
+resource "cockroach_sequence" "invoice_ids" {
+  database     = "app"
+  name         = "invoice_ids"
+  restart_with = data.cockroach_sequence_value.legacy_invoice_ids.last_value + 1
+}
@@ -0,0 +1,13 @@
+data "cockroach_sessions" "example" {
+  min_age = "15m"
+}
+
+# fails the plan while a session other than Terraform's has been open for more than 15 minutes
+resource "null_resource" "maintenance" {
+  lifecycle {
+    precondition {
+      condition     = data.cockroach_sessions.example.session_count == 0
+      error_message = "Long running sessions are open: ${join(", ", [for s in data.cockroach_sessions.example.sessions : "${s.user} (${s.application_name})"])}."
+    }
+  }
+}
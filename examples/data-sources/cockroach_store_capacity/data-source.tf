@@ -0,0 +1,12 @@
+data "cockroach_store_capacity" "example" {
+}
+
+# fails the plan when a node uses more than 80% of its disk
+resource "null_resource" "deploy" {
+  lifecycle {
+    precondition {
+      condition     = alltrue([for node in data.cockroach_store_capacity.example.nodes : node.used_percent < 80])
+      error_message = "A node is running out of disk."
+    }
+  }
+}
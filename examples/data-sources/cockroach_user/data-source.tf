@@ -0,0 +1,11 @@
+data "cockroach_user" "example" {
+  username = "alice"
+}
+
+output "alice_roles" {
+  value = data.cockroach_user.example.inherited_roles
+}
+
+output "alice_is_admin" {
+  value = data.cockroach_user.example.is_admin
+}
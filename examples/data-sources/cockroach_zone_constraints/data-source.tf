@@ -0,0 +1,33 @@
+# Two replicas of the liveness range in us-east1, one of them on ssd stores, the leaseholder in us-east1
+data "cockroach_zone_constraints" "liveness" {
+  replica {
+    num_replicas = 1
+    required = {
+      region = "us-east1"
+      ssd    = ""
+    }
+  }
+
+  replica {
+    num_replicas = 1
+    required = {
+      region = "us-east1"
+    }
+  }
+
+  lease_preference {
+    required = {
+      region = "us-east1"
+    }
+  }
+}
+
+resource "cockroach_zone_configuration" "liveness" {
+  range = "liveness"
+
+  config = {
+    num_replicas      = "5"
+    constraints       = data.cockroach_zone_constraints.liveness.constraints
+    lease_preferences = data.cockroach_zone_constraints.liveness.lease_preferences
+  }
+}
@@ -0,0 +1,2 @@
+# the id is always admission_control
+terraform import cockroach_admission_control.example admission_control
@@ -0,0 +1,11 @@
+resource "cockroach_admission_control" "example" {
+  kv_enabled          = true
+  elastic_cpu_enabled = true
+
+  default_transaction_quality_of_service = "regular"
+
+  provisioned_bandwidth       = "500 MiB"
+  snapshot_rebalance_max_rate = "64 MiB"
+
+  local_port = "26287"
+}
@@ -0,0 +1,10 @@
+resource "cockroach_init" "example" {}
+
+resource "cockroach_bootstrap_user" "example" {
+  username  = "admin_user"
+  password  = var.admin_password
+  is_admin  = true
+  certs_dir = "/cockroach/cockroach-certs"
+
+  depends_on = [cockroach_init.example]
+}
@@ -0,0 +1,3 @@
+resource "cockroach_ca_cert" "example" {
+  rsa_bits = 4096
+}
@@ -0,0 +1,24 @@
+resource "cockroach_changefeed" "orders" {
+  database = cockroach_database.example.name
+  tables   = ["orders", "order_items"]
+
+  kafka {
+    broker       = "kafka.example.com:9093"
+    topic_prefix = "crdb_"
+    tls_enabled  = true
+    ca_cert      = file("kafka-ca.crt")
+
+    sasl {
+      mechanism = "SCRAM-SHA-512"
+      user      = "changefeed"
+      password  = var.kafka_password
+    }
+  }
+
+  options = {
+    updated  = ""
+    resolved = "10s"
+  }
+
+  local_port = "26275"
+}
@@ -0,0 +1,13 @@
+resource "cockroach_client_cert" "example" {
+  username = "root"
+  lifetime = "8760h"
+
+  ca_cert_pem        = cockroach_ca_cert.example.cert_pem
+  ca_private_key_pem = cockroach_ca_cert.example.private_key_pem
+
+  secret {
+    name      = "cockroachdb-root"
+    namespace = "cockroachdb"
+    format    = "tls"
+  }
+}
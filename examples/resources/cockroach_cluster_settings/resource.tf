@@ -0,0 +1,11 @@
+resource "cockroach_cluster_settings" "example" {
+  settings = {
+    "sql.defaults.idle_in_session_timeout" = "1h"
+    "kv.rangefeed.enabled"                 = "true"
+  }
+
+  reset_unmanaged          = true
+  ignore_unmanaged         = ["diagnostics.reporting.enabled"]
+  detect_unmanaged_changes = true
+  local_port               = "26266"
+}
@@ -12,3 +12,20 @@ resource "cockroach_database_backup" "example" {
   backup_recurring = "@daily"
   local_port       = "26259"
 }
+
+resource "cockroach_database_backup" "multi_region" {
+  name          = "multi_region_backups"
+  database_name = cockroach_database.example.name
+
+  backup_path = "s3://backups-default/crdb?AUTH=implicit"
+  locality_backup_paths = {
+    "region=us-east1" = "s3://backups-us-east1/crdb?AUTH=implicit"
+    "region=eu-west1" = "s3://backups-eu-west1/crdb?AUTH=implicit"
+  }
+
+  backup_recurring = "@hourly"
+  backup_full      = "@daily"
+
+  kms_uris   = ["aws:///arn:aws:kms:us-east-1:123456789012:key/backups?AUTH=implicit&REGION=us-east-1"]
+  local_port = "26259"
+}
@@ -0,0 +1,2 @@
+# the id is always default_isolation_and_priority
+terraform import cockroach_default_isolation_and_priority.example default_isolation_and_priority
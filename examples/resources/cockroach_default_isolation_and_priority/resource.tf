@@ -0,0 +1,7 @@
+resource "cockroach_default_isolation_and_priority" "example" {
+  read_committed_enabled              = true
+  default_isolation                   = "read committed"
+  default_priority                    = "normal"
+  idle_in_session_timeout             = "30m"
+  idle_in_transaction_session_timeout = "5m"
+}
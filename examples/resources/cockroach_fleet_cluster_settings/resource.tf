@@ -0,0 +1,14 @@
+resource "cockroach_fleet_cluster_settings" "example" {
+  settings = {
+    "sql.defaults.idle_in_session_timeout" = "1h"
+    "kv.rangefeed.enabled"                 = "true"
+  }
+
+  # every cluster of the clusters of the provider when not set
+  clusters          = ["prod-eu", "prod-us"]
+  continue_on_error = true
+}
+
+output "fleet_status" {
+  value = cockroach_fleet_cluster_settings.example.cluster_status
+}
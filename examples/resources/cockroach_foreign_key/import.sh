@@ -0,0 +1,2 @@
+# database/schema/table/name
+terraform import cockroach_foreign_key.orders_customer example_database/public/orders/orders_customer_fk
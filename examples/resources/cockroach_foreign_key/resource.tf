@@ -0,0 +1,14 @@
+resource "cockroach_foreign_key" "orders_customer" {
+  database           = cockroach_database.example.name
+  table              = "orders"
+  name               = "orders_customer_fk"
+  columns            = ["customer_id"]
+  referenced_table   = "customers"
+  referenced_columns = ["id"]
+  on_delete          = "CASCADE"
+
+  # added NOT VALID, set to true once the existing orders are fixed
+  validate = false
+
+  local_port = "26269"
+}
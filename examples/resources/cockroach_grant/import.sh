@@ -0,0 +1,2 @@
+# role/database/object_type[/schema[/object,object...]]
+terraform import cockroach_grant.tables example_user/example_database/table/public/customers,orders
@@ -0,0 +1,35 @@
+resource "cockroach_grant" "database" {
+  role        = cockroach_user.example.username
+  database    = cockroach_database.example.name
+  object_type = "database"
+  privileges  = ["CONNECT", "CREATE"]
+  local_port  = "26261"
+}
+
+resource "cockroach_grant" "tables" {
+  role              = cockroach_user.example.username
+  database          = cockroach_database.example.name
+  schema            = "public"
+  object_type       = "table"
+  objects           = ["orders", "customers"]
+  privileges        = ["SELECT", "INSERT", "UPDATE"]
+  with_grant_option = false
+  local_port        = "26262"
+}
+
+resource "cockroach_grant" "function" {
+  role        = cockroach_user.example.username
+  database    = cockroach_database.example.name
+  object_type = "function"
+  objects     = ["add(INT8, INT8)"]
+  privileges  = ["EXECUTE"]
+  local_port  = "26263"
+}
+
+resource "cockroach_grant" "external_connection" {
+  role        = cockroach_user.example.username
+  object_type = "external_connection"
+  objects     = ["backup_bucket"]
+  privileges  = ["USAGE"]
+  local_port  = "26264"
+}
@@ -0,0 +1,26 @@
+# requires the http_api block of the provider
+resource "cockroach_health_check" "example" {
+  max_dead_nodes             = 0
+  max_underreplicated_ranges = 0
+  max_cpu_percent            = 80
+
+  max_metric_values = {
+    "sql_conns" = 500
+  }
+
+  triggers = {
+    chart_version = helm_release.cockroachdb.version
+  }
+
+  timeouts {
+    create = "10m"
+  }
+}
+
+resource "cockroach_cluster_settings" "example" {
+  settings = {
+    "kv.rangefeed.enabled" = "true"
+  }
+
+  depends_on = [cockroach_health_check.example]
+}
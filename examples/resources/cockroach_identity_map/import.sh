@@ -0,0 +1,2 @@
+# the id is always identity_map
+terraform import cockroach_identity_map.example identity_map
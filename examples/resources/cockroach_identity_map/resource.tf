@@ -0,0 +1,13 @@
+resource "cockroach_identity_map" "example" {
+  mapping {
+    map_name        = "kerberos"
+    system_identity = "/^(.*)@EXAMPLE\\.COM$"
+    database_user   = "\\1"
+  }
+
+  mapping {
+    map_name        = "certs"
+    system_identity = "CN=billing,OU=services,O=Example"
+    database_user   = "billing"
+  }
+}
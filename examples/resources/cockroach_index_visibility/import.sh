@@ -0,0 +1,2 @@
+# the id has the format database/schema/table/index
+terraform import cockroach_index_visibility.orders_by_customer app/public/orders/orders_customer_id_idx
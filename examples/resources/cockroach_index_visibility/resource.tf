@@ -0,0 +1,9 @@
+# the index is soft-dropped to check the queries don't need it, and made
+# visible again by setting visible to true or destroying the resource
+resource "cockroach_index_visibility" "orders_by_customer" {
+  database   = "app"
+  table      = "orders"
+  index      = "orders_customer_id_idx"
+  visible    = false
+  local_port = "26257"
+}
@@ -0,0 +1,16 @@
+resource "cockroach_init" "example" {
+  container = "db"
+  certs_dir = "/cockroach/cockroach-certs"
+
+  timeouts {
+    create = "15m"
+  }
+
+  depends_on = [helm_release.cockroachdb]
+}
+
+resource "cockroach_wait_for_cluster" "example" {
+  expected_nodes = 3
+
+  depends_on = [cockroach_init.example]
+}
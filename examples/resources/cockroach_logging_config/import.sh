@@ -0,0 +1,2 @@
+# the id is always logging_config
+terraform import cockroach_logging_config.example logging_config
@@ -0,0 +1,12 @@
+resource "cockroach_logging_config" "example" {
+  slow_query_latency_threshold        = "500ms"
+  slow_query_full_table_scans_enabled = true
+
+  auth_log_connections_enabled = true
+  auth_log_sessions_enabled    = true
+
+  telemetry_query_sampling_enabled             = true
+  telemetry_query_sampling_max_event_frequency = 10
+
+  local_port = "26286"
+}
@@ -0,0 +1,23 @@
+resource "cockroach_migration" "app" {
+  database = "app"
+
+  migration {
+    version = "0001_create_orders"
+    sql     = file("${path.module}/migrations/0001_create_orders.sql")
+  }
+
+  migration {
+    version = "0002_orders_status"
+    sql     = "ALTER TABLE orders ADD COLUMN status STRING NOT NULL DEFAULT :'default_status'"
+
+    variables = {
+      default_status = var.default_order_status
+    }
+  }
+
+  migration {
+    version       = "0003_orders_status_index"
+    sql           = "CREATE INDEX CONCURRENTLY orders_status_idx ON orders (status)"
+    transactional = false
+  }
+}
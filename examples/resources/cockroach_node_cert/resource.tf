@@ -0,0 +1,13 @@
+resource "cockroach_node_cert" "example" {
+  hosts = [
+    "localhost",
+    "127.0.0.1",
+    "cockroachdb-public",
+    "cockroachdb-public.default.svc.cluster.local",
+    "*.cockroachdb",
+    "*.cockroachdb.default.svc.cluster.local",
+  ]
+
+  ca_cert_pem        = cockroach_ca_cert.example.cert_pem
+  ca_private_key_pem = cockroach_ca_cert.example.private_key_pem
+}
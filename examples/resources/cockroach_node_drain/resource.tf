@@ -0,0 +1,9 @@
+resource "cockroach_node_drain" "example" {
+  node_id            = 3
+  drain_wait         = "10m"
+  undrain_on_destroy = true
+
+  triggers = {
+    maintenance_window = "2022-03-01"
+  }
+}
@@ -0,0 +1,2 @@
+# the id is always oidc_config
+terraform import cockroach_oidc_config.example oidc_config
@@ -0,0 +1,15 @@
+resource "cockroach_oidc_config" "example" {
+  enabled       = true
+  provider_url  = "https://accounts.google.com"
+  client_id     = var.oidc_client_id
+  client_secret = var.oidc_client_secret
+  redirect_url  = "https://crdb.example.com:8080/oidc/v1/callback"
+  scopes        = "openid email"
+
+  # maps jane@example.com to the SQL user jane
+  claim_json_key  = "email"
+  principal_regex = "^([^@]+)@example\\.com$"
+
+  button_text = "Log in with Google"
+  local_port  = "26283"
+}
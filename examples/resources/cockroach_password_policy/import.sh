@@ -0,0 +1,2 @@
+# the id is always password_policy
+terraform import cockroach_password_policy.example password_policy
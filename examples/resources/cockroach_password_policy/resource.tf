@@ -0,0 +1,10 @@
+resource "cockroach_password_policy" "example" {
+  min_password_length = 16
+  password_encryption = "scram-sha-256"
+  scram_cost          = 119680
+
+  upgrade_bcrypt_to_scram     = true
+  rehash_scram_on_cost_change = true
+
+  local_port = "26297"
+}
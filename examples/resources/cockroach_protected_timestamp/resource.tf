@@ -0,0 +1,8 @@
+# keeps the history of the orders needed by the nightly analytics jobs
+resource "cockroach_protected_timestamp" "analytics" {
+  database      = cockroach_database.example.name
+  tables        = ["orders", "order_items"]
+  timestamp     = "2024-05-01T00:00:00Z"
+  expires_after = "72h"
+  local_port    = "26279"
+}
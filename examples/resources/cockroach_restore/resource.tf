@@ -0,0 +1,13 @@
+# Rehearsal environment restored from the production backups, as of one hour ago
+resource "cockroach_restore" "rehearsal" {
+  collection = "s3://production-backups/crdb?AUTH=implicit"
+  kms_uris   = ["aws:///arn:aws:kms:us-east-1:123456789012:key/backups?AUTH=implicit&REGION=us-east-1"]
+
+  database          = "orders"
+  new_db_name       = "orders_rehearsal"
+  as_of_system_time = "-1h"
+
+  skip_missing_foreign_keys = true
+
+  local_port = "26277"
+}
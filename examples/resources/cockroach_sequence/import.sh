@@ -0,0 +1,2 @@
+# the id has the format database/schema/name
+terraform import cockroach_sequence.order_ids app/public/order_ids
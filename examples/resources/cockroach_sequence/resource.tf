@@ -0,0 +1,16 @@
+resource "cockroach_sequence" "order_ids" {
+  database   = "app"
+  name       = "order_ids"
+  start      = 1000
+  increment  = 1
+  local_port = "26257"
+}
+
+# the ids are moved above the ones imported from the legacy system, changing
+# restart_trigger restarts the sequence with restart_with again
+resource "cockroach_sequence" "invoice_ids" {
+  database        = "app"
+  name            = "invoice_ids"
+  restart_with    = 5000000
+  restart_trigger = "legacy-import"
+}
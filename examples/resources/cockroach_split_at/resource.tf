@@ -0,0 +1,13 @@
+resource "cockroach_split_at" "events" {
+  database   = cockroach_database.example.name
+  table      = "events"
+  values     = ["1000000", "2000000", "3000000"]
+  expiration = "2030-01-01T00:00:00Z"
+  scatter    = true
+
+  triggers = {
+    bulk_load = "2024-06"
+  }
+
+  local_port = "26272"
+}
@@ -0,0 +1,2 @@
+# the id is always sql_stats_config
+terraform import cockroach_sql_stats_config.example sql_stats_config
@@ -0,0 +1,11 @@
+resource "cockroach_sql_stats_config" "example" {
+  automatic_collection_enabled        = true
+  automatic_collection_fraction_stale = 0.1
+  histogram_buckets_count             = 200
+
+  flush_interval     = "10m"
+  persisted_rows_max = 500000
+  cleanup_recurrence = "@hourly"
+
+  local_port = "26278"
+}
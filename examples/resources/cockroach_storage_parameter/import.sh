@@ -0,0 +1,2 @@
+# database/schema/table, every storage parameter of the table is imported
+terraform import cockroach_storage_parameter.events example_database/public/events
@@ -0,0 +1,11 @@
+resource "cockroach_storage_parameter" "events" {
+  database = cockroach_database.example.name
+  table    = "events"
+
+  parameters = {
+    exclude_data_from_backup               = "true"
+    sql_stats_automatic_collection_enabled = "false"
+  }
+
+  local_port = "26273"
+}
@@ -0,0 +1,2 @@
+# database/schema/table
+terraform import cockroach_table.orders example_database/public/orders
@@ -0,0 +1,87 @@
+resource "cockroach_table" "orders" {
+  database = cockroach_database.example.name
+  name     = "orders"
+
+  column {
+    name     = "id"
+    type     = "UUID"
+    nullable = false
+    default  = "gen_random_uuid()"
+  }
+
+  column {
+    name     = "quantity"
+    type     = "INT8"
+    nullable = false
+  }
+
+  column {
+    name     = "unit_price"
+    type     = "DECIMAL(10,2)"
+    nullable = false
+  }
+
+  column {
+    name     = "total"
+    type     = "DECIMAL(12,2)"
+    computed = "quantity * unit_price"
+    stored   = true
+  }
+
+  column {
+    name      = "updated_at"
+    type      = "TIMESTAMPTZ"
+    default   = "now()"
+    on_update = "now()"
+  }
+
+  column {
+    name = "reference"
+    type = "STRING"
+  }
+
+  # sorted by the German rules
+  column {
+    name      = "customer_name"
+    type      = "STRING"
+    collation = "de"
+  }
+
+  primary_key = ["id"]
+
+  # spreads the inserts of sequential keys over several ranges
+  primary_key_bucket_count = 8
+
+  check {
+    name       = "positive_quantity"
+    expression = "quantity > 0"
+  }
+
+  unique {
+    name    = "orders_reference_key"
+    columns = ["reference"]
+  }
+
+  index {
+    name         = "orders_updated_at_idx"
+    columns      = ["updated_at"]
+    storing      = ["total"]
+    bucket_count = 4
+  }
+
+  # searches the references by similarity
+  index {
+    name           = "orders_reference_trgm_idx"
+    columns        = ["reference"]
+    type           = "INVERTED"
+    operator_class = "gin_trgm_ops"
+  }
+
+  # looks the orders up regardless of the case of their references
+  index {
+    name        = "orders_reference_lower_idx"
+    expressions = ["lower(reference)"]
+  }
+
+  local_port = "26268"
+}
@@ -0,0 +1,2 @@
+# database/schema/table
+terraform import cockroach_table_audit.payments example_database/public/payments
@@ -0,0 +1,5 @@
+resource "cockroach_table_audit" "payments" {
+  database   = cockroach_database.example.name
+  table      = "payments"
+  local_port = "26284"
+}
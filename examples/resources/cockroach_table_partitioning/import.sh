@@ -0,0 +1,2 @@
+# database/schema/table[@index]
+terraform import cockroach_table_partitioning.events_archive example_database/public/events@events_created_at_idx
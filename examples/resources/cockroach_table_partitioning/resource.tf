@@ -0,0 +1,60 @@
+resource "cockroach_table_partitioning" "users_by_region" {
+  database = cockroach_database.example.name
+  table    = "users"
+  by       = "LIST"
+  columns  = ["region"]
+
+  partition {
+    name   = "us"
+    values = ["'us-east'", "'us-west'"]
+
+    zone_config = {
+      constraints       = "[+region=us-east1]"
+      lease_preferences = "[[+region=us-east1]]"
+    }
+  }
+
+  partition {
+    name   = "eu"
+    values = ["'eu-west'"]
+
+    zone_config = {
+      constraints = "[+region=europe-west1]"
+    }
+  }
+
+  partition {
+    name   = "other"
+    values = ["DEFAULT"]
+  }
+
+  local_port = "26270"
+}
+
+resource "cockroach_table_partitioning" "events_archive" {
+  database = cockroach_database.example.name
+  table    = "events"
+  index    = "events_created_at_idx"
+  by       = "RANGE"
+  columns  = ["created_at"]
+
+  partition {
+    name = "archive"
+    from = "MINVALUE"
+    to   = "'2024-01-01'"
+
+    zone_config = {
+      num_replicas    = "3"
+      constraints     = "[+storage=hdd]"
+      "gc.ttlseconds" = "86400"
+    }
+  }
+
+  partition {
+    name = "recent"
+    from = "'2024-01-01'"
+    to   = "MAXVALUE"
+  }
+
+  local_port = "26271"
+}
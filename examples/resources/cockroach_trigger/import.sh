@@ -0,0 +1,2 @@
+# database/schema/table/name
+terraform import cockroach_trigger.orders_audit example_database/public/orders/orders_audit
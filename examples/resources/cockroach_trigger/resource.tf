@@ -0,0 +1,14 @@
+resource "cockroach_trigger" "orders_audit" {
+  database = cockroach_database.example.name
+  table    = "orders"
+  name     = "orders_audit"
+
+  timing = "AFTER"
+  events = ["INSERT", "UPDATE"]
+  when   = "NEW.status <> 'draft'"
+
+  function  = "audit_changes"
+  arguments = ["orders"]
+
+  local_port = "26274"
+}
@@ -0,0 +1,5 @@
+# the id has the format database/schema/table
+terraform import cockroach_ttl_schedule.sessions app/public/sessions
+
+# the id of the resource controlling every table is ttl
+terraform import cockroach_ttl_schedule.all ttl
@@ -0,0 +1,13 @@
+# the TTL deletions of the table are paused during a backfill
+resource "cockroach_ttl_schedule" "sessions" {
+  database   = "app"
+  table      = "sessions"
+  paused     = true
+  cron       = "@daily"
+  local_port = "26257"
+}
+
+# the TTL jobs of every table
+resource "cockroach_ttl_schedule" "all" {
+  paused = false
+}
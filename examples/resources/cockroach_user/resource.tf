@@ -5,3 +5,25 @@ resource "cockroach_user" "example" {
   is_admin   = false
   local_port = "26257"
 }
+
+# a service authenticating with certificates issued for its own subject
+resource "cockroach_user" "service" {
+  username   = "billing"
+  password   = "billing_password"
+  subject    = "CN=billing,OU=services,O=Example"
+  local_port = "26257"
+
+  # the tables created by the service are kept when it is destroyed
+  reassign_owned_to = "billing_owner"
+  drop_owned        = true
+}
+
+# an analyst whose options are kept as configured, e.g. if one is granted by
+# hand, and whose password expires
+resource "cockroach_user" "analyst" {
+  username     = "analyst"
+  password     = "analyst_password"
+  role_options = ["VIEWACTIVITY", "CREATEDB"]
+  valid_until  = "2030-01-01T00:00:00Z"
+  local_port   = "26257"
+}
@@ -0,0 +1,2 @@
+# the id is always user_audit_config
+terraform import cockroach_user_audit_config.example user_audit_config
@@ -0,0 +1,18 @@
+# audits the statements of the admins and the contractors, but not of the
+# other users
+resource "cockroach_user_audit_config" "example" {
+  rule {
+    role = "admin"
+  }
+
+  rule {
+    role = "contractors"
+  }
+
+  rule {
+    role       = "ALL"
+    statements = "NONE"
+  }
+
+  local_port = "26285"
+}
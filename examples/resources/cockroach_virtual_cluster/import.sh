@@ -0,0 +1,2 @@
+# name
+terraform import cockroach_virtual_cluster.analytics analytics
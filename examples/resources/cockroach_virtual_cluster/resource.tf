@@ -0,0 +1,5 @@
+resource "cockroach_virtual_cluster" "analytics" {
+  name         = "analytics"
+  service_mode = "shared"
+  local_port   = "26280"
+}
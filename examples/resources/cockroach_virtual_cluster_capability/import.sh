@@ -0,0 +1,2 @@
+# virtual_cluster/capability
+terraform import cockroach_virtual_cluster_capability.analytics_split analytics/can_admin_split
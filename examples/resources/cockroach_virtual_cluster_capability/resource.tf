@@ -0,0 +1,5 @@
+resource "cockroach_virtual_cluster_capability" "analytics_split" {
+  virtual_cluster = cockroach_virtual_cluster.analytics.name
+  capability      = "can_admin_split"
+  local_port      = "26281"
+}
@@ -0,0 +1,12 @@
+# managed through the provider of the standby cluster
+resource "cockroach_virtual_cluster_replication" "main" {
+  name                   = "main"
+  source_virtual_cluster = "main"
+  primary_connection     = "external://primary"
+  retention              = "24h"
+
+  # set to "latest" to fail over to the standby cluster
+  # cutover_to = "latest"
+
+  local_port = "26282"
+}
@@ -0,0 +1,19 @@
+resource "cockroach_wait_for_cluster" "example" {
+  expected_nodes = 3
+  local_port     = "26267"
+
+  triggers = {
+    chart_version = helm_release.cockroachdb.version
+  }
+
+  timeouts {
+    create = "15m"
+  }
+}
+
+resource "cockroach_database" "example" {
+  name       = "example_database"
+  local_port = "26258"
+
+  depends_on = [cockroach_wait_for_cluster.example]
+}
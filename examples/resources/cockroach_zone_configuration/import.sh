@@ -0,0 +1,2 @@
+# the id is the name of the range
+terraform import cockroach_zone_configuration.meta meta
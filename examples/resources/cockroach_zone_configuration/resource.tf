@@ -0,0 +1,17 @@
+resource "cockroach_zone_configuration" "meta" {
+  range = "meta"
+
+  config = {
+    num_replicas    = "5"
+    "gc.ttlseconds" = "3600"
+  }
+}
+
+resource "cockroach_zone_configuration" "liveness" {
+  range = "liveness"
+
+  config = {
+    num_replicas = "5"
+    constraints  = "[+region=us-east1]"
+  }
+}
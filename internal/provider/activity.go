@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// providerActivity counts what the provider did during a Terraform command,
+// logged as a summary when Terraform shuts the provider down once done with
+// it, e.g. at the end of an apply, to help diagnosing slow pipelines.
+type providerActivity struct {
+	statements int64
+	retries    int64
+	// schemaChangeTime is the time spent running the schema changes, which
+	// wait for their jobs to complete
+	schemaChangeTime int64
+	reconnects       int64
+
+	mu sync.Mutex
+	// forwarded are the keys of the port-forwards established so far, one
+	// established again being a reconnect
+	forwarded map[string]bool
+}
+
+// activity is the activity of the provider process, of all its clusters.
+var activity = &providerActivity{forwarded: map[string]bool{}}
+
+// schemaChangeRegexp matches the statements running schema changes.
+var schemaChangeRegexp = regexp.MustCompile(`(?i)^\s*(CREATE|ALTER|DROP|RENAME|TRUNCATE|COMMENT ON)\b`)
+
+// Log implements pgx.Logger, counting and tracing the statements run on the
+// connections.
+func (a *providerActivity) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	if msg != "Exec" && msg != "Query" {
+		return
+	}
+	atomic.AddInt64(&a.statements, 1)
+	traceStatement(ctx, msg, data)
+
+	sql, _ := data["sql"].(string)
+	if elapsed, ok := data["time"].(time.Duration); ok && schemaChangeRegexp.MatchString(sql) {
+		atomic.AddInt64(&a.schemaChangeTime, int64(elapsed))
+	}
+}
+
+func (a *providerActivity) retried() {
+	atomic.AddInt64(&a.retries, 1)
+}
+
+func (a *providerActivity) portForwarded(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.forwarded[key] {
+		a.reconnects++
+		return
+	}
+	a.forwarded[key] = true
+}
+
+// summary returns the summary of the activity as key=value pairs.
+func (a *providerActivity) summary() string {
+	a.mu.Lock()
+	reconnects := a.reconnects
+	a.mu.Unlock()
+
+	return fmt.Sprintf("statements=%d retries=%d schema_change_time=%s port_forward_reconnects=%d",
+		atomic.LoadInt64(&a.statements), atomic.LoadInt64(&a.retries),
+		time.Duration(atomic.LoadInt64(&a.schemaChangeTime)).Round(time.Millisecond), reconnects)
+}
+
+// connectDNS connects to the dns, counting and tracing the statements of the
+// connection in the activity of the provider.
+func connectDNS(ctx context.Context, dns string) (*pgx.Conn, error) {
+	config, err := pgx.ParseConfig(dns)
+	if err != nil {
+		return nil, err
+	}
+	config.Logger = activity
+	config.LogLevel = pgx.LogLevelInfo
+
+	return pgx.ConnectConfig(ctx, config)
+}
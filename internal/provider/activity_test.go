@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func TestProviderActivity(t *testing.T) {
+	a := &providerActivity{forwarded: map[string]bool{}}
+	ctx := context.Background()
+
+	a.Log(ctx, pgx.LogLevelInfo, "Exec", map[string]interface{}{"sql": `ALTER TABLE t ADD COLUMN c INT8`, "time": 2 * time.Second})
+	a.Log(ctx, pgx.LogLevelInfo, "Query", map[string]interface{}{"sql": `SELECT 1`, "time": time.Second})
+	a.Log(ctx, pgx.LogLevelError, "Exec", map[string]interface{}{"sql": `DROP TABLE t`})
+	a.Log(ctx, pgx.LogLevelInfo, "closed connection", nil)
+	a.retried()
+	a.portForwarded("default/cockroachdb-public:26257")
+	a.portForwarded("default/cockroachdb-public:26257")
+	a.portForwarded("other/cockroachdb-public:26257")
+
+	expected := "statements=3 retries=1 schema_change_time=2s port_forward_reconnects=1"
+	if summary := a.summary(); summary != expected {
+		t.Errorf("unexpected summary %q, expected %q", summary, expected)
+	}
+}
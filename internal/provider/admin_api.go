@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The endpoints of the HTTP API read by the data sources. The /_status ones
+// are the endpoints of the DB Console, encoding the 64 bit integers as
+// strings, the /api/v2 ones being the documented API.
+const (
+	httpAPINodesPath         = "/api/v2/nodes/"
+	httpAPIProblemRangesPath = "/_status/problemranges"
+	httpAPIHealthPath        = "/health"
+	httpAPIVarsPath          = "/_status/vars"
+
+	httpAPINodesPageSize = 100
+)
+
+// livenessStatuses are the names of the NodeLivenessStatus values, lowercase
+// without the NODE_STATUS_ prefix.
+var livenessStatuses = []string{"unknown", "dead", "unavailable", "live", "decommissioning", "decommissioned", "draining"}
+
+// livenessStatus is the liveness of a node, encoded as the number or the name
+// of the enum value.
+type livenessStatus string
+
+func (s *livenessStatus) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if i, err := strconv.Atoi(value); err == nil {
+		if i < 0 || i >= len(livenessStatuses) {
+			return fmt.Errorf("unknown liveness status %d", i)
+		}
+		*s = livenessStatus(livenessStatuses[i])
+		return nil
+	}
+
+	*s = livenessStatus(strings.ToLower(strings.TrimPrefix(value, "NODE_STATUS_")))
+	return nil
+}
+
+// jsonInt64 is a 64 bit integer, encoded as a number or a string.
+type jsonInt64 int64
+
+func (i *jsonInt64) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = jsonInt64(value)
+
+	return nil
+}
+
+type httpAPIAddress struct {
+	Address string `json:"address_field"`
+}
+
+type httpAPINode struct {
+	NodeID     int64          `json:"node_id"`
+	Address    httpAPIAddress `json:"address"`
+	SQLAddress httpAPIAddress `json:"sql_address"`
+	Locality   struct {
+		Tiers []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"tiers"`
+	} `json:"locality"`
+	BuildTag          string             `json:"build_tag"`
+	ClusterName       string             `json:"cluster_name"`
+	StartedAt         jsonInt64          `json:"started_at"`
+	UpdatedAt         jsonInt64          `json:"updated_at"`
+	NumCPUs           int64              `json:"num_cpus"`
+	TotalSystemMemory jsonInt64          `json:"total_system_memory"`
+	LivenessStatus    livenessStatus     `json:"liveness_status"`
+	Metrics           map[string]float64 `json:"metrics"`
+}
+
+// locality returns the locality of the node, as set with the --locality flag.
+func (n httpAPINode) locality() string {
+	tiers := make([]string, len(n.Locality.Tiers))
+	for i, tier := range n.Locality.Tiers {
+		tiers[i] = tier.Key + "=" + tier.Value
+	}
+
+	return strings.Join(tiers, ",")
+}
+
+// listNodes returns the nodes of the cluster, sorted by id.
+func (c *adminClient) listNodes(ctx context.Context) ([]httpAPINode, error) {
+	var nodes []httpAPINode
+	for offset := 0; ; {
+		var page struct {
+			Nodes []httpAPINode `json:"nodes"`
+			Next  int           `json:"next"`
+		}
+		query := url.Values{"limit": {strconv.Itoa(httpAPINodesPageSize)}, "offset": {strconv.Itoa(offset)}}
+		if err := c.get(ctx, httpAPINodesPath, query, &page); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, page.Nodes...)
+
+		if page.Next <= offset || len(page.Nodes) == 0 {
+			break
+		}
+		offset = page.Next
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+
+	return nodes, nil
+}
+
+// httpAPIProblems are the problem ranges reported by a node.
+type httpAPIProblems struct {
+	ErrorMessage             string      `json:"error_message"`
+	Unavailable              []jsonInt64 `json:"unavailable_range_ids"`
+	RaftLeaderNotLeaseHolder []jsonInt64 `json:"raft_leader_not_lease_holder_range_ids"`
+	NoRaftLeader             []jsonInt64 `json:"no_raft_leader_range_ids"`
+	NoLease                  []jsonInt64 `json:"no_lease_range_ids"`
+	Underreplicated          []jsonInt64 `json:"underreplicated_range_ids"`
+	Overreplicated           []jsonInt64 `json:"overreplicated_range_ids"`
+	QuiescentEqualsTicking   []jsonInt64 `json:"quiescent_equals_ticking_range_ids"`
+	RaftLogTooLarge          []jsonInt64 `json:"raft_log_too_large_range_ids"`
+	CircuitBreakerError      []jsonInt64 `json:"circuit_breaker_error_range_ids"`
+}
+
+// problemRanges returns the problem ranges by node id, of every node or of
+// the node when not 0.
+func (c *adminClient) problemRanges(ctx context.Context, nodeID int) (map[string]httpAPIProblems, error) {
+	query := url.Values{}
+	if nodeID != 0 {
+		query.Set("node_id", strconv.Itoa(nodeID))
+	}
+
+	var response struct {
+		ProblemsByNodeID map[string]httpAPIProblems `json:"problems_by_node_id"`
+	}
+	if err := c.get(ctx, httpAPIProblemRangesPath, query, &response); err != nil {
+		return nil, err
+	}
+
+	return response.ProblemsByNodeID, nil
+}
+
+// ready returns whether the node served is ready to accept SQL connections,
+// it isn't when draining or not live.
+func (c *adminClient) ready(ctx context.Context) (bool, error) {
+	err := c.get(ctx, httpAPIHealthPath, url.Values{"ready": {"1"}}, nil)
+	if err, ok := err.(*httpAPIError); ok && err.statusCode == http.StatusServiceUnavailable {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// vars returns the metrics of the node served, in the Prometheus format, by
+// name, the maximum of the series of a metric being kept, e.g. of its stores.
+func (c *adminClient) vars(ctx context.Context) (map[string]float64, error) {
+	var text []byte
+	if err := c.get(ctx, httpAPIVarsPath, nil, &text); err != nil {
+		return nil, err
+	}
+
+	return parsePrometheusVars(string(text)), nil
+}
+
+func parsePrometheusVars(text string) map[string]float64 {
+	vars := map[string]float64{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// name{label="value",...} value [timestamp]
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		rest := line[len(name):]
+		if i := strings.LastIndex(rest, "}"); i >= 0 {
+			rest = rest[i+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		if current, ok := vars[name]; !ok || value > current {
+			vars[name] = value
+		}
+	}
+
+	return vars
+}
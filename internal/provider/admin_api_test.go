@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminClientListNodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("offset") {
+		case "0":
+			fmt.Fprint(w, `{"nodes": [{"node_id": 2, "liveness_status": 1, "started_at": "1700000000000000000",
+				"locality": {"tiers": [{"key": "region", "value": "us-east1"}, {"key": "zone", "value": "b"}]}}], "next": 1}`)
+		default:
+			fmt.Fprint(w, `{"nodes": [{"node_id": 1, "liveness_status": "NODE_STATUS_LIVE", "started_at": 1700000000000000000}]}`)
+		}
+	}))
+	defer server.Close()
+
+	nodes, err := newAdminClient(server.URL, nil).listNodes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].NodeID != 1 || nodes[1].NodeID != 2 {
+		t.Fatalf("unexpected nodes %v", nodes)
+	}
+	if nodes[0].LivenessStatus != "live" || nodes[1].LivenessStatus != "dead" {
+		t.Errorf("unexpected liveness statuses %q, %q", nodes[0].LivenessStatus, nodes[1].LivenessStatus)
+	}
+	if nodes[0].StartedAt != 1700000000000000000 || nodes[1].StartedAt != nodes[0].StartedAt {
+		t.Errorf("unexpected start times %d, %d", nodes[0].StartedAt, nodes[1].StartedAt)
+	}
+	if locality := nodes[1].locality(); locality != "region=us-east1,zone=b" {
+		t.Errorf("unexpected locality %q", locality)
+	}
+}
+
+func TestAdminClientReady(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := newAdminClient(server.URL, nil)
+	for _, c := range []struct {
+		status   int
+		expected bool
+		err      bool
+	}{
+		{http.StatusOK, true, false},
+		{http.StatusServiceUnavailable, false, false},
+		{http.StatusInternalServerError, false, true},
+	} {
+		status = c.status
+		ready, err := client.ready(context.Background())
+		if ready != c.expected || (err != nil) != c.err {
+			t.Errorf("status %d: unexpected ready %v, error %v", c.status, ready, err)
+		}
+	}
+}
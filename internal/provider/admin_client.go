@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// The HTTP API of the nodes, served on the port of the DB Console, exposes
+// what isn't cleanly available over SQL, e.g. the decommissioning status of
+// the nodes. The provider logs in with the user and password of the provider,
+// the session being sent with every request.
+const (
+	httpAPILoginPath     = "/api/v2/login/"
+	httpAPILogoutPath    = "/api/v2/logout/"
+	httpAPISessionName   = "X-Cockroach-API-Session"
+	httpAPISessionCookie = "session"
+
+	httpAPIDefaultRemotePort = "8080"
+	httpAPITimeout           = 30 * time.Second
+)
+
+// httpAPIConfig is the configuration of the HTTP API of the provider.
+type httpAPIConfig struct {
+	// url is the URL of the HTTP API, the scheme and host of the local port
+	// when it is port-forwarded
+	url *url.URL
+	// remotePort is the port of the pods port-forwarded to when a kube config
+	// is set
+	remotePort string
+	tlsConfig  *tls.Config
+}
+
+func newHTTPAPIConfig(rawURL string, remotePort string, insecure bool, caCert string, serverName string) (*httpAPIConfig, error) {
+	c := &httpAPIConfig{remotePort: remotePort}
+
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP API url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid HTTP API url %q, expected an http or https url", rawURL)
+		}
+		c.url = u
+	} else if insecure {
+		c.url = &url.URL{Scheme: "http"}
+	} else {
+		c.url = &url.URL{Scheme: "https"}
+	}
+
+	if c.url.Scheme == "https" {
+		c.tlsConfig = &tls.Config{ServerName: serverName}
+		if caCert != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(caCert)) {
+				return nil, fmt.Errorf("failed to parse the CA certificate of the HTTP API")
+			}
+			c.tlsConfig.RootCAs = pool
+		}
+	}
+
+	return c, nil
+}
+
+// adminClient is a client of the HTTP API logged in as the user of the
+// provider.
+type adminClient struct {
+	baseURL string
+	client  *http.Client
+	session string
+}
+
+// openAdminClient port-forwards to the HTTP port of the cluster when a kube
+// config is set and logs in. The returned function logs out and terminates the
+// port-forward, it must be called once the caller is done.
+func openAdminClient(ctx context.Context, meta interface{}) (*adminClient, func(), diag.Diagnostics) {
+	cockroachClient := meta.(*cockroachClient)
+	if diags := cockroachClient.resolveConnection(ctx); diags.HasError() {
+		return nil, nil, diags
+	}
+
+	config := cockroachClient.httpAPI
+	if config == nil {
+		return nil, nil, diag.Errorf("the http_api block of the provider must be set to use the HTTP API")
+	}
+	if cockroachClient.password == "" {
+		return nil, nil, diag.Errorf("the HTTP API requires the password of the user of the provider")
+	}
+
+	// stopCh control the port forwarding lifecycle. When it gets closed the
+	// port forward will terminate
+	stopCh := make(chan struct{}, 1)
+
+	baseURL := *config.url
+	if cockroachClient.kubeConn.kubeConfig != nil {
+		kubeConn := cockroachClient.kubeConn
+		kubeConn.remotePort = config.remotePort
+
+		// the local port doesn't matter to the callers of the HTTP API
+		forwardedPort, err := sharedPortForwardTo(ctx, kubeConn, "0", stopCh)
+		if err != nil {
+			close(stopCh)
+			return nil, nil, diag.FromErr(err)
+		}
+		baseURL.Host = kubeConn.forwardHost() + ":" + forwardedPort
+	} else if baseURL.Host == "" {
+		close(stopCh)
+		return nil, nil, diag.Errorf("the url of the http_api block is required when no kube config is set")
+	}
+
+	client := newAdminClient(strings.TrimSuffix(baseURL.String(), "/"), config.tlsConfig)
+	if err := client.login(ctx, cockroachClient.username, cockroachClient.password); err != nil {
+		close(stopCh)
+		return nil, nil, diag.FromErr(err)
+	}
+
+	closeClient := func() {
+		if err := client.logout(ctx); err != nil {
+			logError("failed to log out of the HTTP API: %v", err)
+		}
+		client.client.CloseIdleConnections()
+		close(stopCh)
+	}
+
+	return client, closeClient, nil
+}
+
+func newAdminClient(baseURL string, tlsConfig *tls.Config) *adminClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &adminClient{
+		baseURL: baseURL,
+		client:  &http.Client{Transport: transport, Timeout: httpAPITimeout},
+	}
+}
+
+func (c *adminClient) login(ctx context.Context, username string, password string) error {
+	form := url.Values{"username": {username}, "password": {password}}
+
+	var response struct {
+		Session string `json:"session"`
+	}
+	if err := c.do(ctx, http.MethodPost, httpAPILoginPath, strings.NewReader(form.Encode()), &response); err != nil {
+		return fmt.Errorf("failed to log in to the HTTP API: %w", err)
+	}
+	if response.Session == "" {
+		return fmt.Errorf("failed to log in to the HTTP API: no session returned")
+	}
+	c.session = response.Session
+
+	return nil
+}
+
+func (c *adminClient) logout(ctx context.Context) error {
+	if c.session == "" {
+		return nil
+	}
+
+	err := c.do(ctx, http.MethodPost, httpAPILogoutPath, nil, nil)
+	c.session = ""
+
+	return err
+}
+
+// get decodes the JSON response of the path of the HTTP API into out, or
+// reads it as is into a *[]byte.
+func (c *adminClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *adminClient) do(ctx context.Context, method string, path string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if c.session != "" {
+		// the endpoints of the DB Console authenticate with the cookie
+		req.Header.Set(httpAPISessionName, c.session)
+		req.AddCookie(&http.Cookie{Name: httpAPISessionCookie, Value: c.session})
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &httpAPIError{
+			method:     method,
+			path:       path,
+			statusCode: resp.StatusCode,
+			status:     resp.Status,
+			message:    strings.TrimSpace(string(message)),
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if text, ok := out.(*[]byte); ok {
+		*text, err = io.ReadAll(resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: failed to decode the response: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// httpAPIError is the error of a request the HTTP API responded to with an
+// error status.
+type httpAPIError struct {
+	method     string
+	path       string
+	statusCode int
+	status     string
+	message    string
+}
+
+func (e *httpAPIError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.method, e.path, e.status, e.message)
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAdminClient(t *testing.T) {
+	loggedOut := false
+	mux := http.NewServeMux()
+	mux.HandleFunc(httpAPILoginPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("username") != "root" || r.FormValue("password") != "secret" {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"session": "token"})
+	})
+	mux.HandleFunc(httpAPILogoutPath, func(w http.ResponseWriter, r *http.Request) {
+		loggedOut = r.Header.Get(httpAPISessionName) == "token"
+	})
+	mux.HandleFunc("/api/v2/nodes/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(httpAPISessionName) != "token" {
+			http.Error(w, "not logged in", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"limit": r.URL.Query().Get("limit")})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := newAdminClient(server.URL, nil)
+
+	if err := client.login(ctx, "root", "wrong"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+	if err := client.login(ctx, "root", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	var nodes struct {
+		Limit string `json:"limit"`
+	}
+	if err := client.get(ctx, "/api/v2/nodes/", url.Values{"limit": {"10"}}, &nodes); err != nil {
+		t.Fatal(err)
+	}
+	if nodes.Limit != "10" {
+		t.Errorf("unexpected response %v", nodes)
+	}
+
+	if err := client.logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !loggedOut {
+		t.Error("expected the session to be logged out")
+	}
+	if err := client.get(ctx, "/api/v2/nodes/", nil, &nodes); err == nil {
+		t.Error("expected an error once logged out")
+	}
+}
+
+func TestNewHTTPAPIConfig(t *testing.T) {
+	config, err := newHTTPAPIConfig("", httpAPIDefaultRemotePort, true, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.url.Scheme != "http" || config.tlsConfig != nil {
+		t.Errorf("unexpected insecure config %v", config)
+	}
+
+	config, err = newHTTPAPIConfig("https://cockroachdb:8080", httpAPIDefaultRemotePort, false, "", "cockroachdb-public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.url.Host != "cockroachdb:8080" || config.tlsConfig.ServerName != "cockroachdb-public" {
+		t.Errorf("unexpected config %v", config)
+	}
+
+	if _, err := newHTTPAPIConfig("cockroachdb:8080", httpAPIDefaultRemotePort, false, "", ""); err == nil {
+		t.Error("expected an error for an url without scheme")
+	}
+	if _, err := newHTTPAPIConfig("", httpAPIDefaultRemotePort, false, "not a certificate", ""); err == nil {
+		t.Error("expected an error for an invalid CA certificate")
+	}
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+var certificateGVR = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+// certManagerConfig is the cert-manager Certificate issuing the client
+// certificate of the provider.
+type certManagerConfig struct {
+	issuerName  string
+	issuerKind  string
+	issuerGroup string
+	secretName  string
+	duration    string
+	waitTimeout time.Duration
+}
+
+// certManagerCertificate returns the Certificate issuing the client
+// certificate of the user, following the conventions of `cockroach cert`.
+func certManagerCertificate(c certManagerConfig, namespace string, username string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"secretName": c.secretName,
+		"commonName": username,
+		"subject": map[string]interface{}{
+			"organizations": []interface{}{certOrganization},
+		},
+		"usages": []interface{}{"digital signature", "key encipherment", "client auth"},
+		"privateKey": map[string]interface{}{
+			"algorithm": "RSA",
+			"size":      int64(2048),
+		},
+		"issuerRef": map[string]interface{}{
+			"name":  c.issuerName,
+			"kind":  c.issuerKind,
+			"group": c.issuerGroup,
+		},
+	}
+	if c.duration != "" {
+		spec["duration"] = c.duration
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      c.secretName,
+				"namespace": namespace,
+				"labels": map[string]interface{}{
+					secretManagedByLabel: secretManagedByProvider,
+				},
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// certificateReady returns whether the Certificate has the Ready condition.
+func certificateReady(certificate *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Ready" && condition["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestClientCert applies the Certificate of the user, waits for
+// cert-manager to issue it and writes it to a temporary directory. It returns
+// the connection parameters authenticating with the certificate.
+func requestClientCert(ctx context.Context, kubeConn kubeConn, c certManagerConfig, username string) (string, error) {
+	client, err := dynamic.NewForConfig(kubeConn.kubeConfig)
+	if err != nil {
+		return "", err
+	}
+	certificates := client.Resource(certificateGVR).Namespace(kubeConn.nameSpace)
+	certificate := certManagerCertificate(c, kubeConn.nameSpace, username)
+
+	var existing *unstructured.Unstructured
+	err = retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		existing, err = certificates.Get(ctx, c.secretName, metav1.GetOptions{})
+		return err
+	})
+	switch {
+	case apierrors.IsNotFound(err):
+		logInfo("creating Certificate %s/%s", kubeConn.nameSpace, c.secretName)
+		err = retryKubeAPI(kubeConn.maxRetries, func() error {
+			_, err := certificates.Create(ctx, certificate, metav1.CreateOptions{})
+			return err
+		})
+	case err == nil:
+		logInfo("updating Certificate %s/%s", kubeConn.nameSpace, c.secretName)
+		certificate.SetResourceVersion(existing.GetResourceVersion())
+		err = retryKubeAPI(kubeConn.maxRetries, func() error {
+			_, err := certificates.Update(ctx, certificate, metav1.UpdateOptions{})
+			return err
+		})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to apply Certificate %s/%s: %w", kubeConn.nameSpace, c.secretName, err)
+	}
+
+	err = wait.PollImmediate(2*time.Second, c.waitTimeout, func() (bool, error) {
+		certificate, err := certificates.Get(ctx, c.secretName, metav1.GetOptions{})
+		if err != nil {
+			logDebug("failed to get Certificate %s/%s: %v", kubeConn.nameSpace, c.secretName, err)
+			return false, nil
+		}
+
+		return certificateReady(certificate), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("Certificate %s/%s not issued: %w", kubeConn.nameSpace, c.secretName, err)
+	}
+
+	var secret *v1.Secret
+	err = retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		secret, err = kubeConn.kubeClient.CoreV1().Secrets(kubeConn.nameSpace).Get(ctx, c.secretName, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Secret %s/%s: %w", kubeConn.nameSpace, c.secretName, err)
+	}
+
+	return writeClientCert(secret)
+}
+
+// writeClientCert writes the certificate of the Secret to a temporary
+// directory only readable by the current user, removed when the provider
+// shuts down, and returns the connection parameters using it.
+func writeClientCert(secret *v1.Secret) (string, error) {
+	dir, err := tempDirs.create("cockroach-certs-")
+	if err != nil {
+		return "", err
+	}
+
+	files := map[string]string{
+		secretCACertKey:     "ca.crt",
+		v1.TLSCertKey:       "client.crt",
+		v1.TLSPrivateKeyKey: "client.key",
+	}
+	paths := map[string]string{}
+	for key, name := range files {
+		data, ok := secret.Data[key]
+		if !ok {
+			return "", fmt.Errorf("Secret %s/%s has no %s", secret.Namespace, secret.Name, key)
+		}
+
+		paths[key] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[key], data, 0600); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("sslmode=verify-ca&sslrootcert=%s&sslcert=%s&sslkey=%s",
+		url.QueryEscape(paths[secretCACertKey]), url.QueryEscape(paths[v1.TLSCertKey]), url.QueryEscape(paths[v1.TLSPrivateKeyKey])), nil
+}
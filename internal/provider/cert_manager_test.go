@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCertManagerCertificate(t *testing.T) {
+	certificate := certManagerCertificate(certManagerConfig{
+		issuerName:  "cockroachdb-ca",
+		issuerKind:  "ClusterIssuer",
+		issuerGroup: "cert-manager.io",
+		secretName:  "cockroachdb-client-terraform",
+		duration:    "720h",
+	}, "cockroachdb", "terraform")
+
+	if certificate.GetName() != "cockroachdb-client-terraform" || certificate.GetNamespace() != "cockroachdb" {
+		t.Errorf("unexpected Certificate %s/%s", certificate.GetNamespace(), certificate.GetName())
+	}
+
+	commonName, _, _ := unstructured.NestedString(certificate.Object, "spec", "commonName")
+	if commonName != "terraform" {
+		t.Errorf("expected common name terraform, got %s", commonName)
+	}
+	issuerKind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind")
+	if issuerKind != "ClusterIssuer" {
+		t.Errorf("expected issuer kind ClusterIssuer, got %s", issuerKind)
+	}
+	duration, _, _ := unstructured.NestedString(certificate.Object, "spec", "duration")
+	if duration != "720h" {
+		t.Errorf("expected duration 720h, got %s", duration)
+	}
+}
+
+func TestCertificateReady(t *testing.T) {
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if certificateReady(certificate) {
+		t.Errorf("expected a Certificate without status not to be ready")
+	}
+
+	certificate.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Issuing", "status": "True"},
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		},
+	}
+	if !certificateReady(certificate) {
+		t.Errorf("expected the Certificate to be ready")
+	}
+}
+
+func TestWriteClientCert(t *testing.T) {
+	secret := &v1.Secret{
+		Data: map[string][]byte{
+			"ca.crt":  []byte("ca"),
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		},
+	}
+
+	params, err := writeClientCert(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := url.ParseQuery(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.Get("sslmode") != "verify-ca" {
+		t.Errorf("expected sslmode verify-ca, got %s", values.Get("sslmode"))
+	}
+
+	key, err := os.ReadFile(values.Get("sslkey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != "key" {
+		t.Errorf("unexpected key %q", key)
+	}
+	info, err := os.Stat(values.Get("sslkey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected key mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// the key is removed when the provider shuts down
+	tempDirs.remove()
+	if _, err := os.Stat(values.Get("sslkey")); !os.IsNotExist(err) {
+		t.Errorf("expected the key to be removed, got %v", err)
+	}
+}
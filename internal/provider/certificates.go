@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	certRSABitsAttr       = "rsa_bits"
+	certLifetimeAttr      = "lifetime"
+	certPEMAttr           = "cert_pem"
+	certPrivateKeyPEMAttr = "private_key_pem"
+	certNotAfterAttr      = "not_after"
+	certCACertPEMAttr     = "ca_cert_pem"
+	certCAPrivateKeyAttr  = "ca_private_key_pem"
+)
+
+// The lifetimes used by `cockroach cert`.
+const (
+	defaultCALifetime   = "87840h"
+	defaultCertLifetime = "43800h"
+)
+
+// certOrganization is the organization of the certificates created by
+// `cockroach cert`.
+const certOrganization = "Cockroach"
+
+// certBackdate is how far in the past the certificates start being valid, to
+// tolerate clock skew between the nodes, as `cockroach cert` does.
+const certBackdate = time.Hour
+
+// certSchema returns the attributes shared by the certificate resources.
+func certSchema(lifetime string, signed bool) map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		certRSABitsAttr: {
+			Description:  "Size of the RSA key.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			Default:      2048,
+			ValidateFunc: validation.IntInSlice([]int{2048, 3072, 4096}),
+		},
+		certLifetimeAttr: {
+			Description:  fmt.Sprintf("Duration of the validity of the certificate. (default is %s, as `cockroach cert`)", lifetime),
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			Default:      lifetime,
+			ValidateFunc: validateDuration,
+		},
+		certPEMAttr: {
+			Description: "Certificate in PEM format.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		certPrivateKeyPEMAttr: {
+			Description: "Private key of the certificate in PEM format.",
+			Type:        schema.TypeString,
+			Computed:    true,
+			Sensitive:   true,
+		},
+		certNotAfterAttr: {
+			Description: "Expiration time of the certificate, in RFC 3339 format.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+	}
+
+	if signed {
+		s[certCACertPEMAttr] = &schema.Schema{
+			Description: "Certificate of the CA signing the certificate, in PEM format.",
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		}
+		s[certCAPrivateKeyAttr] = &schema.Schema{
+			Description: "Private key of the CA signing the certificate, in PEM format.",
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+			Sensitive:   true,
+		}
+	}
+
+	return s
+}
+
+// certTemplate returns the template of a certificate with the common name,
+// valid for the lifetime.
+func certTemplate(commonName string, lifetime time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{certOrganization},
+			CommonName:   commonName,
+		},
+		NotBefore:             now.Add(-certBackdate),
+		NotAfter:              now.Add(lifetime),
+		BasicConstraintsValid: true,
+	}, nil
+}
+
+// addHosts adds the hosts to the subject alternative names of the
+// certificate, as IP addresses or DNS names.
+func addHosts(template *x509.Certificate, hosts []string) {
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+}
+
+// parseCA parses the certificate and the private key of a CA.
+func parseCA(certPEM string, keyPEM string) (*x509.Certificate, crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("CA certificate is not a PEM encoded certificate")
+	}
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	if !caCert.IsCA {
+		return nil, nil, fmt.Errorf("certificate %s is not a CA", caCert.Subject)
+	}
+
+	block, _ = pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, nil, fmt.Errorf("CA private key is not PEM encoded")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported CA private key type %T", key)
+	}
+
+	return caCert, signer, nil
+}
+
+// issuedCert is a certificate and its private key in PEM format.
+type issuedCert struct {
+	certPEM  string
+	keyPEM   string
+	notAfter time.Time
+}
+
+// issueCert creates a key and the certificate from the template, signed by
+// the parent or self-signed when parent is nil. The certificate never
+// outlives its parent.
+func issueCert(template *x509.Certificate, bits int, parent *x509.Certificate, parentKey crypto.Signer) (*issuedCert, error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	} else if template.NotAfter.After(parent.NotAfter) {
+		template.NotAfter = parent.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return &issuedCert{
+		certPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:   string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		notAfter: template.NotAfter,
+	}, nil
+}
+
+// setIssuedCert stores the certificate in the resource.
+func setIssuedCert(d *schema.ResourceData, template *x509.Certificate, cert *issuedCert) error {
+	d.SetId(template.SerialNumber.Text(16))
+
+	if err := d.Set(certPEMAttr, cert.certPEM); err != nil {
+		return err
+	}
+	if err := d.Set(certPrivateKeyPEMAttr, cert.keyPEM); err != nil {
+		return err
+	}
+
+	return d.Set(certNotAfterAttr, cert.notAfter.UTC().Format(time.RFC3339))
+}
+
+// issueSignedCert issues the certificate of the resource from the template,
+// signed by its CA.
+func issueSignedCert(d *schema.ResourceData, template *x509.Certificate) error {
+	caCert, caKey, err := parseCA(d.Get(certCACertPEMAttr).(string), d.Get(certCAPrivateKeyAttr).(string))
+	if err != nil {
+		return err
+	}
+
+	cert, err := issueCert(template, d.Get(certRSABitsAttr).(int), caCert, caKey)
+	if err != nil {
+		return err
+	}
+
+	return setIssuedCert(d, template, cert)
+}
+
+// certLifetime returns the lifetime of the certificate of the resource.
+func certLifetime(d *schema.ResourceData) time.Duration {
+	// the value is validated by validateDuration
+	lifetime, _ := time.ParseDuration(d.Get(certLifetimeAttr).(string))
+	return lifetime
+}
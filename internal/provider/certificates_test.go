@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func parseTestCert(t *testing.T, certPEM string) *x509.Certificate {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		t.Fatalf("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return cert
+}
+
+func TestIssueCert(t *testing.T) {
+	caTemplate, err := certTemplate("Cockroach CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate.IsCA = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign
+	ca, err := issueCert(caTemplate, 2048, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert, caKey, err := parseCA(ca.certPEM, ca.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeTemplate, err := certTemplate("node", 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	addHosts(nodeTemplate, []string{"localhost", "127.0.0.1", "cockroachdb-public"})
+	node, err := issueCert(nodeTemplate, 2048, caCert, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeCert := parseTestCert(t, node.certPEM)
+	if nodeCert.Subject.CommonName != "node" || nodeCert.Subject.Organization[0] != certOrganization {
+		t.Errorf("unexpected subject %s", nodeCert.Subject)
+	}
+	if len(nodeCert.DNSNames) != 2 || len(nodeCert.IPAddresses) != 1 {
+		t.Errorf("unexpected SANs %v %v", nodeCert.DNSNames, nodeCert.IPAddresses)
+	}
+	if nodeCert.NotAfter.After(caCert.NotAfter) {
+		t.Errorf("node certificate expires after its CA")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	_, err = nodeCert.Verify(x509.VerifyOptions{
+		DNSName:   "cockroachdb-public",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		t.Errorf("failed to verify node certificate: %v", err)
+	}
+}
+
+func TestParseCANotCA(t *testing.T) {
+	template, err := certTemplate("root", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := issueCert(template, 2048, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := parseCA(cert.certPEM, cert.keyPEM); err == nil {
+		t.Errorf("expected an error for a certificate which isn't a CA")
+	}
+}
+
+func TestAccResourceCerts(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceCerts,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"cockroach_ca_cert.foo", "cert_pem"),
+					resource.TestCheckResourceAttrSet(
+						"cockroach_node_cert.foo", "cert_pem"),
+					resource.TestCheckResourceAttrSet(
+						"cockroach_client_cert.foo", "cert_pem"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceCerts = `
+resource "cockroach_ca_cert" "foo" {}
+
+resource "cockroach_node_cert" "foo" {
+  hosts              = ["localhost", "127.0.0.1"]
+  ca_cert_pem        = cockroach_ca_cert.foo.cert_pem
+  ca_private_key_pem = cockroach_ca_cert.foo.private_key_pem
+}
+
+resource "cockroach_client_cert" "foo" {
+  username           = "root"
+  ca_cert_pem        = cockroach_ca_cert.foo.cert_pem
+  ca_private_key_pem = cockroach_ca_cert.foo.private_key_pem
+}
+`
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The CockroachDB Cloud API returns the connection string and the CA
+// certificate of the serverless and dedicated clusters of the organization of
+// the API key, the provider connecting with them to manage the SQL objects of
+// the cluster the same way it does the self-hosted ones.
+const (
+	argCloud       = "cloud"
+	argAPIKey      = "api_key"
+	argAPIURL      = "api_url"
+	argClusterID   = "cluster_id"
+	argSQLDatabase = "database"
+
+	cloudAPIDefaultURL = "https://cockroachlabs.cloud"
+	cloudAPITimeout    = 30 * time.Second
+)
+
+// cloudSchema is the block configuring the connection to a CockroachDB Cloud
+// cluster.
+func cloudSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "CockroachDB Cloud cluster to connect to, its connection string and CA certificate being fetched from the Cloud API. The provider connects with its username and password, the dns and the kube config can't be set then",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				argAPIKey: {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "API key of a service account of the organization of the cluster, the COCKROACH_API_KEY environment variable when not set",
+					DefaultFunc: schema.EnvDefaultFunc("COCKROACH_API_KEY", nil),
+				},
+				argClusterID: {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Id of the cluster, as shown in the Cloud Console",
+				},
+				argSQLDatabase: {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Database of the connection string",
+					Default:     "defaultdb",
+				},
+				argAPIURL: {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "URL of the Cloud API",
+					Default:     cloudAPIDefaultURL,
+				},
+			},
+		},
+	}
+}
+
+// cloudConfig is the cluster of the cloud block.
+type cloudConfig struct {
+	apiURL    string
+	apiKey    string
+	clusterID string
+	database  string
+}
+
+// cloudAPIClient is a client of the Cloud API authenticated with the API key.
+type cloudAPIClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func newCloudAPIClient(apiURL string, apiKey string) *cloudAPIClient {
+	return &cloudAPIClient{
+		baseURL: strings.TrimSuffix(apiURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: cloudAPITimeout},
+	}
+}
+
+// get returns the body of the response to the GET request, failing when its
+// status isn't 200.
+func (c *cloudAPIClient) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Cloud API %s returned %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
+
+// cloudConnectionString returns the connection string of the user to the
+// cluster, verifying its certificate with the CA certificate of the cluster
+// written to a temporary directory. The other parameters of the connection
+// string, e.g. the routing id of the serverless clusters, are kept.
+func cloudConnectionString(ctx context.Context, c cloudConfig, username string, password string) (string, error) {
+	client := newCloudAPIClient(c.apiURL, c.apiKey)
+	clusterPath := "/api/v1/clusters/" + url.PathEscape(c.clusterID)
+
+	body, err := client.get(ctx, clusterPath+"/connection-string", url.Values{
+		"database": {c.database},
+		"sql_user": {username},
+		"os":       {"LINUX"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the connection string of cluster %s: %w", c.clusterID, err)
+	}
+	var connection struct {
+		ConnectionString string `json:"connection_string"`
+	}
+	if err := json.Unmarshal(body, &connection); err != nil {
+		return "", fmt.Errorf("failed to parse the connection string of cluster %s: %w", c.clusterID, err)
+	}
+
+	u, err := url.Parse(connection.ConnectionString)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid connection string %q of cluster %s", connection.ConnectionString, c.clusterID)
+	}
+
+	cert, err := client.get(ctx, clusterPath+"/cert", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the CA certificate of cluster %s: %w", c.clusterID, err)
+	}
+	// the certificate may be returned as a JSON string
+	var pem string
+	if err := json.Unmarshal(cert, &pem); err == nil {
+		cert = []byte(pem)
+	}
+
+	dir, err := tempDirs.create("cockroach-cloud-")
+	if err != nil {
+		return "", err
+	}
+	caPath := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caPath, cert, 0600); err != nil {
+		return "", err
+	}
+
+	u.User = url.UserPassword(username, password)
+	params := u.Query()
+	params.Set("sslmode", "verify-full")
+	params.Set("sslrootcert", caPath)
+	u.RawQuery = params.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The resources and data sources manage the objects of the cluster of the
+// provider, or of one of its named clusters when their cluster attribute is
+// set, so a single provider block manages a fleet of clusters. The functions
+// of the resources are given the client of their cluster as meta.
+//
+// A resource of a named cluster is imported with an id prefixed with the name
+// of the cluster and @, e.g. prod@<id>.
+
+const clusterImportSeparator = "@"
+
+// named returns the client of the named cluster, the client of the provider
+// when the name is empty.
+func (c *cockroachClient) named(name string) (*cockroachClient, error) {
+	if name == "" {
+		if !c.hasConnection() {
+			return nil, fmt.Errorf("the provider has no connection of its own, the %s attribute must be one of %s", argCluster, strings.Join(c.clusterNames(), ", "))
+		}
+		return c, nil
+	}
+
+	cluster, ok := c.clusters[name]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q is not configured in the clusters of the provider, expected one of %s", name, strings.Join(c.clusterNames(), ", "))
+	}
+
+	return cluster, nil
+}
+
+func (c *cockroachClient) clusterNames() []string {
+	names := make(map[string]interface{}, len(c.clusters))
+	for name := range c.clusters {
+		names[name] = nil
+	}
+
+	return sortedKeys(names)
+}
+
+// withNamedCluster adds the cluster attribute to the resource, or data source,
+// and gives its functions the client of the cluster. The fleet resources,
+// managing several clusters from their clusters attribute, are left as is.
+func withNamedCluster(r *schema.Resource) *schema.Resource {
+	if _, ok := r.Schema[argClusters]; ok {
+		return r
+	}
+
+	isDataSource := r.CreateContext == nil
+
+	r.Schema[argCluster] = &schema.Schema{
+		Description: "Name of the cluster, of the clusters of the provider, to manage the resource in, the cluster of the provider when not set.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    !isDataSource,
+	}
+	if isDataSource {
+		r.Schema[argCluster].Description = "Name of the cluster, of the clusters of the provider, to read, the cluster of the provider when not set."
+	}
+
+	r.CreateContext = clusterContextFunc(r.CreateContext)
+	r.ReadContext = clusterContextFunc(r.ReadContext)
+	r.UpdateContext = clusterContextFunc(r.UpdateContext)
+	r.DeleteContext = clusterContextFunc(r.DeleteContext)
+
+	if customizeDiff := r.CustomizeDiff; customizeDiff != nil {
+		r.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			client, err := meta.(*cockroachClient).named(d.Get(argCluster).(string))
+			if err != nil {
+				return err
+			}
+			return customizeDiff(ctx, d, client)
+		}
+	}
+
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		importer := r.Importer.StateContext
+		r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			name, id := splitClusterImportID(d.Id(), meta.(*cockroachClient))
+			client, err := meta.(*cockroachClient).named(name)
+			if err != nil {
+				return nil, err
+			}
+
+			d.SetId(id)
+			if err := d.Set(argCluster, name); err != nil {
+				return nil, err
+			}
+			return importer(ctx, d, client)
+		}
+	}
+
+	return r
+}
+
+func clusterContextFunc(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		client, err := meta.(*cockroachClient).named(d.Get(argCluster).(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		return f(ctx, d, client)
+	}
+}
+
+// splitClusterImportID returns the name of the cluster of an imported id and
+// the id of the resource, the name being empty unless the id is prefixed with
+// one of the named clusters.
+func splitClusterImportID(id string, c *cockroachClient) (string, string) {
+	parts := strings.SplitN(id, clusterImportSeparator, 2)
+	if len(parts) == 2 {
+		if _, ok := c.clusters[parts[0]]; ok {
+			return parts[0], parts[1]
+		}
+	}
+
+	return "", id
+}
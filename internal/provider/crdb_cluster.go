@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var crdbClusterGVR = schema.GroupVersionResource{
+	Group:    "crdb.cockroachlabs.com",
+	Version:  "v1alpha1",
+	Resource: "crdbclusters",
+}
+
+// crdbClusterConnection is the connection to a cluster deployed by the
+// CockroachDB operator, discovered from its CrdbCluster.
+type crdbClusterConnection struct {
+	serviceName string
+	sqlPort     string
+	tlsEnabled  bool
+	// clientSecret is the Secret holding the client certificate of root
+	clientSecret string
+}
+
+// crdbClusterConnectionOf returns the connection to the cluster of the
+// CrdbCluster, following the conventions of the operator: the public service
+// is <name>-public and the client certificate of root is in <name>-root
+// unless the spec names another Secret.
+func crdbClusterConnectionOf(cluster *unstructured.Unstructured) crdbClusterConnection {
+	c := crdbClusterConnection{
+		serviceName:  cluster.GetName() + "-public",
+		sqlPort:      "26257",
+		clientSecret: cluster.GetName() + "-root",
+	}
+
+	if port, ok, _ := unstructured.NestedInt64(cluster.Object, "spec", "sqlPort"); ok && port > 0 {
+		c.sqlPort = strconv.FormatInt(port, 10)
+	}
+	c.tlsEnabled, _, _ = unstructured.NestedBool(cluster.Object, "spec", "tlsEnabled")
+	if secret, _, _ := unstructured.NestedString(cluster.Object, "spec", "clientTLSSecret"); secret != "" {
+		c.clientSecret = secret
+	}
+
+	return c
+}
+
+// discoverCrdbCluster reads the CrdbCluster of the namespace of the kube
+// connection and returns the connection to its cluster.
+func discoverCrdbCluster(ctx context.Context, kubeConn kubeConn, name string) (crdbClusterConnection, error) {
+	client, err := dynamic.NewForConfig(kubeConn.kubeConfig)
+	if err != nil {
+		return crdbClusterConnection{}, err
+	}
+
+	var cluster *unstructured.Unstructured
+	err = retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		cluster, err = client.Resource(crdbClusterGVR).Namespace(kubeConn.nameSpace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return crdbClusterConnection{}, fmt.Errorf("failed to get CrdbCluster %s/%s: %w", kubeConn.nameSpace, name, err)
+	}
+
+	return crdbClusterConnectionOf(cluster), nil
+}
+
+// readCrdbClusterClientCert writes the client certificate of the Secret
+// created for the CrdbCluster to a temporary directory and returns the
+// connection parameters using it.
+func readCrdbClusterClientCert(ctx context.Context, kubeConn kubeConn, secretName string) (string, error) {
+	var secret *v1.Secret
+	err := retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		secret, err = kubeConn.kubeClient.CoreV1().Secrets(kubeConn.nameSpace).Get(ctx, secretName, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Secret %s/%s: %w", kubeConn.nameSpace, secretName, err)
+	}
+
+	return writeClientCert(secret)
+}
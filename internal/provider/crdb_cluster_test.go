@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCrdbClusterConnectionOf(t *testing.T) {
+	cluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "cockroachdb", "namespace": "crdb"},
+		"spec":     map[string]interface{}{},
+	}}
+
+	c := crdbClusterConnectionOf(cluster)
+	if c.serviceName != "cockroachdb-public" || c.sqlPort != "26257" || c.tlsEnabled || c.clientSecret != "cockroachdb-root" {
+		t.Errorf("unexpected connection %+v", c)
+	}
+
+	cluster.Object["spec"] = map[string]interface{}{
+		"sqlPort":         int64(26258),
+		"tlsEnabled":      true,
+		"clientTLSSecret": "cockroachdb-client",
+	}
+	c = crdbClusterConnectionOf(cluster)
+	if c.sqlPort != "26258" || !c.tlsEnabled || c.clientSecret != "cockroachdb-client" {
+		t.Errorf("unexpected connection %+v", c)
+	}
+}
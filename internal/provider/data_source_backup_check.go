@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	backupCheckCollectionAttr          = "collection"
+	backupCheckLocalityCollectionsAttr = "locality_collections"
+	backupCheckSubdirAttr              = "subdir"
+	backupCheckCheckFilesAttr          = "check_files"
+	backupCheckEndTimeAttr             = "end_time"
+	backupCheckFullClusterAttr         = "full_cluster"
+	backupCheckSizeBytesAttr           = "size_bytes"
+	backupCheckRowsAttr                = "rows"
+	backupCheckTablesAttr              = "tables"
+
+	backupCheckLatestSubdir    = "LATEST"
+	backupCheckObjectTypeTable = "table"
+
+	backupCheckDefaultLocalPort = "26276"
+)
+
+func dataSourceBackupCheck() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source checking a backup of a CockroachDB cluster with `SHOW BACKUP`, by default the latest one of a collection. " +
+			"Reading it fails when the backup is missing, incomplete or when its files can't be read, so the validity of the backups is asserted on every plan and apply.",
+
+		ReadContext: dataSourceBackupCheckRead,
+
+		Schema: map[string]*schema.Schema{
+			backupCheckCollectionAttr: {
+				Description: "Path of the backup collection, e.g. `s3://backups/crdb?AUTH=implicit`.",
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+			},
+			backupCheckLocalityCollectionsAttr: {
+				Description: "Paths of a locality-aware backup collection keyed by locality, e.g. `region=us-east1`, `collection` being the default one.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional:  true,
+				Sensitive: true,
+			},
+			backupCheckSubdirAttr: {
+				Description: "Subdirectory of the backup to check in the collection, e.g. `2024/05/01-120000.00`, the latest backup is checked when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     backupCheckLatestSubdir,
+			},
+			backupCheckCheckFilesAttr: {
+				Description: "Whether every file of the backup is checked to exist and be readable, which takes longer on large backups.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			backupKMSURIsAttr: {
+				Description: "URIs of the KMS keys the backup is encrypted with.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional:      true,
+				ConflictsWith: []string{backupEncryptionPassphraseAttr},
+			},
+			backupEncryptionPassphraseAttr: {
+				Description:   "Passphrase the backup is encrypted with.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{backupKMSURIsAttr},
+			},
+			backupCheckEndTimeAttr: {
+				Description: "Time up to which the backup, including its incremental backups, holds the data.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			backupCheckFullClusterAttr: {
+				Description: "True if the backup is a backup of the whole cluster.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			backupCheckSizeBytesAttr: {
+				Description: "Size of the data of the backup in bytes.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			backupCheckRowsAttr: {
+				Description: "Number of rows of the backup.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			backupCheckTablesAttr: {
+				Description: "Qualified names of the tables of the backup.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26276), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     backupCheckDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// showBackupStatement returns the SHOW BACKUP statement of the backup of the
+// subdirectory of the collection.
+func showBackupStatement(subdir string, collection string, options []string) string {
+	backup := `LATEST`
+	if subdir != backupCheckLatestSubdir {
+		backup = pq.QuoteLiteral(subdir)
+	}
+
+	statement := `SHOW BACKUP ` + backup + ` IN ` + collection
+	if len(options) != 0 {
+		statement += ` WITH ` + strings.Join(options, `, `)
+	}
+
+	return statement
+}
+
+// backupObject is an object of a backup, as listed by SHOW BACKUP.
+type backupObject struct {
+	database    string
+	schema      string
+	name        string
+	objectType  string
+	endTime     string
+	sizeBytes   int64
+	rows        int64
+	fullCluster bool
+}
+
+// backupSummary sums up the objects of a backup.
+type backupSummary struct {
+	endTime     string
+	fullCluster bool
+	sizeBytes   int64
+	rows        int64
+	tables      []string
+}
+
+// summarizeBackup sums up the objects of a backup, its end time being the one
+// of its latest incremental backup.
+func summarizeBackup(objects []backupObject) backupSummary {
+	var summary backupSummary
+	tables := map[string]bool{}
+	for _, object := range objects {
+		if object.endTime > summary.endTime {
+			summary.endTime = object.endTime
+		}
+		summary.fullCluster = summary.fullCluster || object.fullCluster
+		summary.sizeBytes += object.sizeBytes
+		summary.rows += object.rows
+		if object.objectType == backupCheckObjectTypeTable {
+			tables[strings.Join([]string{object.database, object.schema, object.name}, ".")] = true
+		}
+	}
+
+	summary.tables = []string{}
+	for table := range tables {
+		summary.tables = append(summary.tables, table)
+	}
+	sort.Strings(summary.tables)
+
+	return summary
+}
+
+func dataSourceBackupCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	collection, err := backupDestination(d.Get(backupCheckCollectionAttr).(string), d.Get(backupCheckLocalityCollectionsAttr).(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	options := backupEncryptionOptions(convertToString(d.Get(backupKMSURIsAttr).([]interface{})), d.Get(backupEncryptionPassphraseAttr).(string))
+	if d.Get(backupCheckCheckFilesAttr).(bool) {
+		options = append([]string{`check_files`}, options...)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	subdir := d.Get(backupCheckSubdirAttr).(string)
+	rows, err := conn.Query(ctx,
+		`SELECT coalesce(database_name, ''), coalesce(parent_schema_name, ''), object_name, object_type, `+
+			`coalesce(end_time::STRING, ''), coalesce(size_bytes, 0), coalesce(rows, 0), is_full_cluster `+
+			`FROM [`+showBackupStatement(subdir, collection, options)+`]`)
+	if err != nil {
+		return diag.Errorf("failed to check backup %s: %v", subdir, err)
+	}
+	defer rows.Close()
+
+	var objects []backupObject
+	for rows.Next() {
+		var object backupObject
+		if err := rows.Scan(&object.database, &object.schema, &object.name, &object.objectType,
+			&object.endTime, &object.sizeBytes, &object.rows, &object.fullCluster); err != nil {
+			return diag.FromErr(err)
+		}
+		objects = append(objects, object)
+	}
+	if err := rows.Err(); err != nil {
+		return diag.Errorf("failed to check backup %s: %v", subdir, err)
+	}
+	if len(objects) == 0 {
+		return diag.Errorf("backup %s is empty", subdir)
+	}
+
+	summary := summarizeBackup(objects)
+	values := map[string]interface{}{
+		backupCheckEndTimeAttr:     summary.endTime,
+		backupCheckFullClusterAttr: summary.fullCluster,
+		backupCheckSizeBytesAttr:   summary.sizeBytes,
+		backupCheckRowsAttr:        summary.rows,
+		backupCheckTablesAttr:      summary.tables,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(subdir + "@" + summary.endTime)
+
+	return diag.Diagnostics{}
+}
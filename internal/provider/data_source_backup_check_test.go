@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceBackupCheck(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceBackupCheck,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"data.cockroach_backup_check.foo", "end_time"),
+					resource.TestCheckResourceAttr(
+						"data.cockroach_backup_check.foo", "full_cluster", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestShowBackupStatement(t *testing.T) {
+	statement := showBackupStatement("LATEST", `'s3://backups/crdb'`, []string{"check_files", `kms = ('aws:///key')`})
+	if expected := `SHOW BACKUP LATEST IN 's3://backups/crdb' WITH check_files, kms = ('aws:///key')`; statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+
+	statement = showBackupStatement("2024/05/01-120000.00", `'s3://backups/crdb'`, nil)
+	if expected := `SHOW BACKUP '2024/05/01-120000.00' IN 's3://backups/crdb'`; statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+}
+
+func TestSummarizeBackup(t *testing.T) {
+	summary := summarizeBackup([]backupObject{
+		{name: "foo", objectType: "database", endTime: "2024-05-01 12:00:00"},
+		{database: "foo", schema: "public", name: "orders", objectType: "table", endTime: "2024-05-01 12:00:00", sizeBytes: 100, rows: 10},
+		{database: "foo", schema: "public", name: "customers", objectType: "table", endTime: "2024-05-01 12:00:00", sizeBytes: 50, rows: 5},
+		{database: "foo", schema: "public", name: "orders", objectType: "table", endTime: "2024-05-01 13:00:00", sizeBytes: 10, rows: 1},
+	})
+
+	expected := backupSummary{
+		endTime:   "2024-05-01 13:00:00",
+		sizeBytes: 160,
+		rows:      16,
+		tables:    []string{"foo.public.customers", "foo.public.orders"},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+const testAccDataSourceBackupCheck = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_database_backup" "foo" {
+  name          = "foo_backups"
+  database_name = cockroach_database.foo.name
+  backup_path   = "nodelocal://1/foo"
+}
+
+data "cockroach_backup_check" "foo" {
+  collection = "nodelocal://1/foo"
+
+  depends_on = [cockroach_database_backup.foo]
+}
+`
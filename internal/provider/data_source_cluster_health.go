@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	clusterHealthHealthyAttr               = "healthy"
+	clusterHealthReadyAttr                 = "ready"
+	clusterHealthReasonsAttr               = "reasons"
+	clusterHealthNodeCountsAttr            = "node_counts"
+	clusterHealthUnavailableRangesAttr     = "unavailable_ranges"
+	clusterHealthUnderreplicatedRangesAttr = "underreplicated_ranges"
+)
+
+func dataSourceClusterHealth() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading the health of a CockroachDB cluster, from the liveness of its nodes and its problem ranges, over the HTTP API configured with the http_api block of the provider.",
+
+		ReadContext: dataSourceClusterHealthRead,
+
+		Schema: map[string]*schema.Schema{
+			clusterHealthHealthyAttr: {
+				Description: "True when the node served is ready, no node is dead or unavailable, no range is unavailable or underreplicated and every node reported its ranges.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			clusterHealthReadyAttr: {
+				Description: "True when the node served by the HTTP API is ready to accept SQL connections.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			clusterHealthReasonsAttr: {
+				Description: "Reasons the cluster isn't healthy, empty when it is.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			clusterHealthNodeCountsAttr: {
+				Description: "Number of nodes by liveness status, e.g. `live` or `dead`.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+			clusterHealthUnavailableRangesAttr: {
+				Description: "Number of ranges without a quorum of live replicas.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			clusterHealthUnderreplicatedRangesAttr: {
+				Description: "Number of ranges with fewer replicas than configured.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceClusterHealthRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, closeClient, diags := openAdminClient(ctx, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeClient()
+
+	ready, err := client.ready(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	nodes, err := client.listNodes(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	problems, err := client.problemRanges(ctx, 0)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	values := clusterHealth(ready, nodes, problems)
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("cluster_health")
+
+	return diag.Diagnostics{}
+}
+
+// clusterHealth returns the attributes of the health of the cluster. The
+// decommissioned nodes, expected to be gone, don't make it unhealthy.
+func clusterHealth(ready bool, nodes []httpAPINode, problems map[string]httpAPIProblems) map[string]interface{} {
+	reasons := []string{}
+	if !ready {
+		reasons = append(reasons, "the node served by the HTTP API isn't ready")
+	}
+
+	nodeCounts := map[string]interface{}{}
+	var down []string
+	for _, node := range nodes {
+		status := string(node.LivenessStatus)
+		count, _ := nodeCounts[status].(int)
+		nodeCounts[status] = count + 1
+
+		if status == "dead" || status == "unavailable" {
+			down = append(down, fmt.Sprintf("n%d (%s)", node.NodeID, status))
+		}
+	}
+	if len(down) > 0 {
+		reasons = append(reasons, "nodes down: "+strings.Join(down, ", "))
+	}
+
+	ranges := problemRangesToMap(problems)
+	unavailable := len(ranges[problemRangesUnavailableAttr].([]int))
+	underreplicated := len(ranges[problemRangesUnderreplicatedAttr].([]int))
+	if unavailable > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d unavailable ranges", unavailable))
+	}
+	if underreplicated > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d underreplicated ranges", underreplicated))
+	}
+
+	nodeErrors := ranges[problemRangesNodeErrorsAttr].(map[string]interface{})
+	for _, nodeID := range sortedKeys(nodeErrors) {
+		reasons = append(reasons, fmt.Sprintf("failed to read the ranges of n%s: %s", nodeID, nodeErrors[nodeID]))
+	}
+
+	return map[string]interface{}{
+		clusterHealthHealthyAttr:               len(reasons) == 0,
+		clusterHealthReadyAttr:                 ready,
+		clusterHealthReasonsAttr:               reasons,
+		clusterHealthNodeCountsAttr:            nodeCounts,
+		clusterHealthUnavailableRangesAttr:     unavailable,
+		clusterHealthUnderreplicatedRangesAttr: underreplicated,
+	}
+}
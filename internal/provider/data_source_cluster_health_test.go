@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterHealth(t *testing.T) {
+	nodes := []httpAPINode{
+		{NodeID: 1, LivenessStatus: "live"},
+		{NodeID: 2, LivenessStatus: "live"},
+		{NodeID: 3, LivenessStatus: "decommissioned"},
+	}
+
+	values := clusterHealth(true, nodes, map[string]httpAPIProblems{"1": {}, "2": {}})
+	if values[clusterHealthHealthyAttr] != true || len(values[clusterHealthReasonsAttr].([]string)) != 0 {
+		t.Errorf("expected a healthy cluster, got %v", values)
+	}
+	if counts := values[clusterHealthNodeCountsAttr]; !reflect.DeepEqual(counts, map[string]interface{}{"live": 2, "decommissioned": 1}) {
+		t.Errorf("unexpected node counts %v", counts)
+	}
+
+	nodes[1].LivenessStatus = "dead"
+	values = clusterHealth(false, nodes, map[string]httpAPIProblems{"1": {Underreplicated: []jsonInt64{4, 5}}})
+	expected := []string{
+		"the node served by the HTTP API isn't ready",
+		"nodes down: n2 (dead)",
+		"2 underreplicated ranges",
+	}
+	if values[clusterHealthHealthyAttr] != false || !reflect.DeepEqual(values[clusterHealthReasonsAttr], expected) {
+		t.Errorf("unexpected health %v", values)
+	}
+	if values[clusterHealthUnderreplicatedRangesAttr] != 2 || values[clusterHealthUnavailableRangesAttr] != 0 {
+		t.Errorf("unexpected range counts %v", values)
+	}
+}
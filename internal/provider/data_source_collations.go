@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	collationsPrefixAttr     = "prefix"
+	collationsCollationsAttr = "collations"
+
+	collationsDefaultLocalPort = "26306"
+)
+
+func dataSourceCollations() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source listing the collations supported by the cluster, which can be set as the `collation` of the `STRING` columns of a `cockroach_table`.",
+
+		ReadContext: dataSourceCollationsRead,
+
+		Schema: map[string]*schema.Schema{
+			collationsPrefixAttr: {
+				Description: "Prefix of the collations to list, e.g. `de` for the German ones, every collation being listed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			collationsCollationsAttr: {
+				Description: "Names of the collations, sorted.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + collationsDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     collationsDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func dataSourceCollationsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	prefix := d.Get(collationsPrefixAttr).(string)
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	collations, err := queryStrings(ctx, conn,
+		`SELECT collname FROM pg_catalog.pg_collation WHERE lower(collname) LIKE lower($1) || '%' ORDER BY collname`, prefix)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set(collationsCollationsAttr, collations); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("collations/" + prefix)
+
+	return diag.Diagnostics{}
+}
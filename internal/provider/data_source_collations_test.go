@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceCollations(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceCollations,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.cockroach_collations.german", "collations.0", regexp.MustCompile("^de")),
+					resource.TestCheckResourceAttr("cockroach_table.foo", "column.1.collation", "de"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceCollations = `
+data "cockroach_collations" "german" {
+  prefix = "de"
+}
+
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "foo" {
+  database = cockroach_database.foo.name
+  name     = "customers"
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+
+  column {
+    name      = "name"
+    type      = "STRING"
+    collation = data.cockroach_collations.german.collations[0]
+  }
+
+  primary_key = ["id"]
+}
+`
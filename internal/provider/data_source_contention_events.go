@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	contentionSinceAttr                  = "since"
+	contentionMinDurationAttr            = "min_duration"
+	contentionLimitAttr                  = "limit"
+	contentionEventsAttr                 = "events"
+	contentionCollectedAtAttr            = "collected_at"
+	contentionBlockingTxnFingerprintAttr = "blocking_txn_fingerprint_id"
+	contentionWaitingTxnFingerprintAttr  = "waiting_txn_fingerprint_id"
+	contentionWaitingStmtFingerprintAttr = "waiting_stmt_fingerprint_id"
+	contentionDurationMsAttr             = "duration_ms"
+	contentionKeyAttr                    = "key"
+	contentionCountAttr                  = "event_count"
+	contentionMaxDurationMsAttr          = "max_duration_ms"
+	contentionTotalDurationMsAttr        = "total_duration_ms"
+
+	contentionEventsDefaultLocalPort = "26288"
+)
+
+func dataSourceContentionEvents() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source listing the recent contention events of a CockroachDB cluster, where a transaction waited for another one, e.g. to block a deployment when the contention exceeds a threshold. " +
+			"The events are kept in memory by the nodes for a limited time, see `sql.contention.event_store.duration_threshold`.",
+
+		ReadContext: dataSourceContentionEventsRead,
+
+		Schema: map[string]*schema.Schema{
+			contentionSinceAttr: {
+				Description:  "How far back the events are listed, e.g. `30m`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1h",
+				ValidateFunc: validateDuration,
+			},
+			contentionMinDurationAttr: {
+				Description:  "Minimum time the transactions waited for the events to be listed, e.g. `100ms`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0s",
+				ValidateFunc: validateDuration,
+			},
+			contentionLimitAttr: {
+				Description:  "Maximum number of events listed, the longest ones first. The count and durations cover every event though.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			contentionCountAttr: {
+				Description: "Number of events.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			contentionMaxDurationMsAttr: {
+				Description: "Longest time a transaction waited, in milliseconds.",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			contentionTotalDurationMsAttr: {
+				Description: "Total time the transactions waited, in milliseconds.",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			contentionEventsAttr: {
+				Description: "Events, the longest first.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						contentionCollectedAtAttr: {
+							Description: "Time the event was collected at.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						contentionBlockingTxnFingerprintAttr: {
+							Description: "Fingerprint of the transaction holding the key, in hexadecimal.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						contentionWaitingTxnFingerprintAttr: {
+							Description: "Fingerprint of the transaction which waited, in hexadecimal.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						contentionWaitingStmtFingerprintAttr: {
+							Description: "Fingerprint of the statement which waited, in hexadecimal.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						contentionDurationMsAttr: {
+							Description: "Time the transaction waited, in milliseconds.",
+							Type:        schema.TypeFloat,
+							Computed:    true,
+						},
+						contentionKeyAttr: {
+							Description: "Key the transactions contended on.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26288), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     contentionEventsDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// contentionEvent is a row of crdb_internal.transaction_contention_events.
+type contentionEvent struct {
+	collectedAt              time.Time
+	blockingTxnFingerprintID string
+	waitingTxnFingerprintID  string
+	waitingStmtFingerprintID string
+	durationMs               float64
+	key                      string
+}
+
+func (e contentionEvent) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		contentionCollectedAtAttr:            e.collectedAt.UTC().Format(time.RFC3339Nano),
+		contentionBlockingTxnFingerprintAttr: e.blockingTxnFingerprintID,
+		contentionWaitingTxnFingerprintAttr:  e.waitingTxnFingerprintID,
+		contentionWaitingStmtFingerprintAttr: e.waitingStmtFingerprintID,
+		contentionDurationMsAttr:             e.durationMs,
+		contentionKeyAttr:                    e.key,
+	}
+}
+
+// summarizeContention returns the number of events and the longest and total
+// time the transactions waited.
+func summarizeContention(events []contentionEvent) (int, float64, float64) {
+	var max, total float64
+	for _, e := range events {
+		total += e.durationMs
+		if e.durationMs > max {
+			max = e.durationMs
+		}
+	}
+
+	return len(events), max, total
+}
+
+func dataSourceContentionEventsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	rows, err := conn.Query(ctx,
+		`SELECT collection_ts, encode(blocking_txn_fingerprint_id, 'hex'), encode(waiting_txn_fingerprint_id, 'hex'), `+
+			`encode(waiting_stmt_fingerprint_id, 'hex'), (extract(epoch FROM contention_duration) * 1000)::FLOAT8, crdb_internal.pretty_key(contending_key, 0) `+
+			`FROM crdb_internal.transaction_contention_events `+
+			`WHERE collection_ts > now() - $1::INTERVAL AND contention_duration >= $2::INTERVAL `+
+			`ORDER BY contention_duration DESC, collection_ts DESC`,
+		d.Get(contentionSinceAttr).(string), d.Get(contentionMinDurationAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer rows.Close()
+
+	var events []contentionEvent
+	for rows.Next() {
+		var e contentionEvent
+		if err := rows.Scan(&e.collectedAt, &e.blockingTxnFingerprintID, &e.waitingTxnFingerprintID, &e.waitingStmtFingerprintID, &e.durationMs, &e.key); err != nil {
+			return diag.FromErr(err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	count, max, total := summarizeContention(events)
+	listed := []interface{}{}
+	for i, e := range events {
+		if i == d.Get(contentionLimitAttr).(int) {
+			break
+		}
+		listed = append(listed, e.toMap())
+	}
+
+	values := map[string]interface{}{
+		contentionCountAttr:           count,
+		contentionMaxDurationMsAttr:   max,
+		contentionTotalDurationMsAttr: total,
+		contentionEventsAttr:          listed,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("contention_events")
+
+	return diag.Diagnostics{}
+}
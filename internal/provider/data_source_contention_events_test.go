@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceContentionEvents(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceContentionEvents,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.cockroach_contention_events.foo", "event_count"),
+					resource.TestCheckResourceAttrSet("data.cockroach_contention_events.foo", "max_duration_ms"),
+				),
+			},
+		},
+	})
+}
+
+func TestSummarizeContention(t *testing.T) {
+	count, max, total := summarizeContention([]contentionEvent{{durationMs: 120}, {durationMs: 30.5}, {durationMs: 250}})
+	if count != 3 || max != 250 || total != 400.5 {
+		t.Errorf("unexpected summary %d, %v, %v", count, max, total)
+	}
+
+	if count, max, total := summarizeContention(nil); count != 0 || max != 0 || total != 0 {
+		t.Errorf("unexpected summary of no events %d, %v, %v", count, max, total)
+	}
+}
+
+const testAccDataSourceContentionEvents = `
+data "cockroach_contention_events" "foo" {
+  since        = "15m"
+  min_duration = "10ms"
+}
+`
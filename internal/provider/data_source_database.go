@@ -3,11 +3,9 @@ package provider
 import (
 	"context"
 	"strconv"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/jackc/pgx/v4"
 )
 
 func dataSourceDatabase() *schema.Resource {
@@ -30,6 +28,7 @@ func dataSourceDatabase() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			argFollowerRead: followerReadSchema(),
 			argLocalPort: {
 				Description: "Local port to be used for port-forward. (default is 26259), use different port to avoid same port opening.",
 				Type:        schema.TypeString,
@@ -41,33 +40,23 @@ func dataSourceDatabase() *schema.Resource {
 }
 
 func dataSourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	cockroachClient := meta.(*cockroachClient)
-
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
+
 	name := d.Get("name").(string)
 	var (
 		id    int
 		owner string
 	)
-	err = conn.QueryRow(ctx, `SELECT id, owner FROM crdb_internal.databases WHERE name = $1`, name).Scan(
-		&id,
-		&owner,
-	)
+	err := readAsOfSystemTime(ctx, conn, d.Get(argFollowerRead).(bool), func() error {
+		return conn.QueryRow(ctx, `SELECT id, owner FROM crdb_internal.databases WHERE name = $1`, name).Scan(
+			&id,
+			&owner,
+		)
+	})
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -79,7 +68,5 @@ func dataSourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.FromErr(err)
 	}
 
-	close(stopCh)
-
 	return diag.Diagnostics{}
 }
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultPrivilegesDatabaseAttr        = "database"
+	defaultPrivilegesSchemaAttr          = "schema"
+	defaultPrivilegesRoleAttr            = "role"
+	defaultPrivilegesForAllRolesAttr     = "for_all_roles"
+	defaultPrivilegesObjectTypeAttr      = "object_type"
+	defaultPrivilegesGranteeAttr         = "grantee"
+	defaultPrivilegesPrivilegesAttr      = "privileges"
+	defaultPrivilegesWithGrantOptionAttr = "with_grant_option"
+	defaultPrivilegesDefaultsAttr        = "default_privileges"
+
+	defaultPrivilegesDefaultLocalPort = "26291"
+)
+
+// defaultPrivilegesObjectTypes are the types of objects default privileges
+// are defined for, as reported by crdb_internal.default_privileges.
+var defaultPrivilegesObjectTypes = []string{"tables", "sequences", "types", "schemas", "functions"}
+
+func dataSourceDefaultPrivileges() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source listing the default privileges of a database, granted on the objects created afterwards, e.g. to compare the intended and actual defaults before the objects are created by another role. " +
+			"The privileges granted to the creator of the objects by default aren't listed unless they were altered.",
+
+		ReadContext: dataSourceDefaultPrivilegesRead,
+
+		Schema: map[string]*schema.Schema{
+			defaultPrivilegesDatabaseAttr: {
+				Description: "Name of the database, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			defaultPrivilegesSchemaAttr: {
+				Description: "Name of the schema to list the default privileges of, the default privileges of the whole database are listed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			defaultPrivilegesRoleAttr: {
+				Description: "Name of the role creating the objects to list the default privileges of, the default privileges of every role are listed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			defaultPrivilegesObjectTypeAttr: {
+				Description:  "Type of the objects to list the default privileges of, one of `tables`, `sequences`, `types`, `schemas` or `functions`. Every type is listed when not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(defaultPrivilegesObjectTypes, false),
+			},
+			defaultPrivilegesDefaultsAttr: {
+				Description: "Default privileges, one entry per creating role, object type and grantee.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						defaultPrivilegesRoleAttr: {
+							Description: "Role creating the objects, empty for the default privileges of every role.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						defaultPrivilegesForAllRolesAttr: {
+							Description: "True for the default privileges of the objects created by every role.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						defaultPrivilegesObjectTypeAttr: {
+							Description: "Type of the objects.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						defaultPrivilegesGranteeAttr: {
+							Description: "Role the privileges are granted to.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						defaultPrivilegesPrivilegesAttr: {
+							Description: "Privileges granted.",
+							Type:        schema.TypeSet,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Computed: true,
+						},
+						defaultPrivilegesWithGrantOptionAttr: {
+							Description: "True if the grantee can grant every privilege to other roles.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26291), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultPrivilegesDefaultLocalPort,
+			},
+			argFollowerRead: followerReadSchema(),
+		},
+	}
+}
+
+// defaultPrivilegeRow is a row of crdb_internal.default_privileges.
+type defaultPrivilegeRow struct {
+	role        string
+	forAllRoles bool
+	objectType  string
+	grantee     string
+	privilege   string
+	grantable   bool
+}
+
+// groupDefaultPrivileges groups the privileges by creating role, object type
+// and grantee, sorted so the list doesn't change between reads.
+func groupDefaultPrivileges(rows []defaultPrivilegeRow) []interface{} {
+	type group struct {
+		row        defaultPrivilegeRow
+		privileges []string
+		grantable  bool
+	}
+
+	index := map[[4]string]int{}
+	var groups []*group
+	for _, row := range rows {
+		key := [4]string{row.role, fmt.Sprint(row.forAllRoles), row.objectType, row.grantee}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, &group{row: row, grantable: true})
+		}
+		groups[i].privileges = append(groups[i].privileges, row.privilege)
+		groups[i].grantable = groups[i].grantable && row.grantable
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].row, groups[j].row
+		if a.forAllRoles != b.forAllRoles {
+			return a.forAllRoles
+		}
+		if a.role != b.role {
+			return a.role < b.role
+		}
+		if a.objectType != b.objectType {
+			return a.objectType < b.objectType
+		}
+		return a.grantee < b.grantee
+	})
+
+	result := make([]interface{}, len(groups))
+	for i, g := range groups {
+		sort.Strings(g.privileges)
+		result[i] = map[string]interface{}{
+			defaultPrivilegesRoleAttr:            g.row.role,
+			defaultPrivilegesForAllRolesAttr:     g.row.forAllRoles,
+			defaultPrivilegesObjectTypeAttr:      g.row.objectType,
+			defaultPrivilegesGranteeAttr:         g.row.grantee,
+			defaultPrivilegesPrivilegesAttr:      g.privileges,
+			defaultPrivilegesWithGrantOptionAttr: g.grantable,
+		}
+	}
+
+	return result
+}
+
+func dataSourceDefaultPrivilegesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(defaultPrivilegesDatabaseAttr).(string)
+	if database == "" {
+		database = meta.(*cockroachClient).defaultDatabase
+	}
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", defaultPrivilegesDatabaseAttr)
+	}
+	if err := d.Set(defaultPrivilegesDatabaseAttr, database); err != nil {
+		return diag.FromErr(err)
+	}
+	schemaName := d.Get(defaultPrivilegesSchemaAttr).(string)
+	role := d.Get(defaultPrivilegesRoleAttr).(string)
+	objectType := d.Get(defaultPrivilegesObjectTypeAttr).(string)
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	// crdb_internal.default_privileges only lists the default privileges of
+	// the database it is qualified with.
+	var rows []defaultPrivilegeRow
+	err := readAsOfSystemTime(ctx, conn, d.Get(argFollowerRead).(bool), func() error {
+		result, err := conn.Query(ctx,
+			`SELECT COALESCE(role, ''), for_all_roles, object_type, grantee, privilege_type, is_grantable `+
+				`FROM `+pq.QuoteIdentifier(database)+`.crdb_internal.default_privileges `+
+				`WHERE schema_name IS NOT DISTINCT FROM NULLIF($1, '') AND ($2 = '' OR role = $2) AND ($3 = '' OR object_type = $3)`,
+			schemaName, role, objectType)
+		if err != nil {
+			return err
+		}
+		defer result.Close()
+
+		for result.Next() {
+			var row defaultPrivilegeRow
+			if err := result.Scan(&row.role, &row.forAllRoles, &row.objectType, &row.grantee, &row.privilege, &row.grantable); err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		return result.Err()
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set(defaultPrivilegesDefaultsAttr, groupDefaultPrivileges(rows)); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(database + "/" + schemaName)
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceDefaultPrivileges(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceDefaultPrivileges,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cockroach_default_privileges.foo", "database", "defaultdb"),
+					resource.TestCheckResourceAttrSet("data.cockroach_default_privileges.foo", "default_privileges.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestGroupDefaultPrivileges(t *testing.T) {
+	groups := groupDefaultPrivileges([]defaultPrivilegeRow{
+		{role: "migrator", objectType: "tables", grantee: "reader", privilege: "SELECT"},
+		{forAllRoles: true, objectType: "types", grantee: "public", privilege: "USAGE"},
+		{role: "migrator", objectType: "tables", grantee: "app", privilege: "UPDATE", grantable: true},
+		{role: "migrator", objectType: "tables", grantee: "app", privilege: "INSERT", grantable: true},
+		{role: "migrator", objectType: "tables", grantee: "reader", privilege: "DELETE", grantable: true},
+	})
+	expected := []interface{}{
+		map[string]interface{}{"role": "", "for_all_roles": true, "object_type": "types", "grantee": "public", "privileges": []string{"USAGE"}, "with_grant_option": false},
+		map[string]interface{}{"role": "migrator", "for_all_roles": false, "object_type": "tables", "grantee": "app", "privileges": []string{"INSERT", "UPDATE"}, "with_grant_option": true},
+		map[string]interface{}{"role": "migrator", "for_all_roles": false, "object_type": "tables", "grantee": "reader", "privileges": []string{"DELETE", "SELECT"}, "with_grant_option": false},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expected %v, got %v", expected, groups)
+	}
+}
+
+const testAccDataSourceDefaultPrivileges = `
+data "cockroach_default_privileges" "foo" {
+  database = "defaultdb"
+}
+`
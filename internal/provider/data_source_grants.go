@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	grantsIncludeSystemRolesAttr = "include_system_roles"
+	grantsGrantsAttr             = "grants"
+	grantsObjectAttr             = "object"
+	grantsImportIDAttr           = "import_id"
+)
+
+// systemRoles hold privileges on every object which can't be revoked.
+var systemRoles = []string{"admin", "root"}
+
+func dataSourceGrants() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source listing the existing grants on an object, one entry per role and object. " +
+			"The `import_id` of every entry can be used in `import` blocks to bring existing grants under `cockroach_grant` resources.",
+
+		ReadContext: dataSourceGrantsRead,
+
+		Schema: map[string]*schema.Schema{
+			grantDatabaseAttr: {
+				Description: "Name of the database holding the objects, required for every object type except `external_connection`. The default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			grantSchemaAttr: {
+				Description: "Name of the schema holding the objects, only used for `schema`, `table`, `function` and `type` grants. The default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			grantObjectTypeAttr: {
+				Description:  "Type of the objects to list the grants of, one of `database`, `schema`, `table`, `function`, `type` or `external_connection`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(grantObjectTypes(), false),
+			},
+			grantObjectsAttr: {
+				Description: "Names of the objects to list the grants of, required for every object type except `database` and `schema`.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+			grantsIncludeSystemRolesAttr: {
+				Description: "True to also list the grants of the `admin` and `root` roles, which can't be revoked.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			grantsGrantsAttr: {
+				Description: "Grants found on the objects.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						grantRoleAttr: {
+							Description: "Role holding the privileges.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						grantsObjectAttr: {
+							Description: "Object the privileges are granted on.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						grantPrivilegesAttr: {
+							Description: "Privileges held by the role on the object.",
+							Type:        schema.TypeSet,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Computed: true,
+						},
+						grantWithGrantOptionAttr: {
+							Description: "True if the role can grant the privileges to other roles.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						grantsImportIDAttr: {
+							Description: "Id to import the grant as a `cockroach_grant` resource.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26265), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "26265",
+			},
+			argFollowerRead: followerReadSchema(),
+		},
+	}
+}
+
+func dataSourceGrantsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(grantDatabaseAttr).(string)
+	schemaName := d.Get(grantSchemaAttr).(string)
+	objectType := d.Get(grantObjectTypeAttr).(string)
+	objects := convertToString(d.Get(grantObjectsAttr).(*schema.Set).List())
+	includeSystemRoles := d.Get(grantsIncludeSystemRolesAttr).(bool)
+
+	if database == "" && objectType != grantObjectExternalConnection {
+		database = meta.(*cockroachClient).defaultDatabase
+	}
+	if schemaName == "" {
+		schemaName = defaultSchemaOf(meta)
+	}
+	if err := d.Set(grantDatabaseAttr, database); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(grantSchemaAttr, schemaName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := validateGrant("*", database, objectType, objects, nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	target := grantTarget(objectType, database, schemaName, objects)
+
+	var rows []grantRow
+	err := readAsOfSystemTime(ctx, conn, d.Get(argFollowerRead).(bool), func() (err error) {
+		rows, err = showGrants(ctx, conn, target, "")
+		return err
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	grants := []interface{}{}
+	for _, grant := range groupGrants(rows) {
+		if !includeSystemRoles && contains(systemRoles, grant.grantee) {
+			continue
+		}
+
+		var importObjects []string
+		if grantRequiresObjects(objectType) {
+			importObjects = []string{grant.object}
+		}
+
+		privileges, withGrantOption := grantedPrivileges(objectType, grant.rows, grant.grantee, []string{grant.object})
+		grants = append(grants, map[string]interface{}{
+			grantRoleAttr:            grant.grantee,
+			grantsObjectAttr:         grant.object,
+			grantPrivilegesAttr:      privileges,
+			grantWithGrantOptionAttr: withGrantOption,
+			grantsImportIDAttr:       grantID(grant.grantee, database, objectType, schemaName, importObjects),
+		})
+	}
+
+	d.SetId(target)
+	if err := d.Set(grantsGrantsAttr, grants); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+// groupedGrants are the rows of SHOW GRANTS of one role on one object.
+type groupedGrants struct {
+	grantee string
+	object  string
+	rows    []grantRow
+}
+
+// groupGrants groups the rows of SHOW GRANTS by role and object, sorted so the
+// list doesn't change between reads.
+func groupGrants(rows []grantRow) []groupedGrants {
+	index := map[[2]string]int{}
+	var groups []groupedGrants
+	for _, row := range rows {
+		key := [2]string{row.grantee, row.object}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, groupedGrants{grantee: row.grantee, object: row.object})
+		}
+		groups[i].rows = append(groups[i].rows, row)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].grantee != groups[j].grantee {
+			return groups[i].grantee < groups[j].grantee
+		}
+		return groups[i].object < groups[j].object
+	})
+
+	return groups
+}
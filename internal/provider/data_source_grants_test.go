@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceGrants(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceGrants,
+				Check: resource.ComposeTestCheckFunc(
+					// new databases also grant CONNECT to public, so the grant
+					// of bar is looked up rather than the number of grants
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"data.cockroach_grants.foo", "grants.*", map[string]string{
+							"role":         "bar",
+							"privileges.#": "1",
+							"import_id":    "bar/foo/database",
+						}),
+				),
+			},
+		},
+	})
+}
+
+func TestGroupGrants(t *testing.T) {
+	groups := groupGrants([]grantRow{
+		{object: "t2", grantee: "bar", privilege: "SELECT"},
+		{object: "t1", grantee: "bar", privilege: "SELECT"},
+		{object: "t1", grantee: "bar", privilege: "INSERT"},
+		{object: "t1", grantee: "admin", privilege: "ALL"},
+	})
+
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+
+	if groups[0].grantee != "admin" || groups[1].object != "t1" || len(groups[1].rows) != 2 || groups[2].object != "t2" {
+		t.Errorf("unexpected groups %+v", groups)
+	}
+}
+
+const testAccDataSourceGrants = `
+resource "cockroach_user" "bar" {
+  username   = "bar"
+  password   = "bar123"
+  local_port = "23244"
+}
+
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_grant" "foo" {
+  role        = cockroach_user.bar.username
+  database    = cockroach_database.foo.name
+  object_type = "database"
+  privileges  = ["CONNECT"]
+}
+
+data "cockroach_grants" "foo" {
+  database    = cockroach_database.foo.name
+  object_type = "database"
+
+  depends_on = [cockroach_grant.foo]
+}
+`
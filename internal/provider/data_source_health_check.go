@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	healthCheckFailOnViolationAttr = "fail_on_violation"
+)
+
+func dataSourceHealthCheck() *schema.Resource {
+	s := healthCheckSchema(false)
+	s[healthCheckFailOnViolationAttr] = &schema.Schema{
+		Description: "Whether the read, and so the plan, fails when an assertion doesn't hold, the violations are only reported otherwise.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source checking that the CockroachDB cluster is healthy over the HTTP API configured with the http_api block of the provider, failing the plan when an assertion doesn't hold.",
+
+		ReadContext: dataSourceHealthCheckRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceHealthCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	violations, err := runHealthCheck(ctx, meta, newHealthCheckConfig(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if len(violations) > 0 && d.Get(healthCheckFailOnViolationAttr).(bool) {
+		return diag.Errorf("health check failed: %s", strings.Join(violations, ", "))
+	}
+
+	values := map[string]interface{}{
+		healthCheckPassedAttr:     len(violations) == 0,
+		healthCheckViolationsAttr: violations,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("health_check")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	indexUsageDatabaseAttr   = "database"
+	indexUsageSchemaAttr     = "schema"
+	indexUsageTableAttr      = "table"
+	indexUsageUnusedForAttr  = "unused_for"
+	indexUsageIndexesAttr    = "indexes"
+	indexUsageIndexAttr      = "index"
+	indexUsageIndexTypeAttr  = "index_type"
+	indexUsageIsUniqueAttr   = "is_unique"
+	indexUsageTotalReadsAttr = "total_reads"
+	indexUsageLastReadAttr   = "last_read"
+
+	indexUsageDefaultLocalPort = "26294"
+)
+
+func dataSourceIndexUsageStatistics() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source listing the indexes of the tables of a schema with the number of times they were read and the last time they were, e.g. to find the unused indexes to drop. " +
+			"The statistics are collected since the indexes were created, or since they were reset with `crdb_internal.reset_index_usage_stats()`.",
+
+		ReadContext: dataSourceIndexUsageStatisticsRead,
+
+		Schema: map[string]*schema.Schema{
+			indexUsageDatabaseAttr: {
+				Description: "Name of the database, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			indexUsageSchemaAttr: {
+				Description: "Name of the schema, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			indexUsageTableAttr: {
+				Description: "Name of the table to list the indexes of, the indexes of every table of the schema are listed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			indexUsageUnusedForAttr: {
+				Description:  "When set, only the secondary indexes which weren't read for this long, or never, are listed, e.g. `720h`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+			indexUsageIndexesAttr: {
+				Description: "Indexes, sorted by table and index.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						indexUsageTableAttr: {
+							Description: "Name of the table of the index.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						indexUsageIndexAttr: {
+							Description: "Name of the index.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						indexUsageIndexTypeAttr: {
+							Description: "Type of the index, `primary` or `secondary`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						indexUsageIsUniqueAttr: {
+							Description: "Whether the index is unique.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						indexUsageTotalReadsAttr: {
+							Description: "Number of times the index was read.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						indexUsageLastReadAttr: {
+							Description: "Last time the index was read, empty when it never was.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26294), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     indexUsageDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// indexUsage is the usage of an index.
+type indexUsage struct {
+	table      string
+	index      string
+	indexType  string
+	isUnique   bool
+	totalReads int64
+	lastRead   *time.Time
+}
+
+// unusedSince returns whether the index is a secondary index which wasn't read
+// since the given time.
+func (u indexUsage) unusedSince(since time.Time) bool {
+	return u.indexType != "primary" && (u.lastRead == nil || u.lastRead.Before(since))
+}
+
+func (u indexUsage) toMap() map[string]interface{} {
+	lastRead := ""
+	if u.lastRead != nil {
+		lastRead = u.lastRead.UTC().Format(time.RFC3339)
+	}
+
+	return map[string]interface{}{
+		indexUsageTableAttr:      u.table,
+		indexUsageIndexAttr:      u.index,
+		indexUsageIndexTypeAttr:  u.indexType,
+		indexUsageIsUniqueAttr:   u.isUnique,
+		indexUsageTotalReadsAttr: u.totalReads,
+		indexUsageLastReadAttr:   lastRead,
+	}
+}
+
+func dataSourceIndexUsageStatisticsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(indexUsageDatabaseAttr).(string)
+	if database == "" {
+		database = meta.(*cockroachClient).defaultDatabase
+	}
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", indexUsageDatabaseAttr)
+	}
+	schemaName := d.Get(indexUsageSchemaAttr).(string)
+	if schemaName == "" {
+		schemaName = defaultSchemaOf(meta)
+	}
+	table := d.Get(indexUsageTableAttr).(string)
+
+	var since *time.Time
+	if v, ok := d.GetOk(indexUsageUnusedForAttr); ok {
+		unusedFor, err := time.ParseDuration(v.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		t := time.Now().Add(-unusedFor)
+		since = &t
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	// the crdb_internal tables only list the indexes of the database they
+	// are qualified with.
+	db := pq.QuoteIdentifier(database)
+	rows, err := conn.Query(ctx,
+		`SELECT t.name, i.index_name, i.index_type, i.is_unique, COALESCE(s.total_reads, 0), s.last_read `+
+			`FROM `+db+`.crdb_internal.table_indexes AS i `+
+			`JOIN `+db+`.crdb_internal.tables AS t ON t.table_id = i.descriptor_id `+
+			`LEFT JOIN `+db+`.crdb_internal.index_usage_statistics AS s ON s.table_id = i.descriptor_id AND s.index_id = i.index_id `+
+			`WHERE t.database_name = $1 AND t.schema_name = $2 AND ($3 = '' OR t.name = $3) `+
+			`ORDER BY t.name, i.index_name`,
+		database, schemaName, table)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer rows.Close()
+
+	indexes := []interface{}{}
+	for rows.Next() {
+		var u indexUsage
+		if err := rows.Scan(&u.table, &u.index, &u.indexType, &u.isUnique, &u.totalReads, &u.lastRead); err != nil {
+			return diag.FromErr(err)
+		}
+		if since == nil || u.unusedSince(*since) {
+			indexes = append(indexes, u.toMap())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	values := map[string]interface{}{
+		indexUsageDatabaseAttr: database,
+		indexUsageSchemaAttr:   schemaName,
+		indexUsageIndexesAttr:  indexes,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId(database + "/" + schemaName + "/" + table)
+
+	return diag.Diagnostics{}
+}
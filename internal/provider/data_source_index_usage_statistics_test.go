@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceIndexUsageStatistics(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceIndexUsageStatistics,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cockroach_index_usage_statistics.all", "indexes.#", "2"),
+					resource.TestCheckResourceAttr("data.cockroach_index_usage_statistics.all", "indexes.0.index", "orders_pkey"),
+					resource.TestCheckResourceAttr("data.cockroach_index_usage_statistics.all", "indexes.0.index_type", "primary"),
+					resource.TestCheckResourceAttr("data.cockroach_index_usage_statistics.unused", "indexes.#", "1"),
+					resource.TestCheckResourceAttr("data.cockroach_index_usage_statistics.unused", "indexes.0.index", "orders_quantity_idx"),
+					resource.TestCheckResourceAttr("data.cockroach_index_usage_statistics.unused", "indexes.0.last_read", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestIndexUnusedSince(t *testing.T) {
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before, after := since.Add(-time.Hour), since.Add(time.Hour)
+
+	for _, test := range []struct {
+		usage    indexUsage
+		expected bool
+	}{
+		{indexUsage{indexType: "secondary"}, true},
+		{indexUsage{indexType: "secondary", lastRead: &before}, true},
+		{indexUsage{indexType: "secondary", lastRead: &after}, false},
+		{indexUsage{indexType: "primary"}, false},
+	} {
+		if actual := test.usage.unusedSince(since); actual != test.expected {
+			t.Errorf("expected %v for %+v, got %v", test.expected, test.usage, actual)
+		}
+	}
+}
+
+const testAccDataSourceIndexUsageStatistics = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "foo" {
+  database = cockroach_database.foo.name
+  name     = "orders"
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+
+  column {
+    name = "quantity"
+    type = "INT"
+  }
+
+  primary_key = ["id"]
+
+  index {
+    name    = "orders_quantity_idx"
+    columns = ["quantity"]
+  }
+}
+
+data "cockroach_index_usage_statistics" "all" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.foo.name
+}
+
+data "cockroach_index_usage_statistics" "unused" {
+  database   = cockroach_database.foo.name
+  table      = cockroach_table.foo.name
+  unused_for = "1h"
+}
+`
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	localityMapNodesAttr       = "nodes"
+	localityMapNodeIDAttr      = "node_id"
+	localityMapAddressAttr     = "address"
+	localityMapIsLiveAttr      = "is_live"
+	localityMapLocalityAttr    = "locality"
+	localityMapTiersAttr       = "tiers"
+	localityMapConstraintsAttr = "constraints"
+	localityMapLocalitiesAttr  = "localities"
+	localityMapKeyAttr         = "key"
+	localityMapValuesAttr      = "values"
+
+	localityMapDefaultLocalPort = "26290"
+)
+
+func dataSourceLocalityMap() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading the localities of the nodes of a CockroachDB cluster, e.g. to build the constraints and lease preferences of zone configurations from the actual topology.",
+
+		ReadContext: dataSourceLocalityMapRead,
+
+		Schema: map[string]*schema.Schema{
+			localityMapNodesAttr: {
+				Description: "Nodes, sorted by id.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						localityMapNodeIDAttr: {
+							Description: "Id of the node.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						localityMapAddressAttr: {
+							Description: "Address of the node.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						localityMapIsLiveAttr: {
+							Description: "Whether the node is live.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						localityMapLocalityAttr: {
+							Description: "Locality of the node, e.g. `region=us-east1,zone=us-east1-b`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						localityMapTiersAttr: {
+							Description: "Tiers of the locality of the node, by key.",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						localityMapConstraintsAttr: {
+							Description: "Constraints matching the tiers of the locality of the node, e.g. `+region=us-east1`, from the outermost tier.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			localityMapTiersAttr: {
+				Description: "Keys of the tiers of the localities, from the outermost, with their distinct values.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						localityMapKeyAttr: {
+							Description: "Key of the tier, e.g. `region`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						localityMapValuesAttr: {
+							Description: "Sorted distinct values of the tier.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			localityMapLocalitiesAttr: {
+				Description: "Sorted distinct localities of the nodes.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26290), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     localityMapDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// localityTier is a tier of a locality, e.g. region=us-east1.
+type localityTier struct {
+	key   string
+	value string
+}
+
+// parseLocality returns the tiers of a locality such as
+// region=us-east1,zone=us-east1-b, from the outermost.
+func parseLocality(locality string) ([]localityTier, error) {
+	var tiers []localityTier
+	for _, tier := range strings.Split(locality, ",") {
+		if tier = strings.TrimSpace(tier); tier == "" {
+			continue
+		}
+
+		parts := strings.SplitN(tier, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("unexpected locality tier %q", tier)
+		}
+		tiers = append(tiers, localityTier{key: parts[0], value: parts[1]})
+	}
+
+	return tiers, nil
+}
+
+// localityTierValues returns the keys of the tiers of the localities, in the
+// order they first appear, with their sorted distinct values.
+func localityTierValues(localities [][]localityTier) []interface{} {
+	var keys []string
+	values := map[string][]string{}
+	for _, tiers := range localities {
+		for _, tier := range tiers {
+			if _, ok := values[tier.key]; !ok {
+				keys = append(keys, tier.key)
+			}
+			if !contains(values[tier.key], tier.value) {
+				values[tier.key] = append(values[tier.key], tier.value)
+			}
+		}
+	}
+
+	result := make([]interface{}, len(keys))
+	for i, key := range keys {
+		sort.Strings(values[key])
+		result[i] = map[string]interface{}{
+			localityMapKeyAttr:    key,
+			localityMapValuesAttr: values[key],
+		}
+	}
+
+	return result
+}
+
+func dataSourceLocalityMapRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	rows, err := conn.Query(ctx, `SELECT node_id, address, is_live, locality FROM crdb_internal.gossip_nodes ORDER BY node_id`)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer rows.Close()
+
+	nodes := []interface{}{}
+	var localities []string
+	var nodeTiers [][]localityTier
+	for rows.Next() {
+		var (
+			nodeID            int
+			address, locality string
+			isLive            bool
+		)
+		if err := rows.Scan(&nodeID, &address, &isLive, &locality); err != nil {
+			return diag.FromErr(err)
+		}
+
+		tiers, err := parseLocality(locality)
+		if err != nil {
+			return diag.Errorf("failed to parse the locality of node %d: %v", nodeID, err)
+		}
+		nodeTiers = append(nodeTiers, tiers)
+		if !contains(localities, locality) {
+			localities = append(localities, locality)
+		}
+
+		tierMap := map[string]interface{}{}
+		constraints := make([]string, len(tiers))
+		for i, tier := range tiers {
+			tierMap[tier.key] = tier.value
+			constraints[i] = "+" + tier.key + "=" + tier.value
+		}
+		nodes = append(nodes, map[string]interface{}{
+			localityMapNodeIDAttr:      nodeID,
+			localityMapAddressAttr:     address,
+			localityMapIsLiveAttr:      isLive,
+			localityMapLocalityAttr:    locality,
+			localityMapTiersAttr:       tierMap,
+			localityMapConstraintsAttr: constraints,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diag.FromErr(err)
+	}
+	sort.Strings(localities)
+
+	values := map[string]interface{}{
+		localityMapNodesAttr:      nodes,
+		localityMapTiersAttr:      localityTierValues(nodeTiers),
+		localityMapLocalitiesAttr: localities,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("locality_map")
+
+	return diag.Diagnostics{}
+}
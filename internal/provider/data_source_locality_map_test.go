@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceLocalityMap(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceLocalityMap,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cockroach_locality_map.foo", "nodes.0.node_id", "1"),
+					resource.TestCheckResourceAttr("data.cockroach_locality_map.foo", "nodes.0.is_live", "true"),
+					resource.TestCheckResourceAttrSet("data.cockroach_locality_map.foo", "localities.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestParseLocality(t *testing.T) {
+	tiers, err := parseLocality("region=us-east1, zone=us-east1-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []localityTier{{key: "region", value: "us-east1"}, {key: "zone", value: "us-east1-b"}}
+	if !reflect.DeepEqual(tiers, expected) {
+		t.Errorf("expected %v, got %v", expected, tiers)
+	}
+
+	if tiers, err := parseLocality(""); err != nil || len(tiers) != 0 {
+		t.Errorf("unexpected tiers of an empty locality %v, %v", tiers, err)
+	}
+	if _, err := parseLocality("region"); err == nil {
+		t.Error("expected an error for a tier without value")
+	}
+}
+
+func TestLocalityTierValues(t *testing.T) {
+	values := localityTierValues([][]localityTier{
+		{{key: "region", value: "us-west1"}, {key: "zone", value: "us-west1-a"}},
+		{{key: "region", value: "us-east1"}, {key: "zone", value: "us-east1-b"}},
+		{{key: "region", value: "us-east1"}, {key: "zone", value: "us-east1-b"}, {key: "rack", value: "1"}},
+	})
+	expected := []interface{}{
+		map[string]interface{}{"key": "region", "values": []string{"us-east1", "us-west1"}},
+		map[string]interface{}{"key": "zone", "values": []string{"us-east1-b", "us-west1-a"}},
+		map[string]interface{}{"key": "rack", "values": []string{"1"}},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+const testAccDataSourceLocalityMap = `
+data "cockroach_locality_map" "foo" {
+}
+`
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	nodesNodesAttr             = "nodes"
+	nodesNodeIDAttr            = "node_id"
+	nodesAddressAttr           = "address"
+	nodesSQLAddressAttr        = "sql_address"
+	nodesLocalityAttr          = "locality"
+	nodesBuildTagAttr          = "build_tag"
+	nodesStartedAtAttr         = "started_at"
+	nodesUpdatedAtAttr         = "updated_at"
+	nodesLivenessStatusAttr    = "liveness_status"
+	nodesNumCPUsAttr           = "num_cpus"
+	nodesTotalSystemMemoryAttr = "total_system_memory"
+	nodesMetricNamesAttr       = "metric_names"
+	nodesMetricsAttr           = "metrics"
+	nodesLivenessStatusesAttr  = "liveness_statuses"
+)
+
+func dataSourceNodes() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading the nodes of a CockroachDB cluster, with their liveness and metrics, over the HTTP API configured with the http_api block of the provider.",
+
+		ReadContext: dataSourceNodesRead,
+
+		Schema: map[string]*schema.Schema{
+			nodesLivenessStatusesAttr: {
+				Description: "Liveness statuses of the nodes to read, e.g. `live` or `decommissioning`, every node is read when not set.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			nodesMetricNamesAttr: {
+				Description: "Names of the metrics of the nodes to read, e.g. `sys.cpu.combined.percent-normalized`.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			nodesNodesAttr: {
+				Description: "Nodes, sorted by id.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						nodesNodeIDAttr: {
+							Description: "Id of the node.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						nodesAddressAttr: {
+							Description: "Address the node listens on for the other nodes.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesSQLAddressAttr: {
+							Description: "Address the node listens on for the SQL clients.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesLocalityAttr: {
+							Description: "Locality of the node, e.g. `region=us-east1,zone=us-east1-b`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesBuildTagAttr: {
+							Description: "Version of CockroachDB the node runs, e.g. `v23.1.11`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesStartedAtAttr: {
+							Description: "Time the node started at, in RFC 3339 format.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesUpdatedAtAttr: {
+							Description: "Time the status of the node was last updated at, in RFC 3339 format.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesLivenessStatusAttr: {
+							Description: "Liveness of the node, one of `live`, `dead`, `unavailable`, `decommissioning`, `decommissioned`, `draining` or `unknown`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						nodesNumCPUsAttr: {
+							Description: "Number of CPUs of the node.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						nodesTotalSystemMemoryAttr: {
+							Description: "Memory of the node, in bytes.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						nodesMetricsAttr: {
+							Description: "Values of the metrics of the metric_names argument reported by the node.",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeFloat,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceNodesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, closeClient, diags := openAdminClient(ctx, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeClient()
+
+	nodes, err := client.listNodes(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	statuses := convertToString(d.Get(nodesLivenessStatusesAttr).(*schema.Set).List())
+	metricNames := convertToString(d.Get(nodesMetricNamesAttr).(*schema.Set).List())
+
+	nodeList := []interface{}{}
+	for _, node := range nodes {
+		if len(statuses) > 0 && !contains(statuses, string(node.LivenessStatus)) {
+			continue
+		}
+		nodeList = append(nodeList, nodeToMap(node, metricNames))
+	}
+
+	if err := d.Set(nodesNodesAttr, nodeList); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("nodes")
+
+	return diag.Diagnostics{}
+}
+
+// nodeToMap returns the attributes of the node, with the metrics of the names
+// it reports.
+func nodeToMap(node httpAPINode, metricNames []string) map[string]interface{} {
+	metrics := map[string]interface{}{}
+	for _, name := range metricNames {
+		if value, ok := node.Metrics[name]; ok {
+			metrics[name] = value
+		}
+	}
+
+	return map[string]interface{}{
+		nodesNodeIDAttr:            int(node.NodeID),
+		nodesAddressAttr:           node.Address.Address,
+		nodesSQLAddressAttr:        node.SQLAddress.Address,
+		nodesLocalityAttr:          node.locality(),
+		nodesBuildTagAttr:          node.BuildTag,
+		nodesStartedAtAttr:         unixNanoToRFC3339(int64(node.StartedAt)),
+		nodesUpdatedAtAttr:         unixNanoToRFC3339(int64(node.UpdatedAt)),
+		nodesLivenessStatusAttr:    string(node.LivenessStatus),
+		nodesNumCPUsAttr:           int(node.NumCPUs),
+		nodesTotalSystemMemoryAttr: int(node.TotalSystemMemory),
+		nodesMetricsAttr:           metrics,
+	}
+}
+
+func unixNanoToRFC3339(nanos int64) string {
+	if nanos == 0 {
+		return ""
+	}
+
+	return time.Unix(0, nanos).UTC().Format(time.RFC3339)
+}
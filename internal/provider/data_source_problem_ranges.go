@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	problemRangesNodeIDAttr      = "node_id"
+	problemRangesHasProblemsAttr = "has_problems"
+	problemRangesNodeErrorsAttr  = "node_errors"
+
+	problemRangesUnavailableAttr     = "unavailable_range_ids"
+	problemRangesUnderreplicatedAttr = "underreplicated_range_ids"
+)
+
+// problemRangeCategories are the categories of problem ranges, read into a
+// list of range ids each.
+var problemRangeCategories = []struct {
+	attr        string
+	description string
+	ranges      func(httpAPIProblems) []jsonInt64
+}{
+	{problemRangesUnavailableAttr, "Ranges without a quorum of live replicas.", func(p httpAPIProblems) []jsonInt64 { return p.Unavailable }},
+	{problemRangesUnderreplicatedAttr, "Ranges with fewer replicas than configured.", func(p httpAPIProblems) []jsonInt64 { return p.Underreplicated }},
+	{"overreplicated_range_ids", "Ranges with more replicas than configured.", func(p httpAPIProblems) []jsonInt64 { return p.Overreplicated }},
+	{"no_lease_range_ids", "Ranges without a leaseholder.", func(p httpAPIProblems) []jsonInt64 { return p.NoLease }},
+	{"no_raft_leader_range_ids", "Ranges without a Raft leader.", func(p httpAPIProblems) []jsonInt64 { return p.NoRaftLeader }},
+	{"raft_leader_not_lease_holder_range_ids", "Ranges whose Raft leader isn't the leaseholder.", func(p httpAPIProblems) []jsonInt64 { return p.RaftLeaderNotLeaseHolder }},
+	{"quiescent_equals_ticking_range_ids", "Ranges both quiescent and ticking.", func(p httpAPIProblems) []jsonInt64 { return p.QuiescentEqualsTicking }},
+	{"raft_log_too_large_range_ids", "Ranges whose Raft log is too large.", func(p httpAPIProblems) []jsonInt64 { return p.RaftLogTooLarge }},
+	{"circuit_breaker_error_range_ids", "Ranges whose replication circuit breaker tripped.", func(p httpAPIProblems) []jsonInt64 { return p.CircuitBreakerError }},
+}
+
+func dataSourceProblemRanges() *schema.Resource {
+	s := map[string]*schema.Schema{
+		problemRangesNodeIDAttr: {
+			Description: "Id of the node to read the problem ranges of, every node is read when not set.",
+			Type:        schema.TypeInt,
+			Optional:    true,
+		},
+		problemRangesHasProblemsAttr: {
+			Description: "True when a range has a problem or a node couldn't be read.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		problemRangesNodeErrorsAttr: {
+			Description: "Errors of the nodes whose problem ranges couldn't be read, by node id.",
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+	for _, category := range problemRangeCategories {
+		s[category.attr] = &schema.Schema{
+			Description: category.description + " Sorted ids, reported by any node.",
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+		}
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading the problem ranges of a CockroachDB cluster, as shown by the Problem Ranges report of the DB Console, over the HTTP API configured with the http_api block of the provider.",
+
+		ReadContext: dataSourceProblemRangesRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceProblemRangesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, closeClient, diags := openAdminClient(ctx, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeClient()
+
+	nodeID := d.Get(problemRangesNodeIDAttr).(int)
+	problems, err := client.problemRanges(ctx, nodeID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	values := problemRangesToMap(problems)
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("problem_ranges")
+
+	return diag.Diagnostics{}
+}
+
+// problemRangesToMap returns the attributes of the problem ranges reported by
+// the nodes, a range reported by several nodes being listed once.
+func problemRangesToMap(problems map[string]httpAPIProblems) map[string]interface{} {
+	values := map[string]interface{}{}
+
+	hasProblems := false
+	nodeErrors := map[string]interface{}{}
+	for nodeID, p := range problems {
+		if p.ErrorMessage != "" {
+			nodeErrors[nodeID] = p.ErrorMessage
+			hasProblems = true
+		}
+	}
+
+	for _, category := range problemRangeCategories {
+		seen := map[int64]bool{}
+		ids := []int{}
+		for _, p := range problems {
+			for _, id := range category.ranges(p) {
+				if !seen[int64(id)] {
+					seen[int64(id)] = true
+					ids = append(ids, int(id))
+				}
+			}
+		}
+		sort.Ints(ids)
+
+		if len(ids) > 0 {
+			hasProblems = true
+		}
+		values[category.attr] = ids
+	}
+
+	values[problemRangesHasProblemsAttr] = hasProblems
+	values[problemRangesNodeErrorsAttr] = nodeErrors
+
+	return values
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProblemRangesToMap(t *testing.T) {
+	values := problemRangesToMap(map[string]httpAPIProblems{
+		"1": {Unavailable: []jsonInt64{12, 3}, Underreplicated: []jsonInt64{7}},
+		"2": {Unavailable: []jsonInt64{3}},
+		"3": {ErrorMessage: "node unavailable"},
+	})
+
+	if ids := values[problemRangesUnavailableAttr]; !reflect.DeepEqual(ids, []int{3, 12}) {
+		t.Errorf("unexpected unavailable ranges %v", ids)
+	}
+	if ids := values[problemRangesUnderreplicatedAttr]; !reflect.DeepEqual(ids, []int{7}) {
+		t.Errorf("unexpected underreplicated ranges %v", ids)
+	}
+	if ids := values["no_lease_range_ids"]; !reflect.DeepEqual(ids, []int{}) {
+		t.Errorf("unexpected ranges without lease %v", ids)
+	}
+	if errors := values[problemRangesNodeErrorsAttr]; !reflect.DeepEqual(errors, map[string]interface{}{"3": "node unavailable"}) {
+		t.Errorf("unexpected node errors %v", errors)
+	}
+	if values[problemRangesHasProblemsAttr] != true {
+		t.Error("expected problems")
+	}
+
+	if values := problemRangesToMap(map[string]httpAPIProblems{"1": {}}); values[problemRangesHasProblemsAttr] != false {
+		t.Errorf("unexpected problems %v", values)
+	}
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryQueryAttr      = "query"
+	queryDatabaseAttr   = "database"
+	queryParametersAttr = "parameters"
+	queryMaxRowsAttr    = "max_rows"
+	queryColumnsAttr    = "columns"
+	queryRowsAttr       = "rows"
+
+	queryDefaultLocalPort = "26298"
+)
+
+func dataSourceQuery() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source running a read-only SQL query on a CockroachDB cluster and returning its rows, to derive configuration from data the provider doesn't model. " +
+			"The query runs in a read-only transaction, so it can't change anything.",
+
+		ReadContext: dataSourceQueryRead,
+
+		Schema: map[string]*schema.Schema{
+			queryQueryAttr: {
+				Description:  "Single SQL query to run, e.g. `SELECT name, value FROM app.public.config WHERE name = $1`, its placeholders taking the parameters.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			queryParametersAttr: {
+				Description: "Values of the placeholders `$1`, `$2`... of the query, bound by CockroachDB rather than interpolated into it.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			queryDatabaseAttr: {
+				Description: "Database the query runs in, the database of the connection of the provider when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			queryMaxRowsAttr: {
+				Description:  "Maximum number of rows the query may return, reading fails when it returns more.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1000,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			queryColumnsAttr: {
+				Description: "Names of the columns of the rows, in the order of the query.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			queryRowsAttr: {
+				Description: "Rows returned by the query, in its order, as maps of the text representation of their values keyed by column name. The NULL values are left out of the maps.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeMap,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + queryDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     queryDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func dataSourceQueryRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnectionIn(ctx, d, meta, d.Get(queryDatabaseAttr).(string))
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	var parameters []interface{}
+	for _, p := range d.Get(queryParametersAttr).([]interface{}) {
+		parameters = append(parameters, p)
+	}
+
+	columns, rows, err := runReadOnlyQuery(ctx, conn, d.Get(queryQueryAttr).(string), parameters, d.Get(queryMaxRowsAttr).(int))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	values := map[string]interface{}{
+		queryColumnsAttr: columns,
+		queryRowsAttr:    rows,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("query")
+
+	return diag.Diagnostics{}
+}
+
+// runReadOnlyQuery runs the query in a read-only transaction, rolled back
+// once read, and returns its columns and rows. The values are read in the
+// text format, as psql shows them. The query is sent with the extended
+// protocol, which only accepts a single statement, so it can't end the
+// transaction to run other statements.
+func runReadOnlyQuery(ctx context.Context, conn *pgx.Conn, query string, parameters []interface{}, maxRows int) ([]string, []interface{}, error) {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logError("failed to roll back the query: %v", err)
+		}
+	}()
+
+	options := []interface{}{pgx.QuerySimpleProtocol(false), pgx.QueryResultFormats{pgx.TextFormatCode}}
+	rows, err := tx.Query(ctx, query, append(options, parameters...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for _, field := range rows.FieldDescriptions() {
+		if contains(columns, string(field.Name)) {
+			return nil, nil, fmt.Errorf("the query returns several %s columns, give them different aliases", field.Name)
+		}
+		columns = append(columns, string(field.Name))
+	}
+
+	result := []interface{}{}
+	for rows.Next() {
+		if len(result) == maxRows {
+			return nil, nil, fmt.Errorf("the query returned more than %d rows, add a LIMIT or raise %s", maxRows, queryMaxRowsAttr)
+		}
+		result = append(result, queryRowToMap(columns, rows.RawValues()))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return columns, result, nil
+}
+
+// queryRowToMap returns the values of a row keyed by column name, leaving out
+// the NULL ones.
+func queryRowToMap(columns []string, values [][]byte) map[string]interface{} {
+	row := map[string]interface{}{}
+	for i, column := range columns {
+		if values[i] != nil {
+			row[column] = string(values[i])
+		}
+	}
+
+	return row
+}
@@ -0,0 +1,15 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryRowToMap(t *testing.T) {
+	row := queryRowToMap([]string{"name", "value", "comment"}, [][]byte{[]byte("ttl"), []byte("1h"), nil})
+
+	expected := map[string]interface{}{"name": "ttl", "value": "1h"}
+	if !reflect.DeepEqual(row, expected) {
+		t.Errorf("expected %v, got %v", expected, row)
+	}
+}
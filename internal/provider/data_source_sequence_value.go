@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	sequenceValueDatabaseAttr  = "database"
+	sequenceValueSchemaAttr    = "schema"
+	sequenceValueNameAttr      = "name"
+	sequenceValueLastValueAttr = "last_value"
+	sequenceValueIsCalledAttr  = "is_called"
+
+	sequenceValueDefaultLocalPort = "26305"
+)
+
+func dataSourceSequenceValue() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading the current value of a sequence, without generating one, e.g. to restart another sequence after it during a migration of ids.",
+
+		ReadContext: dataSourceSequenceValueRead,
+
+		Schema: map[string]*schema.Schema{
+			sequenceValueDatabaseAttr: {
+				Description: "Name of the database of the sequence, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			sequenceValueSchemaAttr: {
+				Description: "Name of the schema of the sequence, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			sequenceValueNameAttr: {
+				Description: "Name of the sequence.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			sequenceValueLastValueAttr: {
+				Description: "Last value generated by the sequence, or its start when `is_called` is false.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			sequenceValueIsCalledAttr: {
+				Description: "Whether the sequence generated a value since it was created or restarted, the next value being `last_value` when it didn't.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + sequenceValueDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     sequenceValueDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func dataSourceSequenceValueRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(sequenceValueDatabaseAttr).(string)
+	if database == "" {
+		database = meta.(*cockroachClient).defaultDatabase
+	}
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", sequenceValueDatabaseAttr)
+	}
+	schemaName := d.Get(sequenceValueSchemaAttr).(string)
+	if schemaName == "" {
+		schemaName = defaultSchemaOf(meta)
+	}
+	name := d.Get(sequenceValueNameAttr).(string)
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	var lastValue int64
+	var isCalled bool
+	err := conn.QueryRow(ctx, `SELECT last_value, is_called FROM `+qualifiedNames(database, schemaName, []string{name})).Scan(&lastValue, &isCalled)
+	if err != nil {
+		return diag.Errorf("failed to read the value of the sequence %s: %v", name, err)
+	}
+
+	values := map[string]interface{}{
+		sequenceValueDatabaseAttr:  database,
+		sequenceValueSchemaAttr:    schemaName,
+		sequenceValueLastValueAttr: int(lastValue),
+		sequenceValueIsCalledAttr:  isCalled,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId(database + "/" + schemaName + "/" + name)
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceSequenceValue(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceSequenceValue,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cockroach_sequence_value.foo", "last_value", "1000"),
+					resource.TestCheckResourceAttr("data.cockroach_sequence_value.foo", "is_called", "false"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceSequenceValue = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_sequence" "foo" {
+  database = cockroach_database.foo.name
+  name     = "order_ids"
+  start    = 1000
+}
+
+data "cockroach_sequence_value" "foo" {
+  database = cockroach_database.foo.name
+  name     = cockroach_sequence.foo.name
+}
+`
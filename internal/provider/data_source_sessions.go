@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	sessionsUserAttr             = "user"
+	sessionsApplicationNameAttr  = "application_name"
+	sessionsMinAgeAttr           = "min_age"
+	sessionsExcludeInternalAttr  = "exclude_internal"
+	sessionsExcludeTerraformAttr = "exclude_terraform"
+	sessionsSessionsAttr         = "sessions"
+	sessionsSessionCountAttr     = "session_count"
+	sessionsNodeIDAttr           = "node_id"
+	sessionsSessionIDAttr        = "session_id"
+	sessionsClientAddressAttr    = "client_address"
+	sessionsActiveAttr           = "active"
+	sessionsActiveStatementsAttr = "active_statements"
+	sessionsLastStatementAttr    = "last_statement"
+	sessionsStartedAtAttr        = "started_at"
+	sessionsAgeSecondsAttr       = "age_seconds"
+
+	// internalApplicationNamePrefix prefixes the application name of the
+	// sessions opened by CockroachDB itself.
+	internalApplicationNamePrefix = "$ internal"
+	// terraformApplicationNamePrefix prefixes the default application name of
+	// the sessions opened by the provider.
+	terraformApplicationNamePrefix = "terraform-provider-cockroach/"
+
+	sessionsDefaultLocalPort = "26293"
+)
+
+func dataSourceSessions() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source listing the open SQL sessions of a CockroachDB cluster, e.g. to check that no long running session is open before a destructive change. " +
+			"The session of the data source itself is never listed.",
+
+		ReadContext: dataSourceSessionsRead,
+
+		Schema: map[string]*schema.Schema{
+			sessionsUserAttr: {
+				Description: "Name of the user to list the sessions of, the sessions of every user are listed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			sessionsApplicationNameAttr: {
+				Description: "Application name to list the sessions of, the sessions of every application are listed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			sessionsMinAgeAttr: {
+				Description:  "Minimum time the sessions have been open for to be listed, e.g. `10m`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0s",
+				ValidateFunc: validateDuration,
+			},
+			sessionsExcludeInternalAttr: {
+				Description: "Whether the sessions opened by CockroachDB itself, whose application name starts with `$ internal`, aren't listed.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			sessionsExcludeTerraformAttr: {
+				Description: "Whether the sessions opened by the provider with its default application name, `terraform-provider-cockroach/<version>`, aren't listed.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			sessionsSessionCountAttr: {
+				Description: "Number of sessions listed.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			sessionsSessionsAttr: {
+				Description: "Sessions, the oldest first.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						sessionsNodeIDAttr: {
+							Description: "Id of the node the session is open on.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						sessionsSessionIDAttr: {
+							Description: "Id of the session, which `CANCEL SESSION` takes.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsUserAttr: {
+							Description: "User of the session.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsApplicationNameAttr: {
+							Description: "Application name of the session.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsClientAddressAttr: {
+							Description: "Address of the client.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsActiveAttr: {
+							Description: "Whether a statement of the session is running.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						sessionsActiveStatementsAttr: {
+							Description: "Statements of the session which are running.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsLastStatementAttr: {
+							Description: "Last statement the session ran.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsStartedAtAttr: {
+							Description: "Time the session was opened at.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sessionsAgeSecondsAttr: {
+							Description: "Time the session has been open for, in seconds.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26293), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     sessionsDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// sqlSession is a row of crdb_internal.cluster_sessions.
+type sqlSession struct {
+	nodeID           int
+	sessionID        string
+	user             string
+	applicationName  string
+	clientAddress    string
+	activeStatements string
+	lastStatement    string
+	startedAt        time.Time
+	age              time.Duration
+}
+
+func (s sqlSession) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		sessionsNodeIDAttr:           s.nodeID,
+		sessionsSessionIDAttr:        s.sessionID,
+		sessionsUserAttr:             s.user,
+		sessionsApplicationNameAttr:  s.applicationName,
+		sessionsClientAddressAttr:    s.clientAddress,
+		sessionsActiveAttr:           s.activeStatements != "",
+		sessionsActiveStatementsAttr: s.activeStatements,
+		sessionsLastStatementAttr:    s.lastStatement,
+		sessionsStartedAtAttr:        s.startedAt.UTC().Format(time.RFC3339),
+		sessionsAgeSecondsAttr:       int(s.age.Seconds()),
+	}
+}
+
+// sessionFilter selects the sessions listed by the data source.
+type sessionFilter struct {
+	user             string
+	applicationName  string
+	minAge           time.Duration
+	excludeInternal  bool
+	excludeTerraform bool
+}
+
+func (f sessionFilter) matches(s sqlSession) bool {
+	switch {
+	case f.user != "" && s.user != f.user:
+		return false
+	case f.applicationName != "" && s.applicationName != f.applicationName:
+		return false
+	case s.age < f.minAge:
+		return false
+	case f.excludeInternal && strings.HasPrefix(s.applicationName, internalApplicationNamePrefix):
+		return false
+	case f.excludeTerraform && strings.HasPrefix(s.applicationName, terraformApplicationNamePrefix):
+		return false
+	}
+
+	return true
+}
+
+func dataSourceSessionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	minAge, err := time.ParseDuration(d.Get(sessionsMinAgeAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	filter := sessionFilter{
+		user:             d.Get(sessionsUserAttr).(string),
+		applicationName:  d.Get(sessionsApplicationNameAttr).(string),
+		minAge:           minAge,
+		excludeInternal:  d.Get(sessionsExcludeInternalAttr).(bool),
+		excludeTerraform: d.Get(sessionsExcludeTerraformAttr).(bool),
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	rows, err := conn.Query(ctx,
+		`SELECT node_id, session_id, user_name, application_name, client_address, active_queries, last_active_query, session_start, `+
+			`(extract(epoch FROM now() - session_start) * 1000)::INT8 FROM crdb_internal.cluster_sessions `+
+			`WHERE session_id != (SELECT value FROM [SHOW session_id]) ORDER BY session_start, session_id`)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer rows.Close()
+
+	sessions := []interface{}{}
+	for rows.Next() {
+		var (
+			s     sqlSession
+			ageMs int64
+		)
+		if err := rows.Scan(&s.nodeID, &s.sessionID, &s.user, &s.applicationName, &s.clientAddress, &s.activeStatements, &s.lastStatement, &s.startedAt, &ageMs); err != nil {
+			return diag.FromErr(err)
+		}
+		s.age = time.Duration(ageMs) * time.Millisecond
+		if filter.matches(s) {
+			sessions = append(sessions, s.toMap())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	values := map[string]interface{}{
+		sessionsSessionCountAttr: len(sessions),
+		sessionsSessionsAttr:     sessions,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("sessions")
+
+	return diag.Diagnostics{}
+}
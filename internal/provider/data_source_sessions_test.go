@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceSessions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceSessions,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cockroach_sessions.foo", "session_count", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestSessionFilter(t *testing.T) {
+	sessions := map[string]sqlSession{
+		"app":       {user: "app", applicationName: "billing", age: time.Hour},
+		"young":     {user: "app", applicationName: "billing", age: time.Minute},
+		"internal":  {user: "node", applicationName: "$ internal-job", age: time.Hour},
+		"terraform": {user: "root", applicationName: "terraform-provider-cockroach/1.0.0", age: time.Hour},
+		"shell":     {user: "root", applicationName: "$ cockroach sql", age: time.Hour},
+	}
+
+	for name, test := range map[string]struct {
+		filter   sessionFilter
+		expected []string
+	}{
+		"default":     {sessionFilter{excludeInternal: true, excludeTerraform: true}, []string{"app", "shell", "young"}},
+		"everything":  {sessionFilter{}, []string{"app", "internal", "shell", "terraform", "young"}},
+		"min age":     {sessionFilter{minAge: 10 * time.Minute, excludeInternal: true, excludeTerraform: true}, []string{"app", "shell"}},
+		"user":        {sessionFilter{user: "root"}, []string{"shell", "terraform"}},
+		"application": {sessionFilter{applicationName: "billing", minAge: 10 * time.Minute}, []string{"app"}},
+	} {
+		var matched []string
+		for _, session := range []string{"app", "internal", "shell", "terraform", "young"} {
+			if test.filter.matches(sessions[session]) {
+				matched = append(matched, session)
+			}
+		}
+		if !reflect.DeepEqual(matched, test.expected) {
+			t.Errorf("%s: expected %v, got %v", name, test.expected, matched)
+		}
+	}
+}
+
+const testAccDataSourceSessions = `
+data "cockroach_sessions" "foo" {
+  min_age = "24h"
+}
+`
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	storeCapacityNodeIDAttr         = "node_id"
+	storeCapacityStoreIDAttr        = "store_id"
+	storeCapacityStoresAttr         = "stores"
+	storeCapacityNodesAttr          = "nodes"
+	storeCapacityCapacityBytesAttr  = "capacity_bytes"
+	storeCapacityAvailableBytesAttr = "available_bytes"
+	storeCapacityUsedBytesAttr      = "used_bytes"
+	storeCapacityLogicalBytesAttr   = "logical_bytes"
+	storeCapacityUsedPercentAttr    = "used_percent"
+	storeCapacityRangeCountAttr     = "range_count"
+
+	storeCapacityDefaultLocalPort = "26289"
+)
+
+// capacitySchema returns the schema of the capacity of a store, a node or the
+// cluster, computed or nested in a computed list.
+func capacitySchema(s map[string]*schema.Schema, of string) map[string]*schema.Schema {
+	s[storeCapacityCapacityBytesAttr] = &schema.Schema{
+		Description: "Disk capacity of the " + of + ", in bytes.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	}
+	s[storeCapacityAvailableBytesAttr] = &schema.Schema{
+		Description: "Disk space available to the " + of + ", in bytes.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	}
+	s[storeCapacityUsedBytesAttr] = &schema.Schema{
+		Description: "Disk space used by CockroachDB on the " + of + ", in bytes.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	}
+	s[storeCapacityLogicalBytesAttr] = &schema.Schema{
+		Description: "Logical size of the data of the " + of + ", before compression and replication, in bytes.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	}
+	s[storeCapacityUsedPercentAttr] = &schema.Schema{
+		Description: "Percentage of the usable space of the " + of + " used by CockroachDB, the usable space being the used and the available space, as shown by the DB Console.",
+		Type:        schema.TypeFloat,
+		Computed:    true,
+	}
+
+	return s
+}
+
+func dataSourceStoreCapacity() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading the disk capacity and usage of the stores of a CockroachDB cluster, per store, per node and for the whole cluster.",
+
+		ReadContext: dataSourceStoreCapacityRead,
+
+		Schema: capacitySchema(map[string]*schema.Schema{
+			storeCapacityNodeIDAttr: {
+				Description: "Id of the node to read the stores of, every node is read when not set.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			storeCapacityStoresAttr: {
+				Description: "Stores, sorted by node and store id.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: capacitySchema(map[string]*schema.Schema{
+						storeCapacityNodeIDAttr: {
+							Description: "Id of the node of the store.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						storeCapacityStoreIDAttr: {
+							Description: "Id of the store.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						storeCapacityRangeCountAttr: {
+							Description: "Number of replicas of the store.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					}, "store"),
+				},
+			},
+			storeCapacityNodesAttr: {
+				Description: "Nodes, sorted by id, with the capacity of their stores summed up.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: capacitySchema(map[string]*schema.Schema{
+						storeCapacityNodeIDAttr: {
+							Description: "Id of the node.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					}, "node"),
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26289), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     storeCapacityDefaultLocalPort,
+			},
+		}, "cluster"),
+	}
+}
+
+// storeCapacity is the capacity of a store, or the sum of the capacity of
+// stores.
+type storeCapacity struct {
+	nodeID         int
+	storeID        int
+	capacityBytes  int
+	availableBytes int
+	usedBytes      int
+	logicalBytes   int
+	rangeCount     int
+}
+
+func (c storeCapacity) usedPercent() float64 {
+	if c.usedBytes+c.availableBytes == 0 {
+		return 0
+	}
+
+	return float64(c.usedBytes) * 100 / float64(c.usedBytes+c.availableBytes)
+}
+
+func (c storeCapacity) add(o storeCapacity) storeCapacity {
+	c.capacityBytes += o.capacityBytes
+	c.availableBytes += o.availableBytes
+	c.usedBytes += o.usedBytes
+	c.logicalBytes += o.logicalBytes
+	c.rangeCount += o.rangeCount
+
+	return c
+}
+
+func (c storeCapacity) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		storeCapacityCapacityBytesAttr:  c.capacityBytes,
+		storeCapacityAvailableBytesAttr: c.availableBytes,
+		storeCapacityUsedBytesAttr:      c.usedBytes,
+		storeCapacityLogicalBytesAttr:   c.logicalBytes,
+		storeCapacityUsedPercentAttr:    c.usedPercent(),
+	}
+}
+
+// nodeCapacities sums up the capacity of the stores by node, sorted by node
+// id.
+func nodeCapacities(stores []storeCapacity) []storeCapacity {
+	index := map[int]int{}
+	var nodes []storeCapacity
+	for _, store := range stores {
+		i, ok := index[store.nodeID]
+		if !ok {
+			i = len(nodes)
+			index[store.nodeID] = i
+			nodes = append(nodes, storeCapacity{nodeID: store.nodeID})
+		}
+		nodes[i] = nodes[i].add(store)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].nodeID < nodes[j].nodeID })
+
+	return nodes
+}
+
+func dataSourceStoreCapacityRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	var nodeID interface{}
+	if v, ok := d.GetOk(storeCapacityNodeIDAttr); ok {
+		nodeID = v.(int)
+	}
+	rows, err := conn.Query(ctx,
+		`SELECT node_id, store_id, capacity, available, used, logical_bytes, range_count FROM crdb_internal.kv_store_status `+
+			`WHERE $1::INT IS NULL OR node_id = $1::INT ORDER BY node_id, store_id`, nodeID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer rows.Close()
+
+	var stores []storeCapacity
+	for rows.Next() {
+		var s storeCapacity
+		if err := rows.Scan(&s.nodeID, &s.storeID, &s.capacityBytes, &s.availableBytes, &s.usedBytes, &s.logicalBytes, &s.rangeCount); err != nil {
+			return diag.FromErr(err)
+		}
+		stores = append(stores, s)
+	}
+	if err := rows.Err(); err != nil {
+		return diag.FromErr(err)
+	}
+	if len(stores) == 0 {
+		return diag.Errorf("no store found")
+	}
+
+	var cluster storeCapacity
+	storeList := make([]interface{}, len(stores))
+	for i, store := range stores {
+		cluster = cluster.add(store)
+		m := store.toMap()
+		m[storeCapacityNodeIDAttr] = store.nodeID
+		m[storeCapacityStoreIDAttr] = store.storeID
+		m[storeCapacityRangeCountAttr] = store.rangeCount
+		storeList[i] = m
+	}
+
+	var nodeList []interface{}
+	for _, node := range nodeCapacities(stores) {
+		m := node.toMap()
+		m[storeCapacityNodeIDAttr] = node.nodeID
+		nodeList = append(nodeList, m)
+	}
+
+	values := cluster.toMap()
+	values[storeCapacityStoresAttr] = storeList
+	values[storeCapacityNodesAttr] = nodeList
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId("store_capacity")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceStoreCapacity(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceStoreCapacity,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.cockroach_store_capacity.foo", "capacity_bytes"),
+					resource.TestCheckResourceAttrSet("data.cockroach_store_capacity.foo", "used_percent"),
+					resource.TestCheckResourceAttr("data.cockroach_store_capacity.foo", "nodes.#", "1"),
+					resource.TestCheckResourceAttr("data.cockroach_store_capacity.foo", "nodes.0.node_id", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestNodeCapacities(t *testing.T) {
+	nodes := nodeCapacities([]storeCapacity{
+		{nodeID: 2, storeID: 3, capacityBytes: 100, availableBytes: 60, usedBytes: 20},
+		{nodeID: 1, storeID: 1, capacityBytes: 100, availableBytes: 50, usedBytes: 50},
+		{nodeID: 2, storeID: 4, capacityBytes: 100, availableBytes: 20, usedBytes: 20},
+	})
+	if len(nodes) != 2 {
+		t.Fatalf("unexpected nodes %v", nodes)
+	}
+	if nodes[0].nodeID != 1 || nodes[0].capacityBytes != 100 || nodes[0].usedPercent() != 50 {
+		t.Errorf("unexpected node %v", nodes[0])
+	}
+	if nodes[1].nodeID != 2 || nodes[1].capacityBytes != 200 || nodes[1].availableBytes != 80 || nodes[1].usedBytes != 40 {
+		t.Errorf("unexpected node %v", nodes[1])
+	}
+	if percent := nodes[1].usedPercent(); percent != float64(40)*100/120 {
+		t.Errorf("unexpected used percent %v", percent)
+	}
+
+	if percent := (storeCapacity{}).usedPercent(); percent != 0 {
+		t.Errorf("unexpected used percent of an empty store %v", percent)
+	}
+}
+
+const testAccDataSourceStoreCapacity = `
+data "cockroach_store_capacity" "foo" {
+}
+`
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	userOptionsAttr        = "options"
+	userMemberOfAttr       = "member_of"
+	userInheritedRolesAttr = "inherited_roles"
+
+	userDefaultLocalPort = "26292"
+)
+
+func dataSourceUser() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source reading a user or role of a CockroachDB cluster with its options and the roles it is a member of, directly or through other roles.",
+
+		ReadContext: dataSourceUserRead,
+
+		Schema: map[string]*schema.Schema{
+			dbUsernameAttr: {
+				Description: "Name of the user.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			userOptionsAttr: {
+				Description: "Options of the user, e.g. `NOLOGIN` or `VALID UNTIL=2030-01-01 00:00:00+00`.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			userMemberOfAttr: {
+				Description: "Sorted roles the user was granted directly.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			userInheritedRolesAttr: {
+				Description: "Sorted roles the user is a member of, directly or through other roles.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			dbAdminAttr: {
+				Description: "True if the user is a member of the `admin` role, directly or through other roles.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26292), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     userDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// parseUserOptions returns the options of a row of SHOW USERS, listed as
+// NOLOGIN, VALID UNTIL=... or {NOLOGIN,"VALID UNTIL=..."} depending on the
+// version.
+func parseUserOptions(options string) []string {
+	options = strings.TrimSuffix(strings.TrimPrefix(options, "{"), "}")
+
+	result := []string{}
+	for _, option := range strings.Split(options, ",") {
+		if option = strings.Trim(strings.TrimSpace(option), `"`); option != "" {
+			result = append(result, option)
+		}
+	}
+
+	return result
+}
+
+// inheritedRoles returns the sorted roles the user is a member of, directly or
+// through other roles.
+func inheritedRoles(users []userRow, name string) []string {
+	memberOf := map[string][]string{}
+	for _, user := range users {
+		memberOf[user.username] = user.memberOf
+	}
+
+	roles := []string{}
+	seen := map[string]bool{name: true}
+	pending := append([]string{}, memberOf[name]...)
+	for len(pending) > 0 {
+		role := pending[0]
+		pending = pending[1:]
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+		roles = append(roles, role)
+		pending = append(pending, memberOf[role]...)
+	}
+	sort.Strings(roles)
+
+	return roles
+}
+
+func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	users, err := showUsers(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	name := d.Get(dbUsernameAttr).(string)
+	var user *userRow
+	for i := range users {
+		if roleNamesEqual(users[i].username, name) {
+			user = &users[i]
+			break
+		}
+	}
+	if user == nil {
+		return diag.Errorf("user %s not found", name)
+	}
+
+	memberOf := append([]string{}, user.memberOf...)
+	sort.Strings(memberOf)
+	inherited := inheritedRoles(users, name)
+
+	values := map[string]interface{}{
+		userOptionsAttr:        parseUserOptions(user.options),
+		userMemberOfAttr:       memberOf,
+		userInheritedRolesAttr: inherited,
+		dbAdminAttr:            name == "admin" || contains(inherited, "admin"),
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId(name)
+
+	return diag.Diagnostics{}
+}
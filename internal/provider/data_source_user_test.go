@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceUser(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceUser,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cockroach_user.foo", "is_admin", "true"),
+					resource.TestCheckResourceAttr("data.cockroach_user.foo", "member_of.0", "admin"),
+					resource.TestCheckResourceAttr("data.cockroach_user.foo", "inherited_roles.0", "admin"),
+				),
+			},
+		},
+	})
+}
+
+func TestParseUserOptions(t *testing.T) {
+	for options, expected := range map[string][]string{
+		"":                                   {},
+		"NOLOGIN":                            {"NOLOGIN"},
+		"NOLOGIN, VALID UNTIL=2030-01-01":    {"NOLOGIN", "VALID UNTIL=2030-01-01"},
+		`{NOLOGIN,"VALID UNTIL=2030-01-01"}`: {"NOLOGIN", "VALID UNTIL=2030-01-01"},
+		"{}":                                 {},
+	} {
+		if actual := parseUserOptions(options); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %v for %q, got %v", expected, options, actual)
+		}
+	}
+}
+
+func TestInheritedRoles(t *testing.T) {
+	users := []userRow{
+		{username: "alice", memberOf: []string{"developer", "reader"}},
+		{username: "developer", memberOf: []string{"writer"}},
+		{username: "writer", memberOf: []string{"reader"}},
+		{username: "reader"},
+		{username: "cycle", memberOf: []string{"loop"}},
+		{username: "loop", memberOf: []string{"cycle"}},
+	}
+
+	if roles := inheritedRoles(users, "alice"); !reflect.DeepEqual(roles, []string{"developer", "reader", "writer"}) {
+		t.Errorf("unexpected roles %v", roles)
+	}
+	if roles := inheritedRoles(users, "reader"); len(roles) != 0 {
+		t.Errorf("unexpected roles %v", roles)
+	}
+	if roles := inheritedRoles(users, "cycle"); !reflect.DeepEqual(roles, []string{"loop"}) {
+		t.Errorf("unexpected roles %v", roles)
+	}
+}
+
+const testAccDataSourceUser = `
+data "cockroach_user" "foo" {
+  username = "root"
+}
+`
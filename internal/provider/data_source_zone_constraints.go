@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	zoneConstraintsRequiredAttr         = "required"
+	zoneConstraintsProhibitedAttr       = "prohibited"
+	zoneConstraintsReplicaAttr          = "replica"
+	zoneConstraintsNumReplicasAttr      = "num_replicas"
+	zoneConstraintsLeasePreferenceAttr  = "lease_preference"
+	zoneConstraintsConstraintsAttr      = "constraints"
+	zoneConstraintsLeasePreferencesAttr = "lease_preferences"
+)
+
+// zoneConstraintTierRegexp matches the keys and values of the locality tiers,
+// and the attributes, of the constraints.
+var zoneConstraintTierRegexp = regexp.MustCompile(`^[^=\s,:\[\]{}"']+$`)
+
+func dataSourceZoneConstraints() *schema.Resource {
+	tiers := func(description string, required bool) *schema.Schema {
+		return &schema.Schema{
+			Description:  description,
+			Type:         schema.TypeMap,
+			Required:     required,
+			Optional:     !required,
+			Elem:         &schema.Schema{Type: schema.TypeString},
+			ValidateFunc: validateZoneConstraintTiers,
+		}
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Data source formatting the constraints and lease preferences of zone configurations, e.g. `[+region=us-east1, -zone=us-east1-b]`, from the locality tiers of the replicas, without connecting to the cluster. The tiers are given by key, an attribute of the nodes or stores, e.g. `ssd`, having an empty value.",
+
+		ReadContext: dataSourceZoneConstraintsRead,
+
+		Schema: map[string]*schema.Schema{
+			zoneConstraintsRequiredAttr:   tiers("Locality tiers all the replicas must have.", false),
+			zoneConstraintsProhibitedAttr: tiers("Locality tiers none of the replicas can have.", false),
+			zoneConstraintsReplicaAttr: {
+				Description:   "Per-replica constraints, the locality tiers of a number of the replicas, the other replicas being placed anywhere. Can't be set with required and prohibited.",
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{zoneConstraintsRequiredAttr, zoneConstraintsProhibitedAttr},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						zoneConstraintsNumReplicasAttr: {
+							Description:  "Number of replicas with the locality tiers.",
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						zoneConstraintsRequiredAttr: tiers("Locality tiers of the replicas.", true),
+					},
+				},
+			},
+			zoneConstraintsLeasePreferenceAttr: {
+				Description: "Lease preferences, in order, the leaseholder being placed on a replica with the locality tiers of the first preference it can.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						zoneConstraintsRequiredAttr: tiers("Locality tiers of the leaseholder.", true),
+					},
+				},
+			},
+			zoneConstraintsConstraintsAttr: {
+				Description: "Constraints of the replicas, e.g. `[+region=us-east1]`, or `{\"+region=us-east1\": 2}` for per-replica constraints, to set as the `constraints` or `voter_constraints` of a zone configuration. Empty when no tiers are set.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			zoneConstraintsLeasePreferencesAttr: {
+				Description: "Lease preferences, e.g. `[[+region=us-east1], [+region=us-west1]]`, to set as the `lease_preferences` of a zone configuration. Empty when no lease preference is set.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// validateZoneConstraintTiers checks the keys and values of the tiers can be
+// formatted as constraints.
+func validateZoneConstraintTiers(v interface{}, k string) ([]string, []error) {
+	var errs []error
+	for key, value := range v.(map[string]interface{}) {
+		if !zoneConstraintTierRegexp.MatchString(key) {
+			errs = append(errs, fmt.Errorf("%s: unexpected locality tier key %q", k, key))
+		}
+		if value := value.(string); value != "" && !zoneConstraintTierRegexp.MatchString(value) {
+			errs = append(errs, fmt.Errorf("%s: unexpected value %q of locality tier %s", k, value, key))
+		}
+	}
+	return nil, errs
+}
+
+func dataSourceZoneConstraintsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var constraints string
+	if replicas := d.Get(zoneConstraintsReplicaAttr).([]interface{}); len(replicas) > 0 {
+		counts := map[string]int{}
+		for _, raw := range replicas {
+			replica := raw.(map[string]interface{})
+			key := strings.Join(zoneConstraintList("+", replica[zoneConstraintsRequiredAttr].(map[string]interface{})), ",")
+			if key == "" {
+				return diag.Errorf("the %s blocks must have locality tiers", zoneConstraintsReplicaAttr)
+			}
+			if _, ok := counts[key]; ok {
+				return diag.Errorf("the replicas with locality tiers %s are given more than once", key)
+			}
+			counts[key] = replica[zoneConstraintsNumReplicasAttr].(int)
+		}
+		constraints = formatPerReplicaConstraints(counts)
+	} else {
+		list := append(
+			zoneConstraintList("+", d.Get(zoneConstraintsRequiredAttr).(map[string]interface{})),
+			zoneConstraintList("-", d.Get(zoneConstraintsProhibitedAttr).(map[string]interface{}))...)
+		if len(list) > 0 {
+			constraints = "[" + strings.Join(list, ", ") + "]"
+		}
+	}
+
+	var leasePreferences string
+	if preferences := d.Get(zoneConstraintsLeasePreferenceAttr).([]interface{}); len(preferences) > 0 {
+		lists := make([]string, 0, len(preferences))
+		for _, raw := range preferences {
+			preference := zoneConstraintList("+", raw.(map[string]interface{})[zoneConstraintsRequiredAttr].(map[string]interface{}))
+			if len(preference) == 0 {
+				return diag.Errorf("the %s blocks must have locality tiers", zoneConstraintsLeasePreferenceAttr)
+			}
+			lists = append(lists, "["+strings.Join(preference, ", ")+"]")
+		}
+		leasePreferences = "[" + strings.Join(lists, ", ") + "]"
+	}
+
+	if err := d.Set(zoneConstraintsConstraintsAttr, constraints); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(zoneConstraintsLeasePreferencesAttr, leasePreferences); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.Itoa(schema.HashString(constraints + "|" + leasePreferences)))
+
+	return diag.Diagnostics{}
+}
+
+// zoneConstraintList returns the constraints of the tiers, sorted by key, with
+// the prefix, + or -, e.g. +region=us-east1 or +ssd for an attribute.
+func zoneConstraintList(prefix string, tiers map[string]interface{}) []string {
+	keys := make([]string, 0, len(tiers))
+	for key := range tiers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := tiers[key].(string); value != "" {
+			list = append(list, prefix+key+"="+value)
+		} else {
+			list = append(list, prefix+key)
+		}
+	}
+	return list
+}
+
+// formatPerReplicaConstraints returns the per-replica constraints, the
+// number of replicas by comma separated constraints, sorted.
+func formatPerReplicaConstraints(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf("%q: %d", key, counts[key]))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceZoneConstraintsRead(t *testing.T) {
+	cases := []struct {
+		config                   map[string]interface{}
+		expectedConstraints      string
+		expectedLeasePreferences string
+	}{
+		{
+			config: map[string]interface{}{
+				zoneConstraintsRequiredAttr:   map[string]interface{}{"zone": "us-east1-b", "region": "us-east1", "ssd": ""},
+				zoneConstraintsProhibitedAttr: map[string]interface{}{"rack": "1"},
+				zoneConstraintsLeasePreferenceAttr: []interface{}{
+					map[string]interface{}{zoneConstraintsRequiredAttr: map[string]interface{}{"region": "us-east1"}},
+					map[string]interface{}{zoneConstraintsRequiredAttr: map[string]interface{}{"region": "us-west1", "zone": "us-west1-a"}},
+				},
+			},
+			expectedConstraints:      "[+region=us-east1, +ssd, +zone=us-east1-b, -rack=1]",
+			expectedLeasePreferences: "[[+region=us-east1], [+region=us-west1, +zone=us-west1-a]]",
+		},
+		{
+			config: map[string]interface{}{
+				zoneConstraintsReplicaAttr: []interface{}{
+					map[string]interface{}{zoneConstraintsNumReplicasAttr: 2, zoneConstraintsRequiredAttr: map[string]interface{}{"region": "us-west1"}},
+					map[string]interface{}{zoneConstraintsNumReplicasAttr: 1, zoneConstraintsRequiredAttr: map[string]interface{}{"region": "us-east1", "zone": "us-east1-b"}},
+				},
+			},
+			expectedConstraints: `{"+region=us-east1,+zone=us-east1-b": 1, "+region=us-west1": 2}`,
+		},
+		{
+			config: map[string]interface{}{},
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, dataSourceZoneConstraints().Schema, c.config)
+		if diags := dataSourceZoneConstraintsRead(context.Background(), d, nil); diags.HasError() {
+			t.Fatalf("unexpected error %v", diags)
+		}
+
+		constraints := d.Get(zoneConstraintsConstraintsAttr).(string)
+		leasePreferences := d.Get(zoneConstraintsLeasePreferencesAttr).(string)
+		if constraints != c.expectedConstraints || leasePreferences != c.expectedLeasePreferences {
+			t.Errorf("expected %q and %q, got %q and %q", c.expectedConstraints, c.expectedLeasePreferences, constraints, leasePreferences)
+		}
+		// the zone configurations accept them
+		for _, value := range []string{constraints, leasePreferences} {
+			if _, errs := validateZoneConfig(map[string]interface{}{"constraints": value}, "config"); len(errs) > 0 {
+				t.Errorf("unexpected errors %v for %q", errs, value)
+			}
+		}
+	}
+}
+
+func TestValidateZoneConstraintTiers(t *testing.T) {
+	if _, errs := validateZoneConstraintTiers(map[string]interface{}{"region": "us-east1", "ssd": ""}, "required"); len(errs) > 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	for _, tiers := range []map[string]interface{}{
+		{"region": "us-east1,+zone=b"},
+		{"region=us": "east1"},
+		{"region": "us east1"},
+		{"": "us-east1"},
+	} {
+		if _, errs := validateZoneConstraintTiers(tiers, "required"); len(errs) == 0 {
+			t.Errorf("expected an error for %v", tiers)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+// The objects depending on a table or database, which break when it is
+// dropped, are refreshed into the computed dependents attribute of its
+// resource. Terraform shows the attributes of the resources it destroys, so
+// the dependents are part of the plan, and they are warned about again when
+// the object is dropped.
+
+const (
+	dependentsAttr   = "dependents"
+	dropBehaviorAttr = "drop_behavior"
+
+	dropBehaviorRestrict = "RESTRICT"
+	dropBehaviorCascade  = "CASCADE"
+)
+
+func dependentsSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "Objects depending on the resource, which break or are dropped along with it when it is destroyed, e.g. `view db.public.v`, `foreign key fk_t on db.public.t` or `changefeed 123`. Refreshed with the resource.",
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+}
+
+// dropBehaviorSchema is how an object is dropped, failing when objects depend
+// on it unless they are dropped along with it.
+func dropBehaviorSchema() *schema.Schema {
+	return &schema.Schema{
+		Description:  "How the object is dropped when the resource is destroyed, `RESTRICT` to fail when objects depend on it or `CASCADE` to drop them along with it.",
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      dropBehaviorRestrict,
+		ValidateFunc: validation.StringInSlice([]string{dropBehaviorRestrict, dropBehaviorCascade}, false),
+	}
+}
+
+// tableDependents returns the views and foreign keys depending on the table
+// and the changefeeds watching it.
+func tableDependents(ctx context.Context, conn *pgx.Conn, database string, schemaName string, name string) ([]string, error) {
+	db := pq.QuoteIdentifier(database)
+	dependents := []string{}
+
+	var tableID int64
+	err := conn.QueryRow(ctx,
+		`SELECT table_id FROM `+db+`.crdb_internal.tables WHERE database_name = $1 AND schema_name = $2 AND name = $3`,
+		database, schemaName, name).Scan(&tableID)
+	if err == pgx.ErrNoRows {
+		return dependents, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := queryStrings(ctx, conn,
+		`SELECT DISTINCT 'view ' || t.database_name || '.' || t.schema_name || '.' || t.name
+		FROM `+db+`.crdb_internal.forward_dependencies AS f
+		JOIN "".crdb_internal.tables AS t ON t.table_id = f.dependedonby_id
+		WHERE f.descriptor_id = $1 AND f.dependedonby_type = 'view'`, tableID)
+	if err != nil {
+		return nil, err
+	}
+	dependents = append(dependents, views...)
+
+	foreignKeys, err := queryStrings(ctx, conn,
+		`SELECT 'foreign key ' || c.conname || ' on ' || t.database_name || '.' || t.schema_name || '.' || t.name
+		FROM `+db+`.pg_catalog.pg_constraint AS c
+		JOIN "".crdb_internal.tables AS t ON t.table_id = c.conrelid::INT8
+		WHERE c.contype = 'f' AND c.confrelid::INT8 = $1 AND c.conrelid::INT8 != $1`, tableID)
+	if err != nil {
+		return nil, err
+	}
+	dependents = append(dependents, foreignKeys...)
+
+	changefeeds, err := watchingChangefeeds(ctx, conn, func(table string) bool {
+		return table == database+"."+schemaName+"."+name
+	})
+	if err != nil {
+		return nil, err
+	}
+	dependents = append(dependents, changefeeds...)
+	sort.Strings(dependents)
+
+	return dependents, nil
+}
+
+// databaseDependents returns the changefeeds watching the tables of the
+// database.
+func databaseDependents(ctx context.Context, conn *pgx.Conn, database string) ([]string, error) {
+	dependents, err := watchingChangefeeds(ctx, conn, func(table string) bool {
+		return strings.HasPrefix(table, database+".")
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dependents)
+
+	return dependents, nil
+}
+
+// watchingChangefeeds returns the running or paused changefeeds watching a
+// table matching the filter, by qualified name.
+func watchingChangefeeds(ctx context.Context, conn *pgx.Conn, watches func(table string) bool) ([]string, error) {
+	rows, err := conn.Query(ctx, `SELECT job_id, full_table_names FROM [SHOW CHANGEFEED JOBS] WHERE status IN ('running', 'paused')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changefeeds := []string{}
+	for rows.Next() {
+		var (
+			jobID  int64
+			tables []string
+		)
+		if err := rows.Scan(&jobID, &tables); err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			if watches(table) {
+				changefeeds = append(changefeeds, fmt.Sprintf("changefeed %d", jobID))
+				break
+			}
+		}
+	}
+
+	return changefeeds, rows.Err()
+}
+
+func queryStrings(ctx context.Context, conn *pgx.Conn, query string, args ...interface{}) ([]string, error) {
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+// dependentsWarning returns the warning about the dependents of an object
+// about to be dropped, nil when nothing depends on it.
+func dependentsWarning(object string, dependents []string) diag.Diagnostics {
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Dropping %s breaks %d dependent objects", object, len(dependents)),
+		Detail:   strings.Join(dependents, "\n"),
+	}}
+}
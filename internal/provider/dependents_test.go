@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestDependentsWarning(t *testing.T) {
+	if diags := dependentsWarning("table db.public.t", nil); diags != nil {
+		t.Errorf("expected no warning, got %v", diags)
+	}
+
+	diags := dependentsWarning("table db.public.t", []string{"changefeed 12", "view db.public.v"})
+	if len(diags) != 1 || diags.HasError() {
+		t.Fatalf("expected a warning, got %v", diags)
+	}
+	if diags[0].Summary != "Dropping table db.public.t breaks 2 dependent objects" || diags[0].Detail != "changefeed 12\nview db.public.v" {
+		t.Errorf("unexpected warning %+v", diags[0])
+	}
+}
+
+func TestDropBehaviorSchema(t *testing.T) {
+	s := dropBehaviorSchema()
+	for _, value := range []string{"RESTRICT", "CASCADE"} {
+		if _, errs := s.ValidateFunc(value, dropBehaviorAttr); len(errs) > 0 {
+			t.Errorf("expected %s to be valid, got %v", value, errs)
+		}
+	}
+	if _, errs := s.ValidateFunc("cascade", dropBehaviorAttr); len(errs) == 0 {
+		t.Error("expected a lowercase drop behavior to be invalid")
+	}
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// In dev mode the provider starts a throwaway single-node cluster, with an
+// in-memory store, for the smoke tests of modules in CI without any real
+// cluster. The cockroach binary is the one of the COCKROACH_BINARY environment
+// variable, found in the PATH otherwise. Terraform starting a new provider for
+// every command, the cluster is stopped when the provider shuts down, and
+// killed with the process of the provider otherwise, where the platform
+// allows it.
+const argDevMode = "dev_mode"
+
+// devClusterStartTimeout bounds the time the dev cluster takes to listen.
+const devClusterStartTimeout = time.Minute
+
+// devClusters starts the dev cluster once per provider process.
+var devClusters = &devCluster{}
+
+type devCluster struct {
+	once sync.Once
+	dns  string
+	err  error
+
+	mu  sync.Mutex
+	cmd *exec.Cmd
+}
+
+// devClusterCommand returns the command starting the dev cluster with the
+// binary, writing its connection string to urlFile.
+func devClusterCommand(binary string, urlFile string) *exec.Cmd {
+	cmd := exec.Command(binary, "start-single-node",
+		"--insecure",
+		"--store=type=mem,size=0.25",
+		"--listen-addr=127.0.0.1:0",
+		"--http-addr=127.0.0.1:0",
+		"--listening-url-file="+urlFile,
+	)
+	cmd.SysProcAttr = devClusterProcAttr()
+
+	return cmd
+}
+
+// devClusterBinary returns the cockroach binary to start the dev cluster
+// with.
+func devClusterBinary() (string, error) {
+	if binary := os.Getenv("COCKROACH_BINARY"); binary != "" {
+		return binary, nil
+	}
+
+	binary, err := exec.LookPath("cockroach")
+	if err != nil {
+		return "", fmt.Errorf("no cockroach binary in the PATH, set COCKROACH_BINARY: %w", err)
+	}
+
+	return binary, nil
+}
+
+// get starts the dev cluster on first call and returns the connection string
+// of root.
+func (c *devCluster) get() (string, error) {
+	c.once.Do(func() {
+		logInfo("starting the dev mode cluster")
+		if c.dns, c.err = c.start(); c.err != nil {
+			c.err = fmt.Errorf("failed to start the dev mode cluster: %w", c.err)
+		}
+	})
+
+	return c.dns, c.err
+}
+
+func (c *devCluster) start() (string, error) {
+	binary, err := devClusterBinary()
+	if err != nil {
+		return "", err
+	}
+	dir, err := tempDirs.create("cockroach-dev-")
+	if err != nil {
+		return "", err
+	}
+	urlFile := filepath.Join(dir, "url")
+
+	cmd := devClusterCommand(binary, urlFile)
+	cmd.Dir = dir
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.cmd = cmd
+	c.mu.Unlock()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.After(devClusterStartTimeout)
+	for {
+		if url, err := os.ReadFile(urlFile); err == nil && strings.TrimSpace(string(url)) != "" {
+			return strings.TrimSpace(string(url)), nil
+		}
+
+		select {
+		case err := <-exited:
+			return "", fmt.Errorf("cockroach exited: %v", err)
+		case <-deadline:
+			c.stop()
+			return "", fmt.Errorf("cockroach not listening after %s", devClusterStartTimeout)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// stop kills the dev cluster, if started, its store being in memory.
+func (c *devCluster) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cmd == nil || c.cmd.Process == nil {
+		return
+	}
+	logInfo("provider shut down, stopping the dev mode cluster...")
+	if err := c.cmd.Process.Kill(); err != nil {
+		logDebug("failed to kill the dev mode cluster: %v", err)
+	}
+	c.cmd = nil
+}
@@ -0,0 +1,13 @@
+//go:build linux
+// +build linux
+
+package provider
+
+import "syscall"
+
+// devClusterProcAttr puts the dev cluster in its own process group, out of
+// reach of the signals sent to Terraform, and has it killed when the provider
+// dies without shutting down.
+func devClusterProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package provider
+
+import "syscall"
+
+// devClusterProcAttr leaves the dev cluster in the process group of the
+// provider, the platform not killing it with the provider.
+func devClusterProcAttr() *syscall.SysProcAttr {
+	return nil
+}
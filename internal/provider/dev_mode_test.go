@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDevCluster(t *testing.T) {
+	// a fake cockroach writing its connection string once listening
+	binary := filepath.Join(t.TempDir(), "cockroach")
+	script := `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    --listening-url-file=*) echo "postgresql://root@127.0.0.1:26999/defaultdb?sslmode=disable" > "${arg#*=}" ;;
+  esac
+done
+exec sleep 60
+`
+	if err := os.WriteFile(binary, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("COCKROACH_BINARY", binary)
+
+	cluster := &devCluster{}
+	dns, err := cluster.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dns != "postgresql://root@127.0.0.1:26999/defaultdb?sslmode=disable" {
+		t.Errorf("unexpected connection string %s", dns)
+	}
+
+	process := cluster.cmd.Process
+	cluster.stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for process.Signal(syscall.Signal(0)) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the dev cluster to be stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tempDirs.remove()
+}
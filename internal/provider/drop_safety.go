@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+)
+
+// A table can be guarded against being dropped by a stray plan when it is
+// larger than max_drop_size_bytes, unless force is set, and its garbage
+// collection TTL can be lowered before it is dropped, for the space of a
+// large table to be reclaimed without waiting for the TTL of its zone.
+// Like drop_behavior, these are read from the state when the table is
+// dropped, so they have to be applied before the resource is destroyed.
+
+const (
+	gcTTLSecondsAttr     = "gc_ttl_seconds"
+	dropGCTTLSecondsAttr = "drop_gc_ttl_seconds"
+	waitForGCOnDropAttr  = "wait_for_gc_on_drop"
+	maxDropSizeBytesAttr = "max_drop_size_bytes"
+	forceAttr            = "force"
+
+	gcTTLSecondsVariable = "gc.ttlseconds"
+)
+
+func gcTTLSecondsSchema() *schema.Schema {
+	return &schema.Schema{
+		Description:  "Number of seconds the overwritten values of the table are kept for, the `gc.ttlseconds` variable of its zone configuration. Inherited from the database when not set.",
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+	}
+}
+
+func dropGCTTLSecondsSchema() *schema.Schema {
+	return &schema.Schema{
+		Description:  "Number of seconds the `gc.ttlseconds` of the table is lowered to before it is dropped, for its space to be reclaimed sooner. Left as is when not set.",
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+	}
+}
+
+func waitForGCOnDropSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "True to wait, when the table is dropped, for the job garbage collecting its data to complete, which takes at least its `gc.ttlseconds`. " +
+			"Bounded by the delete timeout of the resource.",
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+}
+
+func maxDropSizeBytesSchema() *schema.Schema {
+	return &schema.Schema{
+		Description:  "Size in bytes of the live data of the table above which destroying the resource fails unless `force` is true. Not checked when not set.",
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+	}
+}
+
+func forceSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "True to drop the table even when it is larger than `max_drop_size_bytes`. It has to be applied before the resource is destroyed.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	}
+}
+
+// gcTTLStatements returns the statements changing the GC TTL of the table
+// from o to n seconds, 0 being inherited, the other variables of its zone
+// being left as is.
+func gcTTLStatements(table string, o int, n int) []string {
+	switch {
+	case o == n:
+		return nil
+	case n == 0:
+		return []string{`ALTER TABLE ` + table + ` CONFIGURE ZONE USING ` + gcTTLSecondsVariable + ` = COPY FROM PARENT`}
+	}
+
+	return []string{`ALTER TABLE ` + table + ` CONFIGURE ZONE USING ` + gcTTLSecondsVariable + ` = ` + strconv.Itoa(n)}
+}
+
+// readGCTTLSeconds reads the GC TTL set on the zone of the table, 0 when it
+// is inherited.
+func readGCTTLSeconds(ctx context.Context, conn *pgx.Conn, tableID int64) (int, error) {
+	var sql string
+	err := conn.QueryRow(ctx,
+		`SELECT coalesce(raw_config_sql, '') FROM crdb_internal.zones WHERE zone_id = $1 AND subzone_id = 0`, tableID).Scan(&sql)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := parseZoneConfig(sql)[gcTTLSecondsVariable]
+	if !ok {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value.(string))
+}
+
+// readTableSizeBytes reads the size of the live data of the table.
+func readTableSizeBytes(ctx context.Context, conn *pgx.Conn, tableID int64) (int64, error) {
+	var size int64
+	err := conn.QueryRow(ctx,
+		`SELECT coalesce(sum(live_bytes), 0)::INT8 FROM crdb_internal.table_span_stats WHERE table_id = $1`, tableID).Scan(&size)
+
+	return size, err
+}
+
+// checkDropSize returns an error when the table is larger than the maximum
+// size of the resource and force isn't set.
+func checkDropSize(ctx context.Context, conn *pgx.Conn, d *schema.ResourceData, table string) error {
+	maxSize := int64(d.Get(maxDropSizeBytesAttr).(int))
+	if maxSize == 0 || d.Get(forceAttr).(bool) {
+		return nil
+	}
+
+	size, err := readTableSizeBytes(ctx, conn, int64(d.Get(descriptorIDAttr).(int)))
+	if err != nil {
+		return fmt.Errorf("failed to read the size of %s: %v", table, err)
+	}
+	if size > maxSize {
+		return fmt.Errorf("refusing to drop %s of %d bytes, above %s of %d, apply %s = true first to drop it",
+			table, size, maxDropSizeBytesAttr, maxSize, forceAttr)
+	}
+
+	return nil
+}
+
+// waitForGCJob waits for the job garbage collecting the dropped table to
+// complete.
+func waitForGCJob(ctx context.Context, conn *pgx.Conn, tableID int64, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		var id int64
+		var status string
+		err := conn.QueryRow(ctx,
+			`SELECT job_id, status FROM crdb_internal.jobs `+
+				`WHERE job_type = 'SCHEMA CHANGE GC' AND $1 = ANY (descriptor_ids) ORDER BY created DESC LIMIT 1`, tableID).Scan(&id, &status)
+		if err == pgx.ErrNoRows {
+			return resource.RetryableError(fmt.Errorf("no GC job found for the table %d", tableID))
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		switch status {
+		case "succeeded":
+			return nil
+		case "failed", "canceled":
+			return resource.NonRetryableError(fmt.Errorf("GC job %d of the table %d is %s", id, tableID, status))
+		}
+
+		return resource.RetryableError(fmt.Errorf("GC job %d of the table %d is %s", id, tableID, status))
+	})
+}
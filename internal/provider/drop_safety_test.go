@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGCTTLStatements(t *testing.T) {
+	for _, test := range []struct {
+		o, n     int
+		expected []string
+	}{
+		{0, 0, nil},
+		{600, 600, nil},
+		{0, 600, []string{`ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = 600`}},
+		{600, 0, []string{`ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = COPY FROM PARENT`}},
+	} {
+		if actual := gcTTLStatements("t", test.o, test.n); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expected %q from %d to %d, got %q", test.expected, test.o, test.n, actual)
+		}
+	}
+}
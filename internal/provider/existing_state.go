@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgconn"
+)
+
+const argTolerateExistingState = "tolerate_existing_state"
+
+var (
+	// alreadyExistsCodes are duplicate_database, duplicate_schema,
+	// duplicate_table and duplicate_object, the code of the roles, triggers
+	// and constraints
+	alreadyExistsCodes = []string{"42P04", "42P06", "42P07", "42710"}
+	// doesNotExistCodes are invalid_catalog_name, of the databases,
+	// invalid_schema_name, undefined_table and undefined_object
+	doesNotExistCodes = []string{"3D000", "3F000", "42P01", "42704"}
+)
+
+func tolerateExistingStateSchema(object string) *schema.Schema {
+	return &schema.Schema{
+		Description: "True for the creation of the resource to succeed when the " + object + " already exists, adopting it, and for its destruction to succeed when the " + object + " doesn't exist anymore, " +
+			"e.g. to go on after an apply which failed half way without editing the state.",
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+}
+
+// toleratedExistingState returns whether the error of the statement creating
+// or dropping the object of the resource is one of the codes, telling the
+// object already exists or doesn't exist, with tolerate_existing_state set.
+func toleratedExistingState(d *schema.ResourceData, err error, codes []string) bool {
+	if err == nil || !d.Get(argTolerateExistingState).(bool) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || !contains(codes, pgErr.Code) {
+		return false
+	}
+	logInfo("tolerating the existing state: %v", err)
+
+	return true
+}
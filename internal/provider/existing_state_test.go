@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgconn"
+)
+
+func TestToleratedExistingState(t *testing.T) {
+	s := map[string]*schema.Schema{argTolerateExistingState: tolerateExistingStateSchema("table")}
+	tolerating := schema.TestResourceDataRaw(t, s, map[string]interface{}{argTolerateExistingState: true})
+	strict := schema.TestResourceDataRaw(t, s, map[string]interface{}{})
+
+	exists := fmt.Errorf("failed: %w", &pgconn.PgError{Code: "42P07", Message: `relation "t" already exists`})
+	if !toleratedExistingState(tolerating, exists, alreadyExistsCodes) {
+		t.Errorf("expected an existing table to be tolerated")
+	}
+	if toleratedExistingState(strict, exists, alreadyExistsCodes) {
+		t.Errorf("expected an existing table not to be tolerated without %s", argTolerateExistingState)
+	}
+	if toleratedExistingState(tolerating, exists, doesNotExistCodes) {
+		t.Errorf("expected an existing table not to be tolerated when dropping it")
+	}
+	if toleratedExistingState(tolerating, errors.New("connection refused"), alreadyExistsCodes) {
+		t.Errorf("expected other errors not to be tolerated")
+	}
+	if toleratedExistingState(tolerating, nil, alreadyExistsCodes) {
+		t.Errorf("expected no error not to be tolerated")
+	}
+}
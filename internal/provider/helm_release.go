@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The labels of the services of the CockroachDB Helm chart, the names of the
+// services depending on the version of the chart and its fullnameOverride.
+const (
+	helmInstanceLabel = "app.kubernetes.io/instance"
+	helmNameLabel     = "app.kubernetes.io/name"
+	helmChartName     = "cockroachdb"
+)
+
+// helmPublicService returns the name and SQL port of the public service of
+// the release among its services, the other one being the headless service
+// of the StatefulSet. The SQL port is named sql when the chart exposes it
+// apart from the gRPC one, grpc otherwise.
+func helmPublicService(release string, services []v1.Service) (string, string, error) {
+	var public []v1.Service
+	for _, service := range services {
+		if service.Spec.ClusterIP != v1.ClusterIPNone {
+			public = append(public, service)
+		}
+	}
+	if len(public) != 1 {
+		return "", "", fmt.Errorf("expected a single public service of the Helm release %s, found %d", release, len(public))
+	}
+
+	ports := map[string]int32{}
+	for _, port := range public[0].Spec.Ports {
+		ports[port.Name] = port.Port
+	}
+	for _, name := range []string{"sql", "grpc"} {
+		if port, ok := ports[name]; ok {
+			return public[0].Name, strconv.Itoa(int(port)), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("the service %s of the Helm release %s has no sql or grpc port", public[0].Name, release)
+}
+
+// discoverHelmRelease returns the name and SQL port of the public service of
+// the Helm release in the namespace of the kube connection.
+func discoverHelmRelease(ctx context.Context, kubeConn kubeConn, release string) (string, string, error) {
+	selector := fmt.Sprintf("%s=%s,%s=%s", helmInstanceLabel, release, helmNameLabel, helmChartName)
+
+	var services *v1.ServiceList
+	err := retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		services, err = kubeConn.kubeClient.CoreV1().Services(kubeConn.nameSpace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		return err
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list the services of the Helm release %s/%s: %w", kubeConn.nameSpace, release, err)
+	}
+
+	return helmPublicService(release, services.Items)
+}
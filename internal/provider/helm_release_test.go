@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHelmPublicService(t *testing.T) {
+	headless := v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "crdb-cockroachdb"},
+		Spec: v1.ServiceSpec{
+			ClusterIP: v1.ClusterIPNone,
+			Ports:     []v1.ServicePort{{Name: "grpc", Port: 26257}},
+		},
+	}
+	public := v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "crdb-cockroachdb-public"},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "10.0.0.1",
+			Ports:     []v1.ServicePort{{Name: "grpc", Port: 26257}, {Name: "http", Port: 8080}},
+		},
+	}
+
+	service, port, err := helmPublicService("crdb", []v1.Service{headless, public})
+	if err != nil || service != "crdb-cockroachdb-public" || port != "26257" {
+		t.Errorf("unexpected service %s:%s (%v)", service, port, err)
+	}
+
+	public.Spec.Ports = append(public.Spec.Ports, v1.ServicePort{Name: "sql", Port: 26258})
+	if _, port, _ := helmPublicService("crdb", []v1.Service{headless, public}); port != "26258" {
+		t.Errorf("expected the sql port, got %s", port)
+	}
+
+	if _, _, err := helmPublicService("crdb", []v1.Service{headless}); err == nil {
+		t.Errorf("expected an error without public service")
+	}
+}
@@ -1,146 +1,980 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgx"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/client-go/transport/spdy"
+	"k8s.io/client-go/util/retry"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
-func tryPortForwardIfNeeded(ctx context.Context, d *schema.ResourceData, meta interface{}, stopCh chan struct{}, readyCh chan struct{}, localPort string) diag.Diagnostics {
+// objectLocks serializes the changes made to the same object by resources
+// applied in parallel, since concurrent schema changes on one object abort
+// each other.
+type objectLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock acquires the locks of the objects, in a stable order to not deadlock
+// with another resource locking some of them, and returns the function
+// releasing them.
+func (l *objectLocks) lock(keys ...string) func() {
+	sorted := []string{}
+	for _, key := range keys {
+		if !contains(sorted, key) {
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sync.Mutex{}
+	}
+	mutexes := make([]*sync.Mutex, len(sorted))
+	for i, key := range sorted {
+		if _, ok := l.locks[key]; !ok {
+			l.locks[key] = &sync.Mutex{}
+		}
+		mutexes[i] = l.locks[key]
+	}
+	l.mu.Unlock()
+
+	for _, mutex := range mutexes {
+		mutex.Lock()
+	}
+
+	return func() {
+		for i := len(mutexes) - 1; i >= 0; i-- {
+			mutexes[i].Unlock()
+		}
+	}
+}
+
+// readCache holds the results of the queries shared by many resources, e.g.
+// SHOW USERS, so refreshing them runs each query once instead of once per
+// resource. It must be invalidated by every change made to the cluster.
+type readCache struct {
+	mu      sync.Mutex
+	entries map[string]*readCacheEntry
+}
+
+type readCacheEntry struct {
+	mu     sync.Mutex
+	loaded bool
+	value  interface{}
+}
+
+// get returns the cached value of key, calling load when it isn't cached yet.
+// Failures are not cached, the next call loads the value again.
+func (c *readCache) get(key string, load func() (interface{}, diag.Diagnostics)) (interface{}, diag.Diagnostics) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]*readCacheEntry{}
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &readCacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.loaded {
+		return entry.value, nil
+	}
+
+	value, diags := load()
+	if diags.HasError() {
+		return nil, diags
+	}
+	entry.value = value
+	entry.loaded = true
+
+	return value, nil
+}
+
+func (c *readCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+}
+
+func databaseLockKey(database string) string {
+	return "database/" + database
+}
+
+// roleLockKey returns the key of the lock of the role, taken by the changes
+// of the role and of the privileges granted to it.
+func roleLockKey(role string) string {
+	return "role/" + strings.ToLower(role)
+}
+
+// setDefaultDatabase plans the default database of the provider for the
+// attribute of a resource when it isn't set.
+func setDefaultDatabase(d *schema.ResourceDiff, meta interface{}, attr string) error {
+	if !defaultable(d, attr) {
+		return nil
+	}
+	if database := meta.(*cockroachClient).defaultDatabase; database != "" {
+		return d.SetNew(attr, database)
+	}
+
+	return nil
+}
+
+// setDefaultSchema plans the default schema of the provider for the attribute
+// of a resource when it isn't set.
+func setDefaultSchema(d *schema.ResourceDiff, meta interface{}, attr string) error {
+	if !defaultable(d, attr) {
+		return nil
+	}
+
+	return d.SetNew(attr, defaultSchemaOf(meta))
+}
+
+// defaultable returns whether a default of the provider can be planned for
+// the attribute, only when it is null in the configuration and not known
+// from the state. A value unknown until apply, e.g. the name of a schema
+// created in the same plan, reads as empty but mustn't be replaced; an unset
+// attribute can't be told apart from it by NewValueKnown, being computed.
+func defaultable(d *schema.ResourceDiff, attr string) bool {
+	config := d.GetRawConfig()
+	if !config.IsNull() && config.IsKnown() && config.Type().IsObjectType() && config.Type().HasAttribute(attr) {
+		if value := config.GetAttr(attr); !value.IsKnown() || !value.IsNull() {
+			return false
+		}
+	}
+
+	return d.Get(attr).(string) == ""
+}
+
+// defaultSchemaOf returns the default schema of the provider.
+func defaultSchemaOf(meta interface{}) string {
+	if schemaName := meta.(*cockroachClient).defaultSchema; schemaName != "" {
+		return schemaName
+	}
+
+	return "public"
+}
+
+// roleNamesEqual returns whether the role names designate the same role,
+// CockroachDB lowercasing the role names even when they are quoted. The names
+// of the other objects keep their case, the provider always quoting them.
+func roleNamesEqual(a string, b string) bool {
+	return strings.ToLower(a) == strings.ToLower(b)
+}
+
+// suppressRoleNameCase suppresses the diff between role names only differing
+// by their case, e.g. `MyUser` as configured and `myuser` as read.
+func suppressRoleNameCase(k, old, new string, d *schema.ResourceData) bool {
+	return roleNamesEqual(old, new)
+}
+
+func objectLockKey(objectType string, database string, schemaName string, object string) string {
+	return strings.Join([]string{objectType, database, schemaName, object}, "/")
+}
+
+// openConnection port-forwards to the cluster when a kube config is set and
+// returns a connection to it. The returned function closes the connection and
+// terminates the port-forward, it must be called once the caller is done. The
+// connection is retried while the nodes are restarting, see retryTransient.
+func openConnection(ctx context.Context, d *schema.ResourceData, meta interface{}) (*pgx.Conn, func(), diag.Diagnostics) {
+	return openConnectionOnPort(ctx, meta, d.Get(argLocalPort).(string))
+}
+
+// openDiffConnection opens a connection like openConnection while planning
+// the changes of a resource, e.g. to validate them against the cluster.
+func openDiffConnection(ctx context.Context, d *schema.ResourceDiff, meta interface{}) (*pgx.Conn, func(), diag.Diagnostics) {
+	return openConnectionOnPort(ctx, meta, d.Get(argLocalPort).(string))
+}
+
+func openConnectionOnPort(ctx context.Context, meta interface{}, localPort string) (*pgx.Conn, func(), diag.Diagnostics) {
 	cockroachClient := meta.(*cockroachClient)
 
-	if kubeConfig := cockroachClient.kubeConn.kubeConfig; kubeConfig != nil {
-		kubeClientSet := cockroachClient.kubeConn.kubeClient
-		nameSpace := cockroachClient.kubeConn.nameSpace
-		serviceName := cockroachClient.kubeConn.serviceName
-		remotePort := cockroachClient.kubeConn.remotePort
+	var conn *pgx.Conn
+	var closeConn func()
+	err := retryTransient(ctx, cockroachClient.connectRetryTimeout, func() error {
+		var err error
+		conn, closeConn, err = connect(ctx, meta, localPort)
+		return err
+	})
+	if err != nil {
+		return nil, nil, diag.FromErr(err)
+	}
 
-		errCh := make(chan error, 1)
+	return conn, closeConn, nil
+}
 
-		// managing termination signal from the terminal. As you can see the stopCh
-		// gets closed to gracefully handle its termination.
+// openConnectionIn opens a connection like openConnection, with the database
+// of its session set to the database, so the unqualified names of the
+// statements, e.g. of the functions and sequences, resolve in it rather than
+// in the database of the connection string. The objects of every database are
+// managed with the same provider this way.
+func openConnectionIn(ctx context.Context, d *schema.ResourceData, meta interface{}, database string) (*pgx.Conn, func(), diag.Diagnostics) {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return nil, nil, diags
+	}
+
+	if err := useDatabase(ctx, conn, database); err != nil {
+		closeConn()
+		return nil, nil, diag.FromErr(err)
+	}
+
+	return conn, closeConn, nil
+}
+
+// useDatabase sets the database of the session of the connection, left as is
+// when the database is empty.
+func useDatabase(ctx context.Context, conn *pgx.Conn, database string) error {
+	if database == "" {
+		return nil
+	}
+
+	_, err := conn.Exec(ctx, `USE `+pq.QuoteIdentifier(database))
+	return err
+}
+
+// diagnosticsError returns the error diagnostics as an error, each with its
+// detail, nil when there is none.
+func diagnosticsError(diags diag.Diagnostics) error {
+	var messages []string
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+		if d.Detail != "" {
+			messages = append(messages, d.Summary+": "+d.Detail)
+		} else {
+			messages = append(messages, d.Summary)
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func connect(ctx context.Context, meta interface{}, localPort string) (*pgx.Conn, func(), error) {
+	cockroachClient := meta.(*cockroachClient)
+
+	// stopCh control the port forwarding lifecycle. When it gets closed the
+	// port forward will terminate
+	stopCh := make(chan struct{}, 1)
+	// readyCh communicate when the port forward is ready to get traffic
+	readyCh := make(chan struct{})
+
+	forwardedPort, diags := tryPortForwardIfNeeded(ctx, meta, stopCh, readyCh, localPort)
+	if diags.HasError() {
+		close(stopCh)
+		return nil, nil, diagnosticsError(diags)
+	}
+	dns := strings.Replace(cockroachClient.dns, "<local_port>", forwardedPort, 1)
+
+	conn, err := connectDNS(ctx, dns)
+	if err != nil {
+		close(stopCh)
+		return nil, nil, err
+	}
+
+	closeConn := func() {
+		if closeErr := conn.Close(ctx); closeErr != nil {
+			logError("failed to close database connection: %v", closeErr)
+		}
+		close(stopCh)
+	}
+
+	if err := conn.Ping(ctx); err != nil {
+		closeConn()
+		return nil, nil, err
+	}
+
+	if err := cockroachClient.verifyCluster(ctx, conn); err != nil {
+		closeConn()
+		return nil, nil, err
+	}
+
+	if cockroachClient.refreshSnapshot && isRefreshRead(ctx) {
+		if err := beginRefreshSnapshot(ctx, conn, &cockroachClient.cache); err != nil {
+			closeConn()
+			return nil, nil, err
+		}
+	}
+
+	return conn, closeConn, nil
+}
+
+// retryTransient calls fn until it succeeds or fails with an error not caused
+// by nodes restarting or draining, e.g. during a rolling upgrade, retrying
+// with an exponential backoff for up to timeout.
+func retryTransient(ctx context.Context, timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	delay := transientRetryMinDelay
+	for {
+		err := fn()
+		if err == nil || !isTransientConnError(err) || time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		logInfo("the cluster is not available, retrying in %s: %v", delay, err)
+		activity.retried()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > transientRetryMaxDelay {
+			delay = transientRetryMaxDelay
+		}
+	}
+}
+
+const (
+	transientRetryMinDelay = 500 * time.Millisecond
+	transientRetryMaxDelay = 10 * time.Second
+)
+
+// transientErrorMessages are the errors of the connections to nodes
+// restarting, when not classified by their code.
+var transientErrorMessages = []string{
+	"connection refused",
+	"connection reset by peer",
+	"broken pipe",
+	"node is draining",
+	"server is not accepting clients",
+	"database is starting up",
+	"no live pods behind the service",
+	"lost connection to pod",
+}
+
+// isTransientConnError returns whether the error is caused by the node
+// connected to restarting or draining, a connection being possible again
+// shortly.
+func isTransientConnError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		// admin_shutdown, crash_shutdown and cannot_connect_now
+		case "57P01", "57P02", "57P03":
+			return true
+		}
+		// connection exceptions, except the protocol violations
+		return strings.HasPrefix(pgErr.Code, "08") && pgErr.Code != "08P01"
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	message := err.Error()
+	for _, transient := range transientErrorMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyCluster checks, once per provider, that the connection is to the
+// expected cluster when the provider sets one.
+func (c *cockroachClient) verifyCluster(ctx context.Context, conn *pgx.Conn) error {
+	if c.expectedClusterID == "" && c.expectedClusterName == "" {
+		return nil
+	}
+
+	clusterID, err := c.readClusterID(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	c.clusterIDMu.Lock()
+	defer c.clusterIDMu.Unlock()
+
+	if c.clusterVerified {
+		return nil
+	}
+
+	var clusterName string
+	if c.expectedClusterName != "" {
+		if err := conn.QueryRow(ctx, `SELECT cluster_name FROM crdb_internal.gossip_nodes LIMIT 1`).Scan(&clusterName); err != nil {
+			return fmt.Errorf("failed to read the cluster name: %w", err)
+		}
+	}
+
+	if err := checkClusterIdentity(c.expectedClusterID, c.expectedClusterName, clusterID, clusterName); err != nil {
+		return err
+	}
+	c.clusterVerified = true
+
+	return nil
+}
+
+// checkClusterIdentity returns an error when the cluster isn't the expected
+// one, the expectations which are empty being skipped.
+func checkClusterIdentity(expectedID string, expectedName string, id string, name string) error {
+	if expectedID != "" && !strings.EqualFold(expectedID, id) {
+		return fmt.Errorf("connected to cluster %s instead of the expected cluster %s, refusing to go on", id, expectedID)
+	}
+	if expectedName != "" && expectedName != name {
+		return fmt.Errorf("connected to cluster %q instead of the expected cluster %q, refusing to go on", name, expectedName)
+	}
+
+	return nil
+}
+
+// execInTransaction runs the statements in a single transaction, retried on
+// serialization failures, so a failing statement doesn't leave the others
+// applied.
+func execInTransaction(ctx context.Context, conn *pgx.Conn, statements ...string) error {
+	return crdbpgx.ExecuteTx(ctx, conn, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// portForwards dedupes the port-forwards to the same service and local port,
+// shared by the clusters of the provider and the resources applied in
+// parallel. Each provider alias runs in its own plugin process, with its own
+// port-forwards, the clusters of a provider being the way to share them.
+var portForwards = &portForwardRegistry{forwards: map[string]*sharedPortForward{}, ports: map[string]string{}}
+
+// sharedPortForward is a port-forward used by refs callers, it terminates
+// once the last of them releases it.
+type sharedPortForward struct {
+	refs int
+	// localPort is the port listened on, "0" for an ephemeral port until
+	// readyCh is closed
+	localPort string
+	stopCh    chan struct{}
+	readyCh   chan struct{}
+	// failedCh is closed, with err set, when the port-forward can't be
+	// established
+	failedCh chan struct{}
+	err      error
+	stopped  bool
+}
+
+// stop terminates the port-forward, the registry lock must be held.
+func (fwd *sharedPortForward) stop() {
+	if !fwd.stopped {
+		fwd.stopped = true
+		close(fwd.stopCh)
+	}
+}
+
+type portForwardRegistry struct {
+	mu       sync.Mutex
+	forwards map[string]*sharedPortForward
+	// ports are the local ports requested by the port-forwards, by key. A
+	// port-forward requesting a port already used by another one, e.g. of
+	// another cluster of the provider, listens on an ephemeral port instead.
+	ports      map[string]string
+	signalOnce sync.Once
+}
+
+// acquire returns the port-forward of the key, created is true when the
+// caller has to establish it.
+func (r *portForwardRegistry) acquire(key string, localPort string) (fwd *sharedPortForward, created bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// managing termination signal from the terminal. As you can see the stop
+	// channels get closed to gracefully handle its termination.
+	r.signalOnce.Do(func() {
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 		go func() {
 			<-sigs
-			logInfo("Stopping a forward process...")
-			close(stopCh)
+			logInfo("Stopping the forward processes...")
+			r.stopAll()
 		}()
+	})
 
-		go func() {
-			defer close(errCh)
-			
-			svc, err := kubeClientSet.CoreV1().Services(nameSpace).Get(ctx, serviceName, metav1.GetOptions{})
-			if err != nil {
-				logError("failed to get Kubernetes service %s in namespace %s: %v", serviceName, nameSpace, err)
-				errCh <- fmt.Errorf("failed to get Kubernetes service: %w", err)
-				return
-			}
+	if fwd, ok := r.forwards[key]; ok {
+		fwd.refs++
+		return fwd, false
+	}
 
-			selector := mapToSelectorStr(svc.Spec.Selector)
-			if selector == "" {
-				err := fmt.Errorf("service %s has no selector", serviceName)
-				logError("failed to get service selector: %v", err)
-				errCh <- err
-				return
-			}
+	fwd = &sharedPortForward{
+		refs:      1,
+		localPort: localPort,
+		stopCh:    make(chan struct{}),
+		readyCh:   make(chan struct{}),
+		failedCh:  make(chan struct{}),
+	}
+	if owner, ok := r.ports[localPort]; ok && owner != key {
+		logInfo("local port %s is used by port-forward %s, using an ephemeral port for %s", localPort, owner, key)
+		fwd.localPort = "0"
+	} else {
+		r.ports[localPort] = key
+	}
+	r.forwards[key] = fwd
 
-			pods, err := kubeClientSet.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
-			if err != nil {
-				logError("failed to get pod list for selector %s: %v", selector, err)
-				errCh <- fmt.Errorf("failed to get pod list: %w", err)
-				return
-			}
+	return fwd, true
+}
 
-			if len(pods.Items) == 0 {
-				err := fmt.Errorf("no CockroachDB pods found with selector %s", selector)
-				logError("%v", err)
-				errCh <- err
-				return
-			}
+// remove forgets the port-forward of the key, the registry lock must be held.
+func (r *portForwardRegistry) remove(key string, fwd *sharedPortForward) {
+	if r.forwards[key] != fwd {
+		return
+	}
 
-			livePod, err := getPodName(pods)
-			if err != nil {
-				logError("failed to get live CockroachDB pod: %v", err)
-				errCh <- fmt.Errorf("failed to get live pod: %w", err)
-				return
-			}
+	delete(r.forwards, key)
+	for port, owner := range r.ports {
+		if owner == key {
+			delete(r.ports, port)
+		}
+	}
+}
 
-			serverURL, err := url.Parse(
-				fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/portforward", kubeConfig.Host, nameSpace, livePod))
-			if err != nil {
-				logError("failed to construct server URL: %v", err)
-				errCh <- fmt.Errorf("failed to construct server URL: %w", err)
-				return
-			}
+// release terminates the port-forward once no caller uses it.
+func (r *portForwardRegistry) release(key string, fwd *sharedPortForward) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-			transport, upgrader, err := spdy.RoundTripperFor(kubeConfig)
-			if err != nil {
-				logError("failed to create round tripper: %v", err)
-				errCh <- fmt.Errorf("failed to create round tripper: %w", err)
-				return
-			}
+	fwd.refs--
+	if fwd.refs > 0 {
+		return
+	}
 
-			dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, serverURL)
-
-			addresses := []string{"127.0.0.1"}
-			ports := []string{fmt.Sprintf("%s:%s", localPort, remotePort)}
-
-			pf, err := portforward.NewOnAddresses(
-				dialer,
-				addresses,
-				ports,
-				stopCh,
-				readyCh,
-				os.Stdout,
-				os.Stderr)
-			if err != nil {
-				logError("failed to create port-forward %s:%s: %v", localPort, remotePort, err)
-				errCh <- fmt.Errorf("failed to create port-forward: %w", err)
-				return
-			}
+	r.remove(key, fwd)
+	fwd.stop()
+}
 
-			go pf.ForwardPorts()
+// fail records the port-forward couldn't be established, the next caller
+// establishing a new one.
+func (r *portForwardRegistry) fail(key string, fwd *sharedPortForward, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-			<-readyCh
+	r.remove(key, fwd)
+	fwd.err = err
+	close(fwd.failedCh)
+}
 
-			actualPorts, err := pf.GetPorts()
-			if err != nil {
-				logError("failed to get port-forward ports: %v", err)
-				errCh <- fmt.Errorf("failed to get port-forward ports: %w", err)
-				return
-			}
-			if len(actualPorts) != 1 {
-				err := fmt.Errorf("unexpected number of forwarded ports: got %d, expected 1", len(actualPorts))
-				logError("%v", err)
-				errCh <- err
-				return
-			}
-			
-			logInfo("Port forwarding established: %s:%s -> %s", localPort, remotePort, livePod)
-		}()
+// ready records the port the port-forward listens on and lets its callers
+// connect.
+func (r *portForwardRegistry) ready(fwd *sharedPortForward, localPort string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fwd.localPort = localPort
+	close(fwd.readyCh)
+}
+
+// forget removes the terminated port-forward, without waiting for its callers
+// to release it.
+func (r *portForwardRegistry) forget(key string, fwd *sharedPortForward) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(key, fwd)
+}
+
+func (r *portForwardRegistry) stopAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, fwd := range r.forwards {
+		r.remove(key, fwd)
+		fwd.stop()
+	}
+}
+
+// followerReadSchema is the attribute of the data sources reading at the
+// follower read timestamp.
+func followerReadSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "True to read with `AS OF SYSTEM TIME follower_read_timestamp()`, served by the closest replica without contending with the production traffic at the cost of slightly stale data. " +
+			"Follower reads require an enterprise license before CockroachDB v23.1.",
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+}
+
+// readAsOfSystemTime runs fn in a read-only transaction at the follower read
+// timestamp when followerRead is set, or directly otherwise, as well as when
+// the connection already is in a transaction, e.g. the one of the refresh
+// snapshot.
+func readAsOfSystemTime(ctx context.Context, conn *pgx.Conn, followerRead bool, fn func() error) error {
+	if !followerRead || conn.PgConn().TxStatus() != 'I' {
+		return fn()
+	}
+
+	if _, err := conn.Exec(ctx, `BEGIN AS OF SYSTEM TIME follower_read_timestamp()`); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if _, rollbackErr := conn.Exec(ctx, `ROLLBACK`); rollbackErr != nil {
+			logError("failed to rollback follower read: %v", rollbackErr)
+		}
+		return err
+	}
+
+	_, err := conn.Exec(ctx, `COMMIT`)
+	return err
+}
+
+// tryPortForwardIfNeeded port-forwards the local port to the service when a
+// kube config is set, returning the local port to connect to. The
+// port-forward is shared with the callers, of any cluster of the provider,
+// forwarding the same port to the same service. It listens on an ephemeral port when the
+// local port is used, by another port-forward or another process. It is
+// released once stopCh is closed and readyCh is closed once it is ready. No
+// port is returned on failure, the local port possibly being forwarded to
+// another service, e.g. of another cluster.
+func tryPortForwardIfNeeded(ctx context.Context, meta interface{}, stopCh chan struct{}, readyCh chan struct{}, localPort string) (string, diag.Diagnostics) {
+	cockroachClient := meta.(*cockroachClient)
+	if diags := cockroachClient.resolveConnection(ctx); diags.HasError() {
+		return "", diags
+	}
+
+	if cockroachClient.kubeConn.kubeConfig != nil {
+		forwardedPort, err := sharedPortForwardTo(ctx, cockroachClient.kubeConn, localPort, stopCh)
+		if err != nil {
+			return "", diag.FromErr(err)
+		}
+		close(readyCh)
+		return forwardedPort, nil
+	}
+
+	return localPort, nil
+}
+
+// sharedPortForwardTo port-forwards the local port to the remote port of the
+// service once ready, sharing the port-forward with the other callers
+// forwarding the same ports, until stopCh is closed.
+func sharedPortForwardTo(ctx context.Context, kubeConn kubeConn, localPort string, stopCh chan struct{}) (string, error) {
+	key := fmt.Sprintf("%s/%s/%s/%s/%s:%s", kubeConn.kubeConfig.Host, kubeConn.nameSpace, kubeConn.serviceName, strings.Join(kubeConn.bindAddresses, ","), localPort, kubeConn.remotePort)
+
+	fwd, created := portForwards.acquire(key, localPort)
+	_, span := traces.tracer().Start(ctx, "port-forward", trace.WithAttributes(
+		attribute.String("port_forward.key", key),
+		attribute.Bool("port_forward.reused", !created),
+	))
+	forwardedPort, err := waitPortForward(ctx, kubeConn, localPort, key, fwd, created, stopCh)
+	endSpan(span, err)
+
+	return forwardedPort, err
+}
+
+// waitPortForward waits for the shared port-forward to be ready, starting it
+// when created.
+func waitPortForward(ctx context.Context, kubeConn kubeConn, localPort string, key string, fwd *sharedPortForward, created bool, stopCh chan struct{}) (string, error) {
+	go func() {
+		<-stopCh
+		portForwards.release(key, fwd)
+	}()
+
+	if created {
+		go startPortForward(ctx, kubeConn, fwd.localPort, key, fwd)
+	} else {
+		logDebug("Reusing port-forward %s", key)
+	}
+
+	timeout := time.NewTimer(kubeConn.portForwardTimeout)
+	defer timeout.Stop()
+	progress := time.NewTicker(portForwardProgressInterval)
+	defer progress.Stop()
+	start := time.Now()
 
+	for {
 		select {
-		case <-readyCh:
+		case <-fwd.readyCh:
 			logDebug("Port-forwarding is ready to handle traffic")
-			break
-		case err := <-errCh:
-			return diag.FromErr(err)
+			return fwd.localPort, nil
+		case <-fwd.failedCh:
+			return localPort, fwd.err
+		case <-progress.C:
+			logInfo("waiting for port-forward %s to be ready (%s elapsed)", key, time.Since(start).Round(time.Second))
+		case <-timeout.C:
+			return localPort, fmt.Errorf("port-forward %s not ready after %s, raise %s if the cluster is slow to answer", key, kubeConn.portForwardTimeout, argPortForwardWait)
 		}
 	}
+}
 
-	return nil
+// portForwardProgressInterval is the interval of the logs of the callers
+// waiting for a port-forward.
+const portForwardProgressInterval = 10 * time.Second
+
+// startPortForward establishes the port-forward to a live pod of the service,
+// on an ephemeral port when the local port can't be listened on.
+func startPortForward(ctx context.Context, kubeConn kubeConn, localPort string, key string, fwd *sharedPortForward) {
+	kubeConfig := kubeConn.kubeConfig
+	nameSpace := kubeConn.nameSpace
+	remotePort := kubeConn.remotePort
+
+	if err := checkPortForwardAccess(ctx, kubeConn); err != nil {
+		portForwards.fail(key, fwd, err)
+		return
+	}
+
+	livePod, err := findLivePod(ctx, kubeConn)
+	if err != nil {
+		portForwards.fail(key, fwd, err)
+		return
+	}
+
+	serverURL, err := url.Parse(
+		fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/portforward", kubeConfig.Host, nameSpace, livePod))
+	if err != nil {
+		logError("failed to construct server URL: %v", err)
+		portForwards.fail(key, fwd, fmt.Errorf("failed to construct server URL: %w", err))
+		return
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(kubeConfig)
+	if err != nil {
+		logError("failed to create round tripper: %v", err)
+		portForwards.fail(key, fwd, fmt.Errorf("failed to create round tripper: %w", err))
+		return
+	}
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, serverURL)
+
+	pf, forwardErrCh, err := forwardPort(dialer, kubeConn.bindAddresses, localPort, remotePort, fwd.stopCh, kubeConn.portForwardTimeout)
+	if err != nil && localPort != "0" && strings.Contains(err.Error(), "unable to listen") {
+		logInfo("local port %s is not available, using an ephemeral port: %v", localPort, err)
+		pf, forwardErrCh, err = forwardPort(dialer, kubeConn.bindAddresses, "0", remotePort, fwd.stopCh, kubeConn.portForwardTimeout)
+	}
+	if err != nil {
+		logError("failed to forward port %s:%s: %v", localPort, remotePort, err)
+		portForwards.fail(key, fwd, fmt.Errorf("failed to forward port: %w", err))
+		return
+	}
+
+	ports, err := pf.GetPorts()
+	if err != nil || len(ports) == 0 {
+		logError("failed to get the forwarded port: %v", err)
+		pf.Close()
+		portForwards.fail(key, fwd, fmt.Errorf("failed to get the forwarded port: %v", err))
+		return
+	}
+	listened := strconv.Itoa(int(ports[0].Local))
+	portForwards.ready(fwd, listened)
+	activity.portForwarded(key)
+
+	logInfo("Port forwarding established: %s:%s -> %s", listened, remotePort, livePod)
+
+	// a terminated port-forward, e.g. when the pod went away, is established
+	// again by the next caller
+	if err := <-forwardErrCh; err != nil {
+		logError("port-forward %s:%s terminated: %v", listened, remotePort, err)
+	}
+	portForwards.forget(key, fwd)
+}
+
+// forwardPort forwards the local port of the addresses, "0" for an ephemeral
+// one, to the remote port once ready, the error of the forwarding being sent to the returned
+// channel once it terminates. It fails when the port-forward is neither ready
+// nor failed after the timeout.
+func forwardPort(dialer httpstream.Dialer, addresses []string, localPort string, remotePort string, stopCh chan struct{}, timeout time.Duration) (*portforward.PortForwarder, chan error, error) {
+	readyCh := make(chan struct{})
+	pf, err := portforward.NewOnAddresses(
+		dialer,
+		addresses,
+		[]string{fmt.Sprintf("%s:%s", localPort, remotePort)},
+		stopCh,
+		readyCh,
+		logWriter{level: "DEBUG"},
+		logWriter{level: "ERROR"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create port-forward: %w", err)
+	}
+
+	forwardErrCh := make(chan error, 1)
+	go func() {
+		forwardErrCh <- pf.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+		return pf, forwardErrCh, nil
+	case err := <-forwardErrCh:
+		if err == nil {
+			err = fmt.Errorf("port-forward stopped before being ready")
+		}
+		return nil, nil, err
+	case <-time.After(timeout):
+		pf.Close()
+		return nil, nil, fmt.Errorf("port-forward %s:%s not ready after %s", localPort, remotePort, timeout)
+	}
+}
+
+// logWriter writes the lines written to it to the log of the provider, e.g.
+// the output of the port-forwards which would otherwise mix with the output
+// of Terraform.
+type logWriter struct {
+	level string
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			log.Printf("[%s] %s", w.level, line)
+		}
+	}
+
+	return len(p), nil
+}
+
+// findLivePod returns the name of a running pod behind the CockroachDB service.
+func findLivePod(ctx context.Context, kubeConn kubeConn) (string, error) {
+	kubeClientSet := kubeConn.kubeClient
+	nameSpace := kubeConn.nameSpace
+	serviceName := kubeConn.serviceName
+	maxRetries := kubeConn.maxRetries
+
+	var svc *v1.Service
+	err := retryKubeAPI(maxRetries, func() (err error) {
+		svc, err = kubeClientSet.CoreV1().Services(nameSpace).Get(ctx, serviceName, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		logError("failed to get Kubernetes service %s in namespace %s: %v", serviceName, nameSpace, err)
+		return "", fmt.Errorf("failed to get Kubernetes service: %w", err)
+	}
+
+	selector := mapToSelectorStr(svc.Spec.Selector)
+	if selector == "" {
+		err := fmt.Errorf("service %s has no selector", serviceName)
+		logError("failed to get service selector: %v", err)
+		return "", err
+	}
+
+	var pods *v1.PodList
+	err = retryKubeAPI(maxRetries, func() (err error) {
+		pods, err = kubeClientSet.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		return err
+	})
+	if err != nil {
+		logError("failed to get pod list for selector %s: %v", selector, err)
+		return "", fmt.Errorf("failed to get pod list: %w", err)
+	}
+
+	if len(pods.Items) == 0 {
+		err := fmt.Errorf("no CockroachDB pods found with selector %s", selector)
+		logError("%v", err)
+		return "", err
+	}
+
+	livePod, err := getPodName(pods)
+	if err != nil {
+		logError("failed to get live CockroachDB pod: %v", err)
+		return "", fmt.Errorf("failed to get live pod: %w", err)
+	}
+
+	return livePod, nil
+}
+
+// execInPod runs the command in the container of the pod and returns its
+// standard output, its standard error being part of the returned error. The
+// stdin, if any, is streamed to the command, keeping what it holds out of the
+// exec request, logged by the API server, and of the processes of the pod.
+func execInPod(kubeConn kubeConn, pod string, container string, command []string, stdin io.Reader) (string, error) {
+	req := kubeConn.kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(kubeConn.nameSpace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(kubeConn.kubeConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return stdout.String(), fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// retryKubeAPI calls fn until it succeeds, retrying up to maxRetries times
+// with an exponential backoff when the API server throttles or times out.
+func retryKubeAPI(maxRetries int, fn func() error) error {
+	// fn is called at least once, retry.OnError not calling it at all when
+	// there are no steps
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	backoff := wait.Backoff{
+		Steps:    maxRetries + 1,
+		Duration: 500 * time.Millisecond,
+		Factor:   2,
+		Jitter:   0.1,
+		Cap:      30 * time.Second,
+	}
+
+	return retry.OnError(backoff, isRetryableKubeError, func() error {
+		err := fn()
+		if err != nil && isRetryableKubeError(err) {
+			logDebug("retrying Kubernetes API call: %v", err)
+			activity.retried()
+		}
+		return err
+	})
+}
+
+func isRetryableKubeError(err error) bool {
+	return apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err)
 }
 
 func getPodName(pods *v1.PodList) (string, error) {
@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/jackc/pgconn"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestObjectLocks(t *testing.T) {
+	var (
+		locks   objectLocks
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running = map[string]int{}
+	)
+
+	keys := [][]string{{"a", "b"}, {"b", "a"}, {"a"}, {"b", "b"}}
+	for i := 0; i < 100; i++ {
+		for _, k := range keys {
+			wg.Add(1)
+			go func(k []string) {
+				defer wg.Done()
+				unlock := locks.lock(k...)
+				defer unlock()
+
+				held := map[string]bool{}
+				for _, key := range k {
+					held[key] = true
+				}
+
+				mu.Lock()
+				for key := range held {
+					running[key]++
+					if running[key] > 1 {
+						t.Errorf("object %s locked twice", key)
+					}
+				}
+				mu.Unlock()
+
+				mu.Lock()
+				for key := range held {
+					running[key]--
+				}
+				mu.Unlock()
+			}(k)
+		}
+	}
+	wg.Wait()
+}
+
+func TestReadCache(t *testing.T) {
+	var cache readCache
+
+	loads := 0
+	load := func() (interface{}, diag.Diagnostics) {
+		loads++
+		return loads, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if value, _ := cache.get("users", load); value != 1 {
+			t.Errorf("expected the cached value, got %v", value)
+		}
+	}
+
+	if _, diags := cache.get("failing", func() (interface{}, diag.Diagnostics) {
+		return nil, diag.Errorf("failed")
+	}); !diags.HasError() {
+		t.Errorf("expected the load error to be returned")
+	}
+	if value, _ := cache.get("failing", load); value != 2 {
+		t.Errorf("expected a failed load not to be cached, got %v", value)
+	}
+
+	cache.invalidate()
+	if value, _ := cache.get("users", load); value != 3 {
+		t.Errorf("expected the value to be loaded again after invalidate, got %v", value)
+	}
+}
+
+func TestRetryKubeAPI(t *testing.T) {
+	calls := 0
+	err := retryKubeAPI(1, func() error {
+		calls++
+		if calls == 1 {
+			return apierrors.NewTooManyRequests("throttled", 0)
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected a throttled call to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = retryKubeAPI(3, func() error {
+		calls++
+		return apierrors.NewNotFound(v1.Resource("services"), "cockroachdb-public")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a not found error not to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = retryKubeAPI(-1, func() error {
+		calls++
+		return nil
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("expected the call to be made once without retries, got %v after %d calls", err, calls)
+	}
+}
+
+func TestIsTransientConnError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{&pgconn.PgError{Code: "57P01", Message: "server is shutting down"}, true},
+		{&pgconn.PgError{Code: "57P03", Message: "the database system is starting up"}, true},
+		{&pgconn.PgError{Code: "08006", Message: "connection failure"}, true},
+		{&pgconn.PgError{Code: "08P01", Message: "protocol violation"}, false},
+		{&pgconn.PgError{Code: "42P01", Message: "relation does not exist"}, false},
+		{fmt.Errorf("failed to connect: %w", syscall.ECONNREFUSED), true},
+		{errors.New("dial tcp 127.0.0.1:26257: connect: connection refused"), true},
+		{errors.New("no live pods behind the service"), true},
+		{errors.New("password authentication failed"), false},
+	}
+
+	for _, c := range cases {
+		if actual := isTransientConnError(c.err); actual != c.expected {
+			t.Errorf("isTransientConnError(%v) = %v, expected %v", c.err, actual, c.expected)
+		}
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	calls := 0
+	err := retryTransient(context.Background(), time.Minute, func() error {
+		calls++
+		if calls == 1 {
+			return &pgconn.PgError{Code: "57P01"}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected a draining node to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = retryTransient(context.Background(), time.Minute, func() error {
+		calls++
+		return errors.New("password authentication failed")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected an authentication error not to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = retryTransient(context.Background(), 0, func() error {
+		calls++
+		return syscall.ECONNREFUSED
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected no retry without timeout, got %v after %d calls", err, calls)
+	}
+}
+
+func TestPortForwardRegistry(t *testing.T) {
+	registry := &portForwardRegistry{forwards: map[string]*sharedPortForward{}, ports: map[string]string{}}
+	// the signal handler is only registered by the package registry
+	registry.signalOnce.Do(func() {})
+
+	fwd, created := registry.acquire("cockroachdb/26258", "26258")
+	if !created {
+		t.Fatalf("expected the first caller to establish the port-forward")
+	}
+	shared, created := registry.acquire("cockroachdb/26258", "26258")
+	if created || shared != fwd {
+		t.Fatalf("expected the second caller to share the port-forward")
+	}
+
+	registry.release("cockroachdb/26258", fwd)
+	select {
+	case <-fwd.stopCh:
+		t.Fatalf("expected the port-forward to run while used")
+	default:
+	}
+
+	registry.release("cockroachdb/26258", fwd)
+	select {
+	case <-fwd.stopCh:
+	default:
+		t.Fatalf("expected the port-forward to stop once released")
+	}
+
+	if _, created := registry.acquire("cockroachdb/26258", "26258"); !created {
+		t.Errorf("expected a stopped port-forward to be established again")
+	}
+
+	registry.stopAll()
+	if len(registry.forwards) != 0 || len(registry.ports) != 0 {
+		t.Errorf("expected no port-forward after stopAll, got %d", len(registry.forwards))
+	}
+}
+
+func TestPortForwardRegistryPortConflict(t *testing.T) {
+	registry := &portForwardRegistry{forwards: map[string]*sharedPortForward{}, ports: map[string]string{}}
+	registry.signalOnce.Do(func() {})
+
+	staging, _ := registry.acquire("staging/cockroachdb/26258", "26258")
+	if staging.localPort != "26258" {
+		t.Errorf("expected the first port-forward to listen on the local port, got %s", staging.localPort)
+	}
+	prod, created := registry.acquire("prod/cockroachdb/26258", "26258")
+	if !created || prod.localPort != "0" {
+		t.Errorf("expected the second cluster to use an ephemeral port, got %s", prod.localPort)
+	}
+
+	registry.ready(prod, "40123")
+	select {
+	case <-prod.readyCh:
+	default:
+		t.Fatalf("expected the port-forward to be ready")
+	}
+	if prod.localPort != "40123" {
+		t.Errorf("expected the ephemeral port to be recorded, got %s", prod.localPort)
+	}
+
+	// the port is available again once its port-forward is released
+	registry.release("staging/cockroachdb/26258", staging)
+	registry.release("prod/cockroachdb/26258", prod)
+	if fwd, _ := registry.acquire("prod/cockroachdb/26258", "26258"); fwd.localPort != "26258" {
+		t.Errorf("expected the released port to be used, got %s", fwd.localPort)
+	}
+}
+
+func TestPortForwardRegistryStopAll(t *testing.T) {
+	registry := &portForwardRegistry{forwards: map[string]*sharedPortForward{}, ports: map[string]string{}}
+	registry.signalOnce.Do(func() {})
+
+	fwd, _ := registry.acquire("cockroachdb/26258", "26258")
+	registry.stopAll()
+
+	select {
+	case <-fwd.stopCh:
+	default:
+		t.Fatalf("expected the port-forward to stop with the provider")
+	}
+
+	// releasing a port-forward stopped by the provider is a no-op
+	registry.release("cockroachdb/26258", fwd)
+	if fwd, created := registry.acquire("cockroachdb/26258", "26258"); !created || fwd.localPort != "26258" {
+		t.Errorf("expected the port to be available once stopped, got %s", fwd.localPort)
+	}
+}
+
+func TestCheckClusterIdentity(t *testing.T) {
+	const id = "6d5ac3b8-2a67-4d2b-9d0e-7c4f3e2a1b90"
+
+	for _, c := range []struct {
+		expectedID   string
+		expectedName string
+		ok           bool
+	}{
+		{"", "", true},
+		{id, "", true},
+		{"6D5AC3B8-2A67-4D2B-9D0E-7C4F3E2A1B90", "prod", true},
+		{"00000000-0000-0000-0000-000000000000", "", false},
+		{"", "staging", false},
+		{id, "staging", false},
+	} {
+		err := checkClusterIdentity(c.expectedID, c.expectedName, id, "prod")
+		if (err == nil) != c.ok {
+			t.Errorf("unexpected result for %q and %q: %v", c.expectedID, c.expectedName, err)
+		}
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer log.SetFlags(flags)
+
+	w := logWriter{level: "DEBUG"}
+	if _, err := w.Write([]byte("Forwarding from 127.0.0.1:26257 -> 26257\n\nHandling connection for 26257\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[DEBUG] Forwarding from 127.0.0.1:26257 -> 26257\n[DEBUG] Handling connection for 26257\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestRoleNamesEqual(t *testing.T) {
+	if !roleNamesEqual("MyUser", "myuser") {
+		t.Errorf("expected MyUser and myuser to be the same role")
+	}
+	if roleNamesEqual("my_user", "myuser") {
+		t.Errorf("expected my_user and myuser to be different roles")
+	}
+}
+
+func TestSetDefaultSchema(t *testing.T) {
+	meta := &cockroachClient{defaultDatabase: "bank", defaultSchema: "app"}
+
+	cases := []struct {
+		schemaName cty.Value
+		expected   string
+	}{
+		{cty.NullVal(cty.String), "app"},
+		{cty.StringVal("audit"), "audit"},
+		// the name of a schema created in the same plan isn't known yet
+		{cty.UnknownVal(cty.String), "(known after apply)"},
+	}
+
+	for _, c := range cases {
+		raw := map[string]interface{}{"table": "accounts", "index": "accounts_idx", "visible": false}
+		if c.schemaName.IsKnown() && !c.schemaName.IsNull() {
+			raw["schema"] = c.schemaName.AsString()
+		}
+		if !c.schemaName.IsKnown() {
+			// the legacy configuration of an unknown value
+			raw["schema"] = "74D93920-ED26-11E3-AC10-0800200C9A66"
+		}
+		state := &terraform.InstanceState{
+			RawConfig: cty.ObjectVal(map[string]cty.Value{"database": cty.NullVal(cty.String), "schema": c.schemaName}),
+		}
+
+		diff, err := resourceIndexVisibility().SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		attr := diff.Attributes["schema"]
+		if c.schemaName.IsKnown() && (attr.New != c.expected || attr.NewComputed) || !c.schemaName.IsKnown() && !attr.NewComputed {
+			t.Errorf("expected the schema %#v to be planned as %q, got %+v", c.schemaName, c.expected, attr)
+		}
+		if database := diff.Attributes["database"]; database.New != "bank" {
+			t.Errorf("expected the default database to be planned, got %+v", database)
+		}
+	}
+}
+
+func TestDiagnosticsError(t *testing.T) {
+	if err := diagnosticsError(diag.Diagnostics{{Severity: diag.Warning, Summary: "deprecated"}}); err != nil {
+		t.Errorf("expected no error from warnings, got %v", err)
+	}
+
+	diags := diag.Diagnostics{
+		{Severity: diag.Error, Summary: "failed to port-forward", Detail: "pod cockroachdb-0 not running"},
+		{Severity: diag.Warning, Summary: "deprecated"},
+		{Severity: diag.Error, Summary: "failed to connect"},
+	}
+	expected := "failed to port-forward: pod cockroachdb-0 not running; failed to connect"
+	if err := diagnosticsError(diags); err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
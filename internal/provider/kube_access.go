@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kubeAccess is a permission on the resources of the namespace.
+type kubeAccess struct {
+	verb        string
+	resource    string
+	subresource string
+}
+
+func (a kubeAccess) String() string {
+	if a.subresource != "" {
+		return fmt.Sprintf("%s %s/%s", a.verb, a.resource, a.subresource)
+	}
+	return fmt.Sprintf("%s %s", a.verb, a.resource)
+}
+
+// portForwardAccess are the permissions port-forwarding to a pod of the
+// service requires, see findLivePod and startPortForward.
+var portForwardAccess = []kubeAccess{
+	{verb: "get", resource: "services"},
+	{verb: "list", resource: "pods"},
+	{verb: "create", resource: "pods", subresource: "portforward"},
+}
+
+// checkedKubeAccess are the namespaces, by API server, whose permissions were
+// granted, so they are only checked once.
+var checkedKubeAccess sync.Map
+
+// checkPortForwardAccess checks with SelfSubjectAccessReviews the user of the
+// kube config may port-forward to the pods of the namespace, failing with the
+// missing permissions rather than with the error of the port-forward. The
+// reviews failing, e.g. when the user may not create them, the port-forward
+// is attempted anyway.
+func checkPortForwardAccess(ctx context.Context, kubeConn kubeConn) error {
+	key := kubeConn.kubeConfig.Host + "/" + kubeConn.nameSpace
+	if _, ok := checkedKubeAccess.Load(key); ok {
+		return nil
+	}
+
+	var missing []string
+	for _, access := range portForwardAccess {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   kubeConn.nameSpace,
+					Verb:        access.verb,
+					Resource:    access.resource,
+					Subresource: access.subresource,
+				},
+			},
+		}
+
+		var result *authorizationv1.SelfSubjectAccessReview
+		err := retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+			result, err = kubeConn.kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			return err
+		})
+		if err != nil {
+			logInfo("failed to review the permission to %s in namespace %s, port-forwarding anyway: %v", access, kubeConn.nameSpace, err)
+			return nil
+		}
+		if !result.Status.Allowed {
+			missing = append(missing, access.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the user of the kube config can't port-forward to CockroachDB in namespace %s, it lacks the permissions to %s", kubeConn.nameSpace, strings.Join(missing, ", "))
+	}
+
+	checkedKubeAccess.Store(key, struct{}{})
+	return nil
+}
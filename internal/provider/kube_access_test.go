@@ -0,0 +1,17 @@
+package provider
+
+import "testing"
+
+func TestKubeAccessString(t *testing.T) {
+	var names []string
+	for _, access := range portForwardAccess {
+		names = append(names, access.String())
+	}
+
+	expected := []string{"get services", "list pods", "create pods/portforward"}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], names[i])
+		}
+	}
+}
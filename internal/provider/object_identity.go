@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+// The databases, tables and users expose the internal id CockroachDB gives
+// them, which doesn't change when they are renamed, and the time they were
+// created by the provider, for the outputs and the other resources needing
+// something more stable than their name.
+const (
+	descriptorIDAttr = "descriptor_id"
+	userIDAttr       = "user_id"
+	createdAtAttr    = "created_at"
+)
+
+// descriptorIDSchema is the computed id of the descriptor of the object.
+func descriptorIDSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "Id of the descriptor of the object, kept when it is renamed.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	}
+}
+
+// createdAtSchema is the computed creation time of the object.
+func createdAtSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "Time the object was created by the provider, in RFC 3339 format. Empty for the imported objects.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+}
+
+// setCreatedAt records the current time as the creation time of the object.
+func setCreatedAt(d *schema.ResourceData) error {
+	return d.Set(createdAtAttr, time.Now().UTC().Format(time.RFC3339))
+}
+
+// readTableDescriptorID returns the id of the descriptor of the table, its
+// OID in CockroachDB.
+func readTableDescriptorID(ctx context.Context, conn *pgx.Conn, database string, schemaName string, name string) (int64, error) {
+	var id int64
+	err := conn.QueryRow(ctx, `SELECT `+pq.QuoteLiteral(qualifiedNames(database, schemaName, []string{name}))+`::REGCLASS::OID::INT8`).Scan(&id)
+
+	return id, err
+}
+
+// readUserIDs returns the ids of the users, by name.
+func readUserIDs(ctx context.Context, conn *pgx.Conn) (map[string]int64, error) {
+	rows, err := conn.Query(ctx, `SELECT rolname, oid::INT8 FROM pg_catalog.pg_roles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := map[string]int64{}
+	for rows.Next() {
+		var name string
+		var id int64
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		ids[name] = id
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSetCreatedAt(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{createdAtAttr: createdAtSchema()}, map[string]interface{}{})
+
+	if err := setCreatedAt(d); err != nil {
+		t.Fatal(err)
+	}
+	createdAt, err := time.Parse(time.RFC3339, d.Get(createdAtAttr).(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(createdAt) > time.Minute {
+		t.Errorf("unexpected creation time %s", createdAt)
+	}
+}
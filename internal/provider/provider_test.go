@@ -2,6 +2,8 @@ package provider
 
 import (
 	"context"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,8 +46,125 @@ func TestProvider(t *testing.T) {
 	require.NoError(t, conn.Close(ctx))
 }
 
+func TestWithSessionVariables(t *testing.T) {
+	cases := []struct {
+		dns       string
+		variables map[string]interface{}
+		expected  string
+	}{
+		{
+			"postgresql://u:p@localhost:<local_port>/system?sslmode=disable",
+			map[string]interface{}{},
+			"postgresql://u:p@localhost:<local_port>/system?sslmode=disable&application_name=terraform-provider-cockroach%2Fdev",
+		},
+		{
+			"postgresql://u@db:26257/defaultdb",
+			map[string]interface{}{"application_name": "infra", "statement_timeout": "30s"},
+			"postgresql://u@db:26257/defaultdb?application_name=infra&statement_timeout=30s",
+		},
+		{
+			"postgresql://u@db:26257/defaultdb?application_name=mine",
+			map[string]interface{}{},
+			"postgresql://u@db:26257/defaultdb?application_name=mine",
+		},
+	}
+
+	for _, c := range cases {
+		if got := withSessionVariables(c.dns, "dev", c.variables); got != c.expected {
+			t.Errorf("withSessionVariables(%q) = %q, expected %q", c.dns, got, c.expected)
+		}
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestConfigureDefersConnection(t *testing.T) {
+	p := New("dev")()
+	d := schema.TestResourceDataRaw(t, p.Schema, map[string]interface{}{
+		"username": "root",
+		"kube_config": []interface{}{
+			map[string]interface{}{
+				"kube_config_path":  filepath.Join(t.TempDir(), "missing"),
+				"namespace":         "cockroachdb",
+				"crdb_cluster_name": "cockroachdb",
+			},
+		},
+	})
+
+	// the kube config of a cluster not created yet doesn't fail the plan
+	meta, diags := configure("dev", p)(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("unexpected error %v", diags)
+	}
+	client := meta.(*cockroachClient)
+	if !client.hasConnection() {
+		t.Error("expected the provider to have a connection")
+	}
+
+	port, diags := tryPortForwardIfNeeded(context.Background(), client, make(chan struct{}), make(chan struct{}), "26257")
+	if !diags.HasError() {
+		t.Error("expected the first connection to fail on the missing kube config")
+	}
+	if port != "" {
+		t.Errorf("expected no port on failure, got %s", port)
+	}
+	if _, err := client.kubeConnection(context.Background()); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected the configuration to be tried again, got %v", err)
+	}
+}
+
+func TestKubeConnForwardHost(t *testing.T) {
+	cases := map[string][]string{
+		"127.0.0.1": nil,
+		"0.0.0.0":   {"0.0.0.0"},
+		"[::1]":     {"::1", "127.0.0.1"},
+	}
+
+	for expected, addresses := range cases {
+		if got := (kubeConn{bindAddresses: addresses}).forwardHost(); got != expected {
+			t.Errorf("forwardHost(%v) = %q, expected %q", addresses, got, expected)
+		}
+	}
+}
+
+func TestWithRoutingID(t *testing.T) {
+	cases := []struct {
+		dns       string
+		routingID string
+		expected  string
+	}{
+		{"postgresql://u@db:26257/defaultdb", "", "postgresql://u@db:26257/defaultdb"},
+		{"postgresql://u@127.0.0.1:<local_port>/system", "crdb-123", "postgresql://u@127.0.0.1:<local_port>/system?options=--cluster%3Dcrdb-123"},
+		{
+			"postgresql://u@proxy:26257/defaultdb?sslmode=verify-full&options=--cluster%3Dold+-c+timezone%3DUTC",
+			"crdb-123",
+			"postgresql://u@proxy:26257/defaultdb?options=--cluster%3Dcrdb-123+-c+timezone%3DUTC&sslmode=verify-full",
+		},
+	}
+
+	for _, c := range cases {
+		if got := withRoutingID(c.dns, c.routingID); got != c.expected {
+			t.Errorf("withRoutingID(%q) = %q, expected %q", c.dns, got, c.expected)
+		}
+	}
+}
+
+func TestSocketConnectionString(t *testing.T) {
+	dns, err := socketConnectionString("root", "", "/tmp/.s.PGSQL.26258")
+	if err != nil || dns != "postgresql://root@/system?host=%2Ftmp&port=26258" {
+		t.Errorf("unexpected connection string %q (%v)", dns, err)
+	}
+
+	dns, err = socketConnectionString("terraform", "secret", "/var/run/cockroach")
+	if err != nil || dns != "postgresql://terraform:secret@/system?host=%2Fvar%2Frun%2Fcockroach&port=26257" {
+		t.Errorf("unexpected connection string %q (%v)", dns, err)
+	}
+
+	if _, err := socketConnectionString("root", "", "tmp/.s.PGSQL.26257"); err == nil {
+		t.Errorf("expected an error with a relative path")
+	}
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+// With refresh_snapshot set, the refreshes of the resources read at the same
+// timestamp, taken by the first of them, in a read-only transaction AS OF
+// SYSTEM TIME. The resources refreshed in parallel see a consistent state of
+// the cluster this way, e.g. a grant changed in the middle of the refresh is
+// seen by none or all of them. The timestamp is dropped along with the read
+// cache once the provider changes anything, the reads following the changes
+// seeing them.
+const argRefreshSnapshot = "refresh_snapshot"
+
+type refreshReadKey struct{}
+
+// withRefreshSnapshot marks the context of the refreshes of the resource, the
+// reads following the changes made by the resource, called by its create or
+// update functions, not being marked.
+func withRefreshSnapshot(r *schema.Resource) *schema.Resource {
+	if read := r.ReadContext; read != nil {
+		r.ReadContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return read(context.WithValue(ctx, refreshReadKey{}, true), d, meta)
+		}
+	}
+
+	return r
+}
+
+// isRefreshRead returns whether the context is the one of a refresh.
+func isRefreshRead(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshReadKey{}).(bool)
+	return refresh
+}
+
+// beginRefreshSnapshot begins the read-only transaction of the connection at
+// the timestamp of the snapshot of the refresh, taken with the connection
+// when there is none yet.
+func beginRefreshSnapshot(ctx context.Context, conn *pgx.Conn, cache *readCache) error {
+	timestamp, diags := cache.get("refresh_snapshot", func() (interface{}, diag.Diagnostics) {
+		var timestamp string
+		if err := conn.QueryRow(ctx, `SELECT cluster_logical_timestamp()::STRING`).Scan(&timestamp); err != nil {
+			return nil, diag.FromErr(err)
+		}
+		logDebug("refreshing at timestamp %s", timestamp)
+		return timestamp, nil
+	})
+	if diags.HasError() {
+		return diagnosticsError(diags)
+	}
+
+	_, err := conn.Exec(ctx, `BEGIN AS OF SYSTEM TIME `+pq.QuoteLiteral(timestamp.(string)))
+	return err
+}
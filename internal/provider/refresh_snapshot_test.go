@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWithRefreshSnapshot(t *testing.T) {
+	refreshes := []bool{}
+	r := &schema.Resource{
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			refreshes = append(refreshes, isRefreshRead(ctx))
+			return nil
+		},
+	}
+	read := r.ReadContext
+
+	withRefreshSnapshot(r)
+	r.ReadContext(context.Background(), nil, nil)
+	// the read called by the create and update functions
+	read(context.Background(), nil, nil)
+
+	if len(refreshes) != 2 || !refreshes[0] || refreshes[1] {
+		t.Errorf("expected a refresh then a read, got %v", refreshes)
+	}
+
+	if withRefreshSnapshot(&schema.Resource{}).ReadContext != nil {
+		t.Error("expected the resource without read to be left as is")
+	}
+}
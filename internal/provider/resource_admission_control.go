@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var byteSizeRegexp = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([KMGT]i?B|B)?$`)
+
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// parseByteSize parses a byte size the way CockroachDB shows them, e.g.
+// 64 MiB.
+func parseByteSize(s string) (float64, bool) {
+	m := byteSizeRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return n * byteSizeUnits[strings.ToUpper(m[2])], true
+}
+
+func suppressEquivalentByteSize(k, old, new string, d *schema.ResourceData) bool {
+	o, ok := parseByteSize(old)
+	if !ok {
+		return false
+	}
+	n, ok := parseByteSize(new)
+
+	return ok && o == n
+}
+
+func byteSizeSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description:      description,
+		Type:             schema.TypeString,
+		ValidateFunc:     validation.StringMatch(byteSizeRegexp, "must be a byte size, e.g. 64 MiB"),
+		DiffSuppressFunc: suppressEquivalentByteSize,
+	}
+}
+
+func resourceAdmissionControl() *schema.Resource {
+	return resourceSettingGroup(settingGroup{
+		id: "admission_control",
+		description: "Resource used to manage the overload protection of a CockroachDB cluster, " +
+			"i.e. the admission control of the work by the nodes, the quality of service of the transactions and the rates of the bulk and rebalancing writes.",
+		localPort: "26287",
+		attributes: map[string]groupedSetting{
+			"kv_enabled": {name: "admission.kv.enabled", schema: &schema.Schema{
+				Description: "Whether the KV work is subject to admission control.",
+				Type:        schema.TypeBool,
+			}},
+			"sql_kv_response_enabled": {name: "admission.sql_kv_response.enabled", schema: &schema.Schema{
+				Description: "Whether the processing of the KV responses by SQL is subject to admission control.",
+				Type:        schema.TypeBool,
+			}},
+			"sql_sql_response_enabled": {name: "admission.sql_sql_response.enabled", schema: &schema.Schema{
+				Description: "Whether the processing of the responses of distributed SQL is subject to admission control.",
+				Type:        schema.TypeBool,
+			}},
+			"elastic_cpu_enabled": {name: "admission.elastic_cpu.enabled", schema: &schema.Schema{
+				Description: "Whether the CPU used by the elastic work, e.g. backups and changefeeds, is limited to leave room for the foreground work.",
+				Type:        schema.TypeBool,
+			}},
+			"epoch_lifo_enabled": {name: "admission.epoch_lifo.enabled", schema: &schema.Schema{
+				Description: "Whether the queued work is admitted last in first out under overload, favoring the recent transactions.",
+				Type:        schema.TypeBool,
+			}},
+			"default_transaction_quality_of_service": {name: "default_transaction_quality_of_service", roleDefault: true, schema: &schema.Schema{
+				Description:  "Default quality of service of the transactions, the priority of their work in the admission queues, `background`, `regular` or `critical`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"background", "regular", "critical"}, true),
+			}},
+			"provisioned_bandwidth": {name: "kvadmission.store.provisioned_bandwidth", schema: byteSizeSchema(
+				"Disk bandwidth provisioned for each store, per second, e.g. `500 MiB`, which the elastic writes are kept under. `0 B` disables the limit.",
+			)},
+			"bulk_io_write_max_rate": {name: "kv.bulk_io_write.max_rate", schema: byteSizeSchema(
+				"Maximum rate of the bulk writes of each store, e.g. of restores and imports, per second, e.g. `1 TiB`.",
+			)},
+			"snapshot_rebalance_max_rate": {name: "kv.snapshot_rebalance.max_rate", schema: byteSizeSchema(
+				"Maximum rate of the snapshots sent to rebalance and recover ranges, per second, e.g. `32 MiB`.",
+			)},
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceAdmissionControl(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceAdmissionControl,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_admission_control.foo", "elastic_cpu_enabled", "true"),
+					resource.TestCheckResourceAttr("cockroach_admission_control.foo", "snapshot_rebalance_max_rate", "64MiB"),
+					resource.TestCheckResourceAttr("cockroach_admission_control.foo", "default_transaction_quality_of_service", "background"),
+				),
+			},
+		},
+	})
+}
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]float64{
+		"0":       0,
+		"512 B":   512,
+		"64 MiB":  64 << 20,
+		"64MiB":   64 << 20,
+		"1.5 GiB": 1.5 * (1 << 30),
+		"2 gb":    2e9,
+	}
+	for s, expected := range cases {
+		if n, ok := parseByteSize(s); !ok || n != expected {
+			t.Errorf("parseByteSize(%q) = %v, %v, expected %v", s, n, ok, expected)
+		}
+	}
+
+	if _, ok := parseByteSize("fast"); ok {
+		t.Errorf("expected an invalid byte size")
+	}
+	if !suppressEquivalentByteSize("", "64 MiB", "64MiB", nil) || suppressEquivalentByteSize("", "64 MiB", "64 MB", nil) {
+		t.Errorf("unexpected byte size comparison")
+	}
+}
+
+const testAccResourceAdmissionControl = `
+resource "cockroach_admission_control" "foo" {
+  elastic_cpu_enabled         = true
+  snapshot_rebalance_max_rate = "64MiB"
+
+  default_transaction_quality_of_service = "background"
+}
+`
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+func resourceBootstrapUser() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource creating the first SQL user with a password right after the cluster is initialized, by running `cockroach sql` as root with its certificates in one of the pods. " +
+			"It requires the `kube_config` block of the provider. Destroying it only removes it from the state, the user is kept.",
+
+		CreateContext: resourceBootstrapUserCreate,
+		ReadContext:   resourceBootstrapUserRead,
+		UpdateContext: resourceBootstrapUserUpdate,
+		DeleteContext: resourceBootstrapUserDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			dbUsernameAttr: {
+				Description: "Name of the user to create.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			dbPasswordAttr: {
+				Description: "Password of the user.",
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+			},
+			dbAdminAttr: {
+				Description: "True if the user is granted the admin role.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+			},
+			initPodNameAttr: {
+				Description: "Pod running `cockroach sql`, a running pod of the service is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			initContainerAttr: {
+				Description: "Container of the pod running CockroachDB.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "db",
+			},
+			initCertsDirAttr: {
+				Description: "Directory of the certificates in the container, it must hold the client certificate of root.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "/cockroach/cockroach-certs",
+			},
+			initHostAttr: {
+				Description: "Address of the node to connect to, as seen from the container.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "localhost",
+			},
+		},
+	}
+}
+
+// rootSQLCommand returns the `cockroach sql` command executing as root the
+// statements read from its standard input, the password they hold never
+// being part of the command.
+func rootSQLCommand(certsDir string, host string) []string {
+	return []string{
+		"cockroach", "sql",
+		fmt.Sprintf("--host=%s", host),
+		fmt.Sprintf("--certs-dir=%s", certsDir),
+		"--user=root",
+	}
+}
+
+// bootstrapUserStatements returns the statements creating the user, or
+// setting its password when it exists.
+func bootstrapUserStatements(username string, password string, isAdmin bool) string {
+	statements := fmt.Sprintf("CREATE USER IF NOT EXISTS %s; ALTER USER %s WITH LOGIN PASSWORD %s;",
+		pq.QuoteIdentifier(username), pq.QuoteIdentifier(username), pq.QuoteLiteral(password))
+	if isAdmin {
+		statements += fmt.Sprintf(" GRANT admin TO %s;", pq.QuoteIdentifier(username))
+	}
+
+	return statements
+}
+
+// errNoReadyPod is the error of execAsRoot when no pod of the service is
+// ready to run the statements yet.
+var errNoReadyPod = errors.New("no ready pod")
+
+// nodeNotReadyMessages are the errors of `cockroach sql`, or of the exec, while
+// the container isn't started yet or the node isn't initialized or ready to
+// serve clients.
+var nodeNotReadyMessages = []string{
+	"container not found",
+	"waiting for cluster initialization",
+	"not accepting clients",
+}
+
+// bootstrapRetryable returns whether the statements of execAsRoot can be run
+// again, only when no pod or node was ready for them, the errors of the
+// statements and of the configuration failing right away.
+func bootstrapRetryable(err error) bool {
+	if errors.Is(err, errNoReadyPod) {
+		return true
+	}
+	for _, message := range nodeNotReadyMessages {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// execAsRoot runs the statements as root in the pod of the resource, or in a
+// running pod of the service, and returns the pod used.
+func execAsRoot(ctx context.Context, d *schema.ResourceData, meta interface{}, statements string) (string, error) {
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return "", err
+	}
+	if kubeConn.kubeConfig == nil {
+		return "", fmt.Errorf("the kube_config block of the provider is required to bootstrap the user")
+	}
+
+	pod := d.Get(initPodNameAttr).(string)
+	if pod == "" {
+		var err error
+		if pod, err = findLivePod(ctx, kubeConn); err != nil {
+			return "", fmt.Errorf("%w: %v", errNoReadyPod, err)
+		}
+	}
+
+	command := rootSQLCommand(d.Get(initCertsDirAttr).(string), d.Get(initHostAttr).(string))
+	if _, err := execInPod(kubeConn, pod, d.Get(initContainerAttr).(string), command, strings.NewReader(statements)); err != nil {
+		return "", err
+	}
+
+	return pod, nil
+}
+
+func resourceBootstrapUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	username := d.Get(dbUsernameAttr).(string)
+	statements := bootstrapUserStatements(username, d.Get(dbPasswordAttr).(string), d.Get(dbAdminAttr).(bool))
+
+	var pod string
+	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		var err error
+		if pod, err = execAsRoot(ctx, d, meta, statements); err != nil {
+			if !bootstrapRetryable(err) {
+				return resource.NonRetryableError(err)
+			}
+			logDebug("failed to bootstrap user %s: %v", username, err)
+			return resource.RetryableError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return diag.Errorf("failed to bootstrap user %s: %v", username, err)
+	}
+
+	d.SetId(username)
+	if err := d.Set(initPodNameAttr, pod); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceBootstrapUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the user is managed by cockroach_user once bootstrapped, nothing to refresh
+	return diag.Diagnostics{}
+}
+
+func resourceBootstrapUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChange(dbPasswordAttr) {
+		return diag.Diagnostics{}
+	}
+
+	username := d.Get(dbUsernameAttr).(string)
+	statements := fmt.Sprintf("ALTER USER %s WITH PASSWORD %s;",
+		pq.QuoteIdentifier(username), pq.QuoteLiteral(d.Get(dbPasswordAttr).(string)))
+
+	pod, err := execAsRoot(ctx, d, meta, statements)
+	if err != nil {
+		return diag.Errorf("failed to update password of user %s: %v", username, err)
+	}
+
+	if err := d.Set(initPodNameAttr, pod); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceBootstrapUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestBootstrapUserStatements(t *testing.T) {
+	cases := []struct {
+		isAdmin  bool
+		expected string
+	}{
+		{false, `CREATE USER IF NOT EXISTS "ops"; ALTER USER "ops" WITH LOGIN PASSWORD 'it''s';`},
+		{true, `CREATE USER IF NOT EXISTS "ops"; ALTER USER "ops" WITH LOGIN PASSWORD 'it''s'; GRANT admin TO "ops";`},
+	}
+
+	for _, c := range cases {
+		if got := bootstrapUserStatements("ops", "it's", c.isAdmin); got != c.expected {
+			t.Errorf("bootstrapUserStatements(isAdmin=%t) = %q, expected %q", c.isAdmin, got, c.expected)
+		}
+	}
+}
+
+func TestBootstrapRetryable(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{fmt.Errorf("%w: no CockroachDB pods found with selector app=cockroachdb", errNoReadyPod), true},
+		{errors.New("command terminated with exit code 1: ERROR: node is waiting for cluster initialization"), true},
+		{errors.New("command terminated with exit code 1: ERROR: server is not accepting clients, try another node"), true},
+		{errors.New(`command terminated with exit code 1: ERROR: role "admin" does not exist`), false},
+		{errors.New("the kube_config block of the provider is required to bootstrap the user"), false},
+	}
+
+	for _, c := range cases {
+		if actual := bootstrapRetryable(c.err); actual != c.expected {
+			t.Errorf("bootstrapRetryable(%q) = %t, expected %t", c.err, actual, c.expected)
+		}
+	}
+}
+
+func TestAccResourceBootstrapUser(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceBootstrapUser,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_bootstrap_user.foo", "id", "bootstrap_admin"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceBootstrapUser = `
+resource "cockroach_bootstrap_user" "foo" {
+  username = "bootstrap_admin"
+  password = "bootstrap_password"
+}
+`
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceCACert() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource generating the CA certificate of a secure CockroachDB cluster, as `cockroach cert create-ca` does. " +
+			"The private key is stored in the state, which must be protected accordingly.",
+
+		CreateContext: resourceCACertCreate,
+		ReadContext:   resourceCertRead,
+		DeleteContext: resourceCertDelete,
+
+		Schema: certSchema(defaultCALifetime, false),
+	}
+}
+
+func resourceCACertCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	template, err := certTemplate("Cockroach CA", certLifetime(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template.IsCA = true
+	template.MaxPathLenZero = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment | x509.KeyUsageKeyEncipherment
+
+	cert, err := issueCert(template, d.Get(certRSABitsAttr).(int), nil, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setIssuedCert(d, template, cert); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceCertRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// certificates only live in the state, nothing to refresh
+	return diag.Diagnostics{}
+}
+
+func resourceCertDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,893 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	changefeedDatabaseAttr     = "database"
+	changefeedSchemaAttr       = "schema"
+	changefeedTablesAttr       = "tables"
+	changefeedSinkURIAttr      = "sink_uri"
+	changefeedKafkaAttr        = "kafka"
+	changefeedWebhookAttr      = "webhook"
+	changefeedCloudStorageAttr = "cloudstorage"
+	changefeedOptionsAttr      = "options"
+	changefeedInitialScanAttr  = "initial_scan"
+	changefeedCursorAttr       = "cursor"
+	changefeedStatusAttr       = "status"
+
+	sinkCACertAttr     = "ca_cert"
+	sinkClientCertAttr = "client_cert"
+	sinkClientKeyAttr  = "client_key"
+	sinkURLAttr        = "url"
+
+	kafkaBrokerAttr      = "broker"
+	kafkaTopicPrefixAttr = "topic_prefix"
+	kafkaTopicNameAttr   = "topic_name"
+	kafkaTLSEnabledAttr  = "tls_enabled"
+	kafkaSASLAttr        = "sasl"
+	saslMechanismAttr    = "mechanism"
+	saslUserAttr         = "user"
+	saslPasswordAttr     = "password"
+
+	webhookAuthHeaderAttr            = "auth_header"
+	webhookInsecureTLSSkipVerifyAttr = "insecure_tls_skip_verify"
+
+	cloudStorageAuthAttr               = "auth"
+	cloudStorageAWSAccessKeyIDAttr     = "aws_access_key_id"
+	cloudStorageAWSSecretAccessKeyAttr = "aws_secret_access_key"
+	cloudStorageAWSRegionAttr          = "aws_region"
+	cloudStorageCredentialsAttr        = "credentials"
+	cloudStorageAzureAccountNameAttr   = "azure_account_name"
+	cloudStorageAzureAccountKeyAttr    = "azure_account_key"
+
+	changefeedDefaultLocalPort = "26275"
+)
+
+var (
+	changefeedSinkAttrs = []string{changefeedSinkURIAttr, changefeedKafkaAttr, changefeedWebhookAttr, changefeedCloudStorageAttr}
+
+	kafkaBrokerRegexp      = regexp.MustCompile(`^[^:/\s]+:\d+$`)
+	changefeedOptionRegexp = regexp.MustCompile(`^[a-z_]+$`)
+	changefeedCursorRegexp = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+	cloudStorageSchemes = []string{"s3", "gs", "azure", "azure-blob", "azure-storage", "nodelocal", "userfile", "http", "https"}
+
+	// changefeedImmutableOptions are the options ALTER CHANGEFEED can't change.
+	changefeedImmutableOptions = []string{"cursor", "end_time", "full_table_name", "initial_scan", "initial_scan_only", "no_initial_scan"}
+
+	// changefeedInitialScanOptions are the options conflicting with the
+	// initial_scan attribute.
+	changefeedInitialScanOptions = []string{"initial_scan", "initial_scan_only", "no_initial_scan"}
+)
+
+// sinkCertificateSchema returns the schema of the certificates used to connect
+// to a sink.
+func sinkCertificateSchema(s map[string]*schema.Schema) map[string]*schema.Schema {
+	s[sinkCACertAttr] = &schema.Schema{
+		Description: "CA certificate in PEM format used to verify the certificate of the sink.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+	}
+	s[sinkClientCertAttr] = &schema.Schema{
+		Description: "Client certificate in PEM format used to authenticate to the sink.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+	}
+	s[sinkClientKeyAttr] = &schema.Schema{
+		Description: "Private key in PEM format of the client certificate.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		ForceNew:    true,
+	}
+
+	return s
+}
+
+func resourceChangefeed() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to create a changefeed of tables of a CockroachDB cluster, emitting their changes to a Kafka, webhook or cloud storage sink. " +
+			"Changes of the tables or the options alter the running changefeed, keeping its progress, the other changes create a new one. " +
+			"The changefeed is canceled when the resource is destroyed. " +
+			"Its id is the id of its job, which isn't scoped to the cluster: applied to another cluster, e.g. restored from a backup, the changefeed job of the same id there, if any, is taken for it.",
+
+		CreateContext: resourceChangefeedCreate,
+		ReadContext:   resourceChangefeedRead,
+		UpdateContext: resourceChangefeedUpdate,
+		DeleteContext: resourceChangefeedDelete,
+		CustomizeDiff: resourceChangefeedCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			changefeedDatabaseAttr: {
+				Description: "Name of the database of the tables, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			changefeedSchemaAttr: {
+				Description: "Name of the schema of the tables, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			changefeedTablesAttr: {
+				Description: "Names of the tables whose changes are emitted.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required: true,
+				MinItems: 1,
+			},
+			changefeedSinkURIAttr: {
+				Description:  "URI of the sink, e.g. `kafka://broker:9092?topic_prefix=crdb_`, for sinks without a dedicated block.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ForceNew:     true,
+				ExactlyOneOf: changefeedSinkAttrs,
+			},
+			changefeedKafkaAttr: {
+				Description:  "Kafka sink of the changefeed.",
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: changefeedSinkAttrs,
+				Elem: &schema.Resource{
+					Schema: sinkCertificateSchema(map[string]*schema.Schema{
+						kafkaBrokerAttr: {
+							Description:  "Address of the Kafka broker, as `host:port`.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringMatch(kafkaBrokerRegexp, "expected host:port"),
+						},
+						kafkaTopicPrefixAttr: {
+							Description: "Prefix added to the names of the topics.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+						},
+						kafkaTopicNameAttr: {
+							Description: "Name of the topic every table is emitted to, each table has its own topic when not set.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+						},
+						kafkaTLSEnabledAttr: {
+							Description: "Whether TLS is used to connect to the broker, required by the certificates.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							ForceNew:    true,
+							Default:     false,
+						},
+						kafkaSASLAttr: {
+							Description: "SASL authentication to the broker.",
+							Type:        schema.TypeList,
+							Optional:    true,
+							ForceNew:    true,
+							MaxItems:    1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									saslMechanismAttr: {
+										Description:  "SASL mechanism, among `PLAIN`, `SCRAM-SHA-256` and `SCRAM-SHA-512`.",
+										Type:         schema.TypeString,
+										Optional:     true,
+										ForceNew:     true,
+										Default:      "PLAIN",
+										ValidateFunc: validation.StringInSlice([]string{"PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512"}, false),
+									},
+									saslUserAttr: {
+										Description: "SASL user.",
+										Type:        schema.TypeString,
+										Required:    true,
+										ForceNew:    true,
+									},
+									saslPasswordAttr: {
+										Description: "SASL password.",
+										Type:        schema.TypeString,
+										Required:    true,
+										Sensitive:   true,
+										ForceNew:    true,
+									},
+								},
+							},
+						},
+					}),
+				},
+			},
+			changefeedWebhookAttr: {
+				Description:  "Webhook sink of the changefeed.",
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: changefeedSinkAttrs,
+				Elem: &schema.Resource{
+					Schema: sinkCertificateSchema(map[string]*schema.Schema{
+						sinkURLAttr: {
+							Description:  "HTTPS URL the changes are posted to.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.IsURLWithHTTPS,
+						},
+						webhookAuthHeaderAttr: {
+							Description: "Value of the `Authorization` header of the requests.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							ForceNew:    true,
+						},
+						webhookInsecureTLSSkipVerifyAttr: {
+							Description: "Whether the certificate of the endpoint is not verified, only meant for testing.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							ForceNew:    true,
+							Default:     false,
+						},
+					}),
+				},
+			},
+			changefeedCloudStorageAttr: {
+				Description:  "Cloud storage sink of the changefeed.",
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: changefeedSinkAttrs,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						sinkURLAttr: {
+							Description:  "URL of the bucket and path the files are written to, without credentials, e.g. `s3://bucket/changefeeds`.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validateCloudStorageURL,
+						},
+						cloudStorageAuthAttr: {
+							Description:  "Authentication to the cloud provider, `specified` when credentials are set or `implicit` to use the ones of the nodes.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice([]string{"specified", "implicit"}, false),
+						},
+						cloudStorageAWSAccessKeyIDAttr: {
+							Description: "AWS access key ID, for `s3` URLs.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+						},
+						cloudStorageAWSSecretAccessKeyAttr: {
+							Description: "AWS secret access key, for `s3` URLs.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							ForceNew:    true,
+						},
+						cloudStorageAWSRegionAttr: {
+							Description: "AWS region of the bucket, for `s3` URLs.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+						},
+						cloudStorageCredentialsAttr: {
+							Description: "JSON key of the Google Cloud service account, for `gs` URLs.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							ForceNew:    true,
+						},
+						cloudStorageAzureAccountNameAttr: {
+							Description: "Name of the Azure storage account, for `azure` URLs.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+						},
+						cloudStorageAzureAccountKeyAttr: {
+							Description: "Key of the Azure storage account, for `azure` URLs.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							ForceNew:    true,
+						},
+					},
+				},
+			},
+			changefeedOptionsAttr: {
+				Description: "Options of the changefeed, keyed by name, e.g. `resolved = \"10s\"`. Options without value, e.g. `updated`, are set to an empty string. " +
+					"Changing `cursor`, `end_time`, `full_table_name` or the initial scan options creates a new changefeed.",
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional:         true,
+				ValidateDiagFunc: validation.MapKeyMatch(changefeedOptionRegexp, "invalid changefeed option name"),
+			},
+			changefeedInitialScanAttr: {
+				Description:  "Whether the current rows of the tables are emitted when the changefeed starts, `yes`, `no` or `only` to stop the changefeed once they are. When not set, they are emitted unless a cursor is set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"yes", "no", "only"}, false),
+			},
+			changefeedCursorAttr: {
+				Description:  "Timestamp the changefeed starts from, either a resolved timestamp, e.g. `1536242855577149065.0000000000`, or a time in RFC 3339 format. It must be within the garbage collection window of the tables.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateChangefeedCursor,
+			},
+			changefeedStatusAttr: {
+				Description: "Status of the job of the changefeed.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26275), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     changefeedDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func resourceChangefeedCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, changefeedDatabaseAttr); err != nil {
+		return err
+	}
+
+	if err := setDefaultSchema(d, meta, changefeedSchemaAttr); err != nil {
+		return err
+	}
+
+	o, n := d.GetChange(changefeedOptionsAttr)
+	if changedOptions := changedChangefeedOptions(o.(map[string]interface{}), n.(map[string]interface{})); len(changedOptions) != 0 {
+		for _, name := range changefeedImmutableOptions {
+			if contains(changedOptions, name) {
+				return d.ForceNew(changefeedOptionsAttr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// changedChangefeedOptions returns the names of the options which are added,
+// changed or removed from o to n.
+func changedChangefeedOptions(o map[string]interface{}, n map[string]interface{}) []string {
+	var changed []string
+	for _, name := range sortedKeys(o) {
+		if value, ok := n[name]; !ok || value != o[name] {
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range sortedKeys(n) {
+		if _, ok := o[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
+
+func validateCloudStorageURL(v interface{}, k string) ([]string, []error) {
+	u, err := url.Parse(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("expected %q to be a URL: %v", k, err)}
+	}
+	if !contains(cloudStorageSchemes, u.Scheme) {
+		return nil, []error{fmt.Errorf("expected the scheme of %q to be one of %v, got %q", k, cloudStorageSchemes, u.Scheme)}
+	}
+	if u.RawQuery != "" {
+		return nil, []error{fmt.Errorf("expected %q to have no query parameters, credentials are set by dedicated attributes", k)}
+	}
+
+	return nil, nil
+}
+
+func validateChangefeedCursor(v interface{}, k string) ([]string, []error) {
+	cursor := v.(string)
+	if changefeedCursorRegexp.MatchString(cursor) {
+		return nil, nil
+	}
+	if _, err := time.Parse(time.RFC3339Nano, cursor); err != nil {
+		return nil, []error{fmt.Errorf("expected %q to be a resolved timestamp or a time in RFC 3339 format, got %q", k, cursor)}
+	}
+
+	return nil, nil
+}
+
+// encodePEM encodes a certificate or a key as expected by the query
+// parameters of a sink URI.
+func encodePEM(pem string) string {
+	return base64.StdEncoding.EncodeToString([]byte(pem))
+}
+
+// setSinkCertificates sets the query parameters of the certificates of a sink.
+func setSinkCertificates(query url.Values, sink map[string]interface{}) error {
+	if sink[sinkClientCertAttr].(string) != "" && sink[sinkClientKeyAttr].(string) == "" {
+		return fmt.Errorf("%s must be set along with %s", sinkClientKeyAttr, sinkClientCertAttr)
+	}
+
+	for _, attr := range []string{sinkCACertAttr, sinkClientCertAttr, sinkClientKeyAttr} {
+		if v := sink[attr].(string); v != "" {
+			query.Set(attr, encodePEM(v))
+		}
+	}
+
+	return nil
+}
+
+// kafkaSinkURI returns the URI of a Kafka sink.
+func kafkaSinkURI(sink map[string]interface{}) (string, error) {
+	query := url.Values{}
+
+	tlsEnabled := sink[kafkaTLSEnabledAttr].(bool)
+	if tlsEnabled {
+		query.Set(kafkaTLSEnabledAttr, "true")
+	} else if sink[sinkCACertAttr].(string) != "" || sink[sinkClientCertAttr].(string) != "" {
+		return "", fmt.Errorf("%s must be true to use certificates", kafkaTLSEnabledAttr)
+	}
+	if err := setSinkCertificates(query, sink); err != nil {
+		return "", err
+	}
+
+	for _, attr := range []string{kafkaTopicPrefixAttr, kafkaTopicNameAttr} {
+		if v := sink[attr].(string); v != "" {
+			query.Set(attr, v)
+		}
+	}
+
+	if sasl := sink[kafkaSASLAttr].([]interface{}); len(sasl) != 0 && sasl[0] != nil {
+		s := sasl[0].(map[string]interface{})
+		query.Set("sasl_enabled", "true")
+		query.Set("sasl_mechanism", s[saslMechanismAttr].(string))
+		query.Set("sasl_user", s[saslUserAttr].(string))
+		query.Set("sasl_password", s[saslPasswordAttr].(string))
+	}
+
+	u := url.URL{Scheme: "kafka", Host: sink[kafkaBrokerAttr].(string), RawQuery: query.Encode()}
+
+	return u.String(), nil
+}
+
+// webhookSinkURI returns the URI of a webhook sink, along with the options of
+// the changefeed it requires.
+func webhookSinkURI(sink map[string]interface{}) (string, map[string]interface{}, error) {
+	u, err := url.Parse(sink[sinkURLAttr].(string))
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := u.Query()
+	if err := setSinkCertificates(query, sink); err != nil {
+		return "", nil, err
+	}
+	if sink[webhookInsecureTLSSkipVerifyAttr].(bool) {
+		query.Set(webhookInsecureTLSSkipVerifyAttr, "true")
+	}
+	u.Scheme = "webhook-" + u.Scheme
+	u.RawQuery = query.Encode()
+
+	options := map[string]interface{}{}
+	if header := sink[webhookAuthHeaderAttr].(string); header != "" {
+		options["webhook_auth_header"] = header
+	}
+
+	return u.String(), options, nil
+}
+
+// cloudStorageSinkURI returns the URI of a cloud storage sink, the credentials
+// being only accepted by the matching cloud provider.
+func cloudStorageSinkURI(sink map[string]interface{}) (string, error) {
+	u, err := url.Parse(sink[sinkURLAttr].(string))
+	if err != nil {
+		return "", err
+	}
+
+	var params map[string]string
+	switch u.Scheme {
+	case "s3":
+		params = map[string]string{
+			cloudStorageAWSAccessKeyIDAttr:     "AWS_ACCESS_KEY_ID",
+			cloudStorageAWSSecretAccessKeyAttr: "AWS_SECRET_ACCESS_KEY",
+			cloudStorageAWSRegionAttr:          "AWS_REGION",
+		}
+	case "gs":
+		params = map[string]string{
+			cloudStorageCredentialsAttr: "CREDENTIALS",
+		}
+	case "azure", "azure-blob", "azure-storage":
+		params = map[string]string{
+			cloudStorageAzureAccountNameAttr: "AZURE_ACCOUNT_NAME",
+			cloudStorageAzureAccountKeyAttr:  "AZURE_ACCOUNT_KEY",
+		}
+	}
+
+	query := url.Values{}
+	credentials := false
+	for _, attr := range []string{
+		cloudStorageAWSAccessKeyIDAttr, cloudStorageAWSSecretAccessKeyAttr, cloudStorageAWSRegionAttr,
+		cloudStorageCredentialsAttr, cloudStorageAzureAccountNameAttr, cloudStorageAzureAccountKeyAttr,
+	} {
+		v := sink[attr].(string)
+		if v == "" {
+			continue
+		}
+
+		param, ok := params[attr]
+		if !ok {
+			return "", fmt.Errorf("%s can't be used with %s URLs", attr, u.Scheme)
+		}
+		if attr == cloudStorageCredentialsAttr {
+			v = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+		query.Set(param, v)
+		// the region doesn't authenticate
+		if attr != cloudStorageAWSRegionAttr {
+			credentials = true
+		}
+	}
+
+	auth := sink[cloudStorageAuthAttr].(string)
+	if auth == "implicit" && credentials {
+		return "", fmt.Errorf("credentials can't be set along with %s = \"implicit\"", cloudStorageAuthAttr)
+	}
+	if auth == "" && credentials && u.Scheme != "azure" && u.Scheme != "azure-blob" && u.Scheme != "azure-storage" {
+		auth = "specified"
+	}
+	if auth != "" {
+		query.Set("AUTH", auth)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// changefeedSink returns the URI of the sink of the changefeed, along with the
+// options of the changefeed it requires.
+func changefeedSink(d *schema.ResourceData) (string, map[string]interface{}, error) {
+	if kafka := d.Get(changefeedKafkaAttr).([]interface{}); len(kafka) != 0 {
+		uri, err := kafkaSinkURI(kafka[0].(map[string]interface{}))
+		return uri, nil, err
+	}
+	if webhook := d.Get(changefeedWebhookAttr).([]interface{}); len(webhook) != 0 {
+		return webhookSinkURI(webhook[0].(map[string]interface{}))
+	}
+	if cloudStorage := d.Get(changefeedCloudStorageAttr).([]interface{}); len(cloudStorage) != 0 {
+		uri, err := cloudStorageSinkURI(cloudStorage[0].(map[string]interface{}))
+		return uri, nil, err
+	}
+
+	return d.Get(changefeedSinkURIAttr).(string), nil, nil
+}
+
+// changefeedOptionsClause returns the WITH clause of the options, options
+// without value being set by their name only.
+func changefeedOptionsClause(options map[string]interface{}) string {
+	if len(options) == 0 {
+		return ""
+	}
+
+	clauses := make([]string, 0, len(options))
+	for _, name := range sortedKeys(options) {
+		if value := options[name].(string); value != "" {
+			clauses = append(clauses, name+` = `+pq.QuoteLiteral(value))
+		} else {
+			clauses = append(clauses, name)
+		}
+	}
+
+	return ` WITH ` + strings.Join(clauses, `, `)
+}
+
+// changefeedScanOptions returns the options of the initial scan and the cursor
+// of the changefeed, which can't be given by the options as well.
+func changefeedScanOptions(initialScan string, cursor string, options map[string]interface{}) (map[string]interface{}, error) {
+	scanOptions := map[string]interface{}{}
+	if initialScan != "" {
+		scanOptions["initial_scan"] = initialScan
+	}
+	if cursor != "" {
+		scanOptions["cursor"] = cursor
+	}
+
+	for _, name := range sortedKeys(options) {
+		if initialScan != "" && contains(changefeedInitialScanOptions, name) {
+			return nil, fmt.Errorf("option %s can't be set along with %s", name, changefeedInitialScanAttr)
+		}
+		if cursor != "" && name == "cursor" {
+			return nil, fmt.Errorf("option %s can't be set along with %s", name, changefeedCursorAttr)
+		}
+	}
+
+	return scanOptions, nil
+}
+
+// createChangefeedStatement returns the CREATE CHANGEFEED statement of the
+// tables.
+func createChangefeedStatement(tables string, sinkURI string, options map[string]interface{}) string {
+	return `CREATE CHANGEFEED FOR TABLE ` + tables + ` INTO ` + pq.QuoteLiteral(sinkURI) + changefeedOptionsClause(options)
+}
+
+// alterChangefeedStatement returns the ALTER CHANGEFEED statement changing the
+// tables and the options of the changefeed from o to n, tables being qualified
+// names. It returns an empty string when nothing changes.
+func alterChangefeedStatement(id string, oldTables []string, newTables []string, oldOptions map[string]interface{}, newOptions map[string]interface{}) string {
+	var commands []string
+
+	var added, dropped []string
+	for _, table := range newTables {
+		if !contains(oldTables, table) {
+			added = append(added, table)
+		}
+	}
+	for _, table := range oldTables {
+		if !contains(newTables, table) {
+			dropped = append(dropped, table)
+		}
+	}
+	if len(added) != 0 {
+		commands = append(commands, `ADD `+strings.Join(added, `, `))
+	}
+	if len(dropped) != 0 {
+		commands = append(commands, `DROP `+strings.Join(dropped, `, `))
+	}
+
+	set := map[string]interface{}{}
+	var unset []string
+	for _, name := range changedChangefeedOptions(oldOptions, newOptions) {
+		if value, ok := newOptions[name]; ok {
+			set[name] = value
+		} else {
+			unset = append(unset, name)
+		}
+	}
+	if len(set) != 0 {
+		commands = append(commands, `SET `+strings.TrimPrefix(changefeedOptionsClause(set), ` WITH `))
+	}
+	if len(unset) != 0 {
+		commands = append(commands, `UNSET `+strings.Join(unset, `, `))
+	}
+
+	if len(commands) == 0 {
+		return ""
+	}
+
+	return `ALTER CHANGEFEED ` + id + ` ` + strings.Join(commands, ` `)
+}
+
+// changefeedTableNames returns the qualified names of the tables, sorted.
+func changefeedTableNames(d *schema.ResourceData, tables interface{}) []string {
+	database, schemaName := d.Get(changefeedDatabaseAttr).(string), d.Get(changefeedSchemaAttr).(string)
+
+	names := make([]string, 0, tables.(*schema.Set).Len())
+	for _, table := range convertToString(tables.(*schema.Set).List()) {
+		names = append(names, qualifiedNames(database, schemaName, []string{table}))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func changefeedTables(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(changefeedDatabaseAttr).(string), d.Get(changefeedSchemaAttr).(string),
+		convertToString(d.Get(changefeedTablesAttr).(*schema.Set).List()))
+}
+
+func resourceChangefeedCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.Get(changefeedDatabaseAttr).(string) == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", changefeedDatabaseAttr)
+	}
+
+	sinkURI, sinkOptions, err := changefeedSink(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	userOptions := d.Get(changefeedOptionsAttr).(map[string]interface{})
+	scanOptions, err := changefeedScanOptions(d.Get(changefeedInitialScanAttr).(string), d.Get(changefeedCursorAttr).(string), userOptions)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	options := map[string]interface{}{}
+	for _, set := range []map[string]interface{}{sinkOptions, userOptions, scanOptions} {
+		for name, value := range set {
+			if _, ok := options[name]; ok {
+				return diag.Errorf("option %s is already set by the sink", name)
+			}
+			options[name] = value
+		}
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	var jobID int64
+	if err := conn.QueryRow(ctx, createChangefeedStatement(changefeedTables(d), sinkURI, options)).Scan(&jobID); err != nil {
+		return diag.Errorf("failed to create the changefeed: %v", err)
+	}
+
+	d.SetId(strconv.FormatInt(jobID, 10))
+
+	return resourceChangefeedRead(ctx, d, meta)
+}
+
+// readChangefeedStatus reads the status of the job of the changefeed, ok being
+// false when the job doesn't exist.
+func readChangefeedStatus(ctx context.Context, conn *pgx.Conn, id string) (string, bool, error) {
+	jobID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid changefeed id %q, expected the id of its job", id)
+	}
+
+	var status string
+	err = conn.QueryRow(ctx, `SELECT status FROM [SHOW CHANGEFEED JOB `+strconv.FormatInt(jobID, 10)+`]`).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return status, true, nil
+}
+
+// changefeedDone returns whether the job of a changefeed has stopped for good.
+func changefeedDone(status string) bool {
+	return status == "failed" || status == "canceled" || status == "succeeded"
+}
+
+func resourceChangefeedRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	status, ok, err := readChangefeedStatus(ctx, conn, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// a failed changefeed can't be resumed, it is created again, while a
+	// changefeed which succeeded, e.g. with initial_scan = "only", is kept
+	if !ok || status == "failed" || status == "canceled" {
+		logInfo("changefeed %s not found or %s, removing it from state", d.Id(), status)
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := d.Set(changefeedStatusAttr, status); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+// waitForChangefeedStatus waits until the job of the changefeed has the
+// status.
+func waitForChangefeedStatus(ctx context.Context, conn *pgx.Conn, id string, expected string, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		status, ok, err := readChangefeedStatus(ctx, conn, id)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if !ok || changefeedDone(status) {
+			return resource.NonRetryableError(fmt.Errorf("changefeed %s not found or %s", id, status))
+		}
+		if status != expected {
+			return resource.RetryableError(fmt.Errorf("changefeed %s is %s, expected %s", id, status, expected))
+		}
+
+		return nil
+	})
+}
+
+func resourceChangefeedUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	oldTables, newTables := d.GetChange(changefeedTablesAttr)
+	oldOptions, newOptions := d.GetChange(changefeedOptionsAttr)
+	statement := alterChangefeedStatement(d.Id(), changefeedTableNames(d, oldTables), changefeedTableNames(d, newTables),
+		oldOptions.(map[string]interface{}), newOptions.(map[string]interface{}))
+	if statement == "" {
+		return resourceChangefeedRead(ctx, d, meta)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	// the changefeed must be paused to be altered, a changefeed paused before
+	// is left paused
+	status, ok, err := readChangefeedStatus(ctx, conn, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok || changefeedDone(status) {
+		return diag.Errorf("changefeed %s not found or %s", d.Id(), status)
+	}
+	pausedHere := status != "paused"
+	if pausedHere {
+		if _, err := conn.Exec(ctx, `PAUSE JOB `+d.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := waitForChangefeedStatus(ctx, conn, d.Id(), "paused", d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.Errorf("failed to pause changefeed %s: %v", d.Id(), err)
+		}
+	}
+
+	logInfo("running %s", statement)
+	_, alterErr := conn.Exec(ctx, statement)
+
+	if pausedHere {
+		if _, err := conn.Exec(ctx, `RESUME JOB `+d.Id()); err != nil {
+			resumeDiags := diag.Errorf("failed to resume changefeed %s: %v", d.Id(), err)
+			if alterErr != nil {
+				return append(resumeDiags, diag.Errorf("failed to alter changefeed %s: %v", d.Id(), alterErr)...)
+			}
+			// the changefeed was altered all the same, its state is read
+			// again, left paused
+			return append(resumeDiags, resourceChangefeedRead(ctx, d, meta)...)
+		}
+	}
+	if alterErr != nil {
+		return diag.Errorf("failed to alter changefeed %s: %v", d.Id(), alterErr)
+	}
+
+	return resourceChangefeedRead(ctx, d, meta)
+}
+
+func resourceChangefeedDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	status, ok, err := readChangefeedStatus(ctx, conn, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if ok && !changefeedDone(status) {
+		if _, err := conn.Exec(ctx, `CANCEL JOB `+d.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
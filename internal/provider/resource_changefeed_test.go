@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccResourceChangefeed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceChangefeed("10s"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_changefeed.foo", "id", regexp.MustCompile("^[0-9]+$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_changefeed.foo", "status", "running"),
+				),
+			},
+			{
+				// altered in place
+				Config: testAccResourceChangefeed("30s"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_changefeed.foo", "options.resolved", "30s"),
+					resource.TestCheckResourceAttr(
+						"cockroach_changefeed.foo", "status", "running"),
+				),
+			},
+		},
+	})
+}
+
+func testChangefeedSink(t *testing.T, raw map[string]interface{}) (string, map[string]interface{}, error) {
+	raw[changefeedTablesAttr] = []interface{}{"orders"}
+	d := schema.TestResourceDataRaw(t, resourceChangefeed().Schema, raw)
+
+	return changefeedSink(d)
+}
+
+func TestKafkaSinkURI(t *testing.T) {
+	uri, _, err := testChangefeedSink(t, map[string]interface{}{
+		changefeedKafkaAttr: []interface{}{map[string]interface{}{
+			kafkaBrokerAttr:      "kafka.example.com:9093",
+			kafkaTopicPrefixAttr: "crdb_",
+			kafkaTLSEnabledAttr:  true,
+			sinkCACertAttr:       "ca",
+			kafkaSASLAttr: []interface{}{map[string]interface{}{
+				saslMechanismAttr: "SCRAM-SHA-512",
+				saslUserAttr:      "feed",
+				saslPasswordAttr:  "s&cret",
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "kafka://kafka.example.com:9093?ca_cert=Y2E%3D" +
+		"&sasl_enabled=true&sasl_mechanism=SCRAM-SHA-512&sasl_password=s%26cret&sasl_user=feed&tls_enabled=true&topic_prefix=crdb_"
+	if uri != expected {
+		t.Errorf("unexpected URI\n%s\nexpected\n%s", uri, expected)
+	}
+
+	_, _, err = testChangefeedSink(t, map[string]interface{}{
+		changefeedKafkaAttr: []interface{}{map[string]interface{}{
+			kafkaBrokerAttr: "kafka.example.com:9093",
+			sinkCACertAttr:  "ca",
+		}},
+	})
+	if err == nil {
+		t.Errorf("expected an error for certificates without TLS")
+	}
+}
+
+func TestWebhookSinkURI(t *testing.T) {
+	uri, options, err := testChangefeedSink(t, map[string]interface{}{
+		changefeedWebhookAttr: []interface{}{map[string]interface{}{
+			sinkURLAttr:                      "https://example.com/changes",
+			webhookAuthHeaderAttr:            "Bearer token",
+			webhookInsecureTLSSkipVerifyAttr: true,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "webhook-https://example.com/changes?insecure_tls_skip_verify=true"; uri != expected {
+		t.Errorf("unexpected URI\n%s\nexpected\n%s", uri, expected)
+	}
+	if !reflect.DeepEqual(options, map[string]interface{}{"webhook_auth_header": "Bearer token"}) {
+		t.Errorf("unexpected options %v", options)
+	}
+}
+
+func TestCloudStorageSinkURI(t *testing.T) {
+	uri, _, err := testChangefeedSink(t, map[string]interface{}{
+		changefeedCloudStorageAttr: []interface{}{map[string]interface{}{
+			sinkURLAttr:                        "s3://bucket/changefeeds",
+			cloudStorageAWSAccessKeyIDAttr:     "key",
+			cloudStorageAWSSecretAccessKeyAttr: "secret",
+			cloudStorageAWSRegionAttr:          "us-east-1",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "s3://bucket/changefeeds?AUTH=specified&AWS_ACCESS_KEY_ID=key&AWS_REGION=us-east-1&AWS_SECRET_ACCESS_KEY=secret"
+	if uri != expected {
+		t.Errorf("unexpected URI\n%s\nexpected\n%s", uri, expected)
+	}
+
+	_, _, err = testChangefeedSink(t, map[string]interface{}{
+		changefeedCloudStorageAttr: []interface{}{map[string]interface{}{
+			sinkURLAttr:                 "s3://bucket/changefeeds",
+			cloudStorageCredentialsAttr: "{}",
+		}},
+	})
+	if err == nil {
+		t.Errorf("expected an error for Google Cloud credentials with an s3 URL")
+	}
+
+	if _, errs := validateCloudStorageURL("ftp://bucket", "url"); len(errs) == 0 {
+		t.Errorf("expected an error for an unsupported scheme")
+	}
+}
+
+func TestCreateChangefeedStatement(t *testing.T) {
+	statement := createChangefeedStatement(`"foo"."public"."orders"`, "kafka://broker:9092", map[string]interface{}{
+		"updated":  "",
+		"resolved": "10s",
+	})
+
+	expected := `CREATE CHANGEFEED FOR TABLE "foo"."public"."orders" INTO 'kafka://broker:9092' WITH resolved = '10s', updated`
+	if statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+}
+
+func TestAlterChangefeedStatement(t *testing.T) {
+	statement := alterChangefeedStatement("42", []string{"a", "b"}, []string{"b", "c"},
+		map[string]interface{}{"updated": "", "resolved": "10s", "diff": ""},
+		map[string]interface{}{"updated": "", "resolved": "30s", "mvcc_timestamp": ""})
+
+	expected := `ALTER CHANGEFEED 42 ADD c DROP a SET mvcc_timestamp, resolved = '30s' UNSET diff`
+	if statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+
+	if statement := alterChangefeedStatement("42", []string{"a"}, []string{"a"}, nil, nil); statement != "" {
+		t.Errorf("expected no statement without change, got %s", statement)
+	}
+}
+
+func TestChangedChangefeedOptions(t *testing.T) {
+	changed := changedChangefeedOptions(
+		map[string]interface{}{"cursor": "1", "updated": ""},
+		map[string]interface{}{"cursor": "2", "updated": "", "initial_scan": "no"})
+
+	if !reflect.DeepEqual(changed, []string{"cursor", "initial_scan"}) {
+		t.Errorf("unexpected changed options %v", changed)
+	}
+}
+
+func TestChangefeedScanOptions(t *testing.T) {
+	options, err := changefeedScanOptions("no", "1536242855577149065.0000000000", map[string]interface{}{"updated": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"initial_scan": "no", "cursor": "1536242855577149065.0000000000"}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("unexpected options %v", options)
+	}
+
+	if _, err := changefeedScanOptions("yes", "", map[string]interface{}{"no_initial_scan": ""}); err == nil {
+		t.Errorf("expected an error for an initial scan option along with initial_scan")
+	}
+	if _, err := changefeedScanOptions("", "", map[string]interface{}{"cursor": "1"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestValidateChangefeedCursor(t *testing.T) {
+	for _, cursor := range []string{"1536242855577149065.0000000000", "1536242855577149065", "2024-05-01T10:00:00Z"} {
+		if _, errs := validateChangefeedCursor(cursor, "cursor"); len(errs) != 0 {
+			t.Errorf("unexpected errors for %q: %v", cursor, errs)
+		}
+	}
+	if _, errs := validateChangefeedCursor("yesterday", "cursor"); len(errs) == 0 {
+		t.Errorf("expected an error for an invalid cursor")
+	}
+}
+
+func testAccResourceChangefeed(resolved string) string {
+	return fmt.Sprintf(`
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "orders" {
+  database = cockroach_database.foo.name
+  name     = "orders"
+
+  column {
+    name = "id"
+    type = "INT8"
+  }
+
+  primary_key = ["id"]
+}
+
+resource "cockroach_changefeed" "foo" {
+  database = cockroach_database.foo.name
+  tables   = [cockroach_table.orders.name]
+
+  cloudstorage {
+    url = "nodelocal://1/changefeeds"
+  }
+
+  options = {
+    updated  = ""
+    resolved = %q
+  }
+}
+`, resolved)
+}
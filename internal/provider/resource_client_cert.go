@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	clientCertSecretAttr    = "secret"
+	secretNameAttr          = "name"
+	secretNamespaceAttr     = "namespace"
+	secretFormatAttr        = "format"
+	secretLabelsAttr        = "labels"
+	secretFormatTLS         = "tls"
+	secretFormatCockroach   = "cockroach"
+	secretCACertKey         = "ca.crt"
+	secretManagedByLabel    = "app.kubernetes.io/managed-by"
+	secretManagedByProvider = "terraform-provider-cockroach"
+)
+
+func resourceClientCert() *schema.Resource {
+	s := certSchema(defaultCertLifetime, true)
+	s[dbUsernameAttr] = &schema.Schema{
+		Description: "Name of the SQL user authenticated by the certificate.",
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+	}
+	s[clientCertSecretAttr] = &schema.Schema{
+		Description: "Kubernetes Secret the certificate, its private key and the CA certificate are written to, it requires the `kube_config` block of the provider.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				secretNameAttr: {
+					Description: "Name of the Secret.",
+					Type:        schema.TypeString,
+					Required:    true,
+				},
+				secretNamespaceAttr: {
+					Description: "Namespace of the Secret, the namespace of the provider is used when not set.",
+					Type:        schema.TypeString,
+					Optional:    true,
+				},
+				secretFormatAttr: {
+					Description: "Format of the Secret: `tls` for a `kubernetes.io/tls` Secret with the `ca.crt`, `tls.crt` and `tls.key` keys, as used by the CockroachDB operator and Helm chart, " +
+						"or `cockroach` for the `ca.crt`, `client.<username>.crt` and `client.<username>.key` keys expected in the certificates directory of `cockroach`.",
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      secretFormatTLS,
+					ValidateFunc: validation.StringInSlice([]string{secretFormatTLS, secretFormatCockroach}, false),
+				},
+				secretLabelsAttr: {
+					Description: "Labels of the Secret.",
+					Type:        schema.TypeMap,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+					Optional: true,
+				},
+			},
+		},
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource generating the certificate of a SQL user of a secure CockroachDB cluster, as `cockroach cert create-client` does. " +
+			"The private key is stored in the state, which must be protected accordingly, and optionally in a Kubernetes Secret.",
+
+		CreateContext: resourceClientCertCreate,
+		ReadContext:   resourceClientCertRead,
+		UpdateContext: resourceClientCertUpdate,
+		DeleteContext: resourceClientCertDelete,
+
+		Schema: s,
+	}
+}
+
+func resourceClientCertCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	template, err := certTemplate(d.Get(dbUsernameAttr).(string), certLifetime(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	if err := issueSignedCert(d, template); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if secret, ok := clientCertSecret(d); ok {
+		if err := writeSecret(ctx, meta, secret); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceClientCertRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	expected, ok := clientCertSecret(d)
+	if !ok {
+		return diag.Diagnostics{}
+	}
+
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if kubeConn.kubeConfig == nil {
+		return diag.Errorf("the kube_config block of the provider is required to read Secret %s", expected.Name)
+	}
+	expected.Namespace = kubeConn.secretNamespace(expected.Namespace)
+
+	var secret *v1.Secret
+	err = retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		secret, err = kubeConn.kubeClient.CoreV1().Secrets(expected.Namespace).Get(ctx, expected.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return diag.Errorf("failed to get Secret %s/%s: %v", expected.Namespace, expected.Name, err)
+	}
+
+	// a missing or modified Secret is written again by the next apply
+	if err != nil || !secretDataEqual(secret.Data, expected.Data) {
+		logInfo("Secret %s/%s is missing or out of date", expected.Namespace, expected.Name)
+		if err := d.Set(clientCertSecretAttr, nil); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceClientCertUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChange(clientCertSecretAttr) {
+		return diag.Diagnostics{}
+	}
+
+	old, _ := d.GetChange(clientCertSecretAttr)
+	oldSecret, hadSecret := secretFromConfig(old.([]interface{}))
+	newSecret, hasSecret := clientCertSecret(d)
+
+	if hadSecret && (!hasSecret || oldSecret.Name != newSecret.Name || oldSecret.Namespace != newSecret.Namespace) {
+		if err := deleteSecret(ctx, meta, oldSecret); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if hasSecret {
+		if err := writeSecret(ctx, meta, newSecret); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceClientCertDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if secret, ok := clientCertSecret(d); ok {
+		if err := deleteSecret(ctx, meta, secret); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+// clientCertSecret returns the Secret of the certificate of the resource, ok
+// being false when the certificate isn't written to a Secret.
+func clientCertSecret(d *schema.ResourceData) (*v1.Secret, bool) {
+	secret, ok := secretFromConfig(d.Get(clientCertSecretAttr).([]interface{}))
+	if !ok {
+		return nil, false
+	}
+
+	caCert := []byte(d.Get(certCACertPEMAttr).(string))
+	cert := []byte(d.Get(certPEMAttr).(string))
+	key := []byte(d.Get(certPrivateKeyPEMAttr).(string))
+
+	if secret.Type == v1.SecretTypeTLS {
+		secret.Data = map[string][]byte{
+			secretCACertKey:     caCert,
+			v1.TLSCertKey:       cert,
+			v1.TLSPrivateKeyKey: key,
+		}
+	} else {
+		username := d.Get(dbUsernameAttr).(string)
+		secret.Data = map[string][]byte{
+			secretCACertKey:                        caCert,
+			fmt.Sprintf("client.%s.crt", username): cert,
+			fmt.Sprintf("client.%s.key", username): key,
+		}
+	}
+
+	return secret, true
+}
+
+// secretFromConfig returns the Secret configured by the secret block, without
+// its data.
+func secretFromConfig(config []interface{}) (*v1.Secret, bool) {
+	if len(config) == 0 || config[0] == nil {
+		return nil, false
+	}
+	c := config[0].(map[string]interface{})
+
+	// the namespace of the kube config when not set, see secretNamespace
+	namespace := c[secretNamespaceAttr].(string)
+
+	labels := map[string]string{secretManagedByLabel: secretManagedByProvider}
+	for k, v := range c[secretLabelsAttr].(map[string]interface{}) {
+		labels[k] = v.(string)
+	}
+
+	secretType := v1.SecretTypeTLS
+	if c[secretFormatAttr].(string) == secretFormatCockroach {
+		secretType = v1.SecretTypeOpaque
+	}
+
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c[secretNameAttr].(string),
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Type: secretType,
+	}, true
+}
+
+// secretDataEqual returns whether the Secret holds the expected data.
+func secretDataEqual(data map[string][]byte, expected map[string][]byte) bool {
+	if len(data) != len(expected) {
+		return false
+	}
+	for k, v := range expected {
+		if string(data[k]) != string(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeSecret creates the Secret, or replaces the one managed by the provider
+// with the same name. Secrets not created by the provider are never
+// overwritten.
+func writeSecret(ctx context.Context, meta interface{}, secret *v1.Secret) error {
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return err
+	}
+	if kubeConn.kubeConfig == nil {
+		return fmt.Errorf("the kube_config block of the provider is required to write Secret %s", secret.Name)
+	}
+	secret.Namespace = kubeConn.secretNamespace(secret.Namespace)
+	secrets := kubeConn.kubeClient.CoreV1().Secrets(secret.Namespace)
+
+	var existing *v1.Secret
+	err = retryKubeAPI(kubeConn.maxRetries, func() (err error) {
+		existing, err = secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	if err != nil {
+		logInfo("creating Secret %s/%s", secret.Namespace, secret.Name)
+		err = retryKubeAPI(kubeConn.maxRetries, func() error {
+			_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+
+		return nil
+	}
+
+	if existing.Labels[secretManagedByLabel] != secretManagedByProvider {
+		return fmt.Errorf("Secret %s/%s already exists and isn't managed by the provider", secret.Namespace, secret.Name)
+	}
+	if existing.Type != secret.Type {
+		return fmt.Errorf("Secret %s/%s already exists with type %s", secret.Namespace, secret.Name, existing.Type)
+	}
+
+	logInfo("updating Secret %s/%s", secret.Namespace, secret.Name)
+	secret.ResourceVersion = existing.ResourceVersion
+	err = retryKubeAPI(kubeConn.maxRetries, func() error {
+		_, err := secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	return nil
+}
+
+// deleteSecret deletes the Secret, if it exists.
+func deleteSecret(ctx context.Context, meta interface{}, secret *v1.Secret) error {
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return err
+	}
+	if kubeConn.kubeConfig == nil {
+		return fmt.Errorf("the kube_config block of the provider is required to delete Secret %s", secret.Name)
+	}
+	secret.Namespace = kubeConn.secretNamespace(secret.Namespace)
+
+	logInfo("deleting Secret %s/%s", secret.Namespace, secret.Name)
+	err = retryKubeAPI(kubeConn.maxRetries, func() error {
+		return kubeConn.kubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	return nil
+}
+
+// secretNamespace returns the namespace of the Secret, the namespace of the
+// kube config when not set.
+func (k kubeConn) secretNamespace(namespace string) string {
+	if namespace == "" {
+		return k.nameSpace
+	}
+	return namespace
+}
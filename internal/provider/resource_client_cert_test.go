@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestClientCertSecret(t *testing.T) {
+	kubeConn := kubeConn{nameSpace: "cockroachdb"}
+
+	cases := []struct {
+		format       string
+		expectedType v1.SecretType
+		expectedKeys []string
+	}{
+		{secretFormatTLS, v1.SecretTypeTLS, []string{"ca.crt", "tls.crt", "tls.key"}},
+		{secretFormatCockroach, v1.SecretTypeOpaque, []string{"ca.crt", "client.app.crt", "client.app.key"}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceClientCert().Schema, map[string]interface{}{
+			dbUsernameAttr:       "app",
+			certCACertPEMAttr:    "ca",
+			certCAPrivateKeyAttr: "ca key",
+			clientCertSecretAttr: []interface{}{
+				map[string]interface{}{
+					secretNameAttr:   "app-client-certs",
+					secretFormatAttr: c.format,
+					secretLabelsAttr: map[string]interface{}{"app": "app"},
+				},
+			},
+		})
+
+		secret, ok := clientCertSecret(d)
+		if !ok {
+			t.Fatalf("expected a Secret for format %s", c.format)
+		}
+		if secret.Namespace != "" || kubeConn.secretNamespace(secret.Namespace) != "cockroachdb" || secret.Name != "app-client-certs" {
+			t.Errorf("unexpected Secret %s/%s", secret.Namespace, secret.Name)
+		}
+		if secret.Type != c.expectedType {
+			t.Errorf("format %s: expected type %s, got %s", c.format, c.expectedType, secret.Type)
+		}
+		if secret.Labels["app"] != "app" || secret.Labels[secretManagedByLabel] != secretManagedByProvider {
+			t.Errorf("unexpected labels %v", secret.Labels)
+		}
+		for _, k := range c.expectedKeys {
+			if _, ok := secret.Data[k]; !ok {
+				t.Errorf("format %s: missing key %s", c.format, k)
+			}
+		}
+		if string(secret.Data["ca.crt"]) != "ca" {
+			t.Errorf("unexpected CA certificate %q", secret.Data["ca.crt"])
+		}
+	}
+}
+
+func TestSecretDataEqual(t *testing.T) {
+	expected := map[string][]byte{"tls.crt": []byte("cert")}
+
+	if !secretDataEqual(map[string][]byte{"tls.crt": []byte("cert")}, expected) {
+		t.Errorf("expected equal data")
+	}
+	if secretDataEqual(map[string][]byte{"tls.crt": []byte("other")}, expected) {
+		t.Errorf("expected different data")
+	}
+	if secretDataEqual(map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}, expected) {
+		t.Errorf("expected different keys")
+	}
+}
@@ -0,0 +1,428 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	clusterSettingsSettingsAttr         = "settings"
+	clusterSettingsResetUnmanagedAttr   = "reset_unmanaged"
+	clusterSettingsIgnoreUnmanagedAttr  = "ignore_unmanaged"
+	clusterSettingsUnmanagedChangedAttr = "unmanaged_changed"
+	clusterSettingsDetectChangesAttr    = "detect_unmanaged_changes"
+
+	clusterSettingsID = "cluster_settings"
+)
+
+// protectedClusterSettings are never reset, as they describe the cluster itself
+// rather than its configuration.
+var protectedClusterSettings = []string{
+	"cluster.organization",
+	"cluster.secret",
+	"enterprise.license",
+	"version",
+}
+
+var clusterSettingNameRegexp = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+func resourceClusterSettings() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to manage a set of cluster settings of a CockroachDB cluster, optionally resetting the settings changed outside of it.",
+
+		CreateContext: resourceClusterSettingsCreate,
+		ReadContext:   resourceClusterSettingsRead,
+		UpdateContext: resourceClusterSettingsUpdate,
+		DeleteContext: resourceClusterSettingsDelete,
+		CustomizeDiff: resourceClusterSettingsCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			clusterSettingsSettingsAttr: {
+				Description: "Cluster settings to set, keyed by name.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required:         true,
+				ValidateDiagFunc: validation.MapKeyMatch(clusterSettingNameRegexp, "invalid cluster setting name"),
+				DiffSuppressFunc: suppressEquivalentSettingValue,
+			},
+			clusterSettingsResetUnmanagedAttr: {
+				Description: "True to reset to their default value the cluster settings which are not in `settings`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			clusterSettingsIgnoreUnmanagedAttr: {
+				Description: "Cluster settings never reset by `reset_unmanaged`.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+			clusterSettingsDetectChangesAttr: {
+				Description: "True to warn when refreshing if the value of a setting of `settings` was changed outside of Terraform since the last apply. The value read is planned to be changed back either way.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			clusterSettingsUnmanagedChangedAttr: {
+				Description: "Cluster settings not in `settings` whose value differs from the default, only tracked with `reset_unmanaged`.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26266), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "26266",
+			},
+		},
+	}
+}
+
+func resourceClusterSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	settings := d.Get(clusterSettingsSettingsAttr).(map[string]interface{})
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	for _, name := range sortedKeys(settings) {
+		if err := setClusterSetting(ctx, conn, name, settings[name].(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.Get(clusterSettingsResetUnmanagedAttr).(bool) {
+		if err := resetUnmanagedClusterSettings(ctx, conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(clusterSettingsID)
+
+	return resourceClusterSettingsReadConn(ctx, conn, d)
+}
+
+func resourceClusterSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the values in the state are the ones of the last apply, to warn about
+	// the changes made since
+	old := map[string]interface{}{}
+	for name, value := range d.Get(clusterSettingsSettingsAttr).(map[string]interface{}) {
+		old[name] = value
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	diags = resourceClusterSettingsReadConn(ctx, conn, d)
+	if diags.HasError() || !d.Get(clusterSettingsDetectChangesAttr).(bool) {
+		return diags
+	}
+
+	return append(diags, clusterSettingsDriftWarnings(old, d.Get(clusterSettingsSettingsAttr).(map[string]interface{}))...)
+}
+
+// clusterSettingsDriftWarnings returns a warning for every setting whose value
+// read differs from the one in the state.
+func clusterSettingsDriftWarnings(state map[string]interface{}, read map[string]interface{}) diag.Diagnostics {
+	warnings := diag.Diagnostics{}
+	for _, name := range sortedKeys(state) {
+		value, ok := read[name]
+		if !ok || settingValuesEqual(state[name].(string), value.(string)) {
+			continue
+		}
+		warnings = append(warnings, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Cluster setting %s changed outside of Terraform", name),
+			Detail:   fmt.Sprintf("The value of cluster setting %s is %q instead of %q since the last apply, it is planned to be changed back.", name, value, state[name]),
+		})
+	}
+
+	return warnings
+}
+
+func resourceClusterSettingsReadConn(ctx context.Context, conn *pgx.Conn, d *schema.ResourceData) diag.Diagnostics {
+	current, err := readClusterSettings(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	settings := d.Get(clusterSettingsSettingsAttr).(map[string]interface{})
+	for name, value := range settings {
+		setting, ok := current[name]
+		if !ok {
+			return diag.Errorf("unknown cluster setting %s", name)
+		}
+		if !settingValuesEqual(value.(string), setting.value) {
+			settings[name] = setting.value
+		}
+	}
+
+	if err := d.Set(clusterSettingsSettingsAttr, settings); err != nil {
+		return diag.FromErr(err)
+	}
+
+	changed := map[string]interface{}{}
+	if d.Get(clusterSettingsResetUnmanagedAttr).(bool) {
+		ignored := convertToString(d.Get(clusterSettingsIgnoreUnmanagedAttr).(*schema.Set).List())
+		for name, value := range unmanagedClusterSettings(current, settings, ignored) {
+			changed[name] = value
+		}
+	}
+
+	if err := d.Set(clusterSettingsUnmanagedChangedAttr, changed); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceClusterSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	if d.HasChange(clusterSettingsSettingsAttr) {
+		oraw, nraw := d.GetChange(clusterSettingsSettingsAttr)
+		o := oraw.(map[string]interface{})
+		n := nraw.(map[string]interface{})
+
+		// settings no longer managed go back to their default value
+		for _, name := range sortedKeys(o) {
+			if _, ok := n[name]; !ok {
+				if err := resetClusterSetting(ctx, conn, name); err != nil {
+					return diag.FromErr(err)
+				}
+			}
+		}
+
+		for _, name := range sortedKeys(n) {
+			if old, ok := o[name]; ok && settingValuesEqual(old.(string), n[name].(string)) {
+				continue
+			}
+			if err := setClusterSetting(ctx, conn, name, n[name].(string)); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
+	if d.Get(clusterSettingsResetUnmanagedAttr).(bool) {
+		if err := resetUnmanagedClusterSettings(ctx, conn, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceClusterSettingsReadConn(ctx, conn, d)
+}
+
+func resourceClusterSettingsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	settings := d.Get(clusterSettingsSettingsAttr).(map[string]interface{})
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	for _, name := range sortedKeys(settings) {
+		if err := resetClusterSetting(ctx, conn, name); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+// resourceClusterSettingsCustomizeDiff plans an update when unmanaged settings
+// have to be reset, so the drift shows up in the plan.
+func resourceClusterSettingsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.Get(clusterSettingsResetUnmanagedAttr).(bool) {
+		return nil
+	}
+
+	if len(d.Get(clusterSettingsUnmanagedChangedAttr).(map[string]interface{})) == 0 {
+		return nil
+	}
+
+	return d.SetNew(clusterSettingsUnmanagedChangedAttr, map[string]interface{}{})
+}
+
+// clusterSetting is a row of crdb_internal.cluster_settings.
+type clusterSetting struct {
+	value        string
+	defaultValue string
+}
+
+func readClusterSettings(ctx context.Context, conn *pgx.Conn) (map[string]clusterSetting, error) {
+	rows, err := conn.Query(ctx, `SELECT variable, value, default_value FROM crdb_internal.cluster_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := map[string]clusterSetting{}
+	for rows.Next() {
+		var (
+			name    string
+			setting clusterSetting
+		)
+		if err := rows.Scan(&name, &setting.value, &setting.defaultValue); err != nil {
+			return nil, err
+		}
+		settings[name] = setting
+	}
+
+	return settings, rows.Err()
+}
+
+func resetUnmanagedClusterSettings(ctx context.Context, conn *pgx.Conn, d *schema.ResourceData) error {
+	current, err := readClusterSettings(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	settings := d.Get(clusterSettingsSettingsAttr).(map[string]interface{})
+	ignored := convertToString(d.Get(clusterSettingsIgnoreUnmanagedAttr).(*schema.Set).List())
+
+	for _, name := range sortedKeys(unmanagedClusterSettings(current, settings, ignored)) {
+		logInfo("resetting unmanaged cluster setting %s", name)
+		if err := resetClusterSetting(ctx, conn, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unmanagedClusterSettings returns the current value of the settings which are
+// neither managed nor ignored and differ from their default value.
+func unmanagedClusterSettings(current map[string]clusterSetting, managed map[string]interface{}, ignored []string) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for name, setting := range current {
+		if _, ok := managed[name]; ok || contains(ignored, name) || contains(protectedClusterSettings, name) {
+			continue
+		}
+		if !settingValuesEqual(setting.value, setting.defaultValue) {
+			changed[name] = setting.value
+		}
+	}
+
+	return changed
+}
+
+// setClusterSetting changes one setting. SET CLUSTER SETTING can't run in an
+// explicit transaction, so the settings are applied one statement at a time.
+func setClusterSetting(ctx context.Context, conn *pgx.Conn, name string, value string) error {
+	if !clusterSettingNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid cluster setting name %q", name)
+	}
+
+	_, err := conn.Exec(ctx, `SET CLUSTER SETTING `+name+` = `+pq.QuoteLiteral(value))
+	return err
+}
+
+func resetClusterSetting(ctx context.Context, conn *pgx.Conn, name string) error {
+	if !clusterSettingNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid cluster setting name %q", name)
+	}
+
+	_, err := conn.Exec(ctx, `RESET CLUSTER SETTING `+name)
+	return err
+}
+
+func suppressEquivalentSettingValue(k, old, new string, d *schema.ResourceData) bool {
+	// the map length is diffed as well and must not be suppressed
+	if strings.HasSuffix(k, ".%") {
+		return false
+	}
+
+	return old != "" && new != "" && settingValuesEqual(old, new)
+}
+
+// settingValuesEqual compares setting values the way CockroachDB interprets
+// them, e.g. 1h and 01:00:00 are the same duration and TRUE is true.
+func settingValuesEqual(a string, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == b || strings.EqualFold(a, b) {
+		return true
+	}
+
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return x == y
+		}
+	}
+
+	if x, ok := parseSettingDuration(a); ok {
+		if y, ok := parseSettingDuration(b); ok {
+			return x == y
+		}
+	}
+
+	return false
+}
+
+// parseSettingDuration parses both Go durations (1h30m) and the interval
+// format used by SHOW CLUSTER SETTING (01:30:00).
+func parseSettingDuration(s string) (time.Duration, bool) {
+	if duration, err := time.ParseDuration(s); err == nil {
+		return duration, true
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), true
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceClusterSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceClusterSettings,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_cluster_settings.foo", "settings.sql.defaults.idle_in_session_timeout", "1h"),
+					resource.TestCheckResourceAttr(
+						"cockroach_cluster_settings.foo", "unmanaged_changed.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestSettingValuesEqual(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{"true", "TRUE", true},
+		{"1h", "01:00:00", true},
+		{"90s", "00:01:30", true},
+		{"1h", "00:30:00", false},
+		{"0.5", "0.50", true},
+		{"64 MiB", "64 MiB", true},
+		{"foo", "bar", false},
+	}
+
+	for _, c := range cases {
+		if actual := settingValuesEqual(c.a, c.b); actual != c.expected {
+			t.Errorf("settingValuesEqual(%q, %q) = %v, expected %v", c.a, c.b, actual, c.expected)
+		}
+	}
+}
+
+func TestUnmanagedClusterSettings(t *testing.T) {
+	current := map[string]clusterSetting{
+		"kv.rangefeed.enabled":      {value: "true", defaultValue: "false"},
+		"sql.stats.enabled":         {value: "true", defaultValue: "true"},
+		"server.shutdown.drain":     {value: "30s", defaultValue: "00:00:10"},
+		"version":                   {value: "23.2", defaultValue: "22.2"},
+		"diagnostics.reporting.foo": {value: "false", defaultValue: "true"},
+	}
+	managed := map[string]interface{}{"kv.rangefeed.enabled": "true"}
+
+	changed := unmanagedClusterSettings(current, managed, []string{"diagnostics.reporting.foo"})
+	if len(changed) != 1 || changed["server.shutdown.drain"] != "30s" {
+		t.Errorf("unexpected unmanaged settings %v", changed)
+	}
+}
+
+func TestClusterSettingsDriftWarnings(t *testing.T) {
+	state := map[string]interface{}{
+		"kv.rangefeed.enabled":                 "true",
+		"sql.defaults.idle_in_session_timeout": "1h",
+		"server.shutdown.drain":                "30s",
+	}
+	read := map[string]interface{}{
+		"kv.rangefeed.enabled":                 "false",
+		"sql.defaults.idle_in_session_timeout": "01:00:00",
+		"server.shutdown.drain":                "00:01:00",
+	}
+
+	warnings := clusterSettingsDriftWarnings(state, read)
+	if len(warnings) != 2 {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+	for i, name := range []string{"kv.rangefeed.enabled", "server.shutdown.drain"} {
+		if warnings[i].Severity != diag.Warning || !strings.Contains(warnings[i].Summary, name) {
+			t.Errorf("unexpected warning %v for %s", warnings[i], name)
+		}
+	}
+
+	if warnings := clusterSettingsDriftWarnings(state, state); len(warnings) != 0 {
+		t.Errorf("unexpected warnings without changes %v", warnings)
+	}
+}
+
+const testAccResourceClusterSettings = `
+resource "cockroach_cluster_settings" "foo" {
+  settings = {
+    "sql.defaults.idle_in_session_timeout" = "1h"
+  }
+  reset_unmanaged  = true
+  ignore_unmanaged = ["cluster.organization"]
+
+  detect_unmanaged_changes = true
+}
+`
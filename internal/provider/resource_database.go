@@ -3,7 +3,6 @@ package provider
 import (
 	"strconv"
 
-	"github.com/jackc/pgx/v4"
 	"github.com/lib/pq"
 
 	"context"
@@ -25,7 +24,8 @@ const (
 func resourceDatabase() *schema.Resource {
 	return &schema.Resource{
 		// This description is used by the documentation generator and the language server.
-		Description: "Resource used to create a new database in a CockroachDB cluster.",
+		Description: "Resource used to create a new database in a CockroachDB cluster. " +
+			"Its id isn't scoped to the cluster, a database of the same name being adopted when the configuration is applied to another cluster, e.g. restored from a backup.",
 
 		CreateContext: resourceDatabaseCreate,
 		ReadContext:   resourceDatabaseRead,
@@ -36,16 +36,20 @@ func resourceDatabase() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
+			dependentsAttr:   dependentsSchema(),
+			descriptorIDAttr: descriptorIDSchema(),
+			createdAtAttr:    createdAtSchema(),
 			dbNameAttr: {
 				Description: "Name of the database.",
 				Type:        schema.TypeString,
 				Required:    true,
 			},
 			dbOwnerAttr: {
-				Description: "Owner of the database.",
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     "",
+				Description:      "Owner of the database, lowercased by CockroachDB.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "",
+				DiffSuppressFunc: suppressRoleNameCase,
 			},
 			dbEncodingAttr: {
 				Description: "Encoding to set to the database. (Optional argument, do not specify if not required)",
@@ -67,6 +71,7 @@ func resourceDatabase() *schema.Resource {
 				},
 				Optional: true,
 			},
+			argTolerateExistingState: tolerateExistingStateSchema("database"),
 			argLocalPort: {
 				Description: "Local port to be used for port-forward. (default is 26258), use different port to avoid same port opening.",
 				Type:        schema.TypeString,
@@ -78,21 +83,11 @@ func resourceDatabase() *schema.Resource {
 }
 
 func resourceDatabaseCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	cockroachClient := meta.(*cockroachClient)
-
-	local_port := d.Get(argLocalPort).(string)
 	name := d.Get(dbNameAttr).(string)
 	owner := d.Get(dbOwnerAttr).(string)
 	encoding := d.Get(dbEncodingAttr).(string)
 	primary_region := d.Get(dbPrimaryRegionAttr).(string)
 	regions := convertToString(d.Get(dbRegionsAttr).([]interface{}))
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
 
 	set_encoding := ""
 	set_primary_region := ""
@@ -114,38 +109,38 @@ func resourceDatabaseCreate(ctx context.Context, d *schema.ResourceData, meta in
 		set_regions = "REGIONS " + pq.QuoteIdentifier(strings.Join(regions, ""))
 	}
 
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
-
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+	defer closeConn()
+
+	statements := []string{
+		`CREATE DATABASE ` +
+			pq.QuoteIdentifier(name) +
+			` ` +
+			set_encoding +
+			` ` +
+			set_primary_region +
+			` ` +
+			set_regions,
 	}
 
-	_, err = conn.Exec(ctx,
-		`CREATE DATABASE `+
-			pq.QuoteIdentifier(name)+
-			` `+
-			set_encoding+
-			` `+
-			set_primary_region+
-			` `+
-			set_regions,
-	)
-	if err != nil {
-		return diag.FromErr(err)
+	if owner != "" {
+		statements = append(statements,
+			`ALTER DATABASE `+
+				pq.QuoteIdentifier(name)+
+				` OWNER TO `+
+				pq.QuoteIdentifier(owner),
+		)
 	}
 
-	_, err = conn.Exec(ctx,
-		`ALTER DATABASE `+
-			pq.QuoteIdentifier(name)+
-			` OWNER TO `+
-			pq.QuoteIdentifier(owner),
-	)
+	// the database is only created if its owner can be set as well
+	err := execInTransaction(ctx, conn, statements...)
+	// the owner of a tolerated existing database is set all the same
+	if toleratedExistingState(d, err, alreadyExistsCodes) {
+		err = execInTransaction(ctx, conn, statements[1:]...)
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -161,42 +156,27 @@ func resourceDatabaseCreate(ctx context.Context, d *schema.ResourceData, meta in
 	d.SetId(strconv.Itoa(id))
 	d.Set(dbNameAttr, name)
 	d.Set(dbOwnerAttr, owner)
+	d.Set(descriptorIDAttr, id)
+	if err := setCreatedAt(d); err != nil {
+		return diag.FromErr(err)
+	}
 	d.Set(dbEncodingAttr, encoding)
 	d.Set(dbPrimaryRegionAttr, primary_region)
 	d.Set(dbRegionsAttr, regions)
 
-	close(stopCh)
-
 	return diag.Diagnostics{}
 }
 
 func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	cockroachClient := meta.(*cockroachClient)
-
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
 	name := d.Get(dbNameAttr).(string)
 
-	rows, err := conn.Query(ctx, "SELECT name AS database_name, owner, primary_region, regions, survival_goal FROM crdb_internal.databases")
+	rows, err := conn.Query(ctx, "SELECT id, name AS database_name, owner, primary_region, regions, survival_goal FROM crdb_internal.databases")
 	if err != nil {
 		// handle this error better than this
 		return diag.FromErr(err)
@@ -204,9 +184,10 @@ func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta inte
 	found := false
 	defer rows.Close()
 
-	// database_name |     owner     | primary_region | regions | survival_goal
+	// id | database_name |     owner     | primary_region | regions | survival_goal
 	for rows.Next() {
 		var (
+			id               int64
 			database_name    string
 			owner            string
 			primary_region   string
@@ -214,7 +195,7 @@ func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta inte
 			regions          []string
 			survival_goal    sql.NullString
 		)
-		err = rows.Scan(&database_name, &owner, &primary_region_n, &regions, &survival_goal)
+		err = rows.Scan(&id, &database_name, &owner, &primary_region_n, &regions, &survival_goal)
 		if err != nil {
 			// handle this error
 			return diag.FromErr(err)
@@ -234,6 +215,10 @@ func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta inte
 				return diag.FromErr(err)
 			}
 
+			if err := d.Set(descriptorIDAttr, id); err != nil {
+				return diag.FromErr(err)
+			}
+
 			if err := d.Set(dbPrimaryRegionAttr, primary_region); err != nil {
 				return diag.FromErr(err)
 			}
@@ -251,8 +236,18 @@ func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta inte
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	rows.Close()
+
+	if found {
+		dependents, err := databaseDependents(ctx, conn, name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set(dependentsAttr, dependents); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
-	close(stopCh)
 	if found == false {
 		return diag.Errorf("Cannot find database with name: " + name)
 	}
@@ -263,28 +258,22 @@ func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta inte
 func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	cockroachClient := meta.(*cockroachClient)
 
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
 	d.Partial(true)
 
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
-	}
+	// grants on the database are applied in parallel, under both names when
+	// it is renamed
+	oname, nname := d.GetChange(dbNameAttr)
+	unlock := cockroachClient.locks.lock(databaseLockKey(oname.(string)), databaseLockKey(nname.(string)))
+	defer unlock()
+	defer cockroachClient.cache.invalidate()
+
+	var statements []string
 
 	if d.HasChange(dbNameAttr) {
 		oraw, nraw := d.GetChange(dbNameAttr)
@@ -293,16 +282,12 @@ func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		if n == "" {
 			return diag.Errorf("database name can't be an empty string")
 		}
-		_, err := conn.Exec(ctx,
+		statements = append(statements,
 			`ALTER DATABASE `+
 				pq.QuoteIdentifier(o)+
 				` RENAME TO `+
 				pq.QuoteIdentifier(n),
 		)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		d.Set(dbNameAttr, n)
 	}
 
 	if d.HasChange(dbOwnerAttr) {
@@ -311,24 +296,29 @@ func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		// o := oraw.(string)
 		n := nraw.(string)
 
-		_, err = conn.Exec(ctx,
+		statements = append(statements,
 			`ALTER DATABASE `+
 				pq.QuoteIdentifier(name)+
 				` OWNER TO `+
 				pq.QuoteIdentifier(n),
 		)
-		if err != nil {
+	}
+
+	if len(statements) != 0 {
+		if err := execInTransaction(ctx, conn, statements...); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
+	// region changes run as their own schema change jobs, which CockroachDB
+	// doesn't allow to be mixed with other schema changes in a transaction
 	if d.HasChange(dbPrimaryRegionAttr) {
 		name := d.Get(dbNameAttr).(string)
 		_, nraw := d.GetChange(dbPrimaryRegionAttr)
 		// o := oraw.(string)
 		n := nraw.(string)
 
-		_, err = conn.Exec(ctx,
+		_, err := conn.Exec(ctx,
 			`ALTER DATABASE `+
 				pq.QuoteIdentifier(name)+
 				` SET PRIMARY REGION `+
@@ -348,7 +338,7 @@ func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		// drop unused regions
 		for _, region := range o {
 			if !contains(n, region) {
-				_, err = conn.Exec(ctx,
+				_, err := conn.Exec(ctx,
 					`ALTER DATABASE `+
 						pq.QuoteIdentifier(name)+
 						` DROP REGION `+
@@ -363,7 +353,7 @@ func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		// create new regions
 		for _, region := range n {
 			if !contains(o, region) {
-				_, err = conn.Exec(ctx,
+				_, err := conn.Exec(ctx,
 					`ALTER DATABASE `+
 						pq.QuoteIdentifier(name)+
 						` ADD REGION `+
@@ -377,85 +367,59 @@ func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta in
 	}
 
 	d.Partial(false)
-	close(stopCh)
 	return diag.Diagnostics{}
 }
 
 func resourceDatabaseDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	cockroachClient := meta.(*cockroachClient)
 
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
-	}
 	name := d.Get(dbNameAttr).(string)
 
 	if name == "" {
 		return diag.Errorf("database name can't be an empty string")
 	}
 
-	_, err = conn.Exec(ctx, `DROP DATABASE `+pq.QuoteIdentifier(name))
+	unlock := cockroachClient.locks.lock(databaseLockKey(name))
+	defer unlock()
+	defer cockroachClient.cache.invalidate()
+
+	dependents, err := databaseDependents(ctx, conn, name)
 	if err != nil {
+		logError("failed to read the dependents of database %s: %v", name, err)
+	}
+
+	_, err = conn.Exec(ctx, `DROP DATABASE `+pq.QuoteIdentifier(name))
+	if err != nil && !toleratedExistingState(d, err, doesNotExistCodes) {
 		return diag.FromErr(err)
 	}
 
 	d.SetId("")
 	d.Set(dbNameAttr, "")
 
-	close(stopCh)
-	return diag.Diagnostics{}
+	return append(diag.Diagnostics{}, dependentsWarning("database "+name, dependents)...)
 }
 
 func resourceDatabaseImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	cockroachClient := meta.(*cockroachClient)
-
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
 	// id is the name of the database from the cockroachdb
 	name := d.Id()
 
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		logError("failed connect to cockroachdb, error: %v", err)
-		return nil, err
-	}
-
-	if err := conn.Ping(ctx); err != nil {
-		logError("failed ping cockroachdb, error: %v", err)
-		return nil, err
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return nil, diagnosticsError(diags)
 	}
+	defer closeConn()
 
 	var (
 		id    int
 		owner string
 	)
-	err = conn.QueryRow(ctx, `SELECT id, owner FROM crdb_internal.databases WHERE name = $1`, name).Scan(
+	err := conn.QueryRow(ctx, `SELECT id, owner FROM crdb_internal.databases WHERE name = $1`, name).Scan(
 		&id,
 		&owner,
 	)
@@ -476,7 +440,5 @@ func resourceDatabaseImporter(ctx context.Context, d *schema.ResourceData, meta
 		return nil, err
 	}
 
-	close(stopCh)
-
 	return []*schema.ResourceData{d}, nil
 }
@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"fmt"
+	"net/url"
+	"reflect"
 	"strconv"
 
-	"github.com/jackc/pgx/v4"
 	"github.com/lib/pq"
 
 	"context"
@@ -20,6 +22,12 @@ const (
 	backupOptionsAttr       = "backup_options"
 	backupReccuringAttr     = "backup_recurring"
 	backupFullBackupAttr    = "backup_full"
+
+	backupLocalityPathsAttr                = "locality_backup_paths"
+	backupIncrementalLocationAttr          = "incremental_location"
+	backupLocalityIncrementalLocationsAttr = "locality_incremental_locations"
+	backupKMSURIsAttr                      = "kms_uris"
+	backupEncryptionPassphraseAttr         = "encryption_passphrase"
 )
 
 func resourceDatabaseBackup() *schema.Resource {
@@ -31,6 +39,9 @@ func resourceDatabaseBackup() *schema.Resource {
 		ReadContext:   resourceDatabaseBackupRead,
 		UpdateContext: resourceDatabaseBackupUpdate,
 		DeleteContext: resourceDatabaseBackupDelete,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			return setDefaultDatabase(d, meta, schedulerDbNameAttr)
+		},
 		Schema: map[string]*schema.Schema{
 			schedulerNameAttr: {
 				Description: "Name of the scheduler.",
@@ -39,9 +50,10 @@ func resourceDatabaseBackup() *schema.Resource {
 				ForceNew:    true,
 			},
 			schedulerDbNameAttr: {
-				Description: "Name of the database where to run the backup.",
+				Description: "Name of the database where to run the backup, the default database of the provider is used when not set.",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 			schedulerBackupPathAttr: {
@@ -73,6 +85,48 @@ func resourceDatabaseBackup() *schema.Resource {
 				ForceNew: true,
 				Optional: true,
 			},
+			backupLocalityPathsAttr: {
+				Description: "Paths keyed by locality, e.g. `region=us-east1`, making a locality-aware backup where each node writes to the path of its locality, `backup_path` being the default one.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ForceNew: true,
+				Optional: true,
+			},
+			backupIncrementalLocationAttr: {
+				Description: "The path where to save the incremental backups, which are saved into the latest full backup of `backup_path` when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			backupLocalityIncrementalLocationsAttr: {
+				Description: "Paths of the incremental backups keyed by locality, with the same localities as `locality_backup_paths`.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ForceNew: true,
+				Optional: true,
+			},
+			backupKMSURIsAttr: {
+				Description: "URIs of the KMS keys used to encrypt the backups, e.g. `aws:///key-id?AUTH=implicit&REGION=us-east-1`, the first one being used to encrypt them.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ForceNew:      true,
+				Optional:      true,
+				ConflictsWith: []string{backupEncryptionPassphraseAttr},
+			},
+			backupEncryptionPassphraseAttr: {
+				Description:   "Passphrase used to encrypt the backups.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ForceNew:      true,
+				ConflictsWith: []string{backupKMSURIsAttr},
+			},
 			argLocalPort: {
 				Description: "Local port to be used for port-forward. (default is 26258), use different port to avoid same port opening.",
 				Type:        schema.TypeString,
@@ -83,26 +137,99 @@ func resourceDatabaseBackup() *schema.Resource {
 	}
 }
 
-func resourceDatabaseBackupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	cockroachClient := meta.(*cockroachClient)
+// backupDestination returns the destination of a backup, the paths keyed by
+// locality making a locality-aware backup along with the default path.
+func backupDestination(path string, localityPaths map[string]interface{}) (string, error) {
+	if len(localityPaths) == 0 {
+		return pq.QuoteLiteral(path), nil
+	}
+	if _, ok := localityPaths["default"]; ok {
+		return "", fmt.Errorf("the path of the default locality is the one of %s", schedulerBackupPathAttr)
+	}
 
-	local_port := d.Get(argLocalPort).(string)
-	scheduler_name := d.Get(schedulerNameAttr).(string)
-	db_name := d.Get(schedulerDbNameAttr).(string)
-	scheduler_backup_path := d.Get(schedulerBackupPathAttr).(string)
-	scheduler_full_backup := d.Get(backupFullBackupAttr).(string)
-	scheduler_backup_reccuring := d.Get(backupReccuringAttr).(string)
-	scheduler_backup_options := convertToString(d.Get(backupOptionsAttr).([]interface{}))
+	uris := make([]string, 0, len(localityPaths)+1)
+	for _, locality := range append([]string{"default"}, sortedKeys(localityPaths)...) {
+		uri := path
+		if locality != "default" {
+			uri = localityPaths[locality].(string)
+		}
+
+		u, err := url.Parse(uri)
+		if err != nil {
+			return "", fmt.Errorf("invalid backup path %q: %v", uri, err)
+		}
+		query := u.Query()
+		query.Set("COCKROACH_LOCALITY", locality)
+		u.RawQuery = query.Encode()
+		uris = append(uris, pq.QuoteLiteral(u.String()))
+	}
+
+	return `(` + strings.Join(uris, `, `) + `)`, nil
+}
+
+// backupScheduleOptions returns the options of the backups of the schedule.
+func backupScheduleOptions(d *schema.ResourceData) ([]string, error) {
+	options := convertToString(d.Get(backupOptionsAttr).([]interface{}))
+
+	incrementalPaths := d.Get(backupLocalityIncrementalLocationsAttr).(map[string]interface{})
+	if incremental := d.Get(backupIncrementalLocationAttr).(string); incremental != "" {
+		localityPaths := d.Get(backupLocalityPathsAttr).(map[string]interface{})
+		if !reflect.DeepEqual(sortedKeys(localityPaths), sortedKeys(incrementalPaths)) {
+			return nil, fmt.Errorf("%s and %s must have the same localities", backupLocalityIncrementalLocationsAttr, backupLocalityPathsAttr)
+		}
+
+		destination, err := backupDestination(incremental, incrementalPaths)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, `incremental_location = `+destination)
+	} else if len(incrementalPaths) != 0 {
+		return nil, fmt.Errorf("%s must be set along with %s", backupIncrementalLocationAttr, backupLocalityIncrementalLocationsAttr)
+	}
 
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
+	options = append(options, backupEncryptionOptions(convertToString(d.Get(backupKMSURIsAttr).([]interface{})), d.Get(backupEncryptionPassphraseAttr).(string))...)
 
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
+	return options, nil
+}
 
-	set_scheduler_backup_options := ""
+// backupEncryptionOptions returns the options encrypting backups, or reading
+// encrypted ones.
+func backupEncryptionOptions(kms []string, passphrase string) []string {
+	var options []string
+	if len(kms) != 0 {
+		uris := make([]string, len(kms))
+		for i, uri := range kms {
+			uris[i] = pq.QuoteLiteral(uri)
+		}
+		options = append(options, `kms = (`+strings.Join(uris, `, `)+`)`)
+	}
+	if passphrase != "" {
+		options = append(options, `encryption_passphrase = `+pq.QuoteLiteral(passphrase))
+	}
+
+	return options
+}
+
+// createBackupScheduleStatement returns the CREATE SCHEDULE statement of the
+// backups of the database.
+func createBackupScheduleStatement(name string, database string, destination string, options []string, recurring string, fullBackup string) string {
+	statement := `CREATE SCHEDULE ` + pq.QuoteIdentifier(name) + ` FOR BACKUP DATABASE ` + pq.QuoteIdentifier(database) + ` INTO ` + destination
+	if len(options) != 0 {
+		statement += ` WITH ` + strings.Join(options, `, `)
+	}
+
+	statement += ` RECURRING ` + pq.QuoteLiteral(recurring) + ` FULL BACKUP `
+	if strings.EqualFold(fullBackup, "ALWAYS") {
+		return statement + `ALWAYS`
+	}
+
+	return statement + pq.QuoteLiteral(fullBackup)
+}
+
+func resourceDatabaseBackupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	scheduler_name := d.Get(schedulerNameAttr).(string)
+	db_name := d.Get(schedulerDbNameAttr).(string)
+	scheduler_backup_path := d.Get(schedulerBackupPathAttr).(string)
 
 	if scheduler_name == "" {
 		return diag.Errorf("Scheduler name can't be an empty string")
@@ -116,37 +243,23 @@ func resourceDatabaseBackupCreate(ctx context.Context, d *schema.ResourceData, m
 		return diag.Errorf("Backup path can't be an empty string")
 	}
 
-	if len(scheduler_backup_options) != 0 {
-		set_scheduler_backup_options = "WITH " + strings.Join(scheduler_backup_options, " ")
+	destination, err := backupDestination(scheduler_backup_path, d.Get(backupLocalityPathsAttr).(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
 	}
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
+	options, err := backupScheduleOptions(d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
-	_, err = conn.Exec(ctx,
-		`CREATE SCHEDULE `+
-			pq.QuoteIdentifier(scheduler_name)+
-			` FOR BACKUP DATABASE `+
-			pq.QuoteIdentifier(db_name)+
-			` INTO `+
-			pq.QuoteIdentifier(scheduler_backup_path)+
-			` `+
-			set_scheduler_backup_options+
-			` RECURRING '`+
-			scheduler_backup_reccuring+
-			`'`+
-			` FULL BACKUP `+
-			scheduler_full_backup,
-	)
+	_, err = conn.Exec(ctx, createBackupScheduleStatement(scheduler_name, db_name, destination, options,
+		d.Get(backupReccuringAttr).(string), d.Get(backupFullBackupAttr).(string)))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -162,41 +275,22 @@ func resourceDatabaseBackupCreate(ctx context.Context, d *schema.ResourceData, m
 
 	d.SetId(strconv.Itoa(id))
 
-	close(stopCh)
-
 	return diag.Diagnostics{}
 }
 
 func resourceDatabaseBackupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	cockroachClient := meta.(*cockroachClient)
-
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
 	scheduller_id := d.Id()
 
 	var scheduler_name string
 	var schedule_expr string
 
-	err = conn.QueryRow(ctx, `SELECT schedule_name, schedule_expr FROM scheduled_jobs WHERE schedule_id = $1`, scheduller_id).Scan(
+	err := conn.QueryRow(ctx, `SELECT schedule_name, schedule_expr FROM scheduled_jobs WHERE schedule_id = $1`, scheduller_id).Scan(
 		&scheduler_name,
 		&schedule_expr,
 	)
@@ -213,43 +307,23 @@ func resourceDatabaseBackupRead(ctx context.Context, d *schema.ResourceData, met
 		return diag.FromErr(err)
 	}
 
-	close(stopCh)
-
 	return diag.Diagnostics{}
 }
 
 func resourceDatabaseBackupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-
 	return diag.Diagnostics{}
 }
 
 func resourceDatabaseBackupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	cockroachClient := meta.(*cockroachClient)
-
 	scheduller_id := d.Id()
 
-	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
-	_, err = conn.Exec(ctx, `DROP SCHEDULE `+scheduller_id)
+	_, err := conn.Exec(ctx, `DROP SCHEDULE `+scheduller_id)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -257,6 +331,5 @@ func resourceDatabaseBackupDelete(ctx context.Context, d *schema.ResourceData, m
 	d.SetId("")
 	d.Set(schedulerDbNameAttr, "")
 
-	close(stopCh)
 	return diag.Diagnostics{}
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestAccResourceDatabaseBackup(t *testing.T) {
@@ -25,6 +26,62 @@ func TestAccResourceDatabaseBackup(t *testing.T) {
 	})
 }
 
+func TestCreateBackupScheduleStatement(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceDatabaseBackup().Schema, map[string]interface{}{
+		schedulerNameAttr:       "daily",
+		schedulerBackupPathAttr: "s3://backups/default?AUTH=implicit",
+		backupLocalityPathsAttr: map[string]interface{}{
+			"region=us-east1": "s3://backups-east/crdb?AUTH=implicit",
+		},
+		backupIncrementalLocationAttr: "s3://incrementals/default?AUTH=implicit",
+		backupLocalityIncrementalLocationsAttr: map[string]interface{}{
+			"region=us-east1": "s3://incrementals-east/crdb?AUTH=implicit",
+		},
+		backupKMSURIsAttr:    []interface{}{"aws:///key?AUTH=implicit&REGION=us-east-1"},
+		backupOptionsAttr:    []interface{}{"revision_history"},
+		backupFullBackupAttr: "@weekly",
+	})
+
+	destination, err := backupDestination(d.Get(schedulerBackupPathAttr).(string), d.Get(backupLocalityPathsAttr).(map[string]interface{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options, err := backupScheduleOptions(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `CREATE SCHEDULE "daily" FOR BACKUP DATABASE "foo" INTO ` +
+		`('s3://backups/default?AUTH=implicit&COCKROACH_LOCALITY=default', 's3://backups-east/crdb?AUTH=implicit&COCKROACH_LOCALITY=region%3Dus-east1') ` +
+		`WITH revision_history, ` +
+		`incremental_location = ('s3://incrementals/default?AUTH=implicit&COCKROACH_LOCALITY=default', 's3://incrementals-east/crdb?AUTH=implicit&COCKROACH_LOCALITY=region%3Dus-east1'), ` +
+		`kms = ('aws:///key?AUTH=implicit&REGION=us-east-1') ` +
+		`RECURRING '@daily' FULL BACKUP '@weekly'`
+	statement := createBackupScheduleStatement("daily", "foo", destination, options, d.Get(backupReccuringAttr).(string), d.Get(backupFullBackupAttr).(string))
+	if statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+
+	if statement := createBackupScheduleStatement("s", "foo", `'nodelocal://1/foo'`, nil, "@hourly", "ALWAYS"); statement != `CREATE SCHEDULE "s" FOR BACKUP DATABASE "foo" INTO 'nodelocal://1/foo' RECURRING '@hourly' FULL BACKUP ALWAYS` {
+		t.Errorf("unexpected statement %s", statement)
+	}
+}
+
+func TestBackupScheduleOptionsLocalities(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceDatabaseBackup().Schema, map[string]interface{}{
+		schedulerBackupPathAttr:       "s3://backups/default",
+		backupLocalityPathsAttr:       map[string]interface{}{"region=us-east1": "s3://backups-east/crdb"},
+		backupIncrementalLocationAttr: "s3://incrementals/default",
+	})
+	if _, err := backupScheduleOptions(d); err == nil {
+		t.Errorf("expected an error for incremental locations without the localities of the backup paths")
+	}
+
+	if _, err := backupDestination("s3://backups/default", map[string]interface{}{"default": "s3://other"}); err == nil {
+		t.Errorf("expected an error for a path of the default locality")
+	}
+}
+
 const testAccResourceDatabaseBackup = `
 resource "cockroach_database_backup" "foo" {
   name = "scheduller"
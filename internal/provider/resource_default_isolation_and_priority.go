@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceDefaultIsolationAndPriority() *schema.Resource {
+	return resourceSettingGroup(settingGroup{
+		id: "default_isolation_and_priority",
+		description: "Resource used to manage the defaults of the transactions and sessions of a CockroachDB cluster, their isolation level, priority and idle timeouts, " +
+			"so every application gets the same transaction semantics. The defaults are session variables set for every role with `ALTER ROLE ALL SET`, " +
+			"the ones set for a role or a database taking precedence.",
+		localPort: "26301",
+		attributes: map[string]groupedSetting{
+			"read_committed_enabled": {name: "sql.txn.read_committed_isolation.enabled", schema: &schema.Schema{
+				Description: "Whether the transactions can run at the `read committed` isolation level, CockroachDB 23.2 or later, the ones asking for it running at `serializable` otherwise.",
+				Type:        schema.TypeBool,
+			}},
+			"default_isolation": {name: "default_transaction_isolation", roleDefault: true, schema: &schema.Schema{
+				Description:  "Default isolation level of the transactions, `serializable` or `read committed`, which requires `read_committed_enabled`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"serializable", "read committed"}, true),
+			}},
+			"default_priority": {name: "default_transaction_priority", roleDefault: true, schema: &schema.Schema{
+				Description:  "Default priority of the transactions, `low`, `normal` or `high`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"low", "normal", "high"}, true),
+			}},
+			"idle_in_session_timeout": {name: "idle_in_session_timeout", roleDefault: true, schema: &schema.Schema{
+				Description:  "Time after which the idle sessions are closed, e.g. `30m`, `0s` not to close them.",
+				Type:         schema.TypeString,
+				ValidateFunc: validateDuration,
+			}},
+			"idle_in_transaction_session_timeout": {name: "idle_in_transaction_session_timeout", roleDefault: true, schema: &schema.Schema{
+				Description:  "Time after which the sessions idle in an open transaction are closed, e.g. `5m`, `0s` not to close them.",
+				Type:         schema.TypeString,
+				ValidateFunc: validateDuration,
+			}},
+		},
+	})
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceDefaultIsolationAndPriority(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceDefaultIsolationAndPriority,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_default_isolation_and_priority.foo", "default_isolation", "read committed"),
+					resource.TestCheckResourceAttr("cockroach_default_isolation_and_priority.foo", "idle_in_session_timeout", "30m"),
+				),
+			},
+		},
+	})
+}
+
+func TestDefaultIsolationAndPriorityValidation(t *testing.T) {
+	s := resourceDefaultIsolationAndPriority().Schema
+	cases := []struct {
+		attr  string
+		value interface{}
+		valid bool
+	}{
+		{"default_isolation", "read committed", true},
+		{"default_isolation", "SERIALIZABLE", true},
+		{"default_isolation", "snapshot", false},
+		{"default_priority", "high", true},
+		{"default_priority", "urgent", false},
+		{"idle_in_session_timeout", "30m", true},
+		{"idle_in_session_timeout", "half an hour", false},
+	}
+
+	for _, c := range cases {
+		_, errs := s[c.attr].ValidateFunc(c.value, c.attr)
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("expected %s = %v to be valid: %v, got %v", c.attr, c.value, c.valid, errs)
+		}
+	}
+
+	if description := s["default_priority"].Description; description != "Default priority of the transactions, `low`, `normal` or `high`. Session variable `default_transaction_priority`, set for every role." {
+		t.Errorf("unexpected description %q", description)
+	}
+}
+
+const testAccResourceDefaultIsolationAndPriority = `
+resource "cockroach_default_isolation_and_priority" "foo" {
+  read_committed_enabled  = true
+  default_isolation       = "read committed"
+  idle_in_session_timeout = "30m"
+}
+`
@@ -0,0 +1,350 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	fleetSettingsSettingsAttr        = "settings"
+	fleetSettingsClustersAttr        = argClusters
+	fleetSettingsContinueOnErrorAttr = "continue_on_error"
+	fleetSettingsClusterStatusAttr   = "cluster_status"
+
+	fleetStatusApplied = "applied"
+
+	fleetClusterSettingsID               = "fleet_cluster_settings"
+	fleetClusterSettingsDefaultLocalPort = "26295"
+)
+
+func resourceFleetClusterSettings() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to manage the same cluster settings on every cluster of the clusters of the provider, or on some of them, reporting the status of each cluster.",
+
+		CreateContext: resourceFleetClusterSettingsCreate,
+		ReadContext:   resourceFleetClusterSettingsRead,
+		UpdateContext: resourceFleetClusterSettingsUpdate,
+		DeleteContext: resourceFleetClusterSettingsDelete,
+
+		Schema: map[string]*schema.Schema{
+			fleetSettingsSettingsAttr: {
+				Description: "Cluster settings to set on every cluster, keyed by name.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required:         true,
+				ValidateDiagFunc: validation.MapKeyMatch(clusterSettingNameRegexp, "invalid cluster setting name"),
+				DiffSuppressFunc: suppressEquivalentSettingValue,
+			},
+			fleetSettingsClustersAttr: {
+				Description: "Names of the clusters of the provider to manage the settings of, every cluster of the provider when not set.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+			fleetSettingsContinueOnErrorAttr: {
+				Description: "True to carry on with the other clusters when a cluster fails, its error being reported in `cluster_status`, rather than failing the apply. The settings of the failed clusters are applied again by the next apply once they differ from the configuration.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			fleetSettingsClusterStatusAttr: {
+				Description: "Status of each cluster, `applied` or the reason the settings of the cluster differ, keyed by cluster name.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + fleetClusterSettingsDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     fleetClusterSettingsDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// fleetClusters returns the names of the clusters the resource manages.
+func fleetClusters(d *schema.ResourceData, meta interface{}) ([]string, error) {
+	client := meta.(*cockroachClient)
+
+	names := managedFleetClusters(client, d.Get(fleetSettingsClustersAttr).(*schema.Set))
+	if len(names) == 0 {
+		return nil, fmt.Errorf("the provider has no clusters")
+	}
+
+	for _, name := range names {
+		if _, err := client.named(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return sortedKeys(stringSet(names)), nil
+}
+
+// managedFleetClusters returns the names of the clusters of the set, all the
+// clusters of the provider when it is empty.
+func managedFleetClusters(client *cockroachClient, clusters *schema.Set) []string {
+	if clusters.Len() == 0 {
+		return client.clusterNames()
+	}
+
+	return convertToString(clusters.List())
+}
+
+func stringSet(values []string) map[string]interface{} {
+	set := make(map[string]interface{}, len(values))
+	for _, v := range values {
+		set[v] = nil
+	}
+
+	return set
+}
+
+// forEachCluster calls fn with a connection to each cluster and returns the
+// status of each of them, failing on the first error unless continueOnError
+// is set.
+func forEachCluster(ctx context.Context, d *schema.ResourceData, meta interface{}, names []string, continueOnError bool, fn func(conn *pgx.Conn) (string, error)) (map[string]interface{}, diag.Diagnostics) {
+	status := map[string]interface{}{}
+	var diags diag.Diagnostics
+
+	for _, name := range names {
+		err := func() error {
+			client, err := meta.(*cockroachClient).named(name)
+			if err != nil {
+				return err
+			}
+
+			conn, closeConn, connDiags := openConnection(ctx, d, client)
+			if connDiags.HasError() {
+				return diagnosticsError(connDiags)
+			}
+			defer closeConn()
+
+			s, err := fn(conn)
+			if err != nil {
+				return err
+			}
+			status[name] = s
+
+			return nil
+		}()
+		if err == nil {
+			continue
+		}
+
+		if !continueOnError {
+			return nil, diag.Errorf("cluster %s: %v", name, err)
+		}
+		status[name] = "error: " + err.Error()
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Cluster %s failed", name),
+			Detail:   err.Error(),
+		})
+	}
+
+	return status, diags
+}
+
+func resourceFleetClusterSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	names, err := fleetClusters(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	settings := d.Get(fleetSettingsSettingsAttr).(map[string]interface{})
+	_, diags := forEachCluster(ctx, d, meta, names, d.Get(fleetSettingsContinueOnErrorAttr).(bool), func(conn *pgx.Conn) (string, error) {
+		return applyFleetClusterSettings(ctx, conn, nil, settings)
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fleetClusterSettingsID)
+
+	return append(diags, resourceFleetClusterSettingsRead(ctx, d, meta)...)
+}
+
+func resourceFleetClusterSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	names, err := fleetClusters(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	settings := d.Get(fleetSettingsSettingsAttr).(map[string]interface{})
+	read := map[string]interface{}{}
+	for name, value := range settings {
+		read[name] = value
+	}
+
+	status, diags := forEachCluster(ctx, d, meta, names, d.Get(fleetSettingsContinueOnErrorAttr).(bool), func(conn *pgx.Conn) (string, error) {
+		current, err := readClusterSettings(ctx, conn)
+		if err != nil {
+			return "", err
+		}
+
+		drifted := fleetSettingsDrift(settings, current)
+		// the first value read differing from the state is planned to be
+		// changed back on every cluster
+		for _, name := range sortedKeys(drifted) {
+			if read[name] == settings[name] {
+				read[name] = drifted[name]
+			}
+		}
+
+		return fleetStatus(drifted), nil
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	values := map[string]interface{}{
+		fleetSettingsSettingsAttr:      read,
+		fleetSettingsClusterStatusAttr: status,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceFleetClusterSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	names, err := fleetClusters(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	continueOnError := d.Get(fleetSettingsContinueOnErrorAttr).(bool)
+
+	oraw, nraw := d.GetChange(fleetSettingsSettingsAttr)
+	o := oraw.(map[string]interface{})
+	n := nraw.(map[string]interface{})
+
+	// the clusters no longer managed get the settings back to their default
+	// value
+	if d.HasChange(fleetSettingsClustersAttr) {
+		// no clusters were all the clusters of the provider
+		oldClusters, _ := d.GetChange(fleetSettingsClustersAttr)
+		var removed []string
+		for _, name := range managedFleetClusters(meta.(*cockroachClient), oldClusters.(*schema.Set)) {
+			if !contains(names, name) {
+				removed = append(removed, name)
+			}
+		}
+		_, diags := forEachCluster(ctx, d, meta, removed, continueOnError, func(conn *pgx.Conn) (string, error) {
+			return "", resetFleetClusterSettings(ctx, conn, o)
+		})
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	// every setting is set, the clusters added having none of them
+	_, diags := forEachCluster(ctx, d, meta, names, continueOnError, func(conn *pgx.Conn) (string, error) {
+		return applyFleetClusterSettings(ctx, conn, o, n)
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, resourceFleetClusterSettingsRead(ctx, d, meta)...)
+}
+
+func resourceFleetClusterSettingsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	names, err := fleetClusters(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	settings := d.Get(fleetSettingsSettingsAttr).(map[string]interface{})
+	_, diags := forEachCluster(ctx, d, meta, names, d.Get(fleetSettingsContinueOnErrorAttr).(bool), func(conn *pgx.Conn) (string, error) {
+		return "", resetFleetClusterSettings(ctx, conn, settings)
+	})
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+// applyFleetClusterSettings resets the settings of old not in settings and
+// sets the settings.
+func applyFleetClusterSettings(ctx context.Context, conn *pgx.Conn, old map[string]interface{}, settings map[string]interface{}) (string, error) {
+	for _, name := range sortedKeys(old) {
+		if _, ok := settings[name]; !ok {
+			if err := resetClusterSetting(ctx, conn, name); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(settings) {
+		if err := setClusterSetting(ctx, conn, name, settings[name].(string)); err != nil {
+			return "", err
+		}
+	}
+
+	return fleetStatusApplied, nil
+}
+
+func resetFleetClusterSettings(ctx context.Context, conn *pgx.Conn, settings map[string]interface{}) error {
+	for _, name := range sortedKeys(settings) {
+		if err := resetClusterSetting(ctx, conn, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fleetSettingsDrift returns the values of the settings of a cluster differing
+// from the managed ones.
+func fleetSettingsDrift(settings map[string]interface{}, current map[string]clusterSetting) map[string]interface{} {
+	drifted := map[string]interface{}{}
+	for name, value := range settings {
+		setting, ok := current[name]
+		if !ok {
+			drifted[name] = ""
+			continue
+		}
+		if !settingValuesEqual(value.(string), setting.value) {
+			drifted[name] = setting.value
+		}
+	}
+
+	return drifted
+}
+
+func fleetStatus(drifted map[string]interface{}) string {
+	if len(drifted) == 0 {
+		return fleetStatusApplied
+	}
+
+	changes := make([]string, 0, len(drifted))
+	for _, name := range sortedKeys(drifted) {
+		if drifted[name] == "" {
+			changes = append(changes, name+" is unknown")
+		} else {
+			changes = append(changes, fmt.Sprintf("%s is %q", name, drifted[name]))
+		}
+	}
+
+	return "changed outside of Terraform: " + strings.Join(changes, ", ")
+}
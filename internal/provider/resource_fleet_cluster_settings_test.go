@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestFleetSettingsDrift(t *testing.T) {
+	settings := map[string]interface{}{
+		"sql.defaults.idle_in_session_timeout": "1h",
+		"kv.rangefeed.enabled":                 "true",
+		"server.unknown":                       "1",
+	}
+	current := map[string]clusterSetting{
+		"sql.defaults.idle_in_session_timeout": {value: "01:00:00"},
+		"kv.rangefeed.enabled":                 {value: "false"},
+	}
+
+	drifted := fleetSettingsDrift(settings, current)
+	if len(drifted) != 2 || drifted["kv.rangefeed.enabled"] != "false" || drifted["server.unknown"] != "" {
+		t.Fatalf("unexpected drift %v", drifted)
+	}
+
+	expected := `changed outside of Terraform: kv.rangefeed.enabled is "false", server.unknown is unknown`
+	if status := fleetStatus(drifted); status != expected {
+		t.Errorf("expected status %q, got %q", expected, status)
+	}
+	if status := fleetStatus(map[string]interface{}{}); status != fleetStatusApplied {
+		t.Errorf("expected status %q, got %q", fleetStatusApplied, status)
+	}
+}
+
+func TestFleetClusterSettingsWithoutNamedCluster(t *testing.T) {
+	r := New("dev")().ResourcesMap["cockroach_fleet_cluster_settings"]
+	if _, ok := r.Schema[argCluster]; ok {
+		t.Errorf("expected the fleet resource not to have the %s attribute", argCluster)
+	}
+}
+
+func TestManagedFleetClusters(t *testing.T) {
+	client := &cockroachClient{clusters: map[string]*cockroachClient{"prod": {}, "staging": {}, "dev": {}}}
+
+	// no clusters are all the clusters of the provider, so that going from
+	// none to a subset resets the settings of the others
+	if names := managedFleetClusters(client, schema.NewSet(schema.HashString, nil)); !reflect.DeepEqual(names, []string{"dev", "prod", "staging"}) {
+		t.Errorf("expected all the clusters, got %v", names)
+	}
+	if names := managedFleetClusters(client, schema.NewSet(schema.HashString, []interface{}{"prod"})); !reflect.DeepEqual(names, []string{"prod"}) {
+		t.Errorf("expected the given clusters, got %v", names)
+	}
+}
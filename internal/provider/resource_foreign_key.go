@@ -0,0 +1,402 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	fkNameAttr              = "name"
+	fkDatabaseAttr          = "database"
+	fkSchemaAttr            = "schema"
+	fkTableAttr             = "table"
+	fkColumnsAttr           = "columns"
+	fkReferencedSchemaAttr  = "referenced_schema"
+	fkReferencedTableAttr   = "referenced_table"
+	fkReferencedColumnsAttr = "referenced_columns"
+	fkOnDeleteAttr          = "on_delete"
+	fkOnUpdateAttr          = "on_update"
+	fkValidateAttr          = "validate"
+	fkValidatedAttr         = "validated"
+
+	fkDefaultLocalPort = "26269"
+)
+
+// fkActions maps the referential actions to their code in pg_constraint.
+var fkActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+func fkActionSchema(clause string) *schema.Schema {
+	return &schema.Schema{
+		Description:  fmt.Sprintf("Action run on the referencing rows %s the referenced row, one of `NO ACTION`, `RESTRICT`, `CASCADE`, `SET NULL` or `SET DEFAULT`.", clause),
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		Default:      "NO ACTION",
+		ValidateFunc: validation.StringInSlice([]string{"NO ACTION", "RESTRICT", "CASCADE", "SET NULL", "SET DEFAULT"}, true),
+		StateFunc: func(v interface{}) string {
+			return strings.ToUpper(v.(string))
+		},
+	}
+}
+
+func resourceForeignKey() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to add a foreign key to a table of a CockroachDB cluster, e.g. to a table created by a migration tool. " +
+			"A foreign key can be added `NOT VALID` and validated later, once the existing rows are fixed.",
+
+		CreateContext: resourceForeignKeyCreate,
+		ReadContext:   resourceForeignKeyRead,
+		UpdateContext: resourceForeignKeyUpdate,
+		DeleteContext: resourceForeignKeyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceForeignKeyImporter,
+		},
+		CustomizeDiff: resourceForeignKeyCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			fkNameAttr: {
+				Description: "Name of the foreign key constraint.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			fkDatabaseAttr: {
+				Description: "Name of the database of the tables, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			fkSchemaAttr: {
+				Description: "Name of the schema of the referencing table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			fkTableAttr: {
+				Description: "Name of the referencing table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			fkColumnsAttr: {
+				Description: "Columns of the referencing table.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+			},
+			fkReferencedSchemaAttr: {
+				Description: "Name of the schema of the referenced table, the schema of the referencing table is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			fkReferencedTableAttr: {
+				Description: "Name of the referenced table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			fkReferencedColumnsAttr: {
+				Description: "Columns of the referenced table, in the order of `columns`. They must be covered by its primary key or a UNIQUE constraint.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+			},
+			fkOnDeleteAttr: fkActionSchema("on the deletion of"),
+			fkOnUpdateAttr: fkActionSchema("on the update of"),
+			fkValidateAttr: {
+				Description: "Whether the existing rows are checked. When false the foreign key is added `NOT VALID`, only the rows written afterwards being checked, " +
+					"and setting it to true later runs `VALIDATE CONSTRAINT`. A validated foreign key can't be invalidated, setting it back to false has no effect.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			fkValidatedAttr: {
+				Description: "Whether every row of the table satisfies the foreign key.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			argTolerateExistingState: tolerateExistingStateSchema("constraint"),
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26269), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     fkDefaultLocalPort,
+			},
+		},
+	}, 4)
+}
+
+func resourceForeignKeyCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, fkDatabaseAttr); err != nil {
+		return err
+	}
+	if err := setDefaultSchema(d, meta, fkSchemaAttr); err != nil {
+		return err
+	}
+	if d.Get(fkReferencedSchemaAttr).(string) == "" && d.NewValueKnown(fkSchemaAttr) {
+		if err := d.SetNew(fkReferencedSchemaAttr, d.Get(fkSchemaAttr).(string)); err != nil {
+			return err
+		}
+	}
+
+	columns := d.Get(fkColumnsAttr).([]interface{})
+	referenced := d.Get(fkReferencedColumnsAttr).([]interface{})
+	if d.NewValueKnown(fkColumnsAttr) && d.NewValueKnown(fkReferencedColumnsAttr) && len(columns) != len(referenced) {
+		return fmt.Errorf("%s and %s must have the same number of columns", fkColumnsAttr, fkReferencedColumnsAttr)
+	}
+
+	// validating the foreign key changes the computed attribute
+	if d.Id() != "" && d.HasChange(fkValidateAttr) && d.Get(fkValidateAttr).(bool) {
+		return d.SetNewComputed(fkValidatedAttr)
+	}
+
+	return nil
+}
+
+// addForeignKeyStatement returns the ALTER TABLE statement adding the foreign
+// key of the resource.
+func addForeignKeyStatement(d *schema.ResourceData) string {
+	database := d.Get(fkDatabaseAttr).(string)
+	referenced := qualifiedNames(database, d.Get(fkReferencedSchemaAttr).(string), []string{d.Get(fkReferencedTableAttr).(string)})
+
+	statement := fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s`,
+		foreignKeyTable(d),
+		pq.QuoteIdentifier(d.Get(fkNameAttr).(string)),
+		quoteIdentifiers(convertToString(d.Get(fkColumnsAttr).([]interface{}))),
+		referenced,
+		quoteIdentifiers(convertToString(d.Get(fkReferencedColumnsAttr).([]interface{}))),
+		strings.ToUpper(d.Get(fkOnDeleteAttr).(string)),
+		strings.ToUpper(d.Get(fkOnUpdateAttr).(string)))
+	if !d.Get(fkValidateAttr).(bool) {
+		statement += ` NOT VALID`
+	}
+
+	return statement
+}
+
+// foreignKeyTable returns the qualified name of the referencing table.
+func foreignKeyTable(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(fkDatabaseAttr).(string), d.Get(fkSchemaAttr).(string), []string{d.Get(fkTableAttr).(string)})
+}
+
+// foreignKeyLockKeys returns the keys of the locks of the referencing and the
+// referenced tables, adding a foreign key changing both.
+func foreignKeyLockKeys(d *schema.ResourceData) []string {
+	database := d.Get(fkDatabaseAttr).(string)
+	return []string{
+		objectLockKey("table", database, d.Get(fkSchemaAttr).(string), d.Get(fkTableAttr).(string)),
+		objectLockKey("table", database, d.Get(fkReferencedSchemaAttr).(string), d.Get(fkReferencedTableAttr).(string)),
+	}
+}
+
+func resourceForeignKeyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(fkDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", fkDatabaseAttr)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(foreignKeyLockKeys(d)...)
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	if _, err := conn.Exec(ctx, addForeignKeyStatement(d)); err != nil && !toleratedExistingState(d, err, alreadyExistsCodes) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(clusterScopedID("", database, d.Get(fkSchemaAttr).(string), d.Get(fkTableAttr).(string), d.Get(fkNameAttr).(string)))
+
+	return resourceForeignKeyRead(ctx, d, meta)
+}
+
+// foreignKey is a foreign key as reported by CockroachDB.
+type foreignKey struct {
+	columns           []string
+	referencedSchema  string
+	referencedTable   string
+	referencedColumns []string
+	onDelete          string
+	onUpdate          string
+	validated         bool
+}
+
+// readForeignKey reads the foreign key of the table, ok being false when it
+// doesn't exist.
+func readForeignKey(ctx context.Context, conn *pgx.Conn, database string, schemaName string, table string, name string) (fk foreignKey, ok bool, err error) {
+	db := pq.QuoteIdentifier(database)
+	columnNames := func(keys string, relation string) string {
+		return `ARRAY(SELECT a.attname FROM unnest(` + keys + `) WITH ORDINALITY AS k(attnum, i) ` +
+			`JOIN ` + db + `.pg_catalog.pg_attribute AS a ON a.attrelid = ` + relation + ` AND a.attnum = k.attnum ORDER BY k.i)`
+	}
+
+	var onDelete, onUpdate string
+	err = conn.QueryRow(ctx,
+		`SELECT `+columnNames(`con.conkey`, `con.conrelid`)+`, rn.nspname, rc.relname, `+columnNames(`con.confkey`, `con.confrelid`)+`, `+
+			`con.confdeltype::STRING, con.confupdtype::STRING, con.convalidated `+
+			`FROM `+db+`.pg_catalog.pg_constraint AS con `+
+			`JOIN `+db+`.pg_catalog.pg_class AS c ON c.oid = con.conrelid `+
+			`JOIN `+db+`.pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace `+
+			`JOIN `+db+`.pg_catalog.pg_class AS rc ON rc.oid = con.confrelid `+
+			`JOIN `+db+`.pg_catalog.pg_namespace AS rn ON rn.oid = rc.relnamespace `+
+			`WHERE con.contype = 'f' AND n.nspname = $1 AND c.relname = $2 AND con.conname = $3`,
+		schemaName, table, name).Scan(&fk.columns, &fk.referencedSchema, &fk.referencedTable, &fk.referencedColumns, &onDelete, &onUpdate, &fk.validated)
+	if err == pgx.ErrNoRows {
+		return fk, false, nil
+	}
+	if err != nil {
+		return fk, false, err
+	}
+
+	fk.onDelete, fk.onUpdate = fkActions[onDelete], fkActions[onUpdate]
+
+	return fk, true, nil
+}
+
+func resourceForeignKeyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, d.Get(fkDatabaseAttr).(string), d.Get(fkSchemaAttr).(string), d.Get(fkTableAttr).(string), d.Get(fkNameAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("foreign key %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	fk, ok, err := readForeignKey(ctx, conn, d.Get(fkDatabaseAttr).(string), d.Get(fkSchemaAttr).(string), d.Get(fkTableAttr).(string), d.Get(fkNameAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("foreign key %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	values := map[string]interface{}{
+		fkColumnsAttr:           fk.columns,
+		fkReferencedSchemaAttr:  fk.referencedSchema,
+		fkReferencedTableAttr:   fk.referencedTable,
+		fkReferencedColumnsAttr: fk.referencedColumns,
+		fkOnDeleteAttr:          fk.onDelete,
+		fkOnUpdateAttr:          fk.onUpdate,
+		fkValidatedAttr:         fk.validated,
+	}
+	// a foreign key which should be validated but isn't is validated by the
+	// next apply
+	if d.Get(fkValidateAttr).(bool) && !fk.validated {
+		values[fkValidateAttr] = false
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceForeignKeyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChange(fkValidateAttr) || !d.Get(fkValidateAttr).(bool) {
+		return resourceForeignKeyRead(ctx, d, meta)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(foreignKeyLockKeys(d)...)
+	defer unlock()
+
+	logInfo("validating foreign key %s", d.Id())
+	_, err := conn.Exec(ctx, `ALTER TABLE `+foreignKeyTable(d)+` VALIDATE CONSTRAINT `+pq.QuoteIdentifier(d.Get(fkNameAttr).(string)))
+	if err != nil {
+		return diag.Errorf("failed to validate foreign key %s: %v", d.Id(), err)
+	}
+
+	return resourceForeignKeyRead(ctx, d, meta)
+}
+
+func resourceForeignKeyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(foreignKeyLockKeys(d)...)
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	_, err := conn.Exec(ctx, `ALTER TABLE IF EXISTS `+foreignKeyTable(d)+` DROP CONSTRAINT IF EXISTS `+pq.QuoteIdentifier(d.Get(fkNameAttr).(string)))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceForeignKeyImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table/name
+	_, parts, err := parseResourceID(d.Id(), 4)
+	if err != nil {
+		return nil, fmt.Errorf("invalid foreign key id %q, expected database/schema/table/name: %v", d.Id(), err)
+	}
+
+	values := map[string]interface{}{
+		fkDatabaseAttr: parts[0],
+		fkSchemaAttr:   parts[1],
+		fkTableAttr:    parts[2],
+		fkNameAttr:     parts[3],
+		fkValidateAttr: true,
+		argLocalPort:   fkDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
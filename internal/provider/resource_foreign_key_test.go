@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccResourceForeignKey(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceForeignKey(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_foreign_key.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:foo:public:orders:orders_customer_fk$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_foreign_key.foo", "validated", "false"),
+				),
+			},
+			{
+				Config: testAccResourceForeignKey(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_foreign_key.foo", "validated", "true"),
+					resource.TestCheckResourceAttr(
+						"cockroach_foreign_key.foo", "on_delete", "CASCADE"),
+				),
+			},
+		},
+	})
+}
+
+func TestAddForeignKeyStatement(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceForeignKey().Schema, map[string]interface{}{
+		fkNameAttr:              "fk",
+		fkDatabaseAttr:          "foo",
+		fkSchemaAttr:            "public",
+		fkTableAttr:             "orders",
+		fkColumnsAttr:           []interface{}{"customer_id"},
+		fkReferencedSchemaAttr:  "crm",
+		fkReferencedTableAttr:   "customers",
+		fkReferencedColumnsAttr: []interface{}{"id"},
+		fkOnDeleteAttr:          "cascade",
+		fkValidateAttr:          false,
+	})
+
+	expected := `ALTER TABLE "foo"."public"."orders" ADD CONSTRAINT "fk" FOREIGN KEY ("customer_id") ` +
+		`REFERENCES "foo"."crm"."customers" ("id") ON DELETE CASCADE ON UPDATE NO ACTION NOT VALID`
+	if actual := addForeignKeyStatement(d); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+
+	// the referenced table is locked as well, as by its own table resource
+	keys := []string{objectLockKey("table", "foo", "public", "orders"), objectLockKey("table", "foo", "crm", "customers")}
+	if actual := foreignKeyLockKeys(d); !reflect.DeepEqual(actual, keys) {
+		t.Errorf("unexpected lock keys %q, expected %q", actual, keys)
+	}
+}
+
+func testAccResourceForeignKey(validate bool) string {
+	return fmt.Sprintf(`
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "customers" {
+  database    = cockroach_database.foo.name
+  name        = "customers"
+  primary_key = ["id"]
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+}
+
+resource "cockroach_table" "orders" {
+  database    = cockroach_database.foo.name
+  name        = "orders"
+  primary_key = ["id"]
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+
+  column {
+    name = "customer_id"
+    type = "INT8"
+  }
+}
+
+resource "cockroach_foreign_key" "foo" {
+  database           = cockroach_database.foo.name
+  table              = cockroach_table.orders.name
+  name               = "orders_customer_fk"
+  columns            = ["customer_id"]
+  referenced_table   = cockroach_table.customers.name
+  referenced_columns = ["id"]
+  on_delete          = "CASCADE"
+  validate           = %t
+}
+`, validate)
+}
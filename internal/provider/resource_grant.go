@@ -0,0 +1,751 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	grantRoleAttr            = "role"
+	grantDatabaseAttr        = "database"
+	grantSchemaAttr          = "schema"
+	grantObjectTypeAttr      = "object_type"
+	grantObjectsAttr         = "objects"
+	grantPrivilegesAttr      = "privileges"
+	grantWithGrantOptionAttr = "with_grant_option"
+
+	grantDefaultLocalPort = "26261"
+)
+
+const (
+	grantObjectDatabase           = "database"
+	grantObjectSchema             = "schema"
+	grantObjectTable              = "table"
+	grantObjectFunction           = "function"
+	grantObjectType               = "type"
+	grantObjectExternalConnection = "external_connection"
+
+	privilegeAll = "ALL"
+)
+
+// grantPrivileges lists the privileges that can be granted on each object type,
+// ALL being equivalent to the whole list.
+var grantPrivileges = map[string][]string{
+	grantObjectDatabase: {"BACKUP", "CONNECT", "CREATE", "DROP", "RESTORE", "ZONECONFIG"},
+	grantObjectSchema:   {"CREATE", "USAGE"},
+	grantObjectTable:    {"BACKUP", "CHANGEFEED", "CREATE", "DELETE", "DROP", "INSERT", "SELECT", "UPDATE", "ZONECONFIG"},
+	grantObjectFunction: {"EXECUTE"},
+	grantObjectType:     {"USAGE"},
+
+	grantObjectExternalConnection: {"DROP", "UPDATE", "USAGE"},
+}
+
+func resourceGrant() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to grant privileges on a database, schema, tables, functions, types or external connections to a role in a CockroachDB cluster. " +
+			"Its id isn't scoped to the cluster, the privileges of the role on the same objects of another cluster the configuration is applied to being adopted.",
+
+		CreateContext: resourceGrantCreate,
+		ReadContext:   resourceGrantRead,
+		UpdateContext: resourceGrantUpdate,
+		DeleteContext: resourceGrantDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGrantImporter,
+		},
+		CustomizeDiff: resourceGrantCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			grantRoleAttr: {
+				Description:      "Name of the role (or user) to grant the privileges to, lowercased by CockroachDB.",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressRoleNameCase,
+			},
+			grantDatabaseAttr: {
+				Description: "Name of the database holding the objects, required for every object type except `external_connection`. The default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			grantSchemaAttr: {
+				Description: "Name of the schema holding the objects, only used for `schema`, `table`, `function` and `type` grants. The default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			grantObjectTypeAttr: {
+				Description:  "Type of the object to grant the privileges on, one of `database`, `schema`, `table`, `function`, `type` or `external_connection`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(grantObjectTypes(), false),
+			},
+			grantObjectsAttr: {
+				Description: "Names of the objects to grant the privileges on, required for every object type except `database` and `schema`. Functions can be given with their argument types, e.g. `add(INT8, INT8)`, to select one overload.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			grantPrivilegesAttr: {
+				Description: "Privileges to grant. Treated as a set, `ALL` and the full list of privileges of the object type are equivalent.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(allPrivileges(), true),
+				},
+				Set:      hashPrivilege,
+				Required: true,
+				MinItems: 1,
+			},
+			grantWithGrantOptionAttr: {
+				Description: "True if the role can grant the privileges to other roles. Setting it back to false only revokes the grant option, the role keeping the privileges.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26261), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     grantDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// resourceGrantCustomizeDiff plans the default database and schema of the
+// provider when the grant doesn't set them.
+func resourceGrantCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get(grantObjectTypeAttr).(string) != grantObjectExternalConnection {
+		if err := setDefaultDatabase(d, meta, grantDatabaseAttr); err != nil {
+			return err
+		}
+	}
+
+	return setDefaultSchema(d, meta, grantSchemaAttr)
+}
+
+func resourceGrantCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	role := d.Get(grantRoleAttr).(string)
+	database := d.Get(grantDatabaseAttr).(string)
+	schemaName := d.Get(grantSchemaAttr).(string)
+	objectType := d.Get(grantObjectTypeAttr).(string)
+	objects := convertToString(d.Get(grantObjectsAttr).(*schema.Set).List())
+	privileges := convertToString(d.Get(grantPrivilegesAttr).(*schema.Set).List())
+	withGrantOption := d.Get(grantWithGrantOptionAttr).(bool)
+
+	if err := validateGrant(role, database, objectType, objects, privileges); err != nil {
+		return diag.FromErr(err)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(append(grantLockKeys(objectType, database, schemaName, objects), roleLockKey(role))...)
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	target := grantTarget(objectType, database, schemaName, objects)
+	if err := grantPrivilegesTo(ctx, conn, target, role, normalizePrivileges(objectType, privileges), withGrantOption); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(grantID(role, database, objectType, schemaName, objects))
+
+	return diag.Diagnostics{}
+}
+
+func resourceGrantRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	role := d.Get(grantRoleAttr).(string)
+	database := d.Get(grantDatabaseAttr).(string)
+	schemaName := d.Get(grantSchemaAttr).(string)
+	objectType := d.Get(grantObjectTypeAttr).(string)
+	objects := convertToString(d.Get(grantObjectsAttr).(*schema.Set).List())
+
+	target := grantTarget(objectType, database, schemaName, objects)
+
+	// the grants of every role on the object are listed once for all the
+	// grants refreshed together
+	cached, diags := meta.(*cockroachClient).cache.get("grants:"+target, func() (interface{}, diag.Diagnostics) {
+		conn, closeConn, diags := openConnection(ctx, d, meta)
+		if diags != nil {
+			return nil, diags
+		}
+		defer closeConn()
+
+		rows, err := showGrants(ctx, conn, target, "")
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		return rows, nil
+	})
+	if diags != nil {
+		return diags
+	}
+
+	privileges, withGrantOption := grantedPrivileges(objectType, cached.([]grantRow), role, grantObjectNames(objectType, database, schemaName, objects))
+	if len(privileges) == 0 {
+		logInfo("no privileges granted to %s on %s, removing it from state", role, d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	// Keep the privileges as configured when they are equivalent to the ones
+	// reported by CockroachDB, e.g. ALL versus the enumerated privileges.
+	current := convertToString(d.Get(grantPrivilegesAttr).(*schema.Set).List())
+	if !privilegesEqual(objectType, current, privileges) {
+		if err := d.Set(grantPrivilegesAttr, privileges); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := d.Set(grantWithGrantOptionAttr, withGrantOption); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceGrantUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	role := d.Get(grantRoleAttr).(string)
+	database := d.Get(grantDatabaseAttr).(string)
+	schemaName := d.Get(grantSchemaAttr).(string)
+	objectType := d.Get(grantObjectTypeAttr).(string)
+	objects := convertToString(d.Get(grantObjectsAttr).(*schema.Set).List())
+
+	if !d.HasChange(grantPrivilegesAttr) && !d.HasChange(grantWithGrantOptionAttr) {
+		return diag.Diagnostics{}
+	}
+
+	oraw, nraw := d.GetChange(grantPrivilegesAttr)
+	o := convertToString(oraw.(*schema.Set).List())
+	n := convertToString(nraw.(*schema.Set).List())
+	oldGrantOption, newGrantOption := d.GetChange(grantWithGrantOptionAttr)
+
+	if err := validateGrant(role, database, objectType, objects, n); err != nil {
+		return diag.FromErr(err)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(append(grantLockKeys(objectType, database, schemaName, objects), roleLockKey(role))...)
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	target := grantTarget(objectType, database, schemaName, objects)
+	statements := grantUpdateStatements(objectType, target, role, o, n, oldGrantOption.(bool), newGrantOption.(bool))
+
+	// revoking and granting together means the role never loses the
+	// privileges kept across the change
+	if err := execInTransaction(ctx, conn, statements...); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+// grantUpdateStatements returns the statements changing the privileges of
+// the role on the target from o to n and its grant option from
+// oldGrantOption to newGrantOption. The privileges kept across the change are
+// never revoked, only their grant option when it is removed.
+func grantUpdateStatements(objectType string, target string, role string, o []string, n []string, oldGrantOption bool, newGrantOption bool) []string {
+	revoke := subtractPrivileges(expandPrivileges(objectType, o), expandPrivileges(objectType, n))
+	grant := subtractPrivileges(expandPrivileges(objectType, n), expandPrivileges(objectType, o))
+
+	var statements []string
+	if len(revoke) != 0 {
+		statements = append(statements, revokeStatement(target, role, revoke))
+	}
+	switch kept := intersectPrivileges(expandPrivileges(objectType, o), expandPrivileges(objectType, n)); {
+	case oldGrantOption && !newGrantOption && len(kept) != 0:
+		statements = append(statements, revokeGrantOptionStatement(target, role, kept))
+	case !oldGrantOption && newGrantOption:
+		// granting the kept privileges again adds the grant option to them
+		grant = expandPrivileges(objectType, n)
+	}
+	if len(grant) != 0 {
+		statements = append(statements, grantStatement(target, role, grant, newGrantOption))
+	}
+
+	return statements
+}
+
+func resourceGrantDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	role := d.Get(grantRoleAttr).(string)
+	database := d.Get(grantDatabaseAttr).(string)
+	schemaName := d.Get(grantSchemaAttr).(string)
+	objectType := d.Get(grantObjectTypeAttr).(string)
+	objects := convertToString(d.Get(grantObjectsAttr).(*schema.Set).List())
+	privileges := convertToString(d.Get(grantPrivilegesAttr).(*schema.Set).List())
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(append(grantLockKeys(objectType, database, schemaName, objects), roleLockKey(role))...)
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	target := grantTarget(objectType, database, schemaName, objects)
+	if err := revokePrivilegesFrom(ctx, conn, target, role, normalizePrivileges(objectType, privileges)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceGrantImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format role/database/object_type[/schema[/object,object...]]
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) < 3 || len(parts) > 5 {
+		return nil, fmt.Errorf("invalid grant id %q, expected role/database/object_type[/schema[/objects]]", d.Id())
+	}
+
+	if err := d.Set(grantRoleAttr, parts[0]); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set(grantDatabaseAttr, parts[1]); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set(grantObjectTypeAttr, parts[2]); err != nil {
+		return nil, err
+	}
+
+	schemaName := "public"
+	if len(parts) > 3 && parts[3] != "" {
+		schemaName = parts[3]
+	}
+	if err := d.Set(grantSchemaAttr, schemaName); err != nil {
+		return nil, err
+	}
+
+	objects := []string{}
+	if len(parts) > 4 {
+		objects = splitGrantObjects(parts[4])
+	}
+	if err := d.Set(grantObjectsAttr, objects); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set(grantWithGrantOptionAttr, false); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set(argLocalPort, grantDefaultLocalPort); err != nil {
+		return nil, err
+	}
+
+	if diags := resourceGrantRead(ctx, d, meta); diags.HasError() {
+		return nil, fmt.Errorf("unable to import grant %s", d.Id())
+	}
+
+	if d.Id() == "" {
+		return nil, fmt.Errorf("no privileges granted for %s", strings.Join(parts, "/"))
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// grantRow is a row returned by SHOW GRANTS.
+type grantRow struct {
+	object      string
+	grantee     string
+	privilege   string
+	isGrantable bool
+}
+
+// showGrants returns the privileges granted to role on target, or to every role
+// when role is empty. The columns of SHOW GRANTS depend on the object type, so
+// they are looked up by name.
+func showGrants(ctx context.Context, conn *pgx.Conn, target string, role string) ([]grantRow, error) {
+	query := `SHOW GRANTS ON ` + target
+	if role != "" {
+		query += ` FOR ` + pq.QuoteIdentifier(role)
+	}
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string]int{}
+	for i, field := range rows.FieldDescriptions() {
+		columns[string(field.Name)] = i
+	}
+
+	var grants []grantRow
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+
+		grant := grantRow{}
+		// the most specific object name is the last one present
+		for _, column := range []string{"database_name", "schema_name", "table_name", "type_name", "routine_signature", "connection_name"} {
+			if i, ok := columns[column]; ok {
+				if name, ok := values[i].(string); ok {
+					grant.object = name
+				}
+			}
+		}
+		if i, ok := columns["grantee"]; ok {
+			grant.grantee, _ = values[i].(string)
+		}
+		if i, ok := columns["privilege_type"]; ok {
+			grant.privilege, _ = values[i].(string)
+		}
+		if i, ok := columns["is_grantable"]; ok {
+			grant.isGrantable, _ = values[i].(bool)
+		}
+
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// grantedPrivileges returns the normalized privileges directly granted to role
+// on every one of the objects, and whether all of them can be granted further.
+// Privileges inherited through role membership are ignored.
+func grantedPrivileges(objectType string, rows []grantRow, role string, objects []string) ([]string, bool) {
+	withGrantOption := true
+
+	var privileges []string
+	for i, object := range objects {
+		var granted []string
+		for _, row := range rows {
+			if !roleNamesEqual(row.grantee, role) || !grantObjectMatches(object, row.object) {
+				continue
+			}
+			granted = append(granted, row.privilege)
+			withGrantOption = withGrantOption && row.isGrantable
+		}
+
+		held := expandPrivileges(objectType, granted)
+		if i == 0 {
+			privileges = held
+			continue
+		}
+		privileges = intersectPrivileges(privileges, held)
+	}
+
+	if len(privileges) == 0 {
+		return nil, false
+	}
+
+	return normalizePrivileges(objectType, privileges), withGrantOption
+}
+
+func grantPrivilegesTo(ctx context.Context, conn *pgx.Conn, target string, role string, privileges []string, withGrantOption bool) error {
+	_, err := conn.Exec(ctx, grantStatement(target, role, privileges, withGrantOption))
+	return err
+}
+
+func revokePrivilegesFrom(ctx context.Context, conn *pgx.Conn, target string, role string, privileges []string) error {
+	_, err := conn.Exec(ctx, revokeStatement(target, role, privileges))
+	return err
+}
+
+func grantStatement(target string, role string, privileges []string, withGrantOption bool) string {
+	statement := `GRANT ` + strings.Join(privileges, ", ") + ` ON ` + target + ` TO ` + pq.QuoteIdentifier(role)
+	if withGrantOption {
+		statement += ` WITH GRANT OPTION`
+	}
+
+	return statement
+}
+
+func revokeStatement(target string, role string, privileges []string) string {
+	return `REVOKE ` + strings.Join(privileges, ", ") + ` ON ` + target + ` FROM ` + pq.QuoteIdentifier(role)
+}
+
+func revokeGrantOptionStatement(target string, role string, privileges []string) string {
+	return `REVOKE GRANT OPTION FOR ` + strings.Join(privileges, ", ") + ` ON ` + target + ` FROM ` + pq.QuoteIdentifier(role)
+}
+
+// grantTarget returns the object part of a GRANT statement.
+func grantTarget(objectType string, database string, schemaName string, objects []string) string {
+	switch objectType {
+	case grantObjectSchema:
+		return `SCHEMA ` + pq.QuoteIdentifier(database) + `.` + pq.QuoteIdentifier(schemaName)
+	case grantObjectTable:
+		return `TABLE ` + qualifiedNames(database, schemaName, objects)
+	case grantObjectType:
+		return `TYPE ` + qualifiedNames(database, schemaName, objects)
+	case grantObjectFunction:
+		names := make([]string, len(objects))
+		for i, object := range objects {
+			// the argument types of the function are kept as written
+			name, args := splitFunctionSignature(object)
+			names[i] = pq.QuoteIdentifier(database) + `.` + pq.QuoteIdentifier(schemaName) + `.` + pq.QuoteIdentifier(name) + args
+		}
+		sort.Strings(names)
+		return `FUNCTION ` + strings.Join(names, ", ")
+	case grantObjectExternalConnection:
+		names := make([]string, len(objects))
+		for i, object := range objects {
+			names[i] = pq.QuoteIdentifier(object)
+		}
+		sort.Strings(names)
+		return `EXTERNAL CONNECTION ` + strings.Join(names, ", ")
+	default:
+		return `DATABASE ` + pq.QuoteIdentifier(database)
+	}
+}
+
+func qualifiedNames(database string, schemaName string, objects []string) string {
+	names := make([]string, len(objects))
+	for i, object := range objects {
+		names[i] = pq.QuoteIdentifier(database) + `.` + pq.QuoteIdentifier(schemaName) + `.` + pq.QuoteIdentifier(object)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// splitFunctionSignature splits add(INT8, INT8) into add and (INT8, INT8).
+func splitFunctionSignature(signature string) (string, string) {
+	if i := strings.Index(signature, "("); i >= 0 {
+		return strings.TrimSpace(signature[:i]), signature[i:]
+	}
+
+	return signature, ""
+}
+
+// grantObjectMatches reports whether a name reported by SHOW GRANTS designates
+// the configured object. Functions are reported with their signature, which
+// only has to match when the configured object has one.
+func grantObjectMatches(object string, reported string) bool {
+	if object == reported {
+		return true
+	}
+
+	name, args := splitFunctionSignature(object)
+	reportedName, reportedArgs := splitFunctionSignature(reported)
+	if name != reportedName {
+		return false
+	}
+
+	normalize := func(args string) string {
+		return strings.ToLower(strings.Join(strings.Fields(args), ""))
+	}
+
+	return args == "" || normalize(args) == normalize(reportedArgs)
+}
+
+// splitGrantObjects splits the comma separated objects of a grant id, ignoring
+// the commas between the argument types of functions.
+func splitGrantObjects(s string) []string {
+	var objects []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				objects = append(objects, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(objects, s[start:])
+}
+
+// grantLockKeys returns the keys locking the objects of the grant.
+func grantLockKeys(objectType string, database string, schemaName string, objects []string) []string {
+	switch objectType {
+	case grantObjectDatabase:
+		return []string{databaseLockKey(database)}
+	case grantObjectSchema:
+		return []string{objectLockKey(objectType, database, schemaName, "")}
+	}
+
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = objectLockKey(objectType, database, schemaName, object)
+	}
+
+	return keys
+}
+
+// grantObjectNames returns the names under which SHOW GRANTS reports the objects.
+func grantObjectNames(objectType string, database string, schemaName string, objects []string) []string {
+	switch objectType {
+	case grantObjectSchema:
+		return []string{schemaName}
+	case grantObjectDatabase:
+		return []string{database}
+	default:
+		return objects
+	}
+}
+
+// grantRequiresObjects reports whether the objects of the grant must be listed,
+// the database and schema grants being on the database and schema themselves.
+func grantRequiresObjects(objectType string) bool {
+	return objectType != grantObjectDatabase && objectType != grantObjectSchema
+}
+
+func grantID(role string, database string, objectType string, schemaName string, objects []string) string {
+	parts := []string{role, database, objectType}
+	if objectType != grantObjectDatabase {
+		if objectType == grantObjectExternalConnection {
+			schemaName = ""
+		}
+		parts = append(parts, schemaName)
+	}
+	if len(objects) != 0 {
+		sorted := append([]string{}, objects...)
+		sort.Strings(sorted)
+		parts = append(parts, strings.Join(sorted, ","))
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func validateGrant(role string, database string, objectType string, objects []string, privileges []string) error {
+	if role == "" {
+		return fmt.Errorf("role can't be an empty string")
+	}
+
+	if objectType != grantObjectExternalConnection && database == "" {
+		return fmt.Errorf("%s must be set for %s grants", grantDatabaseAttr, objectType)
+	}
+
+	if objectType == grantObjectExternalConnection && database != "" {
+		return fmt.Errorf("%s can't be set for %s grants", grantDatabaseAttr, objectType)
+	}
+
+	if grantRequiresObjects(objectType) && len(objects) == 0 {
+		return fmt.Errorf("%s must be set for %s grants", grantObjectsAttr, objectType)
+	}
+
+	if !grantRequiresObjects(objectType) && len(objects) != 0 {
+		return fmt.Errorf("%s can't be set for %s grants", grantObjectsAttr, objectType)
+	}
+
+	for _, privilege := range privileges {
+		privilege = strings.ToUpper(privilege)
+		if privilege != privilegeAll && !contains(grantPrivileges[objectType], privilege) {
+			return fmt.Errorf("privilege %s can't be granted on a %s", privilege, objectType)
+		}
+	}
+
+	return nil
+}
+
+// normalizePrivileges upper-cases, dedupes and sorts the privileges, collapsing
+// them to ALL when they cover every privilege of the object type.
+func normalizePrivileges(objectType string, privileges []string) []string {
+	expanded := expandPrivileges(objectType, privileges)
+	if len(expanded) != 0 && len(subtractPrivileges(grantPrivileges[objectType], expanded)) == 0 {
+		return []string{privilegeAll}
+	}
+
+	return expanded
+}
+
+// expandPrivileges upper-cases, dedupes and sorts the privileges, replacing ALL
+// with every privilege of the object type.
+func expandPrivileges(objectType string, privileges []string) []string {
+	expanded := []string{}
+	for _, privilege := range privileges {
+		privilege = strings.ToUpper(strings.TrimSpace(privilege))
+		if privilege == privilegeAll {
+			return append(expanded[:0], grantPrivileges[objectType]...)
+		}
+		if !contains(expanded, privilege) {
+			expanded = append(expanded, privilege)
+		}
+	}
+	sort.Strings(expanded)
+
+	return expanded
+}
+
+func privilegesEqual(objectType string, a []string, b []string) bool {
+	return strings.Join(normalizePrivileges(objectType, a), ",") == strings.Join(normalizePrivileges(objectType, b), ",")
+}
+
+func subtractPrivileges(a []string, b []string) []string {
+	result := []string{}
+	for _, privilege := range a {
+		if !contains(b, privilege) {
+			result = append(result, privilege)
+		}
+	}
+
+	return result
+}
+
+func intersectPrivileges(a []string, b []string) []string {
+	result := []string{}
+	for _, privilege := range a {
+		if contains(b, privilege) {
+			result = append(result, privilege)
+		}
+	}
+
+	return result
+}
+
+func hashPrivilege(v interface{}) int {
+	return schema.HashString(strings.ToUpper(strings.TrimSpace(v.(string))))
+}
+
+func grantObjectTypes() []string {
+	objectTypes := make([]string, 0, len(grantPrivileges))
+	for objectType := range grantPrivileges {
+		objectTypes = append(objectTypes, objectType)
+	}
+	sort.Strings(objectTypes)
+
+	return objectTypes
+}
+
+func allPrivileges() []string {
+	privileges := []string{privilegeAll}
+	for _, objectType := range grantObjectTypes() {
+		for _, privilege := range grantPrivileges[objectType] {
+			if !contains(privileges, privilege) {
+				privileges = append(privileges, privilege)
+			}
+		}
+	}
+
+	return privileges
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceGrant(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_grant.foo", "id", regexp.MustCompile("^bar/foo/database$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_grant.foo", "privileges.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestNormalizePrivileges(t *testing.T) {
+	cases := []struct {
+		objectType string
+		privileges []string
+		expected   []string
+	}{
+		{grantObjectDatabase, []string{"connect", "CREATE", "CONNECT"}, []string{"CONNECT", "CREATE"}},
+		{grantObjectDatabase, []string{"all"}, []string{"ALL"}},
+		{grantObjectSchema, []string{"USAGE", "CREATE"}, []string{"ALL"}},
+		{grantObjectTable, []string{"UPDATE", "SELECT", "ALL"}, []string{"ALL"}},
+		{grantObjectTable, []string{"UPDATE", "SELECT"}, []string{"SELECT", "UPDATE"}},
+	}
+
+	for _, c := range cases {
+		if actual := normalizePrivileges(c.objectType, c.privileges); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("normalizePrivileges(%s, %v) = %v, expected %v", c.objectType, c.privileges, actual, c.expected)
+		}
+	}
+}
+
+func TestGrantedPrivileges(t *testing.T) {
+	rows := []grantRow{
+		{object: "t1", grantee: "bar", privilege: "SELECT", isGrantable: true},
+		{object: "t1", grantee: "bar", privilege: "INSERT", isGrantable: true},
+		{object: "t2", grantee: "bar", privilege: "SELECT", isGrantable: true},
+		{object: "t2", grantee: "admin", privilege: "ALL", isGrantable: true},
+	}
+
+	privileges, withGrantOption := grantedPrivileges(grantObjectTable, rows, "bar", []string{"t1", "t2"})
+	if !reflect.DeepEqual(privileges, []string{"SELECT"}) {
+		t.Errorf("expected only SELECT to be held on every table, got %v", privileges)
+	}
+	if !withGrantOption {
+		t.Errorf("expected the grant option to be reported")
+	}
+
+	if privileges, _ := grantedPrivileges(grantObjectTable, rows, "bar", []string{"t3"}); len(privileges) != 0 {
+		t.Errorf("expected no privileges on a table without grants, got %v", privileges)
+	}
+}
+
+func TestGrantUpdateStatements(t *testing.T) {
+	cases := []struct {
+		o, n                           []string
+		oldGrantOption, newGrantOption bool
+		expected                       []string
+	}{
+		{[]string{"SELECT"}, []string{"SELECT", "INSERT"}, false, false, []string{
+			`GRANT INSERT ON TABLE t TO "bar"`,
+		}},
+		{[]string{"SELECT", "INSERT"}, []string{"SELECT"}, true, true, []string{
+			`REVOKE INSERT ON TABLE t FROM "bar"`,
+		}},
+		{[]string{"SELECT", "INSERT"}, []string{"SELECT", "UPDATE"}, true, false, []string{
+			`REVOKE INSERT ON TABLE t FROM "bar"`,
+			`REVOKE GRANT OPTION FOR SELECT ON TABLE t FROM "bar"`,
+			`GRANT UPDATE ON TABLE t TO "bar"`,
+		}},
+		{[]string{"SELECT"}, []string{"SELECT", "INSERT"}, false, true, []string{
+			`GRANT INSERT, SELECT ON TABLE t TO "bar" WITH GRANT OPTION`,
+		}},
+	}
+
+	for _, c := range cases {
+		actual := grantUpdateStatements(grantObjectTable, "TABLE t", "bar", c.o, c.n, c.oldGrantOption, c.newGrantOption)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("unexpected statements from %v (%v) to %v (%v)\n%q\nexpected\n%q", c.o, c.oldGrantOption, c.n, c.newGrantOption, actual, c.expected)
+		}
+	}
+}
+
+func TestGrantObjectMatches(t *testing.T) {
+	cases := []struct {
+		object   string
+		reported string
+		expected bool
+	}{
+		{"orders", "orders", true},
+		{"orders", "customers", false},
+		{"add", "add(int8, int8)", true},
+		{"add(INT8, INT8)", "add(int8,int8)", true},
+		{"add(INT8)", "add(int8, int8)", false},
+	}
+
+	for _, c := range cases {
+		if actual := grantObjectMatches(c.object, c.reported); actual != c.expected {
+			t.Errorf("grantObjectMatches(%q, %q) = %v, expected %v", c.object, c.reported, actual, c.expected)
+		}
+	}
+}
+
+func TestSplitGrantObjects(t *testing.T) {
+	objects := splitGrantObjects("add(INT8, INT8),orders")
+	if !reflect.DeepEqual(objects, []string{"add(INT8, INT8)", "orders"}) {
+		t.Errorf("unexpected objects %v", objects)
+	}
+}
+
+const testAccResourceGrant = `
+resource "cockroach_user" "bar" {
+  username   = "bar"
+  password   = "bar123"
+  local_port = "23244"
+}
+
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_grant" "foo" {
+  role        = cockroach_user.bar.username
+  database    = cockroach_database.foo.name
+  object_type = "database"
+  privileges  = ["CONNECT", "create"]
+}
+`
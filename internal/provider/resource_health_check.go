@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	healthCheckRequireReadyAttr       = "require_ready"
+	healthCheckMaxDeadNodesAttr       = "max_dead_nodes"
+	healthCheckMaxUnavailableAttr     = "max_unavailable_ranges"
+	healthCheckMaxUnderreplicatedAttr = "max_underreplicated_ranges"
+	healthCheckMaxCPUPercentAttr      = "max_cpu_percent"
+	healthCheckMaxMetricValuesAttr    = "max_metric_values"
+	healthCheckTriggersAttr           = "triggers"
+	healthCheckPassedAttr             = "passed"
+	healthCheckViolationsAttr         = "violations"
+
+	// healthCheckCPUMetric is the CPU usage of a node, of every process of the
+	// host, between 0 and 1
+	healthCheckCPUMetric = "sys.cpu.combined.percent-normalized"
+)
+
+// healthCheckSchema returns the assertions of the health check, checked again
+// when changed when forceNew is set.
+func healthCheckSchema(forceNew bool) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		healthCheckRequireReadyAttr: {
+			Description: "Whether the node served by the HTTP API must be ready to accept SQL connections.",
+			Type:        schema.TypeBool,
+			Optional:    true,
+			ForceNew:    forceNew,
+			Default:     true,
+		},
+		healthCheckMaxDeadNodesAttr: {
+			Description:  "Maximum number of dead nodes, -1 not to check them. The decommissioned nodes aren't counted.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     forceNew,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+		healthCheckMaxUnavailableAttr: {
+			Description:  "Maximum number of ranges without a quorum of live replicas, -1 not to check them.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     forceNew,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+		healthCheckMaxUnderreplicatedAttr: {
+			Description:  "Maximum number of ranges with fewer replicas than configured, -1 not to check them.",
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     forceNew,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+		healthCheckMaxCPUPercentAttr: {
+			Description:  "Maximum CPU usage of every live node, in percent, from the `" + healthCheckCPUMetric + "` metric. The CPU isn't checked when not set.",
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			ForceNew:     forceNew,
+			ValidateFunc: validation.FloatBetween(0, 100),
+		},
+		healthCheckMaxMetricValuesAttr: {
+			Description: "Maximum values of metrics of the node served by the HTTP API, by name as exported in the Prometheus format on `/_status/vars`, e.g. `sql_conns`. The maximum of the series of a metric is checked.",
+			Type:        schema.TypeMap,
+			Optional:    true,
+			ForceNew:    forceNew,
+			Elem: &schema.Schema{
+				Type: schema.TypeFloat,
+			},
+		},
+		healthCheckPassedAttr: {
+			Description: "True when every assertion holds.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		healthCheckViolationsAttr: {
+			Description: "Assertions not holding, empty when the check passed.",
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+}
+
+func resourceHealthCheck() *schema.Resource {
+	s := healthCheckSchema(true)
+	s[healthCheckTriggersAttr] = &schema.Schema{
+		Description: "Arbitrary values which run the check again when changed, e.g. the version of the Helm release.",
+		Type:        schema.TypeMap,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		Optional: true,
+		ForceNew: true,
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource checking, when created, that the CockroachDB cluster is healthy over the HTTP API configured with the http_api block of the provider, " +
+			"retrying until the create timeout and failing the apply when an assertion doesn't hold. Resources depending on it are only applied once the cluster is healthy, as a canary gate.",
+
+		CreateContext: resourceHealthCheckCreate,
+		ReadContext:   resourceHealthCheckRead,
+		DeleteContext: resourceHealthCheckDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: s,
+	}
+}
+
+func resourceHealthCheckCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := newHealthCheckConfig(d)
+
+	var violations []string
+	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		var err error
+		violations, err = runHealthCheck(ctx, meta, config)
+		if err != nil {
+			logDebug("failed to check the health of the cluster: %v", err)
+			return resource.RetryableError(err)
+		}
+
+		if len(violations) > 0 {
+			logDebug("cluster is not healthy yet: %s", strings.Join(violations, ", "))
+			return resource.RetryableError(fmt.Errorf("%s", strings.Join(violations, ", ")))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return diag.Errorf("health check failed: %v", err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().UnixNano(), 10))
+	values := map[string]interface{}{
+		healthCheckPassedAttr:     true,
+		healthCheckViolationsAttr: []string{},
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceHealthCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the check only happens when the resource is created, nothing to refresh
+	return diag.Diagnostics{}
+}
+
+func resourceHealthCheckDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+// healthCheckConfig are the assertions of a health check, a negative maximum
+// not being checked.
+type healthCheckConfig struct {
+	requireReady       bool
+	maxDeadNodes       int
+	maxUnavailable     int
+	maxUnderreplicated int
+	maxCPUPercent      float64
+	maxMetricValues    map[string]float64
+}
+
+func newHealthCheckConfig(d *schema.ResourceData) healthCheckConfig {
+	config := healthCheckConfig{
+		requireReady:       d.Get(healthCheckRequireReadyAttr).(bool),
+		maxDeadNodes:       d.Get(healthCheckMaxDeadNodesAttr).(int),
+		maxUnavailable:     d.Get(healthCheckMaxUnavailableAttr).(int),
+		maxUnderreplicated: d.Get(healthCheckMaxUnderreplicatedAttr).(int),
+		maxCPUPercent:      -1,
+		maxMetricValues:    map[string]float64{},
+	}
+	if v, ok := d.GetOk(healthCheckMaxCPUPercentAttr); ok {
+		config.maxCPUPercent = v.(float64)
+	}
+	for name, v := range d.Get(healthCheckMaxMetricValuesAttr).(map[string]interface{}) {
+		config.maxMetricValues[name] = v.(float64)
+	}
+
+	return config
+}
+
+// runHealthCheck reads the health of the cluster over the HTTP API and
+// returns the assertions not holding.
+func runHealthCheck(ctx context.Context, meta interface{}, config healthCheckConfig) ([]string, error) {
+	client, closeClient, diags := openAdminClient(ctx, meta)
+	if diags.HasError() {
+		return nil, diagnosticsError(diags)
+	}
+	defer closeClient()
+
+	ready, err := client.ready(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := client.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := client.problemRanges(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]float64{}
+	if len(config.maxMetricValues) > 0 {
+		if vars, err = client.vars(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return healthCheckViolations(config, ready, nodes, problems, vars), nil
+}
+
+func healthCheckViolations(config healthCheckConfig, ready bool, nodes []httpAPINode, problems map[string]httpAPIProblems, vars map[string]float64) []string {
+	violations := []string{}
+
+	if config.requireReady && !ready {
+		violations = append(violations, "the node served by the HTTP API isn't ready")
+	}
+
+	var dead []string
+	for _, node := range nodes {
+		switch node.LivenessStatus {
+		case "dead":
+			dead = append(dead, fmt.Sprintf("n%d", node.NodeID))
+		case "live":
+			if cpu, ok := node.Metrics[healthCheckCPUMetric]; ok && config.maxCPUPercent >= 0 && cpu*100 > config.maxCPUPercent {
+				violations = append(violations, fmt.Sprintf("n%d uses %.1f%% of its CPU, more than %g%%", node.NodeID, cpu*100, config.maxCPUPercent))
+			}
+		}
+	}
+	if config.maxDeadNodes >= 0 && len(dead) > config.maxDeadNodes {
+		violations = append(violations, fmt.Sprintf("%d dead nodes (%s), more than %d", len(dead), strings.Join(dead, ", "), config.maxDeadNodes))
+	}
+
+	ranges := problemRangesToMap(problems)
+	if unavailable := len(ranges[problemRangesUnavailableAttr].([]int)); config.maxUnavailable >= 0 && unavailable > config.maxUnavailable {
+		violations = append(violations, fmt.Sprintf("%d unavailable ranges, more than %d", unavailable, config.maxUnavailable))
+	}
+	if underreplicated := len(ranges[problemRangesUnderreplicatedAttr].([]int)); config.maxUnderreplicated >= 0 && underreplicated > config.maxUnderreplicated {
+		violations = append(violations, fmt.Sprintf("%d underreplicated ranges, more than %d", underreplicated, config.maxUnderreplicated))
+	}
+
+	names := make([]string, 0, len(config.maxMetricValues))
+	for name := range config.maxMetricValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := vars[name]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("metric %s not found", name))
+		} else if value > config.maxMetricValues[name] {
+			violations = append(violations, fmt.Sprintf("metric %s is %g, more than %g", name, value, config.maxMetricValues[name]))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHealthCheckViolations(t *testing.T) {
+	nodes := []httpAPINode{
+		{NodeID: 1, LivenessStatus: "live", Metrics: map[string]float64{healthCheckCPUMetric: 0.95}},
+		{NodeID: 2, LivenessStatus: "live", Metrics: map[string]float64{healthCheckCPUMetric: 0.4}},
+		{NodeID: 3, LivenessStatus: "dead"},
+		{NodeID: 4, LivenessStatus: "decommissioned"},
+	}
+	problems := map[string]httpAPIProblems{"1": {Underreplicated: []jsonInt64{8}}}
+	vars := map[string]float64{"sql_conns": 120}
+
+	config := healthCheckConfig{
+		requireReady:       true,
+		maxDeadNodes:       0,
+		maxUnavailable:     0,
+		maxUnderreplicated: 0,
+		maxCPUPercent:      80,
+		maxMetricValues:    map[string]float64{"sql_conns": 100, "missing": 1},
+	}
+	expected := []string{
+		"the node served by the HTTP API isn't ready",
+		"n1 uses 95.0% of its CPU, more than 80%",
+		"1 dead nodes (n3), more than 0",
+		"1 underreplicated ranges, more than 0",
+		"metric missing not found",
+		"metric sql_conns is 120, more than 100",
+	}
+	if violations := healthCheckViolations(config, false, nodes, problems, vars); !reflect.DeepEqual(violations, expected) {
+		t.Errorf("unexpected violations %q", violations)
+	}
+
+	relaxed := healthCheckConfig{
+		maxDeadNodes:       1,
+		maxUnavailable:     -1,
+		maxUnderreplicated: -1,
+		maxCPUPercent:      -1,
+	}
+	if violations := healthCheckViolations(relaxed, false, nodes, problems, vars); len(violations) != 0 {
+		t.Errorf("unexpected violations %q", violations)
+	}
+}
+
+func TestParsePrometheusVars(t *testing.T) {
+	vars := parsePrometheusVars(`# HELP sql_conns Number of open SQL connections
+# TYPE sql_conns gauge
+sql_conns 12
+capacity_available{store="1"} 100
+capacity_available{store="2",label="a b}"} 300
+liveness_heartbeatlatency_bucket{le="+Inf"} 4 1700000000000
+invalid_value NaNx
+`)
+
+	expected := map[string]float64{
+		"sql_conns":                        12,
+		"capacity_available":               300,
+		"liveness_heartbeatlatency_bucket": 4,
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("unexpected vars %v", vars)
+	}
+}
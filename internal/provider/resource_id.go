@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+)
+
+// The resources of the objects of a database have ids scoped to the cluster
+// they were created in, e.g. <cluster id>:<database>:<schema>:<table>, so
+// the same configuration applied to another cluster, e.g. restored from a
+// backup, plans to create the objects again rather than adopting them. The
+// parts are escaped so they can hold colons. The ids of the databases, users,
+// grants, changefeeds, table partitionings and splits aren't scoped to the
+// cluster, those objects being adopted, as stated in their documentation.
+//
+// The ids of version 0 of the schemas were database/schema/table, they are
+// upgraded with an empty cluster id, bound to the cluster on the next read.
+const clusterScopedIDSchemaVersion = 1
+
+var clusterScopedIDEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+// clusterScopedID returns the id of an object of the cluster.
+func clusterScopedID(clusterID string, parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = clusterScopedIDEscaper.Replace(part)
+	}
+
+	return clusterID + ":" + strings.Join(escaped, ":")
+}
+
+// parseResourceID returns the cluster id and the n parts of a cluster scoped
+// id, or the parts of a database/schema/object id, as taken by the importers,
+// the cluster id being empty then.
+func parseResourceID(id string, n int) (string, []string, error) {
+	if strings.Count(id, ":") != n {
+		parts := strings.Split(id, "/")
+		if len(parts) != n {
+			return "", nil, fmt.Errorf("expected %d parts", n)
+		}
+		return "", parts, nil
+	}
+
+	parts := strings.Split(id, ":")
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return "", nil, err
+		}
+		parts[i] = unescaped
+	}
+
+	return parts[0], parts[1:], nil
+}
+
+// readClusterID returns the id of the cluster, read once per provider.
+func (c *cockroachClient) readClusterID(ctx context.Context, conn *pgx.Conn) (string, error) {
+	c.clusterIDMu.Lock()
+	defer c.clusterIDMu.Unlock()
+
+	if c.clusterID == "" {
+		if err := conn.QueryRow(ctx, `SELECT crdb_internal.cluster_id()::STRING`).Scan(&c.clusterID); err != nil {
+			return "", fmt.Errorf("failed to read the cluster id: %w", err)
+		}
+	}
+
+	return c.clusterID, nil
+}
+
+// bindClusterScopedID sets the id of the resource to the one of the object in
+// the cluster of the connection, ok being false when the resource was created
+// in another cluster.
+func bindClusterScopedID(ctx context.Context, conn *pgx.Conn, d *schema.ResourceData, meta interface{}, parts ...string) (bool, error) {
+	clusterID, err := meta.(*cockroachClient).readClusterID(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	if current, _, err := parseResourceID(d.Id(), len(parts)); err == nil && current != "" && current != clusterID {
+		return false, nil
+	}
+	d.SetId(clusterScopedID(clusterID, parts...))
+
+	return true, nil
+}
+
+// withClusterScopedID upgrades the ids of the states of version 0 of the
+// resource, whose ids were the n parts of the object joined with slashes.
+func withClusterScopedID(r *schema.Resource, n int) *schema.Resource {
+	r.SchemaVersion = clusterScopedIDSchemaVersion
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+				return upgradeToClusterScopedID(rawState, n)
+			},
+		},
+	}
+
+	return r
+}
+
+func upgradeToClusterScopedID(rawState map[string]interface{}, n int) (map[string]interface{}, error) {
+	id, _ := rawState["id"].(string)
+	parts := strings.Split(id, "/")
+	if len(parts) != n {
+		return nil, fmt.Errorf("unexpected id %q, expected %d parts", id, n)
+	}
+	rawState["id"] = clusterScopedID("", parts...)
+
+	return rawState, nil
+}
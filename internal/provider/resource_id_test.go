@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterScopedID(t *testing.T) {
+	id := clusterScopedID("6d5ac3b8-2a67-4d2b-9d0e-7c4f3e2a1b90", "foo", "public", "odd:name%")
+	if id != "6d5ac3b8-2a67-4d2b-9d0e-7c4f3e2a1b90:foo:public:odd%3Aname%25" {
+		t.Errorf("unexpected id %q", id)
+	}
+
+	clusterID, parts, err := parseResourceID(id, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clusterID != "6d5ac3b8-2a67-4d2b-9d0e-7c4f3e2a1b90" || !reflect.DeepEqual(parts, []string{"foo", "public", "odd:name%"}) {
+		t.Errorf("unexpected cluster id %q and parts %v", clusterID, parts)
+	}
+}
+
+func TestParseResourceID(t *testing.T) {
+	clusterID, parts, err := parseResourceID("foo/public/orders", 3)
+	if err != nil || clusterID != "" || !reflect.DeepEqual(parts, []string{"foo", "public", "orders"}) {
+		t.Errorf("unexpected cluster id %q and parts %v: %v", clusterID, parts, err)
+	}
+
+	clusterID, parts, err = parseResourceID(":foo:public:orders", 3)
+	if err != nil || clusterID != "" || !reflect.DeepEqual(parts, []string{"foo", "public", "orders"}) {
+		t.Errorf("unexpected cluster id %q and parts %v: %v", clusterID, parts, err)
+	}
+
+	for _, id := range []string{"foo/orders", "foo:public:orders", "x:foo:public:orders:extra"} {
+		if _, _, err := parseResourceID(id, 3); err == nil {
+			t.Errorf("expected an error for %q", id)
+		}
+	}
+}
+
+func TestUpgradeToClusterScopedID(t *testing.T) {
+	state, err := upgradeToClusterScopedID(map[string]interface{}{"id": "foo/public/orders/orders_fk", "name": "orders_fk"}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state["id"] != ":foo:public:orders:orders_fk" || state["name"] != "orders_fk" {
+		t.Errorf("unexpected state %v", state)
+	}
+
+	if _, err := upgradeToClusterScopedID(map[string]interface{}{"id": "foo/orders"}, 3); err == nil {
+		t.Error("expected an error for an id with missing parts")
+	}
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	identityMapMappingAttr        = "mapping"
+	identityMapMapNameAttr        = "map_name"
+	identityMapSystemIdentityAttr = "system_identity"
+	identityMapDatabaseUserAttr   = "database_user"
+
+	identityMapSetting          = "server.identity_map.configuration"
+	identityMapID               = "identity_map"
+	identityMapDefaultLocalPort = "26296"
+)
+
+var identityMapNameRegexp = regexp.MustCompile(`^[^\s"#]+$`)
+
+func resourceIdentityMap() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to manage the identity map of a CockroachDB cluster, mapping the identities authenticated by GSSAPI, certificates or JWT to database users, " +
+			"as the `" + identityMapSetting + "` cluster setting. The maps are used by the `map` option of the host-based authentication configuration.",
+
+		CreateContext: resourceIdentityMapCreate,
+		ReadContext:   resourceIdentityMapRead,
+		UpdateContext: resourceIdentityMapUpdate,
+		DeleteContext: resourceIdentityMapDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceIdentityMapImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			identityMapMappingAttr: {
+				Description: "Mappings of the identity map, in the order they are matched.",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						identityMapMapNameAttr: {
+							Description:  "Name of the map, referred to by the `map` option of the host-based authentication configuration.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringMatch(identityMapNameRegexp, "must not contain spaces, quotes or #"),
+						},
+						identityMapSystemIdentityAttr: {
+							Description:  "Identity authenticated by the system, e.g. `carl@EXAMPLE.COM`, or a regular expression starting with `/` whose first capture group can be used as `\\1` in the database user, e.g. `/^(.*)@example\\.com$`.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						identityMapDatabaseUserAttr: {
+							Description:  "Database user the identity is mapped to.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + identityMapDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     identityMapDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// identityMapping is a line of the identity map.
+type identityMapping struct {
+	mapName        string
+	systemIdentity string
+	databaseUser   string
+}
+
+// formatIdentityMap returns the value of the identity map setting, a mapping
+// by line in the pg_ident.conf format.
+func formatIdentityMap(mappings []identityMapping) string {
+	lines := make([]string, 0, len(mappings))
+	for _, m := range mappings {
+		lines = append(lines, strings.Join([]string{
+			quoteIdentityMapToken(m.mapName),
+			quoteIdentityMapToken(m.systemIdentity),
+			quoteIdentityMapToken(m.databaseUser),
+		}, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func quoteIdentityMapToken(token string) string {
+	if strings.ContainsAny(token, " \t\"#") {
+		return `"` + strings.ReplaceAll(token, `"`, `""`) + `"`
+	}
+
+	return token
+}
+
+// parseIdentityMap parses the value of the identity map setting, ignoring the
+// empty lines and the comments.
+func parseIdentityMap(value string) ([]identityMapping, error) {
+	mappings := []identityMapping{}
+	for i, line := range strings.Split(value, "\n") {
+		tokens, err := identityMapTokens(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) != 3 {
+			return nil, fmt.Errorf("line %d: expected a map name, a system identity and a database user, got %q", i+1, line)
+		}
+
+		mappings = append(mappings, identityMapping{
+			mapName:        tokens[0],
+			systemIdentity: tokens[1],
+			databaseUser:   tokens[2],
+		})
+	}
+
+	return mappings, nil
+}
+
+// identityMapTokens splits a line of the identity map on spaces, a token being
+// quoted with double quotes, doubled within it, when it contains spaces.
+func identityMapTokens(line string) ([]string, error) {
+	var tokens []string
+	for {
+		line = strings.TrimLeft(line, " \t\r")
+		if line == "" || line[0] == '#' {
+			return tokens, nil
+		}
+
+		if line[0] != '"' {
+			end := strings.IndexAny(line, " \t\r#")
+			if end < 0 {
+				end = len(line)
+			}
+			tokens = append(tokens, line[:end])
+			line = line[end:]
+			continue
+		}
+
+		var token strings.Builder
+		i := 1
+		for {
+			if i >= len(line) {
+				return nil, fmt.Errorf("unterminated quoted token")
+			}
+			if line[i] == '"' {
+				if i+1 < len(line) && line[i+1] == '"' {
+					token.WriteByte('"')
+					i += 2
+					continue
+				}
+				break
+			}
+			token.WriteByte(line[i])
+			i++
+		}
+		tokens = append(tokens, token.String())
+		line = line[i+1:]
+	}
+}
+
+func identityMappingsOf(d *schema.ResourceData) []identityMapping {
+	var mappings []identityMapping
+	for _, raw := range d.Get(identityMapMappingAttr).([]interface{}) {
+		m := raw.(map[string]interface{})
+		mappings = append(mappings, identityMapping{
+			mapName:        m[identityMapMapNameAttr].(string),
+			systemIdentity: m[identityMapSystemIdentityAttr].(string),
+			databaseUser:   m[identityMapDatabaseUserAttr].(string),
+		})
+	}
+
+	return mappings
+}
+
+func applyIdentityMap(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	if err := setClusterSetting(ctx, conn, identityMapSetting, formatIdentityMap(identityMappingsOf(d))); err != nil {
+		return diag.Errorf("failed to set %s: %v", identityMapSetting, err)
+	}
+
+	return nil
+}
+
+func resourceIdentityMapCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyIdentityMap(ctx, d, meta); diags != nil {
+		return diags
+	}
+	d.SetId(identityMapID)
+
+	return resourceIdentityMapRead(ctx, d, meta)
+}
+
+func resourceIdentityMapRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	current, err := readClusterSettings(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	mappings, err := parseIdentityMap(current[identityMapSetting].value)
+	if err != nil {
+		return diag.Errorf("unexpected value of %s: %v", identityMapSetting, err)
+	}
+
+	values := make([]interface{}, 0, len(mappings))
+	for _, m := range mappings {
+		values = append(values, map[string]interface{}{
+			identityMapMapNameAttr:        m.mapName,
+			identityMapSystemIdentityAttr: m.systemIdentity,
+			identityMapDatabaseUserAttr:   m.databaseUser,
+		})
+	}
+	if err := d.Set(identityMapMappingAttr, values); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceIdentityMapUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.HasChange(identityMapMappingAttr) {
+		if diags := applyIdentityMap(ctx, d, meta); diags != nil {
+			return diags
+		}
+	}
+
+	return resourceIdentityMapRead(ctx, d, meta)
+}
+
+func resourceIdentityMapDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	if err := resetClusterSetting(ctx, conn, identityMapSetting); err != nil {
+		return diag.Errorf("failed to reset %s: %v", identityMapSetting, err)
+	}
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceIdentityMapImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if d.Id() != identityMapID {
+		return nil, fmt.Errorf("invalid id %q, expected %s", d.Id(), identityMapID)
+	}
+	if err := d.Set(argLocalPort, identityMapDefaultLocalPort); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentityMapRoundTrip(t *testing.T) {
+	mappings := []identityMapping{
+		{"kerberos", "carl@EXAMPLE.COM", "carl"},
+		{"kerberos", `/^(.*)@example\.com$`, `\1`},
+		{"certs", "CN=Jane Doe", `"jane"`},
+	}
+
+	value := formatIdentityMap(mappings)
+	expected := "kerberos carl@EXAMPLE.COM carl\n" +
+		"kerberos /^(.*)@example\\.com$ \\1\n" +
+		`certs "CN=Jane Doe" """jane"""`
+	if value != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, value)
+	}
+
+	parsed, err := parseIdentityMap(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, mappings) {
+		t.Errorf("expected %v, got %v", mappings, parsed)
+	}
+}
+
+func TestParseIdentityMap(t *testing.T) {
+	parsed, err := parseIdentityMap("# managed by hand\n\n  jwt  alice@example.com   alice # comment\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []identityMapping{{"jwt", "alice@example.com", "alice"}}; !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("expected %v, got %v", expected, parsed)
+	}
+
+	if parsed, err := parseIdentityMap(""); err != nil || len(parsed) != 0 {
+		t.Errorf("expected no mapping, got %v, %v", parsed, err)
+	}
+
+	for _, value := range []string{"jwt alice", `jwt "alice alice`} {
+		if _, err := parseIdentityMap(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	indexVisibilityDatabaseAttr = "database"
+	indexVisibilitySchemaAttr   = "schema"
+	indexVisibilityTableAttr    = "table"
+	indexVisibilityIndexAttr    = "index"
+	indexVisibilityVisibleAttr  = "visible"
+
+	indexVisibilityDefaultLocalPort = "26303"
+)
+
+func resourceIndexVisibility() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to make an index of a table of a CockroachDB cluster visible or not to the optimizer, without managing the index itself, " +
+			"e.g. to try out dropping an index and revert it. The index is made visible again when the resource is destroyed.",
+
+		CreateContext: resourceIndexVisibilityCreate,
+		ReadContext:   resourceIndexVisibilityRead,
+		UpdateContext: resourceIndexVisibilityUpdate,
+		DeleteContext: resourceIndexVisibilityDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceIndexVisibilityImporter,
+		},
+		CustomizeDiff: resourceIndexVisibilityCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			indexVisibilityDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			indexVisibilitySchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			indexVisibilityTableAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			indexVisibilityIndexAttr: {
+				Description: "Name of the index.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			indexVisibilityVisibleAttr: {
+				Description: "Whether the index is used by the optimizer, it is still maintained when not visible.",
+				Type:        schema.TypeBool,
+				Required:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + indexVisibilityDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     indexVisibilityDefaultLocalPort,
+			},
+		},
+	}, 4)
+}
+
+func resourceIndexVisibilityCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, indexVisibilityDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, indexVisibilitySchemaAttr)
+}
+
+// indexVisibilityStatement returns the statement making the index of the
+// table visible or not.
+func indexVisibilityStatement(table string, index string, visible bool) string {
+	statement := `ALTER INDEX ` + table + `@` + pq.QuoteIdentifier(index)
+	if !visible {
+		return statement + ` NOT VISIBLE`
+	}
+
+	return statement + ` VISIBLE`
+}
+
+func indexVisibilityTable(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(indexVisibilityDatabaseAttr).(string), d.Get(indexVisibilitySchemaAttr).(string), []string{d.Get(indexVisibilityTableAttr).(string)})
+}
+
+func applyIndexVisibility(ctx context.Context, d *schema.ResourceData, meta interface{}, visible bool) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("table", d.Get(indexVisibilityDatabaseAttr).(string), d.Get(indexVisibilitySchemaAttr).(string), d.Get(indexVisibilityTableAttr).(string)))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	statement := indexVisibilityStatement(indexVisibilityTable(d), d.Get(indexVisibilityIndexAttr).(string), visible)
+	if _, err := conn.Exec(ctx, statement); err != nil {
+		return diag.Errorf("failed to run %q: %v", statement, err)
+	}
+
+	return nil
+}
+
+func resourceIndexVisibilityCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(indexVisibilityDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", indexVisibilityDatabaseAttr)
+	}
+
+	if diags := applyIndexVisibility(ctx, d, meta, d.Get(indexVisibilityVisibleAttr).(bool)); diags != nil {
+		return diags
+	}
+
+	d.SetId(clusterScopedID("", database, d.Get(indexVisibilitySchemaAttr).(string), d.Get(indexVisibilityTableAttr).(string), d.Get(indexVisibilityIndexAttr).(string)))
+
+	return resourceIndexVisibilityRead(ctx, d, meta)
+}
+
+// readIndexVisibility reads whether the index of the table is visible, ok
+// being false when the table or the index doesn't exist.
+func readIndexVisibility(ctx context.Context, conn *pgx.Conn, table string, index string) (visible bool, ok bool, err error) {
+	err = conn.QueryRow(ctx,
+		`SELECT visible FROM [SHOW INDEXES FROM `+table+`] WHERE index_name = $1 LIMIT 1`, index).Scan(&visible)
+	var pgErr *pgconn.PgError
+	if err == pgx.ErrNoRows || errors.As(err, &pgErr) && contains(doesNotExistCodes, pgErr.Code) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	return visible, true, nil
+}
+
+func resourceIndexVisibilityRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	database, schemaName := d.Get(indexVisibilityDatabaseAttr).(string), d.Get(indexVisibilitySchemaAttr).(string)
+	table, index := d.Get(indexVisibilityTableAttr).(string), d.Get(indexVisibilityIndexAttr).(string)
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, database, schemaName, table, index)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("index %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	visible, ok, err := readIndexVisibility(ctx, conn, indexVisibilityTable(d), index)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("index %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := d.Set(indexVisibilityVisibleAttr, visible); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceIndexVisibilityUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.HasChange(indexVisibilityVisibleAttr) {
+		if diags := applyIndexVisibility(ctx, d, meta, d.Get(indexVisibilityVisibleAttr).(bool)); diags != nil {
+			return diags
+		}
+	}
+
+	return resourceIndexVisibilityRead(ctx, d, meta)
+}
+
+func resourceIndexVisibilityDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.Get(indexVisibilityVisibleAttr).(bool) {
+		if diags := applyIndexVisibility(ctx, d, meta, true); diags != nil {
+			return diags
+		}
+	}
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceIndexVisibilityImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table/index
+	_, parts, err := parseResourceID(d.Id(), 4)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index visibility id %q, expected database/schema/table/index: %v", d.Id(), err)
+	}
+
+	values := map[string]interface{}{
+		indexVisibilityDatabaseAttr: parts[0],
+		indexVisibilitySchemaAttr:   parts[1],
+		indexVisibilityTableAttr:    parts[2],
+		indexVisibilityIndexAttr:    parts[3],
+		argLocalPort:                indexVisibilityDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
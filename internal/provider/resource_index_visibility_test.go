@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceIndexVisibility(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceIndexVisibility("false"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_index_visibility.foo", "schema", "public"),
+					resource.TestCheckResourceAttr("cockroach_index_visibility.foo", "visible", "false"),
+				),
+			},
+			{
+				Config: testAccResourceIndexVisibility("true"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_index_visibility.foo", "visible", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestIndexVisibilityStatement(t *testing.T) {
+	for _, test := range []struct {
+		visible  bool
+		expected string
+	}{
+		{false, `ALTER INDEX foo.public.orders@"orders_quantity_idx" NOT VISIBLE`},
+		{true, `ALTER INDEX foo.public.orders@"orders_quantity_idx" VISIBLE`},
+	} {
+		if actual := indexVisibilityStatement("foo.public.orders", "orders_quantity_idx", test.visible); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func testAccResourceIndexVisibility(visible string) string {
+	return `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "foo" {
+  database = cockroach_database.foo.name
+  name     = "orders"
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+
+  column {
+    name = "quantity"
+    type = "INT"
+  }
+
+  primary_key = ["id"]
+
+  index {
+    name    = "orders_quantity_idx"
+    columns = ["quantity"]
+  }
+}
+
+resource "cockroach_index_visibility" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.foo.name
+  index    = "orders_quantity_idx"
+  visible  = ` + visible + `
+}
+`
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	initPodNameAttr   = "pod_name"
+	initContainerAttr = "container"
+	initCertsDirAttr  = "certs_dir"
+	initInsecureAttr  = "insecure"
+	initHostAttr      = "host"
+	initOutputAttr    = "output"
+)
+
+// alreadyInitializedMsg is part of the error of `cockroach init` when the
+// cluster was initialized before.
+const alreadyInitializedMsg = "cluster has already been initialized"
+
+func resourceInit() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource initializing a new CockroachDB cluster by running `cockroach init` in one of its pods. " +
+			"It requires the `kube_config` block of the provider. A cluster which is already initialized is left as is.",
+
+		CreateContext: resourceInitCreate,
+		ReadContext:   resourceInitRead,
+		DeleteContext: resourceInitDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			initPodNameAttr: {
+				Description: "Pod running `cockroach init`, a running pod of the service is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			initContainerAttr: {
+				Description: "Container of the pod running CockroachDB.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "db",
+			},
+			initCertsDirAttr: {
+				Description: "Directory of the certificates in the container, used for a secure cluster.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "/cockroach/cockroach-certs",
+			},
+			initInsecureAttr: {
+				Description: "Whether the cluster is insecure.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+			},
+			initHostAttr: {
+				Description: "Address of the node to initialize, as seen from the container.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "localhost",
+			},
+			initOutputAttr: {
+				Description: "Output of `cockroach init`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// initCommand returns the `cockroach init` command for the configuration.
+func initCommand(certsDir string, insecure bool, host string) []string {
+	command := []string{"cockroach", "init", fmt.Sprintf("--host=%s", host)}
+	if insecure {
+		return append(command, "--insecure")
+	}
+
+	return append(command, fmt.Sprintf("--certs-dir=%s", certsDir))
+}
+
+func resourceInitCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if kubeConn.kubeConfig == nil {
+		return diag.Errorf("the kube_config block of the provider is required to initialize the cluster")
+	}
+
+	container := d.Get(initContainerAttr).(string)
+	command := initCommand(d.Get(initCertsDirAttr).(string), d.Get(initInsecureAttr).(bool), d.Get(initHostAttr).(string))
+
+	var pod, output string
+	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		pod = d.Get(initPodNameAttr).(string)
+		if pod == "" {
+			var err error
+			if pod, err = findLivePod(ctx, kubeConn); err != nil {
+				return resource.RetryableError(err)
+			}
+		}
+
+		out, err := execInPod(kubeConn, pod, container, command, nil)
+		if err != nil && !strings.Contains(err.Error(), alreadyInitializedMsg) {
+			logDebug("failed to initialize the cluster from pod %s: %v", pod, err)
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			logInfo("cluster is already initialized")
+			out = alreadyInitializedMsg
+		}
+
+		output = strings.TrimSpace(out)
+		return nil
+	})
+	if err != nil {
+		return diag.Errorf("failed to initialize the cluster: %v", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", kubeConn.nameSpace, kubeConn.serviceName))
+	if err := d.Set(initPodNameAttr, pod); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(initOutputAttr, output); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceInitRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// a cluster can't be uninitialized, nothing to refresh
+	return diag.Diagnostics{}
+}
+
+func resourceInitDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
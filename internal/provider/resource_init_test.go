@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestInitCommand(t *testing.T) {
+	cases := []struct {
+		insecure bool
+		expected []string
+	}{
+		{false, []string{"cockroach", "init", "--host=localhost", "--certs-dir=/cockroach/cockroach-certs"}},
+		{true, []string{"cockroach", "init", "--host=localhost", "--insecure"}},
+	}
+
+	for _, c := range cases {
+		if got := initCommand("/cockroach/cockroach-certs", c.insecure, "localhost"); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("initCommand(insecure=%t) = %v, expected %v", c.insecure, got, c.expected)
+		}
+	}
+}
+
+func TestAccResourceInit(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceInit,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"cockroach_init.foo", "pod_name"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceInit = `
+resource "cockroach_init" "foo" {
+  timeouts {
+    create = "2m"
+  }
+}
+`
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceLoggingConfig() *schema.Resource {
+	return resourceSettingGroup(settingGroup{
+		id: "logging_config",
+		description: "Resource used to manage what a CockroachDB cluster logs about the SQL statements, sessions and connections, " +
+			"such as the slow query log, the statement traces and the sampled telemetry events.",
+		localPort: "26286",
+		attributes: map[string]groupedSetting{
+			"slow_query_latency_threshold": {name: "sql.log.slow_query.latency_threshold", schema: &schema.Schema{
+				Description: "Latency above which the statements are logged to the `SQL_PERF` channel, e.g. `500ms`, `0s` disabling the slow query log.",
+				Type:        schema.TypeString,
+			}},
+			"slow_query_internal_queries_enabled": {name: "sql.log.slow_query.internal_queries.enabled", schema: &schema.Schema{
+				Description: "Whether the slow internal statements are logged to the `SQL_INTERNAL_PERF` channel.",
+				Type:        schema.TypeBool,
+			}},
+			"slow_query_full_table_scans_enabled": {name: "sql.log.slow_query.experimental_full_table_scans.enabled", schema: &schema.Schema{
+				Description: "Whether the statements scanning full tables are logged along with the slow ones.",
+				Type:        schema.TypeBool,
+			}},
+			"admin_audit_enabled": {name: "sql.log.admin_audit.enabled", schema: &schema.Schema{
+				Description: "Whether the statements of the admin users are logged to the `SENSITIVE_ACCESS` channel.",
+				Type:        schema.TypeBool,
+			}},
+			"auth_log_connections_enabled": {name: "server.auth_log.sql_connections.enabled", schema: &schema.Schema{
+				Description: "Whether the SQL connections are logged to the `SESSIONS` channel.",
+				Type:        schema.TypeBool,
+			}},
+			"auth_log_sessions_enabled": {name: "server.auth_log.sql_sessions.enabled", schema: &schema.Schema{
+				Description: "Whether the authentications and the ends of the SQL sessions are logged to the `SESSIONS` channel.",
+				Type:        schema.TypeBool,
+			}},
+			"trace_log_statement_execute": {name: "sql.trace.log_statement_execute", schema: &schema.Schema{
+				Description: "Whether every executed statement is logged to the `SQL_EXEC` channel, which is costly.",
+				Type:        schema.TypeBool,
+			}},
+			"trace_statement_threshold": {name: "sql.trace.stmt.enable_threshold", schema: &schema.Schema{
+				Description: "Duration above which the traces of the statements are logged, e.g. `1s`, `0s` disabling them.",
+				Type:        schema.TypeString,
+			}},
+			"trace_transaction_threshold": {name: "sql.trace.txn.enable_threshold", schema: &schema.Schema{
+				Description: "Duration above which the traces of the transactions are logged, e.g. `1s`, `0s` disabling them.",
+				Type:        schema.TypeString,
+			}},
+			"trace_session_eventlog_enabled": {name: "sql.trace.session_eventlog.enabled", schema: &schema.Schema{
+				Description: "Whether the events of the SQL sessions are traced, which is costly.",
+				Type:        schema.TypeBool,
+			}},
+			"telemetry_query_sampling_enabled": {name: "sql.telemetry.query_sampling.enabled", schema: &schema.Schema{
+				Description: "Whether samples of the statements are logged to the `TELEMETRY` channel.",
+				Type:        schema.TypeBool,
+			}},
+			"telemetry_query_sampling_max_event_frequency": {name: "sql.telemetry.query_sampling.max_event_frequency", schema: &schema.Schema{
+				Description:  "Maximum number of statements logged to the `TELEMETRY` channel per second and node.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+			}},
+			"statement_details_dump_to_logs": {name: "sql.metrics.statement_details.dump_to_logs", schema: &schema.Schema{
+				Description: "Whether the statistics of the statements are logged when they are cleared.",
+				Type:        schema.TypeBool,
+			}},
+		},
+	})
+}
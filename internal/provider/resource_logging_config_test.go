@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceLoggingConfig(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceLoggingConfig("500ms"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_logging_config.foo", "slow_query_latency_threshold", "500ms"),
+					resource.TestCheckResourceAttr("cockroach_logging_config.foo", "auth_log_connections_enabled", "true"),
+				),
+			},
+			{
+				Config: testAccResourceLoggingConfig("1s"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_logging_config.foo", "slow_query_latency_threshold", "1s"),
+				),
+			},
+			{
+				ResourceName:      "cockroach_logging_config.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceLoggingConfig(threshold string) string {
+	return `
+resource "cockroach_logging_config" "foo" {
+  slow_query_latency_threshold = "` + threshold + `"
+  auth_log_connections_enabled = true
+}
+`
+}
@@ -0,0 +1,519 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgx"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	migrationDatabaseAttr        = "database"
+	migrationHistoryTableAttr    = "history_table"
+	migrationMigrationAttr       = "migration"
+	migrationVersionAttr         = "version"
+	migrationSQLAttr             = "sql"
+	migrationTransactionalAttr   = "transactional"
+	migrationVariablesAttr       = "variables"
+	migrationAppliedVersionsAttr = "applied_versions"
+	migrationOnChangeAttr        = "on_change"
+	migrationChecksumsAttr       = "checksums"
+
+	migrationOnChangeFail     = "fail"
+	migrationOnChangeRerun    = "rerun"
+	migrationOnChangeRecreate = "recreate"
+	migrationOnChangeIgnore   = "ignore"
+
+	migrationDefaultHistoryTable = "terraform_migrations"
+	migrationDefaultLocalPort    = "26299"
+)
+
+// migrationLockVersion is the version of the row of the history table the
+// applies lock to apply the migrations one at a time, which no migration has
+// since their versions can't be blank. Its checksum is the version of the
+// non-transactional migration being applied, if any.
+const migrationLockVersion = ""
+
+var migrationHistoryTableRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func resourceMigration() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to apply versioned SQL migrations to a CockroachDB cluster, each of them exactly once and in order. " +
+			"The applied versions are recorded in a history table, so the migrations added later are the only ones applied by the next apply. " +
+			"The applies sharing a history table apply the migrations one at a time, the ones applied meanwhile by another apply being skipped. " +
+			"The migrations aren't reverted when the resource is destroyed.",
+
+		CreateContext: resourceMigrationCreate,
+		ReadContext:   resourceMigrationRead,
+		UpdateContext: resourceMigrationUpdate,
+		DeleteContext: resourceMigrationDelete,
+		CustomizeDiff: resourceMigrationCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			migrationDatabaseAttr: {
+				Description: "Database the migrations and the history table are in, the database of the connection of the provider when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			migrationHistoryTableAttr: {
+				Description:  "Table of the database recording the applied versions, created when it doesn't exist.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      migrationDefaultHistoryTable,
+				ValidateFunc: validation.StringMatch(migrationHistoryTableRegexp, "must be an unqualified table name"),
+			},
+			migrationMigrationAttr: {
+				Description: "Migrations, applied in their order. The SQL of a migration can't change once it is applied, the changes are made by new migrations.",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						migrationVersionAttr: {
+							Description:  "Unique version of the migration, e.g. `0001_create_orders`.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						migrationSQLAttr: {
+							Description:  "SQL statements of the migration, e.g. from `file(\"migrations/0001_create_orders.sql\")`. The variables are referred to with `:'name'`, replaced with the value quoted as a string literal, or `:\"name\"`, replaced with the value quoted as an identifier.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						migrationVariablesAttr: {
+							Description: "Variables the SQL of the migration refers to, by name. The migration is considered changed when they change, as its SQL.",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						migrationTransactionalAttr: {
+							Description: "Whether the migration runs in a transaction with the recording of its version. Statements which CockroachDB doesn't run in a transaction with others, e.g. some schema changes, need it disabled.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+			migrationOnChangeAttr: {
+				Description: "What happens when the SQL of an applied migration changes: `fail` the plan, `rerun` the migration, `recreate` the resource, " +
+					"forgetting its versions in the history table when it is destroyed so every migration runs again, or `ignore` the change.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      migrationOnChangeFail,
+				ValidateFunc: validation.StringInSlice([]string{migrationOnChangeFail, migrationOnChangeRerun, migrationOnChangeRecreate, migrationOnChangeIgnore}, false),
+			},
+			migrationChecksumsAttr: {
+				Description: "SHA-256 checksums of the SQL the migrations were applied with, by version, as recorded in the history table.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			migrationAppliedVersionsAttr: {
+				Description: "Versions recorded in the history table, in the order they were applied.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + migrationDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     migrationDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// migration is a versioned SQL script, with its variables replaced.
+type migration struct {
+	version       string
+	sql           string
+	transactional bool
+}
+
+// checksum identifies the SQL of the migration, recorded with its version to
+// detect the migrations changed once applied.
+func (m migration) checksum() string {
+	sum := sha256.Sum256([]byte(m.sql))
+	return hex.EncodeToString(sum[:])
+}
+
+func migrationsOf(raw []interface{}) ([]migration, error) {
+	migrations := make([]migration, 0, len(raw))
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		version := m[migrationVersionAttr].(string)
+
+		variables := map[string]string{}
+		if v, ok := m[migrationVariablesAttr].(map[string]interface{}); ok {
+			for name, value := range v {
+				variables[name] = value.(string)
+			}
+		}
+		sql, err := renderSQL(m[migrationSQLAttr].(string), variables)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", version, err)
+		}
+
+		migrations = append(migrations, migration{
+			version:       version,
+			sql:           sql,
+			transactional: m[migrationTransactionalAttr].(bool),
+		})
+	}
+
+	return migrations, nil
+}
+
+// pendingMigrations returns the migrations to apply, in order, failing when a
+// version is used twice. The applied migrations which changed are applied
+// again, ignored or fail depending on onChange.
+func pendingMigrations(migrations []migration, applied map[string]string, onChange string) ([]migration, error) {
+	var pending []migration
+	seen := map[string]bool{}
+	for _, m := range migrations {
+		if seen[m.version] {
+			return nil, fmt.Errorf("migration %s is defined twice", m.version)
+		}
+		seen[m.version] = true
+
+		checksum, ok := applied[m.version]
+		if !ok {
+			pending = append(pending, m)
+			continue
+		}
+		if checksum == m.checksum() {
+			continue
+		}
+		switch onChange {
+		case migrationOnChangeRerun:
+			pending = append(pending, m)
+		case migrationOnChangeFail:
+			return nil, fmt.Errorf("migration %s was changed after it was applied, add a new migration instead or set %s", m.version, migrationOnChangeAttr)
+		}
+	}
+
+	return pending, nil
+}
+
+func resourceMigrationCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	o, n := d.GetChange(migrationMigrationAttr)
+
+	// the SQL or the variables of the migrations may not be known yet, e.g.
+	// when they refer to resources to be created
+	if config := d.GetRawConfig(); !config.IsNull() && !config.GetAttr(migrationMigrationAttr).IsWhollyKnown() {
+		if err := d.SetNewComputed(migrationChecksumsAttr); err != nil {
+			return err
+		}
+		return d.SetNewComputed(migrationAppliedVersionsAttr)
+	}
+
+	// the migrations already in the state were applied with their SQL
+	applied := map[string]string{}
+	oldMigrations, err := migrationsOf(o.([]interface{}))
+	if err != nil {
+		return err
+	}
+	for _, m := range oldMigrations {
+		applied[m.version] = m.checksum()
+	}
+	migrations, err := migrationsOf(n.([]interface{}))
+	if err != nil {
+		return err
+	}
+	onChange := d.Get(migrationOnChangeAttr).(string)
+	if onChange == migrationOnChangeRecreate {
+		changed, err := pendingMigrations(migrations, applied, migrationOnChangeRerun)
+		if err != nil {
+			return err
+		}
+		for _, m := range changed {
+			if _, ok := applied[m.version]; ok {
+				return d.ForceNew(migrationMigrationAttr)
+			}
+		}
+	}
+	pending, err := pendingMigrations(migrations, applied, onChange)
+	if err != nil {
+		return err
+	}
+
+	// the versions missing from the history table are applied again
+	appliedVersions := convertToString(d.Get(migrationAppliedVersionsAttr).([]interface{}))
+	for _, m := range migrations {
+		if !contains(appliedVersions, m.version) {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) > 0 {
+		if err := d.SetNewComputed(migrationChecksumsAttr); err != nil {
+			return err
+		}
+		return d.SetNewComputed(migrationAppliedVersionsAttr)
+	}
+
+	return nil
+}
+
+// openMigrationConnection returns a connection to the database of the
+// migrations, with the history table created.
+func openMigrationConnection(ctx context.Context, d *schema.ResourceData, meta interface{}) (*pgx.Conn, func(), diag.Diagnostics) {
+	return openConnectionIn(ctx, d, meta, d.Get(migrationDatabaseAttr).(string))
+}
+
+func migrationHistoryTable(d *schema.ResourceData) string {
+	return pq.QuoteIdentifier(d.Get(migrationHistoryTableAttr).(string))
+}
+
+// readAppliedMigrations returns the checksums of the applied migrations by
+// version and their versions in the order they were applied.
+func readAppliedMigrations(ctx context.Context, conn *pgx.Conn, table string) (map[string]string, []string, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum FROM `+table+` WHERE version != $1 ORDER BY applied_at, version`, migrationLockVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	applied := map[string]string{}
+	versions := []string{}
+	for rows.Next() {
+		var version, checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, nil, err
+		}
+		applied[version] = checksum
+		versions = append(versions, version)
+	}
+
+	return applied, versions, rows.Err()
+}
+
+func applyMigrations(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openMigrationConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	table := migrationHistoryTable(d)
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+		version STRING PRIMARY KEY,
+		checksum STRING NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return diag.FromErr(err)
+	}
+	if _, err := conn.Exec(ctx, `INSERT INTO `+table+` (version, checksum) VALUES ($1, '') ON CONFLICT (version) DO NOTHING`, migrationLockVersion); err != nil {
+		return diag.FromErr(err)
+	}
+
+	applied, _, err := readAppliedMigrations(ctx, conn, table)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	migrations, err := migrationsOf(d.Get(migrationMigrationAttr).([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	pending, err := pendingMigrations(migrations, applied, d.Get(migrationOnChangeAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// a migration run again replaces its record
+	record := `UPSERT INTO ` + table + ` (version, checksum, applied_at) VALUES ($1, $2, now())`
+	for _, m := range pending {
+		if !m.transactional {
+			if err := applyNonTransactionalMigration(ctx, conn, table, m); err != nil {
+				return diag.FromErr(err)
+			}
+			continue
+		}
+
+		err := crdbpgx.ExecuteTx(ctx, conn, pgx.TxOptions{}, func(tx pgx.Tx) error {
+			if pending, err := lockMigration(ctx, tx, table, m); err != nil || !pending {
+				return err
+			}
+			logInfo("applying migration %s", m.version)
+			if _, err := tx.Exec(ctx, m.sql); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, record, m.version, m.checksum())
+			return err
+		})
+		if err != nil {
+			return diag.Errorf("migration %s failed: %v", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// lockMigration locks the history table until the end of the transaction,
+// returning whether the migration is still pending, another apply having
+// possibly applied it since the history table was read. It fails while
+// another apply runs a non-transactional migration.
+func lockMigration(ctx context.Context, tx pgx.Tx, table string, m migration) (bool, error) {
+	var running string
+	var since time.Time
+	if err := tx.QueryRow(ctx, `SELECT checksum, applied_at FROM `+table+` WHERE version = $1 FOR UPDATE`, migrationLockVersion).Scan(&running, &since); err != nil {
+		return false, err
+	}
+	if running != "" {
+		return false, fmt.Errorf("migration %s is being applied by another apply since %s, clear the checksum of the row of %s with an empty version if that apply was interrupted",
+			running, since.Format(time.RFC3339), table)
+	}
+
+	var checksum string
+	err := tx.QueryRow(ctx, `SELECT checksum FROM `+table+` WHERE version = $1`, m.version).Scan(&checksum)
+	if err == pgx.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if checksum == m.checksum() {
+		logInfo("migration %s was applied by another apply", m.version)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// applyNonTransactionalMigration applies the migration outside of a
+// transaction, marking it as running in the lock row meanwhile so the other
+// applies fail rather than applying migrations concurrently.
+func applyNonTransactionalMigration(ctx context.Context, conn *pgx.Conn, table string, m migration) error {
+	var pending bool
+	err := crdbpgx.ExecuteTx(ctx, conn, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+		if pending, err = lockMigration(ctx, tx, table, m); err != nil || !pending {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE `+table+` SET checksum = $2, applied_at = now() WHERE version = $1`, migrationLockVersion, m.version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %s failed: %v", m.version, err)
+	}
+	if !pending {
+		return nil
+	}
+
+	logInfo("applying migration %s", m.version)
+	_, migrationErr := conn.Exec(ctx, m.sql)
+
+	err = crdbpgx.ExecuteTx(ctx, conn, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `UPDATE `+table+` SET checksum = '' WHERE version = $1`, migrationLockVersion); err != nil {
+			return err
+		}
+		if migrationErr != nil {
+			return nil
+		}
+		_, err := tx.Exec(ctx, `UPSERT INTO `+table+` (version, checksum, applied_at) VALUES ($1, $2, now())`, m.version, m.checksum())
+		return err
+	})
+	if migrationErr != nil {
+		return fmt.Errorf("migration %s failed: %v", m.version, migrationErr)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %s was applied but not recorded: %v", m.version, err)
+	}
+
+	return nil
+}
+
+func resourceMigrationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyMigrations(ctx, d, meta); diags != nil {
+		return diags
+	}
+	d.SetId(d.Get(migrationDatabaseAttr).(string) + "/" + d.Get(migrationHistoryTableAttr).(string))
+
+	return resourceMigrationRead(ctx, d, meta)
+}
+
+func resourceMigrationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openMigrationConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT count(*) > 0 FROM information_schema.tables WHERE table_catalog = current_database() AND table_name = $1`,
+		d.Get(migrationHistoryTableAttr).(string)).Scan(&exists); err != nil {
+		return diag.FromErr(err)
+	}
+
+	checksums := map[string]string{}
+	versions := []string{}
+	if exists {
+		var err error
+		if checksums, versions, err = readAppliedMigrations(ctx, conn, migrationHistoryTable(d)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	values := map[string]interface{}{
+		migrationAppliedVersionsAttr: versions,
+		migrationChecksumsAttr:       checksums,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceMigrationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyMigrations(ctx, d, meta); diags != nil {
+		return diags
+	}
+
+	return resourceMigrationRead(ctx, d, meta)
+}
+
+func resourceMigrationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the migrations can't be reverted, the history table is kept so they
+	// aren't applied again by a new resource, unless they are recreated
+	if d.Get(migrationOnChangeAttr).(string) == migrationOnChangeRecreate {
+		conn, closeConn, diags := openMigrationConnection(ctx, d, meta)
+		if diags != nil {
+			return diags
+		}
+		defer closeConn()
+
+		var versions []string
+		for _, raw := range d.Get(migrationMigrationAttr).([]interface{}) {
+			versions = append(versions, raw.(map[string]interface{})[migrationVersionAttr].(string))
+		}
+		if _, err := conn.Exec(ctx, `DELETE FROM `+migrationHistoryTable(d)+` WHERE version = ANY($1)`, versions); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []migration{
+		{version: "0001", sql: "CREATE TABLE a (id INT PRIMARY KEY)"},
+		{version: "0002", sql: "CREATE TABLE b (id INT PRIMARY KEY)"},
+		{version: "0003", sql: "ALTER TABLE a ADD COLUMN name STRING"},
+	}
+
+	pending, err := pendingMigrations(migrations, map[string]string{"0001": migrations[0].checksum()}, migrationOnChangeFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].version != "0002" || pending[1].version != "0003" {
+		t.Errorf("expected 0002 and 0003 to be pending, got %v", pending)
+	}
+
+	changed := map[string]string{"0001": migrations[0].checksum(), "0002": migrations[0].checksum()}
+	if _, err := pendingMigrations(migrations, changed, migrationOnChangeFail); err == nil {
+		t.Error("expected an error for a migration changed after it was applied")
+	}
+	if pending, err := pendingMigrations(migrations, changed, migrationOnChangeRerun); err != nil || len(pending) != 2 || pending[0].version != "0002" {
+		t.Errorf("expected the changed migration to run again, got %v, %v", pending, err)
+	}
+	if pending, err := pendingMigrations(migrations, changed, migrationOnChangeIgnore); err != nil || len(pending) != 1 || pending[0].version != "0003" {
+		t.Errorf("expected the changed migration to be ignored, got %v, %v", pending, err)
+	}
+
+	if _, err := pendingMigrations(append(migrations, migration{version: "0001", sql: "SELECT 1"}), nil, migrationOnChangeFail); err == nil {
+		t.Error("expected an error for a version defined twice")
+	}
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const nodeCertHostsAttr = "hosts"
+
+func resourceNodeCert() *schema.Resource {
+	s := certSchema(defaultCertLifetime, true)
+	s[nodeCertHostsAttr] = &schema.Schema{
+		Description: "Addresses of the node, IP addresses or DNS names, e.g. `localhost`, the pod names and the services of the cluster.",
+		Type:        schema.TypeList,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		Required: true,
+		ForceNew: true,
+		MinItems: 1,
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource generating the certificate of the nodes of a secure CockroachDB cluster, as `cockroach cert create-node` does. " +
+			"The private key is stored in the state, which must be protected accordingly.",
+
+		CreateContext: resourceNodeCertCreate,
+		ReadContext:   resourceCertRead,
+		DeleteContext: resourceCertDelete,
+
+		Schema: s,
+	}
+}
+
+func resourceNodeCertCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	template, err := certTemplate("node", certLifetime(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	addHosts(template, convertToString(d.Get(nodeCertHostsAttr).([]interface{})))
+
+	if err := issueSignedCert(d, template); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
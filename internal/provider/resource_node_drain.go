@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	drainNodeIDAttr           = "node_id"
+	drainWaitAttr             = "drain_wait"
+	drainUndrainOnDestroyAttr = "undrain_on_destroy"
+	drainTriggersAttr         = "triggers"
+	drainNodeAddressAttr      = "node_address"
+	drainNodePodNameAttr      = "node_pod_name"
+)
+
+func resourceNodeDrain() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource draining a node of the CockroachDB cluster, moving its range leases and SQL clients to the other nodes, e.g. before a maintenance window. " +
+			"CockroachDB can only undrain a node by restarting it, so destroying the resource optionally deletes the pod of the node for it to be restarted by its StatefulSet. " +
+			"It requires the `kube_config` block of the provider.",
+
+		CreateContext: resourceNodeDrainCreate,
+		ReadContext:   resourceNodeDrainRead,
+		DeleteContext: resourceNodeDrainDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			drainNodeIDAttr: {
+				Description:  "ID of the node to drain.",
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			drainWaitAttr: {
+				Description:  "Maximum time to wait for the node to drain, e.g. `10m`, the default of `cockroach node drain` is used when not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateDuration,
+			},
+			drainUndrainOnDestroyAttr: {
+				Description: "Whether the pod of the node is deleted when the resource is destroyed, for the node to restart undrained.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+			},
+			drainTriggersAttr: {
+				Description: "Arbitrary values which drain the node again when changed.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			initPodNameAttr: {
+				Description: "Pod running `cockroach node drain`, a running pod of the service is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			initContainerAttr: {
+				Description: "Container of the pod running CockroachDB.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "db",
+			},
+			initCertsDirAttr: {
+				Description: "Directory of the certificates in the container, it must hold the client certificate of root.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "/cockroach/cockroach-certs",
+			},
+			initInsecureAttr: {
+				Description: "Whether the cluster is insecure.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+			},
+			initHostAttr: {
+				Description: "Address of the cluster as seen from the container, the service of the provider is used when not set since it only routes to the nodes which are not draining.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			drainNodeAddressAttr: {
+				Description: "Address of the drained node.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			drainNodePodNameAttr: {
+				Description: "Pod of the drained node, derived from its address.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// validateDuration checks the value is a Go duration, e.g. `10m`.
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %v", k, err)}
+	}
+
+	return nil, nil
+}
+
+// cockroachCommand returns the `cockroach` command run with the arguments
+// against the host, as root.
+func cockroachCommand(host string, certsDir string, insecure bool, args ...string) []string {
+	command := append([]string{"cockroach"}, args...)
+	command = append(command, fmt.Sprintf("--host=%s", host))
+	if insecure {
+		return append(command, "--insecure")
+	}
+
+	return append(command, fmt.Sprintf("--certs-dir=%s", certsDir))
+}
+
+// nodePodName returns the pod of a node from its address, the pods of a
+// StatefulSet being addressed as <pod>.<service>.<namespace>.
+func nodePodName(address string) string {
+	host := address
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+
+	return strings.SplitN(host, ".", 2)[0]
+}
+
+// drainCommandRunner runs `cockroach` commands for the resource.
+func drainCommandRunner(ctx context.Context, d *schema.ResourceData, meta interface{}) (func(args ...string) (string, error), error) {
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if kubeConn.kubeConfig == nil {
+		return nil, fmt.Errorf("the kube_config block of the provider is required to drain a node")
+	}
+
+	pod := d.Get(initPodNameAttr).(string)
+	if pod == "" {
+		var err error
+		if pod, err = findLivePod(ctx, kubeConn); err != nil {
+			return nil, err
+		}
+		if err := d.Set(initPodNameAttr, pod); err != nil {
+			return nil, err
+		}
+	}
+
+	host := d.Get(initHostAttr).(string)
+	if host == "" {
+		host = kubeConn.serviceName
+	}
+
+	return func(args ...string) (string, error) {
+		command := cockroachCommand(host, d.Get(initCertsDirAttr).(string), d.Get(initInsecureAttr).(bool), args...)
+		return execInPod(kubeConn, pod, d.Get(initContainerAttr).(string), command, nil)
+	}, nil
+}
+
+// nodeLiveness returns whether the node is draining and its address, ok being
+// false when the node doesn't exist.
+func nodeLiveness(run func(args ...string) (string, error), nodeID int) (draining bool, address string, ok bool, err error) {
+	out, err := run("sql", "--format=csv", fmt.Sprintf(
+		"--execute=SELECT l.draining, n.address FROM crdb_internal.gossip_liveness AS l "+
+			"JOIN crdb_internal.gossip_nodes AS n USING (node_id) WHERE node_id = %d", nodeID))
+	if err != nil {
+		return false, "", false, err
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		return false, "", false, fmt.Errorf("failed to parse node liveness: %w", err)
+	}
+	if len(records) < 2 {
+		return false, "", false, nil
+	}
+
+	draining, err = strconv.ParseBool(records[1][0])
+	if err != nil {
+		return false, "", false, fmt.Errorf("failed to parse node liveness: %w", err)
+	}
+
+	return draining, records[1][1], true, nil
+}
+
+func resourceNodeDrainCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	nodeID := d.Get(drainNodeIDAttr).(int)
+
+	run, err := drainCommandRunner(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, address, ok, err := nodeLiveness(run, nodeID)
+	if err != nil {
+		return diag.Errorf("failed to get node %d: %v", nodeID, err)
+	}
+	if !ok {
+		return diag.Errorf("node %d not found", nodeID)
+	}
+
+	args := []string{"node", "drain", strconv.Itoa(nodeID)}
+	if drainWait := d.Get(drainWaitAttr).(string); drainWait != "" {
+		args = append(args, fmt.Sprintf("--drain-wait=%s", drainWait))
+	}
+
+	logInfo("draining node %d", nodeID)
+	if _, err := run(args...); err != nil {
+		return diag.Errorf("failed to drain node %d: %v", nodeID, err)
+	}
+
+	d.SetId(strconv.Itoa(nodeID))
+	if err := d.Set(drainNodeAddressAttr, address); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(drainNodePodNameAttr, nodePodName(address)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceNodeDrainRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	nodeID := d.Get(drainNodeIDAttr).(int)
+
+	run, err := drainCommandRunner(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	draining, _, ok, err := nodeLiveness(run, nodeID)
+	if err != nil {
+		return diag.Errorf("failed to get node %d: %v", nodeID, err)
+	}
+
+	// a restarted node is no longer drained, drain it again
+	if !ok || !draining {
+		logInfo("node %d is not draining anymore", nodeID)
+		d.SetId("")
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceNodeDrainDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.Get(drainUndrainOnDestroyAttr).(bool) {
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	kubeConn, err := meta.(*cockroachClient).kubeConnection(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if kubeConn.kubeConfig == nil {
+		return diag.Errorf("the kube_config block of the provider is required to undrain a node")
+	}
+
+	pod := d.Get(drainNodePodNameAttr).(string)
+	logInfo("deleting pod %s to undrain node %d", pod, d.Get(drainNodeIDAttr).(int))
+	err = retryKubeAPI(kubeConn.maxRetries, func() error {
+		return kubeConn.kubeClient.CoreV1().Pods(kubeConn.nameSpace).Delete(ctx, pod, metav1.DeleteOptions{})
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return diag.Errorf("failed to delete pod %s: %v", pod, err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
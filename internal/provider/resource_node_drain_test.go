@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestNodePodName(t *testing.T) {
+	cases := map[string]string{
+		"cockroachdb-2.cockroachdb.default.svc.cluster.local:26257": "cockroachdb-2",
+		"cockroachdb-0:26257": "cockroachdb-0",
+		"cockroachdb-1":       "cockroachdb-1",
+	}
+
+	for address, expected := range cases {
+		if got := nodePodName(address); got != expected {
+			t.Errorf("nodePodName(%q) = %q, expected %q", address, got, expected)
+		}
+	}
+}
+
+func TestCockroachCommand(t *testing.T) {
+	got := cockroachCommand("cockroachdb-public", "/certs", false, "node", "drain", "2")
+	expected := []string{"cockroach", "node", "drain", "2", "--host=cockroachdb-public", "--certs-dir=/certs"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("cockroachCommand() = %v, expected %v", got, expected)
+	}
+
+	got = cockroachCommand("localhost", "/certs", true, "node", "status")
+	expected = []string{"cockroach", "node", "status", "--host=localhost", "--insecure"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("cockroachCommand() = %v, expected %v", got, expected)
+	}
+}
+
+func TestAccResourceNodeDrain(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceNodeDrain,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"cockroach_node_drain.foo", "node_pod_name"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceNodeDrain = `
+resource "cockroach_node_drain" "foo" {
+  node_id            = 1
+  undrain_on_destroy = true
+}
+`
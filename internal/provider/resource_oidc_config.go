@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var oidcScopesRegexp = regexp.MustCompile(`(^|\s)openid(\s|$)`)
+
+func resourceOIDCConfig() *schema.Resource {
+	return resourceSettingGroup(settingGroup{
+		id:          "oidc_config",
+		description: "Resource used to configure the single sign-on to the DB Console of a CockroachDB cluster with an OpenID Connect provider.",
+		localPort:   "26283",
+		attributes: map[string]groupedSetting{
+			"enabled": {name: "server.oidc_authentication.enabled", schema: &schema.Schema{
+				Description: "Whether the users can log in to the DB Console with the OpenID Connect provider.",
+				Type:        schema.TypeBool,
+			}},
+			"provider_url": {name: "server.oidc_authentication.provider_url", schema: &schema.Schema{
+				Description:  "URL of the issuer of the OpenID Connect provider, e.g. `https://accounts.google.com`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			}},
+			"client_id": {name: "server.oidc_authentication.client_id", schema: &schema.Schema{
+				Description: "Id of the client registered with the OpenID Connect provider.",
+				Type:        schema.TypeString,
+				Sensitive:   true,
+			}},
+			"client_secret": {name: "server.oidc_authentication.client_secret", schema: &schema.Schema{
+				Description: "Secret of the client registered with the OpenID Connect provider.",
+				Type:        schema.TypeString,
+				Sensitive:   true,
+			}},
+			"redirect_url": {name: "server.oidc_authentication.redirect_url", schema: &schema.Schema{
+				Description: "URL the OpenID Connect provider redirects to after a login, e.g. `https://crdb.example.com:8080/oidc/v1/callback`, or a JSON object of URLs keyed by region.",
+				Type:        schema.TypeString,
+			}},
+			"scopes": {name: "server.oidc_authentication.scopes", schema: &schema.Schema{
+				Description:  "Space separated scopes requested from the OpenID Connect provider, which must include `openid`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringMatch(oidcScopesRegexp, "must include openid"),
+			}},
+			"claim_json_key": {name: "server.oidc_authentication.claim_json_key", schema: &schema.Schema{
+				Description: "Claim of the token the SQL user is mapped from, e.g. `email`.",
+				Type:        schema.TypeString,
+			}},
+			"principal_regex": {name: "server.oidc_authentication.principal_regex", schema: &schema.Schema{
+				Description:  "Regular expression mapping the claim to the SQL user with its first capture group, e.g. `^([^@]+)@example\\.com$`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsValidRegExp,
+			}},
+			"button_text": {name: "server.oidc_authentication.button_text", schema: &schema.Schema{
+				Description: "Text of the login button of the DB Console.",
+				Type:        schema.TypeString,
+			}},
+			"autologin_enabled": {name: "server.oidc_authentication.autologin.enabled", schema: &schema.Schema{
+				Description: "Whether the DB Console logs in with the OpenID Connect provider automatically.",
+				Type:        schema.TypeBool,
+			}},
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceOIDCConfig(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceOIDCConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_oidc_config.foo", "enabled", "true"),
+					resource.TestCheckResourceAttr("cockroach_oidc_config.foo", "claim_json_key", "email"),
+				),
+			},
+		},
+	})
+}
+
+func TestOIDCScopes(t *testing.T) {
+	for _, scopes := range []string{"openid", "openid email", "email openid profile"} {
+		if !oidcScopesRegexp.MatchString(scopes) {
+			t.Errorf("expected %q to be valid", scopes)
+		}
+	}
+	for _, scopes := range []string{"email", "openidx email"} {
+		if oidcScopesRegexp.MatchString(scopes) {
+			t.Errorf("expected %q to be invalid", scopes)
+		}
+	}
+}
+
+const testAccResourceOIDCConfig = `
+resource "cockroach_oidc_config" "foo" {
+  enabled         = true
+  provider_url    = "https://accounts.google.com"
+  client_id       = "crdb"
+  client_secret   = "secret"
+  redirect_url    = "https://localhost:8080/oidc/v1/callback"
+  scopes          = "openid email"
+  claim_json_key  = "email"
+  principal_regex = "^([^@]+)@example\\.com$"
+}
+`
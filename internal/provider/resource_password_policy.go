@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourcePasswordPolicy() *schema.Resource {
+	return resourceSettingGroup(settingGroup{
+		id:          "password_policy",
+		description: "Resource used to manage the password policy of a CockroachDB cluster, how the passwords of the users are checked, hashed and stored.",
+		localPort:   "26297",
+		attributes: map[string]groupedSetting{
+			"min_password_length": {name: "server.user_login.min_password_length", schema: &schema.Schema{
+				Description:  "Minimum length of the passwords set by the users.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(1),
+			}},
+			"password_encryption": {name: "server.user_login.password_encryption", schema: &schema.Schema{
+				Description:  "Algorithm the new passwords are hashed with, `scram-sha-256` or `crdb-bcrypt`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"scram-sha-256", "crdb-bcrypt"}, false),
+			}},
+			"bcrypt_cost": {name: "server.user_login.password_hashes.default_cost.crdb_bcrypt", schema: &schema.Schema{
+				Description:  "Cost of the passwords hashed with `crdb-bcrypt`, between 4 and 31.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(4, 31),
+			}},
+			"scram_cost": {name: "server.user_login.password_hashes.default_cost.scram_sha_256", schema: &schema.Schema{
+				Description:  "Number of iterations of the passwords hashed with `scram-sha-256`, between 4096 and 240000000.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(4096, 240000000),
+			}},
+			"store_client_pre_hashed_passwords": {name: "server.user_login.store_client_pre_hashed_passwords.enabled", schema: &schema.Schema{
+				Description: "Whether the passwords hashed by the clients are stored as is.",
+				Type:        schema.TypeBool,
+			}},
+			"upgrade_bcrypt_to_scram": {name: "server.user_login.upgrade_bcrypt_stored_passwords_to_scram.enabled", schema: &schema.Schema{
+				Description: "Whether the passwords stored with `crdb-bcrypt` are hashed again with `scram-sha-256` when the users log in and `password_encryption` is `scram-sha-256`.",
+				Type:        schema.TypeBool,
+			}},
+			"downgrade_scram_to_bcrypt": {name: "server.user_login.downgrade_scram_stored_passwords_to_bcrypt.enabled", schema: &schema.Schema{
+				Description: "Whether the passwords stored with `scram-sha-256` are hashed again with `crdb-bcrypt` when the users log in and `password_encryption` is `crdb-bcrypt`.",
+				Type:        schema.TypeBool,
+			}},
+			"rehash_scram_on_cost_change": {name: "server.user_login.rehash_scram_stored_passwords_on_cost_change.enabled", schema: &schema.Schema{
+				Description: "Whether the passwords stored with `scram-sha-256` are hashed again with `scram_cost` when it changed and the users log in.",
+				Type:        schema.TypeBool,
+			}},
+			"login_timeout": {name: "server.user_login.timeout", schema: &schema.Schema{
+				Description:  "Timeout of the lookup of the users when they log in, e.g. `10s`.",
+				Type:         schema.TypeString,
+				ValidateFunc: validateDuration,
+			}},
+		},
+	})
+}
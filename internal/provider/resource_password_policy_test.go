@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourcePasswordPolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourcePasswordPolicy,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_password_policy.foo", "min_password_length", "12"),
+					resource.TestCheckResourceAttr("cockroach_password_policy.foo", "password_encryption", "scram-sha-256"),
+				),
+			},
+		},
+	})
+}
+
+func TestPasswordPolicyValidation(t *testing.T) {
+	s := resourcePasswordPolicy().Schema
+	cases := []struct {
+		attr  string
+		value interface{}
+		valid bool
+	}{
+		{"password_encryption", "scram-sha-256", true},
+		{"password_encryption", "md5", false},
+		{"bcrypt_cost", 10, true},
+		{"bcrypt_cost", 32, false},
+		{"scram_cost", 1000, false},
+		{"login_timeout", "10s", true},
+		{"login_timeout", "ten seconds", false},
+	}
+
+	for _, c := range cases {
+		_, errs := s[c.attr].ValidateFunc(c.value, c.attr)
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("expected %s = %v to be valid: %v, got %v", c.attr, c.value, c.valid, errs)
+		}
+	}
+}
+
+const testAccResourcePasswordPolicy = `
+resource "cockroach_password_policy" "foo" {
+  min_password_length = 12
+  password_encryption = "scram-sha-256"
+}
+`
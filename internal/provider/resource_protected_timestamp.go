@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	protectedTimestampDatabaseAttr     = "database"
+	protectedTimestampSchemaAttr       = "schema"
+	protectedTimestampTablesAttr       = "tables"
+	protectedTimestampTimestampAttr    = "timestamp"
+	protectedTimestampExpiresAfterAttr = "expires_after"
+	protectedTimestampStatusAttr       = "status"
+
+	// protectedTimestampSinkURI is the sink of the changefeed holding the
+	// protected timestamp record, which discards the changes.
+	protectedTimestampSinkURI = "null://"
+
+	protectedTimestampDefaultLocalPort = "26279"
+)
+
+func resourceProtectedTimestamp() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to protect the history of tables of a CockroachDB cluster from garbage collection, e.g. for long-running analytics or external CDC consumers. " +
+			"CockroachDB has no SQL statement creating protected timestamp records, so the record is held by a paused changefeed emitting nothing, created with `protect_data_from_gc_on_pause`, which requires `kv.rangefeed.enabled`. " +
+			"The record is released when the resource is destroyed.",
+
+		CreateContext: resourceProtectedTimestampCreate,
+		ReadContext:   resourceProtectedTimestampRead,
+		DeleteContext: resourceChangefeedDelete,
+		CustomizeDiff: resourceProtectedTimestampCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			protectedTimestampDatabaseAttr: {
+				Description: "Name of the database of the tables, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			protectedTimestampSchemaAttr: {
+				Description: "Name of the schema of the tables, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			protectedTimestampTablesAttr: {
+				Description: "Names of the tables to protect. When not set, the tables of every schema of the database at creation are protected.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			protectedTimestampTimestampAttr: {
+				Description:  "Timestamp the history is protected from, either a resolved timestamp, e.g. `1536242855577149065.0000000000`, or a time in RFC 3339 format. It must be within the garbage collection window of the tables, the time of creation is used when not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateChangefeedCursor,
+			},
+			protectedTimestampExpiresAfterAttr: {
+				Description: "Duration after which the record is released by CockroachDB even though the resource isn't destroyed, e.g. `72h`. The record is held until the resource is destroyed when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			protectedTimestampStatusAttr: {
+				Description: "Status of the job of the changefeed holding the record, which is held as long as it is `paused`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26279), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     protectedTimestampDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func resourceProtectedTimestampCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, protectedTimestampDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, protectedTimestampSchemaAttr)
+}
+
+// protectedTimestampOptions returns the options of the changefeed holding the
+// protected timestamp record.
+func protectedTimestampOptions(timestamp string, expiresAfter string) map[string]interface{} {
+	options := map[string]interface{}{
+		"initial_scan":                  "no",
+		"protect_data_from_gc_on_pause": "",
+	}
+	if timestamp != "" {
+		options["cursor"] = timestamp
+	}
+	if expiresAfter != "" {
+		options["gc_protect_expires_after"] = expiresAfter
+	}
+
+	return options
+}
+
+// databaseTables returns the qualified names of the tables of every schema of
+// the database.
+func databaseTables(ctx context.Context, conn *pgx.Conn, database string) (string, error) {
+	rows, err := conn.Query(ctx,
+		`SELECT table_schema, table_name FROM `+pq.QuoteIdentifier(database)+`.information_schema.tables `+
+			`WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('crdb_internal', 'information_schema', 'pg_catalog', 'pg_extension') `+
+			`ORDER BY table_schema, table_name`)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var schemaName, table string
+		if err := rows.Scan(&schemaName, &table); err != nil {
+			return "", err
+		}
+		names = append(names, qualifiedNames(database, schemaName, []string{table}))
+	}
+
+	return strings.Join(names, ", "), rows.Err()
+}
+
+func resourceProtectedTimestampCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(protectedTimestampDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", protectedTimestampDatabaseAttr)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	tables := qualifiedNames(database, d.Get(protectedTimestampSchemaAttr).(string),
+		convertToString(d.Get(protectedTimestampTablesAttr).(*schema.Set).List()))
+	if d.Get(protectedTimestampTablesAttr).(*schema.Set).Len() == 0 {
+		var err error
+		if tables, err = databaseTables(ctx, conn, database); err != nil {
+			return diag.FromErr(err)
+		}
+		if tables == "" {
+			return diag.Errorf("database %s has no tables to protect", database)
+		}
+	}
+
+	options := protectedTimestampOptions(d.Get(protectedTimestampTimestampAttr).(string), d.Get(protectedTimestampExpiresAfterAttr).(string))
+	var jobID int64
+	if err := conn.QueryRow(ctx, createChangefeedStatement(tables, protectedTimestampSinkURI, options)).Scan(&jobID); err != nil {
+		return diag.Errorf("failed to create the changefeed holding the protected timestamp: %v", err)
+	}
+	d.SetId(strconv.FormatInt(jobID, 10))
+
+	if _, err := conn.Exec(ctx, `PAUSE JOB `+d.Id()); err != nil {
+		return diag.Errorf("failed to pause changefeed %s: %v", d.Id(), err)
+	}
+	if err := waitForChangefeedStatus(ctx, conn, d.Id(), "paused", d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceProtectedTimestampRead(ctx, d, meta)
+}
+
+func resourceProtectedTimestampRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	status, ok, err := readChangefeedStatus(ctx, conn, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok || changefeedDone(status) {
+		logInfo("changefeed %s holding the protected timestamp not found or %s, removing it from state", d.Id(), status)
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := d.Set(protectedTimestampStatusAttr, status); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceProtectedTimestamp(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceProtectedTimestamp,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_protected_timestamp.foo", "status", "paused"),
+				),
+			},
+		},
+	})
+}
+
+func TestProtectedTimestampStatement(t *testing.T) {
+	statement := createChangefeedStatement(`"db"."public"."orders"`, protectedTimestampSinkURI, protectedTimestampOptions("1536242855577149065.0000000000", "72h"))
+	expected := `CREATE CHANGEFEED FOR TABLE "db"."public"."orders" INTO 'null://' ` +
+		`WITH cursor = '1536242855577149065.0000000000', gc_protect_expires_after = '72h', initial_scan = 'no', protect_data_from_gc_on_pause`
+	if statement != expected {
+		t.Errorf("got %s, expected %s", statement, expected)
+	}
+
+	statement = createChangefeedStatement(`"db"."public"."orders"`, protectedTimestampSinkURI, protectedTimestampOptions("", ""))
+	expected = `CREATE CHANGEFEED FOR TABLE "db"."public"."orders" INTO 'null://' WITH initial_scan = 'no', protect_data_from_gc_on_pause`
+	if statement != expected {
+		t.Errorf("got %s, expected %s", statement, expected)
+	}
+}
+
+const testAccResourceProtectedTimestamp = `
+resource "cockroach_cluster_settings" "rangefeed" {
+  settings = {
+    "kv.rangefeed.enabled" = "true"
+  }
+}
+
+resource "cockroach_database" "foo" {
+  name = "protected_timestamp_test"
+}
+
+resource "cockroach_table" "foo" {
+  database = cockroach_database.foo.name
+  name     = "events"
+
+  column {
+    name = "id"
+    type = "INT8"
+  }
+
+  primary_key = ["id"]
+}
+
+resource "cockroach_protected_timestamp" "foo" {
+  database      = cockroach_database.foo.name
+  tables        = [cockroach_table.foo.name]
+  expires_after = "24h"
+
+  depends_on = [cockroach_cluster_settings.rangefeed]
+}
+`
@@ -0,0 +1,350 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	restoreDatabaseAttr               = "database"
+	restoreSchemaAttr                 = "schema"
+	restoreTablesAttr                 = "tables"
+	restoreAsOfSystemTimeAttr         = "as_of_system_time"
+	restoreNewDBNameAttr              = "new_db_name"
+	restoreIntoDBAttr                 = "into_db"
+	restoreSkipMissingForeignKeysAttr = "skip_missing_foreign_keys"
+	restoreJobIDAttr                  = "job_id"
+	restoreRestoredDatabaseAttr       = "restored_database"
+
+	restoreDefaultLocalPort = "26277"
+)
+
+func resourceRestore() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to restore a database or tables of a CockroachDB cluster from a backup, e.g. to stamp out a rehearsal environment from the backups of production. " +
+			"The restored database or tables are dropped when the resource is destroyed, and restored again when they are dropped outside of Terraform.",
+
+		CreateContext: resourceRestoreCreate,
+		ReadContext:   resourceRestoreRead,
+		DeleteContext: resourceRestoreDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			backupCheckCollectionAttr: {
+				Description: "Path of the backup collection, e.g. `s3://backups/crdb?AUTH=implicit`.",
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				ForceNew:    true,
+			},
+			backupCheckLocalityCollectionsAttr: {
+				Description: "Paths of a locality-aware backup collection keyed by locality, e.g. `region=us-east1`, `collection` being the default one.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional:  true,
+				Sensitive: true,
+				ForceNew:  true,
+			},
+			backupCheckSubdirAttr: {
+				Description: "Subdirectory of the backup to restore in the collection, e.g. `2024/05/01-120000.00`, the latest backup is restored when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     backupCheckLatestSubdir,
+			},
+			restoreDatabaseAttr: {
+				Description: "Name of the database in the backup, restored as a whole unless `tables` is set.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			restoreSchemaAttr: {
+				Description: "Name of the schema of the tables in the backup.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+			},
+			restoreTablesAttr: {
+				Description: "Names of the tables of the database to restore, into an existing database.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			restoreAsOfSystemTimeAttr: {
+				Description: "Time the data is restored as of, e.g. `2024-05-01 12:00:00` or `-1h`, for backups taken with `revision_history`. The end time of the backup is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			restoreNewDBNameAttr: {
+				Description:   "Name of the restored database, the name of the database in the backup is used when not set. Only used when the database is restored as a whole.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{restoreTablesAttr, restoreIntoDBAttr},
+			},
+			restoreIntoDBAttr: {
+				Description:  "Name of the existing database the tables are restored into, the name of the database in the backup is used when not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				RequiredWith: []string{restoreTablesAttr},
+			},
+			restoreSkipMissingForeignKeysAttr: {
+				Description: "Whether the foreign keys referencing tables which aren't restored are removed instead of failing the restore.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+			},
+			backupKMSURIsAttr: {
+				Description: "URIs of the KMS keys the backup is encrypted with.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{backupEncryptionPassphraseAttr},
+			},
+			backupEncryptionPassphraseAttr: {
+				Description:   "Passphrase the backup is encrypted with.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ForceNew:      true,
+				ConflictsWith: []string{backupKMSURIsAttr},
+			},
+			restoreJobIDAttr: {
+				Description: "Id of the job of the restore.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			restoreRestoredDatabaseAttr: {
+				Description: "Name of the database the data is restored into.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26277), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     restoreDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// restore is the definition of a restore.
+type restore struct {
+	database               string
+	schema                 string
+	tables                 []string
+	asOfSystemTime         string
+	newDBName              string
+	intoDB                 string
+	skipMissingForeignKeys bool
+}
+
+// restoredDatabase returns the database the data is restored into.
+func (r restore) restoredDatabase() string {
+	if r.newDBName != "" {
+		return r.newDBName
+	}
+	if r.intoDB != "" {
+		return r.intoDB
+	}
+
+	return r.database
+}
+
+// restoreStatement returns the RESTORE statement of the backup of the
+// subdirectory of the collection.
+func restoreStatement(r restore, subdir string, collection string, options []string) string {
+	var statement string
+	if len(r.tables) != 0 {
+		names := make([]string, len(r.tables))
+		for i, table := range r.tables {
+			names[i] = pq.QuoteIdentifier(r.database) + `.` + pq.QuoteIdentifier(r.schema) + `.` + pq.QuoteIdentifier(table)
+		}
+		statement = `RESTORE TABLE ` + strings.Join(names, `, `)
+	} else {
+		statement = `RESTORE DATABASE ` + pq.QuoteIdentifier(r.database)
+	}
+
+	backup := `LATEST`
+	if subdir != backupCheckLatestSubdir {
+		backup = pq.QuoteLiteral(subdir)
+	}
+	statement += ` FROM ` + backup + ` IN ` + collection
+
+	if r.asOfSystemTime != "" {
+		statement += ` AS OF SYSTEM TIME ` + pq.QuoteLiteral(r.asOfSystemTime)
+	}
+
+	if r.newDBName != "" {
+		options = append(options, `new_db_name = `+pq.QuoteLiteral(r.newDBName))
+	}
+	if r.intoDB != "" {
+		options = append(options, `into_db = `+pq.QuoteLiteral(r.intoDB))
+	}
+	if r.skipMissingForeignKeys {
+		options = append(options, `skip_missing_foreign_keys`)
+	}
+	if len(options) != 0 {
+		statement += ` WITH ` + strings.Join(options, `, `)
+	}
+
+	return statement
+}
+
+func restoreOf(d *schema.ResourceData) restore {
+	tables := convertToString(d.Get(restoreTablesAttr).(*schema.Set).List())
+	sort.Strings(tables)
+
+	return restore{
+		database:               d.Get(restoreDatabaseAttr).(string),
+		schema:                 d.Get(restoreSchemaAttr).(string),
+		tables:                 tables,
+		asOfSystemTime:         d.Get(restoreAsOfSystemTimeAttr).(string),
+		newDBName:              d.Get(restoreNewDBNameAttr).(string),
+		intoDB:                 d.Get(restoreIntoDBAttr).(string),
+		skipMissingForeignKeys: d.Get(restoreSkipMissingForeignKeysAttr).(bool),
+	}
+}
+
+func resourceRestoreCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	collection, err := backupDestination(d.Get(backupCheckCollectionAttr).(string), d.Get(backupCheckLocalityCollectionsAttr).(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	options := backupEncryptionOptions(convertToString(d.Get(backupKMSURIsAttr).([]interface{})), d.Get(backupEncryptionPassphraseAttr).(string))
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	r := restoreOf(d)
+	unlock := meta.(*cockroachClient).locks.lock(databaseLockKey(r.restoredDatabase()))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	// RESTORE waits for its job and returns a single row, only its job_id is
+	// kept as the other columns differ between versions
+	rows, err := conn.Query(ctx, restoreStatement(r, d.Get(backupCheckSubdirAttr).(string), collection, options))
+	if err != nil {
+		return diag.Errorf("failed to restore: %v", err)
+	}
+	var jobID string
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			rows.Close()
+			return diag.FromErr(err)
+		}
+		for i, field := range rows.FieldDescriptions() {
+			if string(field.Name) == "job_id" {
+				jobID = fmt.Sprint(values[i])
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return diag.Errorf("failed to restore: %v", err)
+	}
+
+	d.SetId(r.restoredDatabase() + "/" + jobID)
+	if err := d.Set(restoreJobIDAttr, jobID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceRestoreRead(ctx, d, meta)
+}
+
+func resourceRestoreRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	r := restoreOf(d)
+	database := r.restoredDatabase()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT count(*) > 0 FROM crdb_internal.databases WHERE name = $1`, database).Scan(&exists); err != nil {
+		return diag.FromErr(err)
+	}
+	if exists && len(r.tables) != 0 {
+		var count int
+		err := conn.QueryRow(ctx,
+			`SELECT count(*) FROM `+pq.QuoteIdentifier(database)+`.information_schema.tables WHERE table_schema = $1 AND table_name = ANY($2)`,
+			r.schema, r.tables).Scan(&count)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		exists = count == len(r.tables)
+	}
+
+	if !exists {
+		logInfo("restored data of %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := d.Set(restoreRestoredDatabaseAttr, database); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceRestoreDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	r := restoreOf(d)
+	database := r.restoredDatabase()
+
+	unlock := meta.(*cockroachClient).locks.lock(databaseLockKey(database))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	statement := `DROP DATABASE IF EXISTS ` + pq.QuoteIdentifier(database) + ` CASCADE`
+	if len(r.tables) != 0 {
+		statement = `DROP TABLE IF EXISTS ` + qualifiedNames(database, r.schema, r.tables) + ` CASCADE`
+	}
+	if _, err := conn.Exec(ctx, statement); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceRestore(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceRestore,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_restore.foo", "restored_database", "foo_rehearsal"),
+					resource.TestCheckResourceAttrSet(
+						"cockroach_restore.foo", "job_id"),
+				),
+			},
+		},
+	})
+}
+
+func TestRestoreStatement(t *testing.T) {
+	r := restore{
+		database:               "foo",
+		schema:                 "public",
+		asOfSystemTime:         "2024-05-01 12:00:00",
+		newDBName:              "foo_rehearsal",
+		skipMissingForeignKeys: true,
+	}
+
+	expected := `RESTORE DATABASE "foo" FROM LATEST IN 's3://backups/crdb' AS OF SYSTEM TIME '2024-05-01 12:00:00' ` +
+		`WITH encryption_passphrase = 'secret', new_db_name = 'foo_rehearsal', skip_missing_foreign_keys`
+	if statement := restoreStatement(r, "LATEST", `'s3://backups/crdb'`, []string{`encryption_passphrase = 'secret'`}); statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+	if database := r.restoredDatabase(); database != "foo_rehearsal" {
+		t.Errorf("unexpected restored database %s", database)
+	}
+
+	r = restore{database: "foo", schema: "public", tables: []string{"customers", "orders"}, intoDB: "bar"}
+	expected = `RESTORE TABLE "foo"."public"."customers", "foo"."public"."orders" FROM '2024/05/01-120000.00' IN 's3://backups/crdb' WITH into_db = 'bar'`
+	if statement := restoreStatement(r, "2024/05/01-120000.00", `'s3://backups/crdb'`, nil); statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+	if database := r.restoredDatabase(); database != "bar" {
+		t.Errorf("unexpected restored database %s", database)
+	}
+}
+
+const testAccResourceRestore = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_database_backup" "foo" {
+  name          = "foo_backups"
+  database_name = cockroach_database.foo.name
+  backup_path   = "nodelocal://1/foo"
+}
+
+resource "cockroach_restore" "foo" {
+  collection  = "nodelocal://1/foo"
+  database    = cockroach_database.foo.name
+  new_db_name = "foo_rehearsal"
+
+  depends_on = [cockroach_database_backup.foo]
+}
+`
@@ -0,0 +1,360 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	sequenceNameAttr           = "name"
+	sequenceDatabaseAttr       = "database"
+	sequenceSchemaAttr         = "schema"
+	sequenceIncrementAttr      = "increment"
+	sequenceMinValueAttr       = "min_value"
+	sequenceMaxValueAttr       = "max_value"
+	sequenceStartAttr          = "start"
+	sequenceRestartWithAttr    = "restart_with"
+	sequenceRestartTriggerAttr = "restart_trigger"
+
+	sequenceDefaultLocalPort = "26304"
+)
+
+func resourceSequence() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to create a sequence in a CockroachDB cluster. " +
+			"The sequence can be restarted, e.g. to move the ids it generates to another range during a migration, by changing `restart_with` or `restart_trigger`.",
+
+		CreateContext: resourceSequenceCreate,
+		ReadContext:   resourceSequenceRead,
+		UpdateContext: resourceSequenceUpdate,
+		DeleteContext: resourceSequenceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSequenceImporter,
+		},
+		CustomizeDiff: resourceSequenceCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			sequenceNameAttr: {
+				Description: "Name of the sequence.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			sequenceDatabaseAttr: {
+				Description: "Name of the database of the sequence, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			sequenceSchemaAttr: {
+				Description: "Name of the schema of the sequence, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			sequenceIncrementAttr: {
+				Description:  "Value added to the sequence for each value generated, negative for a descending sequence.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntNotInSlice([]int{0}),
+			},
+			sequenceMinValueAttr: {
+				Description: "Minimum value of the sequence, 1 for an ascending sequence when not set.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+			},
+			sequenceMaxValueAttr: {
+				Description: "Maximum value of the sequence, the maximum of an INT8 for an ascending sequence when not set.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+			},
+			sequenceStartAttr: {
+				Description: "First value of the sequence, also the value it restarts with when `restart_trigger` changes without `restart_with`. The minimum value for an ascending sequence when not set.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+			},
+			sequenceRestartWithAttr: {
+				Description: "Value the sequence restarts with when it changes or `restart_trigger` does, the next value generated being this one. Set when the sequence is created, it is restarted with it right away.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			sequenceRestartTriggerAttr: {
+				Description: "Arbitrary value restarting the sequence when it changes, with `restart_with` or `start` when not set, e.g. the name of the migration moving the ids.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			dropBehaviorAttr:         dropBehaviorSchema(),
+			argTolerateExistingState: tolerateExistingStateSchema("sequence"),
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + sequenceDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     sequenceDefaultLocalPort,
+			},
+		},
+	}, 3)
+}
+
+func resourceSequenceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, sequenceDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, sequenceSchemaAttr)
+}
+
+// sequence is the definition of a sequence, the unset values being 0.
+type sequence struct {
+	increment int
+	minValue  int
+	maxValue  int
+	start     int
+}
+
+func sequenceOf(get func(string) interface{}) sequence {
+	return sequence{
+		increment: get(sequenceIncrementAttr).(int),
+		minValue:  get(sequenceMinValueAttr).(int),
+		maxValue:  get(sequenceMaxValueAttr).(int),
+		start:     get(sequenceStartAttr).(int),
+	}
+}
+
+// sequenceOptions returns the options of the sequence changed from o to n,
+// every set option of n when o is nil.
+func sequenceOptions(o *sequence, n sequence) string {
+	var options []string
+	if o == nil || o.increment != n.increment {
+		options = append(options, `INCREMENT BY `+strconv.Itoa(n.increment))
+	}
+	if n.minValue != 0 && (o == nil || o.minValue != n.minValue) {
+		options = append(options, `MINVALUE `+strconv.Itoa(n.minValue))
+	}
+	if n.maxValue != 0 && (o == nil || o.maxValue != n.maxValue) {
+		options = append(options, `MAXVALUE `+strconv.Itoa(n.maxValue))
+	}
+	if n.start != 0 && (o == nil || o.start != n.start) {
+		options = append(options, `START WITH `+strconv.Itoa(n.start))
+	}
+
+	return strings.Join(options, ` `)
+}
+
+func createSequenceStatement(name string, s sequence) string {
+	return `CREATE SEQUENCE ` + name + ` ` + sequenceOptions(nil, s)
+}
+
+// alterSequenceStatements returns the statements changing the sequence from o
+// to n, then restarting it when restart is set, with restartWith or its
+// start when restartWith is 0.
+func alterSequenceStatements(name string, o sequence, n sequence, restart bool, restartWith int) []string {
+	var statements []string
+	if options := sequenceOptions(&o, n); options != "" {
+		statements = append(statements, `ALTER SEQUENCE `+name+` `+options)
+	}
+	if restart {
+		statement := `ALTER SEQUENCE ` + name + ` RESTART`
+		if restartWith != 0 {
+			statement += ` WITH ` + strconv.Itoa(restartWith)
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+func sequenceName(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(sequenceDatabaseAttr).(string), d.Get(sequenceSchemaAttr).(string), []string{d.Get(sequenceNameAttr).(string)})
+}
+
+func sequenceLockKey(d *schema.ResourceData) string {
+	return objectLockKey("sequence", d.Get(sequenceDatabaseAttr).(string), d.Get(sequenceSchemaAttr).(string), d.Get(sequenceNameAttr).(string))
+}
+
+func resourceSequenceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(sequenceDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", sequenceDatabaseAttr)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(sequenceLockKey(d))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	s := sequenceOf(d.Get)
+	if _, err := conn.Exec(ctx, createSequenceStatement(sequenceName(d), s)); err != nil && !toleratedExistingState(d, err, alreadyExistsCodes) {
+		return diag.FromErr(err)
+	}
+	if restartWith := d.Get(sequenceRestartWithAttr).(int); restartWith != 0 {
+		for _, statement := range alterSequenceStatements(sequenceName(d), s, s, true, restartWith) {
+			if _, err := conn.Exec(ctx, statement); err != nil {
+				return diag.Errorf("failed to run %q: %v", statement, err)
+			}
+		}
+	}
+
+	d.SetId(clusterScopedID("", database, d.Get(sequenceSchemaAttr).(string), d.Get(sequenceNameAttr).(string)))
+
+	return resourceSequenceRead(ctx, d, meta)
+}
+
+// readSequence reads the definition of the sequence, ok being false when it
+// doesn't exist.
+func readSequence(ctx context.Context, conn *pgx.Conn, database string, schemaName string, name string) (s sequence, ok bool, err error) {
+	var increment, minValue, maxValue, start int64
+	err = conn.QueryRow(ctx,
+		`SELECT increment::INT8, minimum_value::INT8, maximum_value::INT8, start_value::INT8 `+
+			`FROM `+qualifiedNames(database, "information_schema", []string{"sequences"})+` WHERE sequence_schema = $1 AND sequence_name = $2`,
+		schemaName, name).Scan(&increment, &minValue, &maxValue, &start)
+	if err == pgx.ErrNoRows {
+		return s, false, nil
+	}
+	if err != nil {
+		return s, false, err
+	}
+
+	return sequence{increment: int(increment), minValue: int(minValue), maxValue: int(maxValue), start: int(start)}, true, nil
+}
+
+func resourceSequenceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(sequenceDatabaseAttr).(string)
+	schemaName := d.Get(sequenceSchemaAttr).(string)
+	name := d.Get(sequenceNameAttr).(string)
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, database, schemaName, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("sequence %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	s, ok, err := readSequence(ctx, conn, database, schemaName, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("sequence %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	values := map[string]interface{}{
+		sequenceIncrementAttr: s.increment,
+		sequenceMinValueAttr:  s.minValue,
+		sequenceMaxValueAttr:  s.maxValue,
+		sequenceStartAttr:     s.start,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceSequenceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	old := sequenceOf(func(k string) interface{} {
+		o, _ := d.GetChange(k)
+		return o
+	})
+
+	// removing restart_with doesn't restart the sequence
+	restartWith := d.Get(sequenceRestartWithAttr).(int)
+	restart := d.HasChange(sequenceRestartTriggerAttr) || d.HasChange(sequenceRestartWithAttr) && restartWith != 0
+	statements := alterSequenceStatements(sequenceName(d), old, sequenceOf(d.Get), restart, restartWith)
+	if len(statements) == 0 {
+		return resourceSequenceRead(ctx, d, meta)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(sequenceLockKey(d))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return resourceSequenceRead(ctx, d, meta)
+}
+
+func resourceSequenceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(sequenceLockKey(d))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	statement := `DROP SEQUENCE IF EXISTS ` + sequenceName(d) + ` ` + d.Get(dropBehaviorAttr).(string)
+	if _, err := conn.Exec(ctx, statement); err != nil && !toleratedExistingState(d, err, doesNotExistCodes) {
+		return diag.Errorf("failed to run %q: %v", statement, err)
+	}
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceSequenceImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/name
+	_, parts, err := parseResourceID(d.Id(), 3)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sequence id %q, expected database/schema/name: %v", d.Id(), err)
+	}
+
+	values := map[string]interface{}{
+		sequenceDatabaseAttr:     parts[0],
+		sequenceSchemaAttr:       parts[1],
+		sequenceNameAttr:         parts[2],
+		dropBehaviorAttr:         dropBehaviorRestrict,
+		argTolerateExistingState: false,
+		argLocalPort:             sequenceDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceSequence(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceSequence(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_sequence.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:foo:public:order_ids$")),
+					resource.TestCheckResourceAttr("cockroach_sequence.foo", "start", "1000"),
+					resource.TestCheckResourceAttr("cockroach_sequence.foo", "min_value", "1"),
+				),
+			},
+			{
+				Config: testAccResourceSequence("restart_with = 5000"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_sequence.foo", "restart_with", "5000"),
+				),
+			},
+		},
+	})
+}
+
+func TestCreateSequenceStatement(t *testing.T) {
+	expected := `CREATE SEQUENCE s INCREMENT BY 10 MAXVALUE 100000 START WITH 1000`
+	if actual := createSequenceStatement("s", sequence{increment: 10, maxValue: 100000, start: 1000}); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestAlterSequenceStatements(t *testing.T) {
+	s := sequence{increment: 1, minValue: 1, maxValue: 100, start: 1}
+
+	for _, test := range []struct {
+		n           sequence
+		restart     bool
+		restartWith int
+		expected    []string
+	}{
+		{s, false, 0, nil},
+		{sequence{increment: 2, minValue: 1, maxValue: 200, start: 1}, false, 0, []string{`ALTER SEQUENCE s INCREMENT BY 2 MAXVALUE 200`}},
+		{s, true, 50, []string{`ALTER SEQUENCE s RESTART WITH 50`}},
+		{sequence{increment: 1, minValue: 1, maxValue: 100, start: 10}, true, 0, []string{`ALTER SEQUENCE s START WITH 10`, `ALTER SEQUENCE s RESTART`}},
+	} {
+		if actual := alterSequenceStatements("s", s, test.n, test.restart, test.restartWith); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func testAccResourceSequence(restart string) string {
+	return `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_sequence" "foo" {
+  database = cockroach_database.foo.name
+  name     = "order_ids"
+  start    = 1000
+  ` + restart + `
+}
+`
+}
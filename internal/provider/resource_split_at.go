@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	splitDatabaseAttr   = "database"
+	splitSchemaAttr     = "schema"
+	splitTableAttr      = "table"
+	splitIndexAttr      = "index"
+	splitValuesAttr     = "values"
+	splitExpirationAttr = "expiration"
+	splitScatterAttr    = "scatter"
+	splitTriggersAttr   = "triggers"
+
+	splitDefaultLocalPort = "26272"
+)
+
+func resourceSplitAt() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to split the ranges of a table or an index of a CockroachDB cluster at given values, e.g. before a bulk load, and optionally scatter them across the nodes. " +
+			"Destroying the resource unsplits the ranges, letting CockroachDB merge them again. " +
+			"Its id isn't scoped to the cluster, the splits of the table of the same name being adopted when the configuration is applied to another cluster.",
+
+		CreateContext: resourceSplitAtCreate,
+		ReadContext:   resourceSplitAtRead,
+		DeleteContext: resourceSplitAtDelete,
+		CustomizeDiff: resourceSplitAtCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			splitDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			splitSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			splitTableAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			splitIndexAttr: {
+				Description: "Name of the index to split, the primary index of the table is split when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			splitValuesAttr: {
+				Description: "Values of the columns of the index to split at, as SQL expressions, e.g. `1000` or `('us-east', 1000)` to split on several columns.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+			},
+			splitExpirationAttr: {
+				Description:  "Time in RFC 3339 format after which CockroachDB may merge the ranges again, the split never expires when not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			splitScatterAttr: {
+				Description: "Whether the ranges of the table or the index are scattered across the nodes after the split.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+			},
+			splitTriggersAttr: {
+				Description: "Arbitrary values which split the ranges again when changed.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26272), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     splitDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func resourceSplitAtCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, splitDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, splitSchemaAttr)
+}
+
+// splitObject returns the table or the index of the resource, as expected by
+// ALTER TABLE and ALTER INDEX.
+func splitObject(d *schema.ResourceData) string {
+	table := qualifiedNames(d.Get(splitDatabaseAttr).(string), d.Get(splitSchemaAttr).(string), []string{d.Get(splitTableAttr).(string)})
+	if index := d.Get(splitIndexAttr).(string); index != "" {
+		return `INDEX ` + table + `@` + pq.QuoteIdentifier(index)
+	}
+
+	return `TABLE ` + table
+}
+
+// splitValues returns the rows of the VALUES clause of the split points.
+func splitValues(values []string) string {
+	rows := make([]string, len(values))
+	for i, v := range values {
+		v = strings.TrimSpace(v)
+		if !strings.HasPrefix(v, "(") || !strings.HasSuffix(v, ")") || !balancedParentheses(v[1:len(v)-1]) {
+			v = `(` + v + `)`
+		}
+		rows[i] = v
+	}
+
+	return strings.Join(rows, `, `)
+}
+
+// splitAtStatements returns the statements splitting the table or the index.
+func splitAtStatements(object string, values []string, expiration string, scatter bool) []string {
+	split := `ALTER ` + object + ` SPLIT AT VALUES ` + splitValues(values)
+	if expiration != "" {
+		split += ` WITH EXPIRATION ` + pq.QuoteLiteral(expiration)
+	}
+
+	statements := []string{split}
+	if scatter {
+		statements = append(statements, `ALTER `+object+` SCATTER`)
+	}
+
+	return statements
+}
+
+func splitLockKey(d *schema.ResourceData) string {
+	return objectLockKey("table", d.Get(splitDatabaseAttr).(string), d.Get(splitSchemaAttr).(string), d.Get(splitTableAttr).(string))
+}
+
+func resourceSplitAtCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(splitDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", splitDatabaseAttr)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(splitLockKey(d))
+	defer unlock()
+
+	values := convertToString(d.Get(splitValuesAttr).([]interface{}))
+	statements := splitAtStatements(splitObject(d), values, d.Get(splitExpirationAttr).(string), d.Get(splitScatterAttr).(bool))
+	for _, statement := range statements {
+		logInfo("running %s", statement)
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	id := strings.Join([]string{database, d.Get(splitSchemaAttr).(string), d.Get(splitTableAttr).(string)}, "/")
+	if index := d.Get(splitIndexAttr).(string); index != "" {
+		id += "@" + index
+	}
+	d.SetId(id + ":" + strings.Join(values, ","))
+
+	return diag.Diagnostics{}
+}
+
+func resourceSplitAtRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	// CockroachDB merges the ranges once the split expires, which is expected,
+	// so only the table or the index is checked
+	database, schemaName, table := d.Get(splitDatabaseAttr).(string), d.Get(splitSchemaAttr).(string), d.Get(splitTableAttr).(string)
+	var exists bool
+	var err error
+	if index := d.Get(splitIndexAttr).(string); index != "" {
+		err = conn.QueryRow(ctx,
+			`SELECT count(*) > 0 FROM `+pq.QuoteIdentifier(database)+`.pg_catalog.pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3`,
+			schemaName, table, index).Scan(&exists)
+	} else {
+		err = conn.QueryRow(ctx,
+			`SELECT count(*) > 0 FROM `+pq.QuoteIdentifier(database)+`.information_schema.tables WHERE table_schema = $1 AND table_name = $2`,
+			schemaName, table).Scan(&exists)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if !exists {
+		logInfo("table or index of split %s not found, removing it from state", d.Id())
+		d.SetId("")
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceSplitAtDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(splitLockKey(d))
+	defer unlock()
+
+	statement := `ALTER ` + splitObject(d) + ` UNSPLIT AT VALUES ` + splitValues(convertToString(d.Get(splitValuesAttr).([]interface{})))
+	// a split which expired has already been merged
+	if _, err := conn.Exec(ctx, statement); err != nil && !strings.Contains(err.Error(), "is not the start of a range") {
+		return diag.Errorf("failed to run %q: %v", statement, err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
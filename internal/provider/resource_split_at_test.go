@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceSplitAt(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceSplitAt,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_split_at.foo", "id", regexp.MustCompile("^foo/public/events:1000,2000$")),
+				),
+			},
+		},
+	})
+}
+
+func TestSplitAtStatements(t *testing.T) {
+	expected := []string{
+		`ALTER TABLE t SPLIT AT VALUES (1000), ('us-east', 1) WITH EXPIRATION '2030-01-01T00:00:00Z'`,
+		`ALTER TABLE t SCATTER`,
+	}
+	actual := splitAtStatements("TABLE t", []string{"1000", "('us-east', 1)"}, "2030-01-01T00:00:00Z", true)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expected)
+	}
+
+	actual = splitAtStatements("INDEX t@i", []string{"(1) + (2)"}, "", false)
+	if !reflect.DeepEqual(actual, []string{`ALTER INDEX t@i SPLIT AT VALUES ((1) + (2))`}) {
+		t.Errorf("unexpected statements %q", actual)
+	}
+}
+
+const testAccResourceSplitAt = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "events" {
+  database    = cockroach_database.foo.name
+  name        = "events"
+  primary_key = ["id"]
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+}
+
+resource "cockroach_split_at" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.events.name
+  values   = ["1000", "2000"]
+  scatter  = true
+}
+`
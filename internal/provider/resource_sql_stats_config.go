@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceSQLStatsConfig() *schema.Resource {
+	return resourceSettingGroup(settingGroup{
+		id: "sql_stats_config",
+		description: "Resource used to manage how a CockroachDB cluster collects the table statistics used by the query planner " +
+			"and persists the SQL statistics of statements and transactions.",
+		localPort: "26278",
+		attributes: map[string]groupedSetting{
+			"automatic_collection_enabled": {name: "sql.stats.automatic_collection.enabled", schema: &schema.Schema{
+				Description: "Whether table statistics are collected automatically.",
+				Type:        schema.TypeBool,
+			}},
+			"automatic_collection_fraction_stale": {name: "sql.stats.automatic_collection.fraction_stale_rows", schema: &schema.Schema{
+				Description:  "Fraction of the rows of a table which must be stale to refresh its statistics automatically.",
+				Type:         schema.TypeFloat,
+				ValidateFunc: validation.FloatAtLeast(0),
+			}},
+			"automatic_collection_min_stale": {name: "sql.stats.automatic_collection.min_stale_rows", schema: &schema.Schema{
+				Description:  "Minimum number of stale rows of a table to refresh its statistics automatically.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+			}},
+			"histogram_collection_enabled": {name: "sql.stats.histogram_collection.enabled", schema: &schema.Schema{
+				Description: "Whether histograms are collected along with the statistics.",
+				Type:        schema.TypeBool,
+			}},
+			"histogram_buckets_count": {name: "sql.stats.histogram_buckets.count", schema: &schema.Schema{
+				Description:  "Maximum number of buckets of the histograms.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(1),
+			}},
+			"multi_column_collection_enabled": {name: "sql.stats.multi_column_collection.enabled", schema: &schema.Schema{
+				Description: "Whether statistics are collected on the prefixes of the columns of the indexes.",
+				Type:        schema.TypeBool,
+			}},
+			"forecasts_enabled": {name: "sql.stats.forecasts.enabled", schema: &schema.Schema{
+				Description: "Whether the query planner uses statistics forecasted from the previous ones.",
+				Type:        schema.TypeBool,
+			}},
+			"flush_enabled": {name: "sql.stats.flush.enabled", schema: &schema.Schema{
+				Description: "Whether the SQL statistics of statements and transactions are persisted.",
+				Type:        schema.TypeBool,
+			}},
+			"flush_interval": {name: "sql.stats.flush.interval", schema: &schema.Schema{
+				Description: "Interval the SQL statistics are persisted at, e.g. `10m`.",
+				Type:        schema.TypeString,
+			}},
+			"persisted_rows_max": {name: "sql.stats.persisted_rows.max", schema: &schema.Schema{
+				Description:  "Maximum number of rows of persisted SQL statistics, the oldest ones being compacted away.",
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(1),
+			}},
+			"cleanup_recurrence": {name: "sql.stats.cleanup.recurrence", schema: &schema.Schema{
+				Description: "Cron expression of the job compacting the persisted SQL statistics, e.g. `@hourly`.",
+				Type:        schema.TypeString,
+			}},
+		},
+	})
+}
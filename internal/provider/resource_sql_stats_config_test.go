@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceSQLStatsConfig(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceSQLStatsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_sql_stats_config.foo", "automatic_collection_enabled", "false"),
+					resource.TestCheckResourceAttr(
+						"cockroach_sql_stats_config.foo", "histogram_buckets_count", "100"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceSQLStatsConfig = `
+resource "cockroach_sql_stats_config" "foo" {
+  automatic_collection_enabled = false
+  histogram_buckets_count      = 100
+  flush_interval               = "15m"
+}
+`
@@ -0,0 +1,317 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	storageParameterDatabaseAttr   = "database"
+	storageParameterSchemaAttr     = "schema"
+	storageParameterTableAttr      = "table"
+	storageParameterParametersAttr = "parameters"
+
+	storageParameterDefaultLocalPort = "26273"
+)
+
+var storageParameterNameRegexp = regexp.MustCompile(`^[a-z0-9_.]+$`)
+
+func resourceStorageParameter() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to set storage parameters of a table of a CockroachDB cluster, e.g. `exclude_data_from_backup`, without managing the table itself. " +
+			"Only the parameters of the resource are managed, the other ones are left untouched.",
+
+		CreateContext: resourceStorageParameterCreate,
+		ReadContext:   resourceStorageParameterRead,
+		UpdateContext: resourceStorageParameterUpdate,
+		DeleteContext: resourceStorageParameterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceStorageParameterImporter,
+		},
+		CustomizeDiff: resourceStorageParameterCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			storageParameterDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			storageParameterSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			storageParameterTableAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			storageParameterParametersAttr: {
+				Description: "Storage parameters to set, keyed by name, e.g. `sql_stats_automatic_collection_enabled = \"false\"`. " +
+					"Numbers and booleans are passed as is, the other values as strings. Removed parameters are reset.",
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required:         true,
+				ValidateDiagFunc: validation.MapKeyMatch(storageParameterNameRegexp, "invalid storage parameter name"),
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26273), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     storageParameterDefaultLocalPort,
+			},
+		},
+	}, 3)
+}
+
+func resourceStorageParameterCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, storageParameterDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, storageParameterSchemaAttr)
+}
+
+// storageParameterValue returns the SQL value of a storage parameter, numbers
+// and booleans being passed as is.
+func storageParameterValue(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return value
+	}
+
+	return pq.QuoteLiteral(value)
+}
+
+// storageParameterStatements returns the statements changing the storage
+// parameters of the table from o to n.
+func storageParameterStatements(table string, o map[string]interface{}, n map[string]interface{}) []string {
+	var statements []string
+
+	var reset []string
+	for _, name := range sortedKeys(o) {
+		if _, ok := n[name]; !ok {
+			reset = append(reset, name)
+		}
+	}
+	if len(reset) != 0 {
+		statements = append(statements, `ALTER TABLE `+table+` RESET (`+strings.Join(reset, `, `)+`)`)
+	}
+
+	var set []string
+	for _, name := range sortedKeys(n) {
+		value := n[name].(string)
+		if old, ok := o[name]; ok && old.(string) == value {
+			continue
+		}
+		set = append(set, name+` = `+storageParameterValue(value))
+	}
+	if len(set) != 0 {
+		statements = append(statements, `ALTER TABLE `+table+` SET (`+strings.Join(set, `, `)+`)`)
+	}
+
+	return statements
+}
+
+func storageParameterTable(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(storageParameterDatabaseAttr).(string), d.Get(storageParameterSchemaAttr).(string), []string{d.Get(storageParameterTableAttr).(string)})
+}
+
+func storageParameterLockKey(d *schema.ResourceData) string {
+	return objectLockKey("table", d.Get(storageParameterDatabaseAttr).(string), d.Get(storageParameterSchemaAttr).(string), d.Get(storageParameterTableAttr).(string))
+}
+
+// applyStorageParameters runs the statements changing the storage parameters
+// of the table from o to n.
+func applyStorageParameters(ctx context.Context, d *schema.ResourceData, meta interface{}, o map[string]interface{}, n map[string]interface{}) diag.Diagnostics {
+	statements := storageParameterStatements(storageParameterTable(d), o, n)
+	if len(statements) == 0 {
+		return nil
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(storageParameterLockKey(d))
+	defer unlock()
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return nil
+}
+
+func resourceStorageParameterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(storageParameterDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", storageParameterDatabaseAttr)
+	}
+
+	if diags := applyStorageParameters(ctx, d, meta, nil, d.Get(storageParameterParametersAttr).(map[string]interface{})); diags != nil {
+		return diags
+	}
+
+	d.SetId(clusterScopedID("", database, d.Get(storageParameterSchemaAttr).(string), d.Get(storageParameterTableAttr).(string)))
+
+	return resourceStorageParameterRead(ctx, d, meta)
+}
+
+// readStorageParameters reads the storage parameters of the table, ok being
+// false when the table doesn't exist.
+func readStorageParameters(ctx context.Context, conn *pgx.Conn, database string, schemaName string, table string) (map[string]interface{}, bool, error) {
+	db := pq.QuoteIdentifier(database)
+
+	var options []string
+	err := conn.QueryRow(ctx,
+		`SELECT coalesce(c.reloptions, ARRAY[]::STRING[]) FROM `+db+`.pg_catalog.pg_class AS c `+
+			`JOIN `+db+`.pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace `+
+			`WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'r'`,
+		schemaName, table).Scan(&options)
+	if err == pgx.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	parameters := map[string]interface{}{}
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			return nil, false, fmt.Errorf("unexpected storage parameter %q", option)
+		}
+		parameters[parts[0]] = parts[1]
+	}
+
+	return parameters, true, nil
+}
+
+func resourceStorageParameterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, d.Get(storageParameterDatabaseAttr).(string), d.Get(storageParameterSchemaAttr).(string), d.Get(storageParameterTableAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("table %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	current, ok, err := readStorageParameters(ctx, conn, d.Get(storageParameterDatabaseAttr).(string),
+		d.Get(storageParameterSchemaAttr).(string), d.Get(storageParameterTableAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("table %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	// only the parameters of the resource are tracked
+	parameters := d.Get(storageParameterParametersAttr).(map[string]interface{})
+	for name := range parameters {
+		value, ok := current[name]
+		if !ok {
+			delete(parameters, name)
+			continue
+		}
+		parameters[name] = value
+	}
+
+	if err := d.Set(storageParameterParametersAttr, parameters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceStorageParameterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	o, n := d.GetChange(storageParameterParametersAttr)
+	if diags := applyStorageParameters(ctx, d, meta, o.(map[string]interface{}), n.(map[string]interface{})); diags != nil {
+		return diags
+	}
+
+	return resourceStorageParameterRead(ctx, d, meta)
+}
+
+func resourceStorageParameterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyStorageParameters(ctx, d, meta, d.Get(storageParameterParametersAttr).(map[string]interface{}), nil); diags != nil {
+		return diags
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceStorageParameterImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table
+	_, parts, err := parseResourceID(d.Id(), 3)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage parameter id %q, expected database/schema/table: %v", d.Id(), err)
+	}
+
+	values := map[string]interface{}{
+		storageParameterDatabaseAttr: parts[0],
+		storageParameterSchemaAttr:   parts[1],
+		storageParameterTableAttr:    parts[2],
+		argLocalPort:                 storageParameterDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	// every parameter of the table is imported
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return nil, fmt.Errorf("failed to connect to the cluster: %v", diagnosticsError(diags))
+	}
+	defer closeConn()
+
+	parameters, ok, err := readStorageParameters(ctx, conn, parts[0], parts[1], parts[2])
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("table %s not found", d.Id())
+	}
+	if err := d.Set(storageParameterParametersAttr, parameters); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceStorageParameter(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceStorageParameter,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_storage_parameter.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:foo:public:events$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_storage_parameter.foo", "parameters.exclude_data_from_backup", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestStorageParameterStatements(t *testing.T) {
+	o := map[string]interface{}{"exclude_data_from_backup": "true", "fillfactor": "100"}
+	n := map[string]interface{}{"exclude_data_from_backup": "true", "sql_stats_automatic_collection_enabled": "false", "ttl_expiration_expression": "created_at + INTERVAL '1 day'"}
+
+	expected := []string{
+		`ALTER TABLE t RESET (fillfactor)`,
+		`ALTER TABLE t SET (sql_stats_automatic_collection_enabled = false, ttl_expiration_expression = 'created_at + INTERVAL ''1 day''')`,
+	}
+	if actual := storageParameterStatements("t", o, n); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expected)
+	}
+
+	if actual := storageParameterStatements("t", o, o); len(actual) != 0 {
+		t.Errorf("expected no statements without change, got %q", actual)
+	}
+}
+
+const testAccResourceStorageParameter = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "events" {
+  database = cockroach_database.foo.name
+  name     = "events"
+
+  column {
+    name = "payload"
+    type = "JSONB"
+  }
+}
+
+resource "cockroach_storage_parameter" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.events.name
+
+  parameters = {
+    exclude_data_from_backup               = "true"
+    sql_stats_automatic_collection_enabled = "false"
+  }
+}
+`
@@ -0,0 +1,1652 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	tableNameAttr       = "name"
+	tableDatabaseAttr   = "database"
+	tableSchemaAttr     = "schema"
+	tableColumnAttr     = "column"
+	tablePrimaryKeyAttr = "primary_key"
+	tableCheckAttr      = "check"
+	tableUniqueAttr     = "unique"
+	tableIndexAttr      = "index"
+
+	tablePrimaryKeyBucketCountAttr = "primary_key_bucket_count"
+
+	columnNameAttr      = "name"
+	columnTypeAttr      = "type"
+	columnNullableAttr  = "nullable"
+	columnDefaultAttr   = "default"
+	columnOnUpdateAttr  = "on_update"
+	columnComputedAttr  = "computed"
+	columnStoredAttr    = "stored"
+	columnCollationAttr = "collation"
+
+	constraintNameAttr       = "name"
+	constraintExpressionAttr = "expression"
+	constraintColumnsAttr    = "columns"
+
+	indexStoringAttr     = "storing"
+	indexUniqueAttr      = "unique"
+	indexBucketCountAttr = "bucket_count"
+	indexTypeAttr        = "type"
+	indexExpressionsAttr = "expressions"
+	indexOperatorClass   = "operator_class"
+	indexParametersAttr  = "parameters"
+
+	indexTypeForward  = "FORWARD"
+	indexTypeInverted = "INVERTED"
+	indexTypeVector   = "VECTOR"
+
+	tableDefaultLocalPort = "26268"
+)
+
+func resourceTable() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to create a table in a CockroachDB cluster. " +
+			"Columns, the primary key, CHECK and UNIQUE constraints are changed in place with `ALTER TABLE` where CockroachDB allows it.",
+
+		CreateContext: resourceTableCreate,
+		ReadContext:   resourceTableRead,
+		UpdateContext: resourceTableUpdate,
+		DeleteContext: resourceTableDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTableImporter,
+		},
+		CustomizeDiff: resourceTableCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			tableNameAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			tableDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			tableSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			tableColumnAttr: {
+				Description: "Columns of the table, in their order of creation. A column can't be renamed, a column replaced by another one at its position being rejected.",
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						columnNameAttr: {
+							Description: "Name of the column.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						columnTypeAttr: {
+							Description:      "Type of the column, e.g. `INT8` or `STRING`. Aliases such as `INT` and `TEXT` are equivalent to the type CockroachDB reports.",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressEquivalentType,
+						},
+						columnCollationAttr: {
+							Description:      "Collation of the `STRING` column, e.g. `de` or `en_US`, the values being compared and sorted by the rules of this locale. The supported collations are listed by the `cockroach_collations` data source.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressEquivalentCollation,
+						},
+						columnNullableAttr: {
+							Description:      "Whether the column accepts NULL values. The columns of the primary key never do, whatever it is set to.",
+							Type:             schema.TypeBool,
+							Optional:         true,
+							Default:          true,
+							DiffSuppressFunc: suppressPrimaryKeyNullable,
+						},
+						columnDefaultAttr: {
+							Description:      "DEFAULT expression of the column.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressEquivalentExpression,
+						},
+						columnOnUpdateAttr: {
+							Description: "ON UPDATE expression of the column. CockroachDB doesn't report it, so it isn't refreshed.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						columnComputedAttr: {
+							Description:      "Expression computing the column, which is then a computed column. Changing it drops and adds the column back.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressEquivalentExpression,
+						},
+						columnStoredAttr: {
+							Description: "Whether the computed column is stored, it is virtual otherwise.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
+			tablePrimaryKeyAttr: {
+				Description: "Columns of the primary key, CockroachDB adds a hidden `rowid` primary key when not set.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+			tablePrimaryKeyBucketCountAttr: {
+				Description:  "Number of buckets of the primary key when it is hash-sharded, spreading sequential keys over several ranges. The primary key isn't hash-sharded when not set.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateBucketCount,
+			},
+			tableCheckAttr: {
+				Description: "CHECK constraints of the table.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						constraintNameAttr: {
+							Description: "Name of the constraint.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						constraintExpressionAttr: {
+							Description:      "Boolean expression the rows must satisfy.",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressEquivalentExpression,
+						},
+					},
+				},
+			},
+			tableUniqueAttr: {
+				Description: "UNIQUE constraints of the table.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						constraintNameAttr: {
+							Description: "Name of the constraint.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						constraintColumnsAttr: {
+							Description: "Columns which must be unique together.",
+							Type:        schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Required: true,
+							MinItems: 1,
+						},
+					},
+				},
+			},
+			tableIndexAttr: {
+				Description: "Secondary indexes of the table. Changing an index drops it and creates it again.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						constraintNameAttr: {
+							Description: "Name of the index.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						constraintColumnsAttr: {
+							Description: "Columns of the index. At least one column or expression must be set.",
+							Type:        schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Optional: true,
+						},
+						indexExpressionsAttr: {
+							Description: "Expressions indexed after the columns, e.g. `lower(email)` or `doc->'tags'` for the tags of a `JSONB` document in an inverted index. " +
+								"They are compared with the ones CockroachDB reports regardless of the type annotations and parentheses it adds.",
+							Type: schema.TypeList,
+							Elem: &schema.Schema{
+								Type:             schema.TypeString,
+								DiffSuppressFunc: suppressEquivalentExpression,
+							},
+							Optional: true,
+						},
+						indexStoringAttr: {
+							Description: "Columns stored in the index, without being part of its key.",
+							Type:        schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Optional: true,
+						},
+						indexUniqueAttr: {
+							Description: "Whether the index is unique.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						indexBucketCountAttr: {
+							Description:  "Number of buckets of the index when it is hash-sharded. The index isn't hash-sharded when not set.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validateBucketCount,
+						},
+						indexTypeAttr: {
+							Description: "Type of the index, `FORWARD`, `INVERTED` to index the elements of `JSONB` and `ARRAY` columns or the trigrams of `STRING` ones, " +
+								"or `VECTOR` to index the `VECTOR` columns for nearest neighbor searches, which requires CockroachDB v25.2 or later. Only the last column of an inverted or vector index is indexed so.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      indexTypeForward,
+							ValidateFunc: validation.StringInSlice([]string{indexTypeForward, indexTypeInverted, indexTypeVector}, false),
+						},
+						indexOperatorClass: {
+							Description:      "Operator class of the last column of an inverted or vector index, e.g. `gin_trgm_ops` for the trigrams of a `STRING` column or `vector_cosine_ops` for a cosine distance. The default one of the type of the column when not set.",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressDefaultOperatorClass,
+						},
+						indexParametersAttr: {
+							Description: "Storage parameters of the index, e.g. `min_partition_size` and `max_partition_size` for a vector index. CockroachDB doesn't report them, so they aren't refreshed.",
+							Type:        schema.TypeMap,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Optional: true,
+						},
+					},
+				},
+			},
+			gcTTLSecondsAttr:         gcTTLSecondsSchema(),
+			dependentsAttr:           dependentsSchema(),
+			dropBehaviorAttr:         dropBehaviorSchema(),
+			dropGCTTLSecondsAttr:     dropGCTTLSecondsSchema(),
+			waitForGCOnDropAttr:      waitForGCOnDropSchema(),
+			maxDropSizeBytesAttr:     maxDropSizeBytesSchema(),
+			forceAttr:                forceSchema(),
+			descriptorIDAttr:         descriptorIDSchema(),
+			createdAtAttr:            createdAtSchema(),
+			argTolerateExistingState: tolerateExistingStateSchema("table"),
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26268), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     tableDefaultLocalPort,
+			},
+		},
+	}, 3)
+}
+
+type tableColumn struct {
+	name string
+	typ  string
+	// collation is the collation of a collated string, e.g. de
+	collation string
+	nullable  bool
+	def       string
+	onUpdate  string
+	computed  string
+	stored    bool
+}
+
+type tableCheck struct {
+	name       string
+	expression string
+}
+
+type tableUnique struct {
+	name    string
+	columns []string
+}
+
+type tableIndex struct {
+	name    string
+	columns []string
+	storing []string
+	// expressions are indexed after the columns
+	expressions []string
+	unique      bool
+	bucketCount int
+	// typ is FORWARD, INVERTED or VECTOR
+	typ           string
+	operatorClass string
+	parameters    map[string]interface{}
+}
+
+// tableDefinition is the part of the table resource changed with ALTER TABLE.
+type tableDefinition struct {
+	columns               []tableColumn
+	primaryKey            []string
+	primaryKeyBucketCount int
+	checks                []tableCheck
+	uniques               []tableUnique
+	indexes               []tableIndex
+}
+
+// validateBucketCount checks the bucket count of a hash-sharded index, 0
+// meaning the index isn't hash-sharded.
+var validateBucketCount = validation.Any(validation.IntInSlice([]int{0}), validation.IntBetween(2, 2048))
+
+func expandTableColumns(raw []interface{}) []tableColumn {
+	columns := make([]tableColumn, len(raw))
+	for i, r := range raw {
+		c := r.(map[string]interface{})
+		columns[i] = tableColumn{
+			name:      c[columnNameAttr].(string),
+			typ:       c[columnTypeAttr].(string),
+			collation: c[columnCollationAttr].(string),
+			nullable:  c[columnNullableAttr].(bool),
+			def:       c[columnDefaultAttr].(string),
+			onUpdate:  c[columnOnUpdateAttr].(string),
+			computed:  c[columnComputedAttr].(string),
+			stored:    c[columnStoredAttr].(bool),
+		}
+	}
+
+	return columns
+}
+
+func flattenTableColumns(columns []tableColumn) []interface{} {
+	raw := make([]interface{}, len(columns))
+	for i, c := range columns {
+		raw[i] = map[string]interface{}{
+			columnNameAttr:      c.name,
+			columnTypeAttr:      c.typ,
+			columnCollationAttr: c.collation,
+			columnNullableAttr:  c.nullable,
+			columnDefaultAttr:   c.def,
+			columnOnUpdateAttr:  c.onUpdate,
+			columnComputedAttr:  c.computed,
+			columnStoredAttr:    c.stored,
+		}
+	}
+
+	return raw
+}
+
+func expandTableChecks(raw []interface{}) []tableCheck {
+	checks := make([]tableCheck, len(raw))
+	for i, r := range raw {
+		c := r.(map[string]interface{})
+		checks[i] = tableCheck{
+			name:       c[constraintNameAttr].(string),
+			expression: c[constraintExpressionAttr].(string),
+		}
+	}
+
+	return checks
+}
+
+func flattenTableChecks(checks []tableCheck) []interface{} {
+	raw := make([]interface{}, len(checks))
+	for i, c := range checks {
+		raw[i] = map[string]interface{}{
+			constraintNameAttr:       c.name,
+			constraintExpressionAttr: c.expression,
+		}
+	}
+
+	return raw
+}
+
+func expandTableUniques(raw []interface{}) []tableUnique {
+	uniques := make([]tableUnique, len(raw))
+	for i, r := range raw {
+		u := r.(map[string]interface{})
+		uniques[i] = tableUnique{
+			name:    u[constraintNameAttr].(string),
+			columns: convertToString(u[constraintColumnsAttr].([]interface{})),
+		}
+	}
+
+	return uniques
+}
+
+func flattenTableUniques(uniques []tableUnique) []interface{} {
+	raw := make([]interface{}, len(uniques))
+	for i, u := range uniques {
+		raw[i] = map[string]interface{}{
+			constraintNameAttr:    u.name,
+			constraintColumnsAttr: u.columns,
+		}
+	}
+
+	return raw
+}
+
+func expandTableIndexes(raw []interface{}) []tableIndex {
+	indexes := make([]tableIndex, len(raw))
+	for i, r := range raw {
+		idx := r.(map[string]interface{})
+		indexes[i] = tableIndex{
+			name:          idx[constraintNameAttr].(string),
+			columns:       convertToString(idx[constraintColumnsAttr].([]interface{})),
+			expressions:   convertToString(idx[indexExpressionsAttr].([]interface{})),
+			storing:       convertToString(idx[indexStoringAttr].([]interface{})),
+			unique:        idx[indexUniqueAttr].(bool),
+			bucketCount:   idx[indexBucketCountAttr].(int),
+			typ:           idx[indexTypeAttr].(string),
+			operatorClass: idx[indexOperatorClass].(string),
+			parameters:    idx[indexParametersAttr].(map[string]interface{}),
+		}
+	}
+
+	return indexes
+}
+
+func flattenTableIndexes(indexes []tableIndex) []interface{} {
+	raw := make([]interface{}, len(indexes))
+	for i, idx := range indexes {
+		raw[i] = map[string]interface{}{
+			constraintNameAttr:    idx.name,
+			constraintColumnsAttr: idx.columns,
+			indexExpressionsAttr:  idx.expressions,
+			indexStoringAttr:      idx.storing,
+			indexUniqueAttr:       idx.unique,
+			indexBucketCountAttr:  idx.bucketCount,
+			indexTypeAttr:         idx.typ,
+			indexOperatorClass:    idx.operatorClass,
+			indexParametersAttr:   idx.parameters,
+		}
+	}
+
+	return raw
+}
+
+// tableDefinitionOf returns the definition of the table resource, from the
+// state or the configuration depending on the getter.
+func tableDefinitionOf(get func(string) interface{}) tableDefinition {
+	t := tableDefinition{
+		columns:               expandTableColumns(get(tableColumnAttr).([]interface{})),
+		primaryKey:            convertToString(get(tablePrimaryKeyAttr).([]interface{})),
+		primaryKeyBucketCount: get(tablePrimaryKeyBucketCountAttr).(int),
+		checks:                expandTableChecks(get(tableCheckAttr).([]interface{})),
+		uniques:               expandTableUniques(get(tableUniqueAttr).([]interface{})),
+		indexes:               expandTableIndexes(get(tableIndexAttr).([]interface{})),
+	}
+
+	// the columns of the primary key are NOT NULL, as read, whatever their
+	// nullable
+	for i := range t.columns {
+		if contains(t.primaryKey, t.columns[i].name) {
+			t.columns[i].nullable = false
+		}
+	}
+
+	return t
+}
+
+// oldTableDefinition and newTableDefinition return the definition of the table
+// before and after the planned change.
+func oldTableDefinition(d interface {
+	GetChange(string) (interface{}, interface{})
+}) tableDefinition {
+	return tableDefinitionOf(func(k string) interface{} {
+		o, _ := d.GetChange(k)
+		return o
+	})
+}
+
+func newTableDefinition(d interface {
+	GetChange(string) (interface{}, interface{})
+}) tableDefinition {
+	return tableDefinitionOf(func(k string) interface{} {
+		_, n := d.GetChange(k)
+		return n
+	})
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = pq.QuoteIdentifier(n)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// columnType returns the type of the column, with its collation.
+func columnType(c tableColumn) string {
+	if c.collation == "" {
+		return c.typ
+	}
+
+	return c.typ + ` COLLATE ` + pq.QuoteIdentifier(c.collation)
+}
+
+// columnDefinition returns the definition of the column in CREATE TABLE and
+// ADD COLUMN.
+func columnDefinition(c tableColumn) string {
+	def := pq.QuoteIdentifier(c.name) + ` ` + columnType(c)
+	if !c.nullable {
+		def += ` NOT NULL`
+	}
+	if c.def != "" {
+		def += ` DEFAULT ` + c.def
+	}
+	if c.onUpdate != "" {
+		def += ` ON UPDATE ` + c.onUpdate
+	}
+	if c.computed != "" {
+		def += ` AS (` + c.computed + `)`
+		if c.stored {
+			def += ` STORED`
+		} else {
+			def += ` VIRTUAL`
+		}
+	}
+
+	return def
+}
+
+func checkDefinition(c tableCheck) string {
+	return `CONSTRAINT ` + pq.QuoteIdentifier(c.name) + ` CHECK (` + c.expression + `)`
+}
+
+func uniqueDefinition(u tableUnique) string {
+	return `CONSTRAINT ` + pq.QuoteIdentifier(u.name) + ` UNIQUE (` + quoteIdentifiers(u.columns) + `)`
+}
+
+// hashSharded returns the clause hash-sharding an index with the bucket
+// count, if any.
+func hashSharded(bucketCount int) string {
+	if bucketCount == 0 {
+		return ``
+	}
+
+	return fmt.Sprintf(` USING HASH WITH (bucket_count = %d)`, bucketCount)
+}
+
+// primaryKeyColumns returns the columns of the primary key in CREATE TABLE
+// and ALTER PRIMARY KEY.
+func primaryKeyColumns(t tableDefinition) string {
+	return `(` + quoteIdentifiers(t.primaryKey) + `)` + hashSharded(t.primaryKeyBucketCount)
+}
+
+// indexDefinition returns the definition of the index in CREATE TABLE, and in
+// CREATE INDEX after ON <table>.
+func indexDefinition(idx tableIndex) string {
+	var elements []string
+	if len(idx.columns) != 0 {
+		elements = append(elements, quoteIdentifiers(idx.columns))
+	}
+	for _, e := range idx.expressions {
+		elements = append(elements, `(`+e+`)`)
+	}
+	key := strings.Join(elements, `, `)
+	if idx.operatorClass != "" {
+		key += ` ` + idx.operatorClass
+	}
+	def := `(` + key + `)`
+	if idx.bucketCount != 0 {
+		def += ` USING HASH`
+	}
+	if len(idx.storing) != 0 {
+		def += ` STORING (` + quoteIdentifiers(idx.storing) + `)`
+	}
+
+	var parameters []string
+	if idx.bucketCount != 0 {
+		parameters = append(parameters, fmt.Sprintf(`bucket_count = %d`, idx.bucketCount))
+	}
+	for _, k := range sortedKeys(idx.parameters) {
+		parameters = append(parameters, k+` = `+idx.parameters[k].(string))
+	}
+	if len(parameters) != 0 {
+		def += ` WITH (` + strings.Join(parameters, `, `) + `)`
+	}
+
+	return def
+}
+
+// indexKind returns the keywords preceding INDEX in the definition of the
+// index, e.g. `UNIQUE ` or `INVERTED `.
+func indexKind(idx tableIndex) string {
+	kind := ``
+	if idx.unique {
+		kind = `UNIQUE `
+	}
+	if idx.typ == indexTypeInverted || idx.typ == indexTypeVector {
+		kind += idx.typ + ` `
+	}
+
+	return kind
+}
+
+func createIndexStatement(table string, idx tableIndex) string {
+	return `CREATE ` + indexKind(idx) + `INDEX ` + pq.QuoteIdentifier(idx.name) + ` ON ` + table + ` ` + indexDefinition(idx)
+}
+
+func indexesEqual(a tableIndex, b tableIndex) bool {
+	return a.unique == b.unique && a.bucketCount == b.bucketCount &&
+		strings.Join(a.columns, ",") == strings.Join(b.columns, ",") && strings.Join(a.storing, ",") == strings.Join(b.storing, ",") && expressionListsEqual(a.expressions, b.expressions) &&
+		indexTypeOf(a) == indexTypeOf(b) && operatorClassesEqual(a.operatorClass, b.operatorClass) && parametersEqual(a.parameters, b.parameters)
+}
+
+// indexTypeOf returns the type of the index, the indexes of the states
+// created before the type was added being forward ones.
+func indexTypeOf(idx tableIndex) string {
+	if idx.typ == "" {
+		return indexTypeForward
+	}
+
+	return idx.typ
+}
+
+// defaultOperatorClasses are the operator classes CockroachDB uses when none
+// is given, which it may report.
+var defaultOperatorClasses = []string{"jsonb_ops", "array_ops", "vector_l2_ops"}
+
+func operatorClassesEqual(a string, b string) bool {
+	return strings.EqualFold(a, b) || a == "" && contains(defaultOperatorClasses, strings.ToLower(b)) || b == "" && contains(defaultOperatorClasses, strings.ToLower(a))
+}
+
+func suppressDefaultOperatorClass(k, old, new string, d *schema.ResourceData) bool {
+	return operatorClassesEqual(old, new)
+}
+
+func expressionListsEqual(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !expressionsEqual(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parametersEqual(a map[string]interface{}, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// createTableStatement returns the CREATE TABLE statement of the table.
+func createTableStatement(table string, t tableDefinition) string {
+	var defs []string
+	for _, c := range t.columns {
+		defs = append(defs, columnDefinition(c))
+	}
+	if len(t.primaryKey) != 0 {
+		defs = append(defs, `PRIMARY KEY `+primaryKeyColumns(t))
+	}
+	for _, c := range t.checks {
+		defs = append(defs, checkDefinition(c))
+	}
+	for _, u := range t.uniques {
+		defs = append(defs, uniqueDefinition(u))
+	}
+	for _, idx := range t.indexes {
+		defs = append(defs, indexKind(idx)+`INDEX `+pq.QuoteIdentifier(idx.name)+` `+indexDefinition(idx))
+	}
+
+	return `CREATE TABLE ` + table + ` (` + strings.Join(defs, `, `) + `)`
+}
+
+// alterTableStatements returns the statements changing the table from the
+// old definition to the new one. Constraints are dropped before the columns
+// they may use and added after them.
+func alterTableStatements(table string, o tableDefinition, n tableDefinition) []string {
+	var statements []string
+	alter := func(clause string) {
+		statements = append(statements, `ALTER TABLE `+table+` `+clause)
+	}
+
+	newChecks := map[string]tableCheck{}
+	for _, c := range n.checks {
+		newChecks[c.name] = c
+	}
+	oldChecks := map[string]tableCheck{}
+	for _, c := range o.checks {
+		oldChecks[c.name] = c
+		if nc, ok := newChecks[c.name]; !ok || !expressionsEqual(nc.expression, c.expression) {
+			alter(`DROP CONSTRAINT ` + pq.QuoteIdentifier(c.name))
+		}
+	}
+
+	newUniques := map[string]tableUnique{}
+	for _, u := range n.uniques {
+		newUniques[u.name] = u
+	}
+	oldUniques := map[string]tableUnique{}
+	for _, u := range o.uniques {
+		oldUniques[u.name] = u
+		if nu, ok := newUniques[u.name]; !ok || strings.Join(nu.columns, ",") != strings.Join(u.columns, ",") {
+			// UNIQUE constraints are backed by an index of the same name,
+			// dropped without CASCADE so the foreign keys referencing it make
+			// the change fail rather than being dropped along with it
+			statements = append(statements, `DROP INDEX `+table+`@`+pq.QuoteIdentifier(u.name))
+		}
+	}
+
+	newIndexes := map[string]tableIndex{}
+	for _, idx := range n.indexes {
+		newIndexes[idx.name] = idx
+	}
+	oldIndexes := map[string]tableIndex{}
+	for _, idx := range o.indexes {
+		oldIndexes[idx.name] = idx
+		if ni, ok := newIndexes[idx.name]; !ok || !indexesEqual(ni, idx) {
+			statements = append(statements, `DROP INDEX `+table+`@`+pq.QuoteIdentifier(idx.name))
+		}
+	}
+
+	newColumns := map[string]tableColumn{}
+	for _, c := range n.columns {
+		newColumns[c.name] = c
+	}
+	oldColumns := map[string]tableColumn{}
+	for _, c := range o.columns {
+		oldColumns[c.name] = c
+		nc, ok := newColumns[c.name]
+		if !ok {
+			alter(`DROP COLUMN ` + pq.QuoteIdentifier(c.name))
+			continue
+		}
+
+		name := pq.QuoteIdentifier(c.name)
+		// computed columns only hold derived values, so they are changed by
+		// dropping and adding them back
+		if c.computed != "" && (!expressionsEqual(nc.computed, c.computed) || nc.stored != c.stored) {
+			alter(`DROP COLUMN ` + name)
+			alter(`ADD COLUMN ` + columnDefinition(nc))
+			continue
+		}
+
+		if !typesEqual(nc.typ, c.typ) || !collationsEqual(nc.collation, c.collation) {
+			alter(`ALTER COLUMN ` + name + ` TYPE ` + columnType(nc))
+		}
+		if nc.nullable != c.nullable {
+			if nc.nullable {
+				alter(`ALTER COLUMN ` + name + ` DROP NOT NULL`)
+			} else {
+				alter(`ALTER COLUMN ` + name + ` SET NOT NULL`)
+			}
+		}
+		if !expressionsEqual(nc.def, c.def) {
+			if nc.def == "" {
+				alter(`ALTER COLUMN ` + name + ` DROP DEFAULT`)
+			} else {
+				alter(`ALTER COLUMN ` + name + ` SET DEFAULT ` + nc.def)
+			}
+		}
+		if nc.onUpdate != c.onUpdate {
+			if nc.onUpdate == "" {
+				alter(`ALTER COLUMN ` + name + ` DROP ON UPDATE`)
+			} else {
+				alter(`ALTER COLUMN ` + name + ` SET ON UPDATE ` + nc.onUpdate)
+			}
+		}
+	}
+	for _, c := range n.columns {
+		if _, ok := oldColumns[c.name]; !ok {
+			alter(`ADD COLUMN ` + columnDefinition(c))
+		}
+	}
+
+	if strings.Join(n.primaryKey, ",") != strings.Join(o.primaryKey, ",") || n.primaryKeyBucketCount != o.primaryKeyBucketCount {
+		pk := n
+		if len(pk.primaryKey) == 0 {
+			pk = tableDefinition{primaryKey: []string{"rowid"}}
+		}
+		alter(`ALTER PRIMARY KEY USING COLUMNS ` + primaryKeyColumns(pk))
+	}
+
+	for _, c := range n.checks {
+		if oc, ok := oldChecks[c.name]; !ok || !expressionsEqual(oc.expression, c.expression) {
+			alter(`ADD ` + checkDefinition(c))
+		}
+	}
+	for _, u := range n.uniques {
+		if ou, ok := oldUniques[u.name]; !ok || strings.Join(ou.columns, ",") != strings.Join(u.columns, ",") {
+			alter(`ADD ` + uniqueDefinition(u))
+		}
+	}
+	for _, idx := range n.indexes {
+		if oi, ok := oldIndexes[idx.name]; !ok || !indexesEqual(oi, idx) {
+			statements = append(statements, createIndexStatement(table, idx))
+		}
+	}
+
+	return statements
+}
+
+// validateTableIndexes rejects the indexes without key.
+func validateTableIndexes(t tableDefinition) error {
+	for _, idx := range t.indexes {
+		if len(idx.columns) == 0 && len(idx.expressions) == 0 {
+			return fmt.Errorf("index %s must have at least one column or expression", idx.name)
+		}
+	}
+
+	return nil
+}
+
+// validateTableChange rejects the changes CockroachDB can't apply in place.
+func validateTableChange(o tableDefinition, n tableDefinition) error {
+	oldColumns := map[string]tableColumn{}
+	for _, c := range o.columns {
+		oldColumns[c.name] = c
+	}
+
+	newColumns := map[string]bool{}
+	for _, c := range n.columns {
+		newColumns[c.name] = true
+	}
+
+	for i, c := range n.columns {
+		oc, ok := oldColumns[c.name]
+		if !ok {
+			// a column replaced by another one at its position is most likely
+			// renamed, which would drop its data
+			if i < len(o.columns) && !newColumns[o.columns[i].name] {
+				return fmt.Errorf("column %s can't be renamed to %s, it would be dropped along with its data, drop it and add the new column in separate changes instead", o.columns[i].name, c.name)
+			}
+			continue
+		}
+		if oc.computed == "" && c.computed != "" {
+			return fmt.Errorf("column %s can't become a computed column, add a new column instead", c.name)
+		}
+		if oc.computed != "" && c.computed == "" {
+			return fmt.Errorf("column %s can't stop being a computed column, add a new column instead", c.name)
+		}
+	}
+
+	return nil
+}
+
+func resourceTableCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, tableDatabaseAttr); err != nil {
+		return err
+	}
+	if err := setDefaultSchema(d, meta, tableSchemaAttr); err != nil {
+		return err
+	}
+	if err := validateTableIndexes(newTableDefinition(d)); err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	return validateTableChange(oldTableDefinition(d), newTableDefinition(d))
+}
+
+// tableName returns the qualified name of the table of the resource.
+func tableName(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(tableDatabaseAttr).(string), d.Get(tableSchemaAttr).(string), []string{d.Get(tableNameAttr).(string)})
+}
+
+func resourceTableCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(tableDatabaseAttr).(string)
+	schemaName := d.Get(tableSchemaAttr).(string)
+	name := d.Get(tableNameAttr).(string)
+
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", tableDatabaseAttr)
+	}
+
+	// the defaults and computed columns resolve their functions and
+	// sequences in the database of the table
+	conn, closeConn, diags := openConnectionIn(ctx, d, meta, database)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("table", database, schemaName, name))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	table := tableDefinitionOf(d.Get)
+	if _, err := conn.Exec(ctx, createTableStatement(tableName(d), table)); err != nil && !toleratedExistingState(d, err, alreadyExistsCodes) {
+		return diag.FromErr(err)
+	}
+	for _, statement := range gcTTLStatements(tableName(d), 0, d.Get(gcTTLSecondsAttr).(int)) {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	d.SetId(clusterScopedID("", database, schemaName, name))
+	if err := setCreatedAt(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceTableRead(ctx, d, meta)
+}
+
+// tableColumnRow is a column of a table as reported by CockroachDB.
+type tableColumnRow struct {
+	tableColumn
+	hidden bool
+}
+
+// readTable reads the definition of the table, ok being false when the table
+// doesn't exist.
+func readTable(ctx context.Context, conn *pgx.Conn, database string, schemaName string, name string) (t tableDefinition, ok bool, err error) {
+	db := pq.QuoteIdentifier(database)
+
+	var exists bool
+	err = conn.QueryRow(ctx,
+		`SELECT count(*) > 0 FROM `+db+`.information_schema.tables WHERE table_schema = $1 AND table_name = $2`,
+		schemaName, name).Scan(&exists)
+	if err != nil || !exists {
+		return t, false, err
+	}
+
+	stored := map[string]bool{}
+	// columns are the names of the columns, hidden or not, and
+	// hiddenExpressions the expressions of the hidden computed ones
+	columns := map[string]bool{}
+	hiddenExpressions := map[string]string{}
+	rows, err := conn.Query(ctx,
+		`SELECT a.attname, a.attgenerated = 's' FROM `+db+`.pg_catalog.pg_attribute AS a `+
+			`JOIN `+db+`.pg_catalog.pg_class AS c ON c.oid = a.attrelid `+
+			`JOIN `+db+`.pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace `+
+			`WHERE n.nspname = $1 AND c.relname = $2 AND a.attgenerated != ''`,
+		schemaName, name)
+	if err != nil {
+		return t, false, err
+	}
+	for rows.Next() {
+		var column string
+		var isStored bool
+		if err := rows.Scan(&column, &isStored); err != nil {
+			rows.Close()
+			return t, false, err
+		}
+		stored[column] = isStored
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return t, false, err
+	}
+
+	rows, err = conn.Query(ctx,
+		`SELECT column_name, crdb_sql_type, coalesce(collation_name, ''), is_nullable = 'YES', coalesce(column_default, ''), coalesce(generation_expression, ''), is_hidden = 'YES' `+
+			`FROM `+db+`.information_schema.columns WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position`,
+		schemaName, name)
+	if err != nil {
+		return t, false, err
+	}
+	for rows.Next() {
+		var c tableColumnRow
+		if err := rows.Scan(&c.name, &c.typ, &c.collation, &c.nullable, &c.def, &c.computed, &c.hidden); err != nil {
+			rows.Close()
+			return t, false, err
+		}
+		columns[c.name] = true
+		if c.hidden {
+			// the expressions of the indexes are hidden computed columns
+			if c.computed != "" {
+				hiddenExpressions[c.name] = c.computed
+			}
+			continue
+		}
+		c.stored = stored[c.name]
+		// the type of a collated string is reported with its collation
+		c.typ = collateRegexp.ReplaceAllString(c.typ, "")
+		t.columns = append(t.columns, c.tableColumn)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return t, false, err
+	}
+
+	rows, err = conn.Query(ctx,
+		`SELECT tc.constraint_name, tc.constraint_type, k.column_name `+
+			`FROM `+db+`.information_schema.table_constraints AS tc `+
+			`JOIN `+db+`.information_schema.key_column_usage AS k `+
+			`ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name AND k.table_name = tc.table_name `+
+			`WHERE tc.table_schema = $1 AND tc.table_name = $2 AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE') `+
+			`ORDER BY tc.constraint_name, k.ordinal_position`,
+		schemaName, name)
+	if err != nil {
+		return t, false, err
+	}
+	uniques := map[string]int{}
+	var primaryKeyName string
+	for rows.Next() {
+		var constraintName, constraintType, column string
+		if err := rows.Scan(&constraintName, &constraintType, &column); err != nil {
+			rows.Close()
+			return t, false, err
+		}
+		if constraintType == "PRIMARY KEY" {
+			primaryKeyName = constraintName
+			if bucketCount, ok := shardColumnBucketCount(column); ok {
+				t.primaryKeyBucketCount = bucketCount
+				continue
+			}
+			t.primaryKey = append(t.primaryKey, column)
+			continue
+		}
+		i, ok := uniques[constraintName]
+		if !ok {
+			i = len(t.uniques)
+			uniques[constraintName] = i
+			t.uniques = append(t.uniques, tableUnique{name: constraintName})
+		}
+		t.uniques[i].columns = append(t.uniques[i].columns, column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return t, false, err
+	}
+	// the hidden rowid primary key is the one of tables without primary key
+	if len(t.primaryKey) == 1 && t.primaryKey[0] == "rowid" {
+		t.primaryKey = nil
+	}
+
+	rows, err = conn.Query(ctx,
+		`SELECT tc.constraint_name, c.check_clause `+
+			`FROM `+db+`.information_schema.table_constraints AS tc `+
+			`JOIN `+db+`.information_schema.check_constraints AS c `+
+			`ON c.constraint_schema = tc.constraint_schema AND c.constraint_name = tc.constraint_name `+
+			`WHERE tc.table_schema = $1 AND tc.table_name = $2 AND tc.constraint_type = 'CHECK' `+
+			`ORDER BY tc.constraint_name`,
+		schemaName, name)
+	if err != nil {
+		return t, false, err
+	}
+	for rows.Next() {
+		var c tableCheck
+		if err := rows.Scan(&c.name, &c.expression); err != nil {
+			rows.Close()
+			return t, false, err
+		}
+		// NOT NULL columns are reported as CHECK constraints, and the shard
+		// columns of hash-sharded indexes have their own
+		if notNullConstraintRegexp.MatchString(c.name) || strings.HasPrefix(c.name, "check_crdb_internal_") {
+			continue
+		}
+		t.checks = append(t.checks, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return t, false, err
+	}
+
+	rows, err = conn.Query(ctx,
+		`SELECT index_name, non_unique, column_name, storing, implicit `+
+			`FROM [SHOW INDEXES FROM `+qualifiedNames(database, schemaName, []string{name})+`] ORDER BY index_name, seq_in_index`)
+	if err != nil {
+		return t, false, err
+	}
+	indexes := map[string]int{}
+	for rows.Next() {
+		var indexName, column string
+		var nonUnique, storing, implicit bool
+		if err := rows.Scan(&indexName, &nonUnique, &column, &storing, &implicit); err != nil {
+			rows.Close()
+			return t, false, err
+		}
+		if indexName == primaryKeyName {
+			continue
+		}
+		i, ok := indexes[indexName]
+		if !ok {
+			i = len(t.indexes)
+			indexes[indexName] = i
+			t.indexes = append(t.indexes, tableIndex{name: indexName, unique: !nonUnique, typ: indexTypeForward})
+		}
+		switch bucketCount, sharded := shardColumnBucketCount(column); {
+		case sharded:
+			t.indexes[i].bucketCount = bucketCount
+		case implicit:
+			// the columns of the primary key are part of every index
+		case storing:
+			t.indexes[i].storing = append(t.indexes[i].storing, column)
+		case hiddenExpressions[column] != "":
+			t.indexes[i].expressions = append(t.indexes[i].expressions, hiddenExpressions[column])
+		case !columns[column]:
+			// the expressions may be reported instead of their hidden column
+			t.indexes[i].expressions = append(t.indexes[i].expressions, column)
+		default:
+			t.indexes[i].columns = append(t.indexes[i].columns, column)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return t, false, err
+	}
+
+	rows, err = conn.Query(ctx,
+		`SELECT indexname, indexdef FROM `+db+`.pg_catalog.pg_indexes WHERE schemaname = $1 AND tablename = $2`,
+		schemaName, name)
+	if err != nil {
+		return t, false, err
+	}
+	for rows.Next() {
+		var indexName, def string
+		if err := rows.Scan(&indexName, &def); err != nil {
+			rows.Close()
+			return t, false, err
+		}
+		if i, ok := indexes[indexName]; ok {
+			t.indexes[i].typ, t.indexes[i].operatorClass = parseIndexDefinition(def)
+		}
+	}
+	rows.Close()
+
+	return t, true, rows.Err()
+}
+
+var (
+	indexKindRegexp         = regexp.MustCompile(`(?i)^CREATE\s+(?:UNIQUE\s+)?(INVERTED|VECTOR)\s+INDEX\b`)
+	indexAccessMethodRegexp = regexp.MustCompile(`(?i)\bUSING\s+(\w+)\s*\(`)
+	operatorClassRegexp     = regexp.MustCompile(`\s(\w+_ops)\b`)
+	// indexAccessMethodTypes are the types of the indexes reported with the
+	// access method of PostgreSQL
+	indexAccessMethodTypes = map[string]string{"gin": indexTypeInverted, "cspann": indexTypeVector}
+)
+
+// parseIndexDefinition returns the type and the operator class of the index
+// from its definition as reported by pg_indexes, e.g.
+// "CREATE INDEX i ON db.public.t USING gin (j jsonb_ops)".
+func parseIndexDefinition(def string) (typ string, operatorClass string) {
+	typ = indexTypeForward
+	if match := indexKindRegexp.FindStringSubmatch(def); match != nil {
+		typ = strings.ToUpper(match[1])
+	} else if match := indexAccessMethodRegexp.FindStringSubmatch(def); match != nil {
+		if t, ok := indexAccessMethodTypes[strings.ToLower(match[1])]; ok {
+			typ = t
+		}
+	}
+	if match := operatorClassRegexp.FindStringSubmatch(def); match != nil {
+		operatorClass = match[1]
+	}
+
+	return typ, operatorClass
+}
+
+var shardColumnRegexp = regexp.MustCompile(`^crdb_internal_.+_shard_(\d+)$`)
+
+// shardColumnBucketCount returns the bucket count of the hidden shard column
+// of a hash-sharded index, ok being false for the other columns.
+func shardColumnBucketCount(column string) (int, bool) {
+	match := shardColumnRegexp.FindStringSubmatch(column)
+	if match == nil {
+		return 0, false
+	}
+	bucketCount, err := strconv.Atoi(match[1])
+
+	return bucketCount, err == nil
+}
+
+// mergeTableDefinition returns the definition read from CockroachDB in the
+// order of the configured one, keeping what CockroachDB doesn't report.
+func mergeTableDefinition(configured tableDefinition, read tableDefinition) tableDefinition {
+	merged := tableDefinition{primaryKey: read.primaryKey, primaryKeyBucketCount: read.primaryKeyBucketCount}
+
+	readColumns := map[string]tableColumn{}
+	for _, c := range read.columns {
+		readColumns[c.name] = c
+	}
+	seen := map[string]bool{}
+	for _, c := range configured.columns {
+		rc, ok := readColumns[c.name]
+		if !ok {
+			continue
+		}
+		rc.onUpdate = c.onUpdate
+		merged.columns = append(merged.columns, rc)
+		seen[c.name] = true
+	}
+	for _, c := range read.columns {
+		if !seen[c.name] {
+			merged.columns = append(merged.columns, c)
+		}
+	}
+
+	readChecks := map[string]tableCheck{}
+	for _, c := range read.checks {
+		readChecks[c.name] = c
+	}
+	seen = map[string]bool{}
+	for _, c := range configured.checks {
+		if rc, ok := readChecks[c.name]; ok {
+			merged.checks = append(merged.checks, rc)
+			seen[c.name] = true
+		}
+	}
+	for _, c := range read.checks {
+		if !seen[c.name] {
+			merged.checks = append(merged.checks, c)
+		}
+	}
+
+	// unique indexes are also reported as UNIQUE constraints, they are kept
+	// as indexes when configured so and as constraints otherwise
+	readIndexes := map[string]tableIndex{}
+	for _, idx := range read.indexes {
+		readIndexes[idx.name] = idx
+	}
+	seen = map[string]bool{}
+	for _, idx := range configured.indexes {
+		if ri, ok := readIndexes[idx.name]; ok {
+			// the storage parameters of the indexes aren't reported
+			ri.parameters = idx.parameters
+			merged.indexes = append(merged.indexes, ri)
+			seen[idx.name] = true
+		}
+	}
+	indexes := seen
+
+	readUniques := map[string]tableUnique{}
+	for _, u := range read.uniques {
+		readUniques[u.name] = u
+	}
+	seen = map[string]bool{}
+	for _, u := range configured.uniques {
+		if ru, ok := readUniques[u.name]; ok {
+			merged.uniques = append(merged.uniques, ru)
+			seen[u.name] = true
+		}
+	}
+	for _, u := range read.uniques {
+		if !seen[u.name] && !indexes[u.name] {
+			merged.uniques = append(merged.uniques, u)
+			seen[u.name] = true
+		}
+	}
+
+	for _, idx := range read.indexes {
+		if !indexes[idx.name] && !seen[idx.name] {
+			merged.indexes = append(merged.indexes, idx)
+		}
+	}
+
+	return merged
+}
+
+func setTableDefinition(d *schema.ResourceData, t tableDefinition) error {
+	if err := d.Set(tableColumnAttr, flattenTableColumns(t.columns)); err != nil {
+		return err
+	}
+	if err := d.Set(tablePrimaryKeyAttr, t.primaryKey); err != nil {
+		return err
+	}
+	if err := d.Set(tablePrimaryKeyBucketCountAttr, t.primaryKeyBucketCount); err != nil {
+		return err
+	}
+	if err := d.Set(tableCheckAttr, flattenTableChecks(t.checks)); err != nil {
+		return err
+	}
+	if err := d.Set(tableUniqueAttr, flattenTableUniques(t.uniques)); err != nil {
+		return err
+	}
+
+	return d.Set(tableIndexAttr, flattenTableIndexes(t.indexes))
+}
+
+func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(tableDatabaseAttr).(string)
+	schemaName := d.Get(tableSchemaAttr).(string)
+	name := d.Get(tableNameAttr).(string)
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, database, schemaName, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("table %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	read, ok, err := readTable(ctx, conn, database, schemaName, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("table %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := setTableDefinition(d, mergeTableDefinition(tableDefinitionOf(d.Get), read)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	id, err := readTableDescriptorID(ctx, conn, database, schemaName, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(descriptorIDAttr, id); err != nil {
+		return diag.FromErr(err)
+	}
+
+	gcTTL, err := readGCTTLSeconds(ctx, conn, id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(gcTTLSecondsAttr, gcTTL); err != nil {
+		return diag.FromErr(err)
+	}
+
+	dependents, err := tableDependents(ctx, conn, database, schemaName, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(dependentsAttr, dependents); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceTableUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(tableDatabaseAttr).(string)
+	schemaName := d.Get(tableSchemaAttr).(string)
+	name := d.Get(tableNameAttr).(string)
+
+	statements := alterTableStatements(tableName(d), oldTableDefinition(d), newTableDefinition(d))
+	oldGCTTL, newGCTTL := d.GetChange(gcTTLSecondsAttr)
+	statements = append(statements, gcTTLStatements(tableName(d), oldGCTTL.(int), newGCTTL.(int))...)
+	if len(statements) == 0 {
+		return resourceTableRead(ctx, d, meta)
+	}
+
+	// the defaults and computed columns resolve their functions and
+	// sequences in the database of the table
+	conn, closeConn, diags := openConnectionIn(ctx, d, meta, database)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("table", database, schemaName, name))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	// schema changes are run one by one, CockroachDB not supporting every
+	// combination of them in a transaction, e.g. with ALTER PRIMARY KEY
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return resourceTableRead(ctx, d, meta)
+}
+
+func resourceTableDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(tableDatabaseAttr).(string)
+	schemaName := d.Get(tableSchemaAttr).(string)
+	name := d.Get(tableNameAttr).(string)
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("table", database, schemaName, name))
+	defer unlock()
+	defer meta.(*cockroachClient).cache.invalidate()
+
+	if err := checkDropSize(ctx, conn, d, tableName(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	dependents, err := tableDependents(ctx, conn, database, schemaName, name)
+	if err != nil {
+		logError("failed to read the dependents of %s: %v", tableName(d), err)
+	}
+
+	if dropGCTTL := d.Get(dropGCTTLSecondsAttr).(int); dropGCTTL > 0 {
+		for _, statement := range gcTTLStatements(tableName(d), d.Get(gcTTLSecondsAttr).(int), dropGCTTL) {
+			if _, err := conn.Exec(ctx, statement); err != nil && !toleratedExistingState(d, err, doesNotExistCodes) {
+				return diag.Errorf("failed to run %q: %v", statement, err)
+			}
+		}
+	}
+
+	dropBehavior := d.Get(dropBehaviorAttr).(string)
+	if _, err := conn.Exec(ctx, `DROP TABLE IF EXISTS `+tableName(d)+` `+dropBehavior); err != nil && !toleratedExistingState(d, err, doesNotExistCodes) {
+		if dropBehavior == dropBehaviorRestrict && len(dependents) > 0 {
+			return diag.Errorf("failed to drop %s, depended on by %s, set %s to %s to drop them along with it: %v",
+				tableName(d), strings.Join(dependents, ", "), dropBehaviorAttr, dropBehaviorCascade, err)
+		}
+		return diag.FromErr(err)
+	}
+
+	if d.Get(waitForGCOnDropAttr).(bool) {
+		if err := waitForGCJob(ctx, conn, int64(d.Get(descriptorIDAttr).(int)), d.Timeout(schema.TimeoutDelete)); err != nil {
+			return diag.Errorf("failed to wait for the data of %s to be garbage collected: %v", tableName(d), err)
+		}
+	}
+
+	d.SetId("")
+
+	return append(diag.Diagnostics{}, dependentsWarning("table "+tableName(d), dependents)...)
+}
+
+func resourceTableImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table
+	_, parts, err := parseResourceID(d.Id(), 3)
+	if err != nil {
+		return nil, fmt.Errorf("invalid table id %q, expected database/schema/table: %v", d.Id(), err)
+	}
+
+	if err := d.Set(tableDatabaseAttr, parts[0]); err != nil {
+		return nil, err
+	}
+	if err := d.Set(tableSchemaAttr, parts[1]); err != nil {
+		return nil, err
+	}
+	if err := d.Set(tableNameAttr, parts[2]); err != nil {
+		return nil, err
+	}
+	if err := d.Set(argLocalPort, tableDefaultLocalPort); err != nil {
+		return nil, err
+	}
+	if err := d.Set(dropBehaviorAttr, dropBehaviorRestrict); err != nil {
+		return nil, err
+	}
+	if err := d.Set(waitForGCOnDropAttr, false); err != nil {
+		return nil, err
+	}
+	if err := d.Set(forceAttr, false); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// typeAliases maps the type names accepted by CockroachDB to the ones it
+// reports.
+var typeAliases = map[string]string{
+	"INT":                         "INT8",
+	"INTEGER":                     "INT8",
+	"BIGINT":                      "INT8",
+	"INT64":                       "INT8",
+	"SMALLINT":                    "INT2",
+	"STRING":                      "STRING",
+	"TEXT":                        "STRING",
+	"VARCHAR":                     "STRING",
+	"CHARACTER VARYING":           "STRING",
+	"BOOLEAN":                     "BOOL",
+	"FLOAT":                       "FLOAT8",
+	"DOUBLE PRECISION":            "FLOAT8",
+	"REAL":                        "FLOAT4",
+	"NUMERIC":                     "DECIMAL",
+	"DEC":                         "DECIMAL",
+	"TIMESTAMP WITHOUT TIME ZONE": "TIMESTAMP",
+	"TIMESTAMP WITH TIME ZONE":    "TIMESTAMPTZ",
+	"BYTEA":                       "BYTES",
+	"BLOB":                        "BYTES",
+	"JSON":                        "JSONB",
+}
+
+// normalizeType returns the name CockroachDB reports for the type.
+func normalizeType(t string) string {
+	t = strings.ToUpper(strings.Join(strings.Fields(t), " "))
+
+	array := ""
+	for strings.HasSuffix(t, "[]") {
+		t = strings.TrimSpace(strings.TrimSuffix(t, "[]"))
+		array += "[]"
+	}
+
+	params := ""
+	if i := strings.Index(t, "("); i >= 0 {
+		t, params = strings.TrimSpace(t[:i]), strings.ReplaceAll(t[i:], " ", "")
+	}
+	if alias, ok := typeAliases[t]; ok {
+		t = alias
+	}
+
+	return t + params + array
+}
+
+func typesEqual(a string, b string) bool {
+	return normalizeType(a) == normalizeType(b)
+}
+
+func suppressEquivalentType(k, old, new string, d *schema.ResourceData) bool {
+	return typesEqual(old, new)
+}
+
+var collateRegexp = regexp.MustCompile(`(?i)\s+COLLATE\s+.+$`)
+
+// collationsEqual compares the locales of the collations, CockroachDB
+// reporting them in their canonical form, e.g. en_US for en-us.
+func collationsEqual(a string, b string) bool {
+	normalize := func(c string) string {
+		return strings.ReplaceAll(strings.ToLower(strings.Trim(c, `"`)), "-", "_")
+	}
+
+	return normalize(a) == normalize(b)
+}
+
+func suppressEquivalentCollation(k, old, new string, d *schema.ResourceData) bool {
+	return collationsEqual(old, new)
+}
+
+// suppressPrimaryKeyNullable suppresses the diff of nullable for the columns
+// of the primary key, read as NOT NULL whatever they are configured as.
+func suppressPrimaryKeyNullable(k, old, new string, d *schema.ResourceData) bool {
+	name := d.Get(strings.TrimSuffix(k, columnNullableAttr) + columnNameAttr).(string)
+
+	return contains(convertToString(d.Get(tablePrimaryKeyAttr).([]interface{})), name)
+}
+
+// notNullConstraintRegexp matches the names of the CHECK constraints
+// information_schema reports for the NOT NULL columns, e.g. 2200_104_1_not_null
+// for the namespace, the table and the column.
+var notNullConstraintRegexp = regexp.MustCompile(`^[0-9]+_[0-9]+_[0-9]+_not_null$`)
+
+var typeAnnotation = regexp.MustCompile(`:::[A-Za-z0-9_]+(\[\])?`)
+
+// normalizeExpression removes what CockroachDB adds to the expressions it
+// reports: type annotations and enclosing parentheses.
+func normalizeExpression(e string) string {
+	e = typeAnnotation.ReplaceAllString(e, "")
+	e = strings.Join(strings.Fields(e), " ")
+	for strings.HasPrefix(e, "(") && strings.HasSuffix(e, ")") && balancedParentheses(e[1:len(e)-1]) {
+		e = strings.TrimSpace(e[1 : len(e)-1])
+	}
+
+	return lowerOutsideQuotes(e)
+}
+
+// lowerOutsideQuotes lowercases the expression but its string literals and
+// quoted identifiers, whose case matters.
+func lowerOutsideQuotes(e string) string {
+	var out strings.Builder
+	for i := 0; i < len(e); {
+		if e[i] != '\'' && e[i] != '"' {
+			end := strings.IndexAny(e[i:], `'"`)
+			if end < 0 {
+				end = len(e) - i
+			}
+			out.WriteString(strings.ToLower(e[i : i+end]))
+			i += end
+			continue
+		}
+
+		// a doubled quote is read as the end of a quoted part and the start
+		// of the next one, both kept as is
+		end := strings.IndexByte(e[i+1:], e[i])
+		if end < 0 {
+			out.WriteString(e[i:])
+			break
+		}
+		out.WriteString(e[i : i+end+2])
+		i += end + 2
+	}
+
+	return out.String()
+}
+
+// balancedParentheses returns whether the parentheses of the expression are
+// balanced, i.e. enclosing parentheses can be removed.
+func balancedParentheses(e string) bool {
+	depth := 0
+	for _, c := range e {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+
+	return depth == 0
+}
+
+func expressionsEqual(a string, b string) bool {
+	return normalizeExpression(a) == normalizeExpression(b)
+}
+
+func suppressEquivalentExpression(k, old, new string, d *schema.ResourceData) bool {
+	return expressionsEqual(old, new)
+}
@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	tableAuditDatabaseAttr = "database"
+	tableAuditSchemaAttr   = "schema"
+	tableAuditTableAttr    = "table"
+
+	tableAuditDefaultLocalPort = "26284"
+)
+
+func resourceTableAudit() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to audit the reads and writes of a table of a CockroachDB cluster, logged to the `SENSITIVE_ACCESS` channel. " +
+			"The audit is turned off when the resource is destroyed.",
+
+		CreateContext: resourceTableAuditCreate,
+		ReadContext:   resourceTableAuditRead,
+		DeleteContext: resourceTableAuditDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTableAuditImporter,
+		},
+		CustomizeDiff: resourceTableAuditCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			tableAuditDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			tableAuditSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			tableAuditTableAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26284), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     tableAuditDefaultLocalPort,
+			},
+		},
+	}, 3)
+}
+
+func resourceTableAuditCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, tableAuditDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, tableAuditSchemaAttr)
+}
+
+func tableAuditTable(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(tableAuditDatabaseAttr).(string), d.Get(tableAuditSchemaAttr).(string), []string{d.Get(tableAuditTableAttr).(string)})
+}
+
+// setTableAudit turns the audit of the table on or off.
+func setTableAudit(ctx context.Context, d *schema.ResourceData, meta interface{}, mode string) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("table", d.Get(tableAuditDatabaseAttr).(string), d.Get(tableAuditSchemaAttr).(string), d.Get(tableAuditTableAttr).(string)))
+	defer unlock()
+
+	if _, err := conn.Exec(ctx, `ALTER TABLE `+tableAuditTable(d)+` EXPERIMENTAL_AUDIT SET `+mode); err != nil {
+		return diag.Errorf("failed to set the audit of table %s to %s: %v", tableAuditTable(d), mode, err)
+	}
+
+	return nil
+}
+
+func resourceTableAuditCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(tableAuditDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", tableAuditDatabaseAttr)
+	}
+
+	if diags := setTableAudit(ctx, d, meta, `READ WRITE`); diags != nil {
+		return diags
+	}
+
+	d.SetId(clusterScopedID("", database, d.Get(tableAuditSchemaAttr).(string), d.Get(tableAuditTableAttr).(string)))
+
+	return resourceTableAuditRead(ctx, d, meta)
+}
+
+// tableAudited returns whether the audit of the table is on, which SHOW CREATE
+// TABLE reports, ok being false when the table doesn't exist.
+func tableAudited(ctx context.Context, conn *pgx.Conn, table string) (bool, bool, error) {
+	var statement string
+	err := conn.QueryRow(ctx, `SELECT create_statement FROM [SHOW CREATE TABLE `+table+`]`).Scan(&statement)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	return strings.Contains(strings.ToUpper(statement), "EXPERIMENTAL_AUDIT SET READ WRITE"), true, nil
+}
+
+func resourceTableAuditRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, d.Get(tableAuditDatabaseAttr).(string), d.Get(tableAuditSchemaAttr).(string), d.Get(tableAuditTableAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("table %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	audited, ok, err := tableAudited(ctx, conn, tableAuditTable(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok || !audited {
+		logInfo("table %s not found or not audited, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceTableAuditDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := setTableAudit(ctx, d, meta, `OFF`); diags != nil {
+		return diags
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceTableAuditImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table
+	_, parts, err := parseResourceID(d.Id(), 3)
+	if err != nil {
+		return nil, fmt.Errorf("invalid table audit id %q, expected database/schema/table: %v", d.Id(), err)
+	}
+
+	values := map[string]interface{}{
+		tableAuditDatabaseAttr: parts[0],
+		tableAuditSchemaAttr:   parts[1],
+		tableAuditTableAttr:    parts[2],
+		argLocalPort:           tableAuditDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
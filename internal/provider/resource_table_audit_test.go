@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceTableAudit(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTableAudit,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("cockroach_table_audit.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:table_audit_test:public:payments$")),
+				),
+			},
+			{
+				ResourceName:      "cockroach_table_audit.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccResourceTableAudit = `
+resource "cockroach_database" "foo" {
+  name = "table_audit_test"
+}
+
+resource "cockroach_table" "foo" {
+  database = cockroach_database.foo.name
+  name     = "payments"
+
+  column {
+    name = "id"
+    type = "INT8"
+  }
+
+  primary_key = ["id"]
+}
+
+resource "cockroach_table_audit" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.foo.name
+}
+`
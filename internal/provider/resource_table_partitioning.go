@@ -0,0 +1,500 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	partitioningDatabaseAttr  = "database"
+	partitioningSchemaAttr    = "schema"
+	partitioningTableAttr     = "table"
+	partitioningIndexAttr     = "index"
+	partitioningByAttr        = "by"
+	partitioningColumnsAttr   = "columns"
+	partitioningPartitionAttr = "partition"
+
+	partitionNameAttr       = "name"
+	partitionValuesAttr     = "values"
+	partitionFromAttr       = "from"
+	partitionToAttr         = "to"
+	partitionZoneConfigAttr = "zone_config"
+
+	partitionByList  = "LIST"
+	partitionByRange = "RANGE"
+
+	partitioningDefaultLocalPort = "26270"
+)
+
+func resourceTablePartitioning() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to partition a table or an index of a CockroachDB cluster with `PARTITION BY LIST` or `PARTITION BY RANGE`, " +
+			"and to configure the zone of each partition, e.g. to pin the rows of a region to its nodes. " +
+			"Unlike the one of the table, its id isn't scoped to the cluster, the partitioning of the table of the same name being adopted when the configuration is applied to another cluster.",
+
+		CreateContext: resourceTablePartitioningCreate,
+		ReadContext:   resourceTablePartitioningRead,
+		UpdateContext: resourceTablePartitioningUpdate,
+		DeleteContext: resourceTablePartitioningDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTablePartitioningImporter,
+		},
+		CustomizeDiff: resourceTablePartitioningCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			partitioningDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			partitioningSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			partitioningTableAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			partitioningIndexAttr: {
+				Description: "Name of the index to partition, the primary index of the table is partitioned when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			partitioningByAttr: {
+				Description:  "Kind of partitioning, `LIST` or `RANGE`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{partitionByList, partitionByRange}, true),
+				StateFunc: func(v interface{}) string {
+					return strings.ToUpper(v.(string))
+				},
+			},
+			partitioningColumnsAttr: {
+				Description: "Columns the rows are partitioned by, they must be a prefix of the columns of the index.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Required: true,
+				MinItems: 1,
+			},
+			partitioningPartitionAttr: {
+				Description: "Partitions of the table or the index.",
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						partitionNameAttr: {
+							Description: "Name of the partition.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						partitionValuesAttr: {
+							Description: "Values of the columns of a `LIST` partition, as SQL expressions, e.g. `'us-east'`, `('us-east', 1)` with several columns or `DEFAULT`.",
+							Type:        schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Optional: true,
+						},
+						partitionFromAttr: {
+							Description: "Inclusive lower bound of a `RANGE` partition, as SQL expressions separated by commas, e.g. `0` or `MINVALUE`.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						partitionToAttr: {
+							Description: "Exclusive upper bound of a `RANGE` partition, as SQL expressions separated by commas, e.g. `1000` or `MAXVALUE`.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						partitionZoneConfigAttr: zoneConfigSchema("Zone configuration of the partition."),
+					},
+				},
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26270), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     partitioningDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// tablePartition is a partition of the resource.
+type tablePartition struct {
+	name       string
+	values     []string
+	from       string
+	to         string
+	zoneConfig map[string]interface{}
+}
+
+func expandTablePartitions(raw []interface{}) []tablePartition {
+	partitions := make([]tablePartition, len(raw))
+	for i, r := range raw {
+		p := r.(map[string]interface{})
+		partitions[i] = tablePartition{
+			name:       p[partitionNameAttr].(string),
+			values:     convertToString(p[partitionValuesAttr].([]interface{})),
+			from:       p[partitionFromAttr].(string),
+			to:         p[partitionToAttr].(string),
+			zoneConfig: p[partitionZoneConfigAttr].(map[string]interface{}),
+		}
+	}
+
+	return partitions
+}
+
+func flattenTablePartitions(partitions []tablePartition) []interface{} {
+	raw := make([]interface{}, len(partitions))
+	for i, p := range partitions {
+		raw[i] = map[string]interface{}{
+			partitionNameAttr:       p.name,
+			partitionValuesAttr:     p.values,
+			partitionFromAttr:       p.from,
+			partitionToAttr:         p.to,
+			partitionZoneConfigAttr: p.zoneConfig,
+		}
+	}
+
+	return raw
+}
+
+// validatePartitions checks the partitions have the bounds of the kind of
+// partitioning.
+func validatePartitions(by string, partitions []tablePartition) error {
+	for _, p := range partitions {
+		if strings.EqualFold(by, partitionByList) {
+			if len(p.values) == 0 || p.from != "" || p.to != "" {
+				return fmt.Errorf("partition %s: %s is required and %s and %s are not allowed by LIST", p.name, partitionValuesAttr, partitionFromAttr, partitionToAttr)
+			}
+		} else if p.from == "" || p.to == "" || len(p.values) != 0 {
+			return fmt.Errorf("partition %s: %s and %s are required and %s is not allowed by RANGE", p.name, partitionFromAttr, partitionToAttr, partitionValuesAttr)
+		}
+	}
+
+	return nil
+}
+
+func resourceTablePartitioningCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, partitioningDatabaseAttr); err != nil {
+		return err
+	}
+	if err := setDefaultSchema(d, meta, partitioningSchemaAttr); err != nil {
+		return err
+	}
+
+	if !d.NewValueKnown(partitioningByAttr) || !d.NewValueKnown(partitioningPartitionAttr) {
+		return nil
+	}
+
+	partitions := expandTablePartitions(d.Get(partitioningPartitionAttr).([]interface{}))
+	if err := validatePartitions(d.Get(partitioningByAttr).(string), partitions); err != nil {
+		return err
+	}
+	if !d.HasChange(partitioningPartitionAttr) {
+		return nil
+	}
+
+	configs := make([]map[string]interface{}, len(partitions))
+	for i, p := range partitions {
+		configs[i] = p.zoneConfig
+	}
+	return validateZoneConfigLocalities(ctx, d, meta, configs)
+}
+
+// partitionedObject returns the table or the index of the resource, as
+// expected by ALTER TABLE and ALTER INDEX.
+func partitionedObject(d *schema.ResourceData) string {
+	table := qualifiedNames(d.Get(partitioningDatabaseAttr).(string), d.Get(partitioningSchemaAttr).(string), []string{d.Get(partitioningTableAttr).(string)})
+	if index := d.Get(partitioningIndexAttr).(string); index != "" {
+		return `INDEX ` + table + `@` + pq.QuoteIdentifier(index)
+	}
+
+	return `TABLE ` + table
+}
+
+// partitionByStatement returns the statement partitioning the table or the
+// index, replacing its previous partitions.
+func partitionByStatement(object string, by string, columns []string, partitions []tablePartition) string {
+	by = strings.ToUpper(by)
+
+	defs := make([]string, len(partitions))
+	for i, p := range partitions {
+		if by == partitionByList {
+			defs[i] = `PARTITION ` + pq.QuoteIdentifier(p.name) + ` VALUES IN (` + strings.Join(p.values, `, `) + `)`
+		} else {
+			defs[i] = `PARTITION ` + pq.QuoteIdentifier(p.name) + ` VALUES FROM (` + p.from + `) TO (` + p.to + `)`
+		}
+	}
+
+	return `ALTER ` + object + ` PARTITION BY ` + by + ` (` + quoteIdentifiers(columns) + `) (` + strings.Join(defs, `, `) + `)`
+}
+
+// partitionZoneStatements returns the statements configuring the zones of the
+// partitions, from the old partitions to the new ones.
+func partitionZoneStatements(object string, o []tablePartition, n []tablePartition) []string {
+	oldZones := map[string]map[string]interface{}{}
+	for _, p := range o {
+		oldZones[p.name] = p.zoneConfig
+	}
+
+	var statements []string
+	for _, p := range n {
+		target := `PARTITION ` + pq.QuoteIdentifier(p.name) + ` OF ` + object
+		statements = append(statements, configureZoneStatements(target, oldZones[p.name], p.zoneConfig)...)
+	}
+
+	return statements
+}
+
+// partitionsEqual returns whether the partitions have the same bounds, i.e.
+// only their zone configurations differ.
+func partitionsEqual(o []tablePartition, n []tablePartition) bool {
+	if len(o) != len(n) {
+		return false
+	}
+	for i := range o {
+		if o[i].name != n[i].name || strings.Join(o[i].values, "\x00") != strings.Join(n[i].values, "\x00") || o[i].from != n[i].from || o[i].to != n[i].to {
+			return false
+		}
+	}
+
+	return true
+}
+
+func partitioningLockKey(d *schema.ResourceData) string {
+	return objectLockKey("table", d.Get(partitioningDatabaseAttr).(string), d.Get(partitioningSchemaAttr).(string), d.Get(partitioningTableAttr).(string))
+}
+
+func resourceTablePartitioningCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(partitioningDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", partitioningDatabaseAttr)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(partitioningLockKey(d))
+	defer unlock()
+
+	object := partitionedObject(d)
+	partitions := expandTablePartitions(d.Get(partitioningPartitionAttr).([]interface{}))
+	statements := append(
+		[]string{partitionByStatement(object, d.Get(partitioningByAttr).(string), convertToString(d.Get(partitioningColumnsAttr).([]interface{})), partitions)},
+		partitionZoneStatements(object, nil, partitions)...)
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	id := strings.Join([]string{database, d.Get(partitioningSchemaAttr).(string), d.Get(partitioningTableAttr).(string)}, "/")
+	if index := d.Get(partitioningIndexAttr).(string); index != "" {
+		id += "@" + index
+	}
+	d.SetId(id)
+
+	return resourceTablePartitioningRead(ctx, d, meta)
+}
+
+// readPartitions reads the partitions of the index, or of the primary index
+// when index is empty, with their zone configurations. ok is false when the
+// table or the index doesn't exist.
+func readPartitions(ctx context.Context, conn *pgx.Conn, database string, schemaName string, table string, index string) (partitions []tablePartition, ok bool, err error) {
+	db := pq.QuoteIdentifier(database)
+
+	if index == "" {
+		err = conn.QueryRow(ctx,
+			`SELECT constraint_name FROM `+db+`.information_schema.table_constraints `+
+				`WHERE table_schema = $1 AND table_name = $2 AND constraint_type = 'PRIMARY KEY'`,
+			schemaName, table).Scan(&index)
+		if err == pgx.ErrNoRows {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	} else {
+		var exists bool
+		err = conn.QueryRow(ctx,
+			`SELECT count(*) > 0 FROM `+db+`.pg_catalog.pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3`,
+			schemaName, table, index).Scan(&exists)
+		if err != nil || !exists {
+			return nil, false, err
+		}
+	}
+
+	rows, err := conn.Query(ctx,
+		`SELECT partition_name, coalesce(zone_config, '') FROM [SHOW PARTITIONS FROM INDEX `+
+			qualifiedNames(database, schemaName, []string{table})+`@`+pq.QuoteIdentifier(index)+`] `+
+			`WHERE parent_partition IS NULL`)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p tablePartition
+		var zoneConfig string
+		if err := rows.Scan(&p.name, &zoneConfig); err != nil {
+			return nil, false, err
+		}
+		p.zoneConfig = parseZoneConfig(zoneConfig)
+		partitions = append(partitions, p)
+	}
+
+	return partitions, true, rows.Err()
+}
+
+func resourceTablePartitioningRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	read, ok, err := readPartitions(ctx, conn, d.Get(partitioningDatabaseAttr).(string), d.Get(partitioningSchemaAttr).(string),
+		d.Get(partitioningTableAttr).(string), d.Get(partitioningIndexAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("table or index %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	// CockroachDB reports the bounds of the partitions in its own format, the
+	// configured ones are kept for the partitions which still exist
+	configured := map[string]tablePartition{}
+	for _, p := range expandTablePartitions(d.Get(partitioningPartitionAttr).([]interface{})) {
+		configured[p.name] = p
+	}
+	partitions := make([]tablePartition, len(read))
+	for i, p := range read {
+		if c, ok := configured[p.name]; ok {
+			p.values, p.from, p.to = c.values, c.from, c.to
+		}
+		partitions[i] = p
+	}
+
+	if err := d.Set(partitioningPartitionAttr, flattenTablePartitions(partitions)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceTablePartitioningUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	object := partitionedObject(d)
+	o, n := d.GetChange(partitioningPartitionAttr)
+	oldPartitions := expandTablePartitions(o.([]interface{}))
+	newPartitions := expandTablePartitions(n.([]interface{}))
+
+	var statements []string
+	if d.HasChanges(partitioningByAttr, partitioningColumnsAttr) || !partitionsEqual(oldPartitions, newPartitions) {
+		statements = append(statements, partitionByStatement(object, d.Get(partitioningByAttr).(string), convertToString(d.Get(partitioningColumnsAttr).([]interface{})), newPartitions))
+	}
+	statements = append(statements, partitionZoneStatements(object, oldPartitions, newPartitions)...)
+	if len(statements) == 0 {
+		return resourceTablePartitioningRead(ctx, d, meta)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(partitioningLockKey(d))
+	defer unlock()
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return resourceTablePartitioningRead(ctx, d, meta)
+}
+
+func resourceTablePartitioningDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(partitioningLockKey(d))
+	defer unlock()
+
+	object := partitionedObject(d)
+	var statements []string
+	for _, p := range expandTablePartitions(d.Get(partitioningPartitionAttr).([]interface{})) {
+		if len(p.zoneConfig) != 0 {
+			statements = append(statements, `ALTER PARTITION `+pq.QuoteIdentifier(p.name)+` OF `+object+` CONFIGURE ZONE DISCARD`)
+		}
+	}
+	statements = append(statements, `ALTER `+object+` PARTITION BY NOTHING`)
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceTablePartitioningImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table[@index]
+	id := d.Id()
+	index := ""
+	if i := strings.LastIndex(id, "@"); i >= 0 {
+		id, index = id[:i], id[i+1:]
+	}
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid table partitioning id %q, expected database/schema/table[@index]", d.Id())
+	}
+
+	values := map[string]interface{}{
+		partitioningDatabaseAttr: parts[0],
+		partitioningSchemaAttr:   parts[1],
+		partitioningTableAttr:    parts[2],
+		partitioningIndexAttr:    index,
+		argLocalPort:             partitioningDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
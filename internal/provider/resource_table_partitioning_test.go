@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceTablePartitioning(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTablePartitioning,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_table_partitioning.foo", "id", regexp.MustCompile("^foo/public/users$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_table_partitioning.foo", "partition.#", "2"),
+					resource.TestCheckResourceAttr(
+						"cockroach_table_partitioning.foo", "partition.0.zone_config.gc.ttlseconds", "600"),
+				),
+			},
+		},
+	})
+}
+
+func TestPartitionByStatement(t *testing.T) {
+	list := partitionByStatement(`TABLE "foo"."public"."users"`, "list", []string{"region"}, []tablePartition{
+		{name: "us", values: []string{"'us-east'", "'us-west'"}},
+		{name: "other", values: []string{"DEFAULT"}},
+	})
+	expected := `ALTER TABLE "foo"."public"."users" PARTITION BY LIST ("region") ` +
+		`(PARTITION "us" VALUES IN ('us-east', 'us-west'), PARTITION "other" VALUES IN (DEFAULT))`
+	if list != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", list, expected)
+	}
+
+	rng := partitionByStatement(`INDEX "foo"."public"."events"@"events_ts_idx"`, "RANGE", []string{"ts"}, []tablePartition{
+		{name: "archive", from: "MINVALUE", to: "'2024-01-01'"},
+		{name: "recent", from: "'2024-01-01'", to: "MAXVALUE"},
+	})
+	expected = `ALTER INDEX "foo"."public"."events"@"events_ts_idx" PARTITION BY RANGE ("ts") ` +
+		`(PARTITION "archive" VALUES FROM (MINVALUE) TO ('2024-01-01'), PARTITION "recent" VALUES FROM ('2024-01-01') TO (MAXVALUE))`
+	if rng != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", rng, expected)
+	}
+}
+
+func TestValidatePartitions(t *testing.T) {
+	if err := validatePartitions("LIST", []tablePartition{{name: "p", from: "0", to: "1"}}); err == nil {
+		t.Errorf("expected an error for a LIST partition with bounds")
+	}
+	if err := validatePartitions("RANGE", []tablePartition{{name: "p", values: []string{"1"}}}); err == nil {
+		t.Errorf("expected an error for a RANGE partition with values")
+	}
+	if err := validatePartitions("range", []tablePartition{{name: "p", from: "0", to: "1"}}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPartitionZoneStatements(t *testing.T) {
+	o := []tablePartition{{name: "us", zoneConfig: map[string]interface{}{"num_replicas": "3"}}}
+	n := []tablePartition{
+		{name: "us", zoneConfig: map[string]interface{}{"num_replicas": "3"}},
+		{name: "eu", zoneConfig: map[string]interface{}{"constraints": "[+region=eu-west1]"}},
+	}
+
+	expected := []string{`ALTER PARTITION "eu" OF TABLE t CONFIGURE ZONE USING constraints = '[+region=eu-west1]'`}
+	if actual := partitionZoneStatements("TABLE t", o, n); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements %q", actual)
+	}
+}
+
+const testAccResourceTablePartitioning = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "users" {
+  database    = cockroach_database.foo.name
+  name        = "users"
+  primary_key = ["region", "id"]
+
+  column {
+    name     = "region"
+    type     = "STRING"
+    nullable = false
+  }
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+}
+
+resource "cockroach_table_partitioning" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.users.name
+  by       = "LIST"
+  columns  = ["region"]
+
+  partition {
+    name   = "us"
+    values = ["'us-east'", "'us-west'"]
+
+    zone_config = {
+      "gc.ttlseconds" = "600"
+    }
+  }
+
+  partition {
+    name   = "other"
+    values = ["DEFAULT"]
+  }
+}
+`
@@ -0,0 +1,431 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccResourceTable(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTable,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_table.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:foo:public:orders$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_table.foo", "column.#", "4"),
+					resource.TestCheckResourceAttr(
+						"cockroach_table.foo", "column.3.stored", "true"),
+					resource.TestCheckResourceAttr(
+						"cockroach_table.foo", "gc_ttl_seconds", "600"),
+				),
+			},
+		},
+	})
+}
+
+func TestCreateTableStatement(t *testing.T) {
+	table := tableDefinition{
+		columns: []tableColumn{
+			{name: "id", typ: "INT8", def: "unique_rowid()"},
+			{name: "quantity", typ: "INT8"},
+			{name: "price", typ: "DECIMAL", nullable: true},
+			{name: "total", typ: "DECIMAL", nullable: true, computed: "quantity * price", stored: true},
+		},
+		primaryKey: []string{"id"},
+		checks:     []tableCheck{{name: "positive", expression: "quantity > 0"}},
+		uniques:    []tableUnique{{name: "u", columns: []string{"quantity", "price"}}},
+	}
+
+	expected := `CREATE TABLE "foo"."public"."orders" (` +
+		`"id" INT8 NOT NULL DEFAULT unique_rowid(), ` +
+		`"quantity" INT8 NOT NULL, ` +
+		`"price" DECIMAL, ` +
+		`"total" DECIMAL AS (quantity * price) STORED, ` +
+		`PRIMARY KEY ("id"), ` +
+		`CONSTRAINT "positive" CHECK (quantity > 0), ` +
+		`CONSTRAINT "u" UNIQUE ("quantity", "price"))`
+	if actual := createTableStatement(`"foo"."public"."orders"`, table); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+}
+
+func TestAlterTableStatements(t *testing.T) {
+	o := tableDefinition{
+		columns: []tableColumn{
+			{name: "id", typ: "INT8"},
+			{name: "a", typ: "INT8", nullable: true},
+			{name: "b", typ: "STRING", nullable: true, def: "'x'"},
+			{name: "c", typ: "INT8", nullable: true, computed: "a + 1"},
+		},
+		primaryKey: []string{"id"},
+		checks:     []tableCheck{{name: "k", expression: "a > 0"}, {name: "same", expression: "id > 0"}},
+		uniques:    []tableUnique{{name: "u", columns: []string{"b"}}},
+	}
+	n := tableDefinition{
+		columns: []tableColumn{
+			{name: "id", typ: "INT"},
+			{name: "a", typ: "INT8"},
+			{name: "c", typ: "INT8", nullable: true, computed: "a + 2", stored: true},
+			{name: "d", typ: "STRING", nullable: true, onUpdate: "'y'"},
+		},
+		primaryKey: []string{"id", "a"},
+		checks:     []tableCheck{{name: "k", expression: "a > 1"}, {name: "same", expression: "(id > 0:::INT8)"}},
+	}
+
+	expected := []string{
+		`ALTER TABLE t DROP CONSTRAINT "k"`,
+		`DROP INDEX t@"u"`,
+		`ALTER TABLE t ALTER COLUMN "a" SET NOT NULL`,
+		`ALTER TABLE t DROP COLUMN "b"`,
+		`ALTER TABLE t DROP COLUMN "c"`,
+		`ALTER TABLE t ADD COLUMN "c" INT8 AS (a + 2) STORED`,
+		`ALTER TABLE t ADD COLUMN "d" STRING ON UPDATE 'y'`,
+		`ALTER TABLE t ALTER PRIMARY KEY USING COLUMNS ("id", "a")`,
+		`ALTER TABLE t ADD CONSTRAINT "k" CHECK (a > 1)`,
+	}
+	if actual := alterTableStatements("t", o, n); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expected)
+	}
+
+	if actual := alterTableStatements("t", o, o); len(actual) != 0 {
+		t.Errorf("expected no statements without change, got %q", actual)
+	}
+}
+
+func TestHashShardedStatements(t *testing.T) {
+	table := tableDefinition{
+		columns:               []tableColumn{{name: "id", typ: "INT8"}, {name: "ts", typ: "TIMESTAMPTZ"}, {name: "v", typ: "STRING"}},
+		primaryKey:            []string{"id"},
+		primaryKeyBucketCount: 8,
+		indexes:               []tableIndex{{name: "by_ts", columns: []string{"ts"}, storing: []string{"v"}, bucketCount: 4}},
+	}
+
+	expected := `CREATE TABLE t (` +
+		`"id" INT8 NOT NULL, "ts" TIMESTAMPTZ NOT NULL, "v" STRING NOT NULL, ` +
+		`PRIMARY KEY ("id") USING HASH WITH (bucket_count = 8), ` +
+		`INDEX "by_ts" ("ts") USING HASH STORING ("v") WITH (bucket_count = 4))`
+	if actual := createTableStatement("t", table); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+
+	changed := table
+	changed.primaryKeyBucketCount = 16
+	changed.indexes = []tableIndex{{name: "by_ts", columns: []string{"ts"}, unique: true}}
+	expectedStatements := []string{
+		`DROP INDEX t@"by_ts"`,
+		`ALTER TABLE t ALTER PRIMARY KEY USING COLUMNS ("id") USING HASH WITH (bucket_count = 16)`,
+		`CREATE UNIQUE INDEX "by_ts" ON t ("ts")`,
+	}
+	if actual := alterTableStatements("t", table, changed); !reflect.DeepEqual(actual, expectedStatements) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expectedStatements)
+	}
+}
+
+func TestInvertedAndVectorIndexes(t *testing.T) {
+	table := tableDefinition{
+		columns:    []tableColumn{{name: "id", typ: "INT8"}, {name: "doc", typ: "JSONB"}, {name: "embedding", typ: "VECTOR(3)"}},
+		primaryKey: []string{"id"},
+		indexes:    []tableIndex{{name: "by_doc", columns: []string{"doc"}, typ: indexTypeInverted}},
+	}
+
+	expected := `CREATE TABLE t (` +
+		`"id" INT8 NOT NULL, "doc" JSONB NOT NULL, "embedding" VECTOR(3) NOT NULL, ` +
+		`PRIMARY KEY ("id"), ` +
+		`INVERTED INDEX "by_doc" ("doc"))`
+	if actual := createTableStatement("t", table); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+
+	vector := tableIndex{
+		name:          "by_embedding",
+		columns:       []string{"embedding"},
+		typ:           indexTypeVector,
+		operatorClass: "vector_cosine_ops",
+		parameters:    map[string]interface{}{"min_partition_size": "16", "build_beam_size": "8"},
+	}
+	expectedStatement := `CREATE VECTOR INDEX "by_embedding" ON t ("embedding" vector_cosine_ops) WITH (build_beam_size = 8, min_partition_size = 16)`
+	if actual := createIndexStatement("t", vector); actual != expectedStatement {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expectedStatement)
+	}
+
+	if !indexesEqual(tableIndex{name: "i", columns: []string{"doc"}, typ: indexTypeInverted, operatorClass: "jsonb_ops"}, table.indexes[0]) {
+		t.Errorf("expected the default operator class to be equivalent to none")
+	}
+	if indexesEqual(tableIndex{name: "by_doc", columns: []string{"doc"}}, table.indexes[0]) {
+		t.Errorf("expected a forward index to differ from an inverted one")
+	}
+}
+
+func TestParseIndexDefinition(t *testing.T) {
+	for def, expected := range map[string][2]string{
+		`CREATE INDEX i ON db.public.t USING btree (a ASC)`:                            {indexTypeForward, ""},
+		`CREATE INDEX i ON db.public.t USING gin (doc)`:                                {indexTypeInverted, ""},
+		`CREATE INDEX i ON db.public.t USING gin (name gin_trgm_ops)`:                  {indexTypeInverted, "gin_trgm_ops"},
+		`CREATE VECTOR INDEX i ON db.public.t (embedding vector_cosine_ops)`:           {indexTypeVector, "vector_cosine_ops"},
+		`CREATE UNIQUE INDEX i ON db.public.t USING btree (a ASC) USING HASH WITH (x)`: {indexTypeForward, ""},
+	} {
+		if typ, operatorClass := parseIndexDefinition(def); typ != expected[0] || operatorClass != expected[1] {
+			t.Errorf("expected %v from %q, got %s %s", expected, def, typ, operatorClass)
+		}
+	}
+}
+
+func TestExpressionIndexes(t *testing.T) {
+	idx := tableIndex{name: "by_email", columns: []string{"tenant_id"}, expressions: []string{"lower(email)"}, unique: true}
+	expected := `CREATE UNIQUE INDEX "by_email" ON t ("tenant_id", (lower(email)))`
+	if actual := createIndexStatement("t", idx); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+
+	tags := tableIndex{name: "by_tags", expressions: []string{"doc->'tags'"}, typ: indexTypeInverted}
+	expected = `CREATE INVERTED INDEX "by_tags" ON t ((doc->'tags'))`
+	if actual := createIndexStatement("t", tags); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+
+	// CockroachDB reports the expressions with type annotations
+	reported := tags
+	reported.expressions = []string{"(doc->'tags':::STRING)"}
+	if !indexesEqual(tags, reported) {
+		t.Errorf("expected the reported expression to be equivalent to the configured one")
+	}
+
+	if err := validateTableIndexes(tableDefinition{indexes: []tableIndex{{name: "empty"}}}); err == nil {
+		t.Errorf("expected an error for an index without column nor expression")
+	}
+	if err := validateTableIndexes(tableDefinition{indexes: []tableIndex{tags}}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestShardColumnBucketCount(t *testing.T) {
+	if bucketCount, ok := shardColumnBucketCount("crdb_internal_id_shard_16"); !ok || bucketCount != 16 {
+		t.Errorf("expected a bucket count of 16, got %d", bucketCount)
+	}
+	if _, ok := shardColumnBucketCount("shard_16"); ok {
+		t.Errorf("expected a regular column")
+	}
+}
+
+func TestValidateTableChange(t *testing.T) {
+	regular := tableDefinition{columns: []tableColumn{{name: "a", typ: "INT8"}}}
+	computed := tableDefinition{columns: []tableColumn{{name: "a", typ: "INT8", computed: "1"}}}
+
+	if err := validateTableChange(regular, computed); err == nil {
+		t.Errorf("expected an error when a column becomes computed")
+	}
+	if err := validateTableChange(computed, regular); err == nil {
+		t.Errorf("expected an error when a column stops being computed")
+	}
+	if err := validateTableChange(regular, regular); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	renamed := tableDefinition{columns: []tableColumn{{name: "b", typ: "INT8"}}}
+	if err := validateTableChange(regular, renamed); err == nil {
+		t.Errorf("expected an error when a column is renamed")
+	}
+	added := tableDefinition{columns: []tableColumn{{name: "a", typ: "INT8"}, {name: "b", typ: "INT8"}}}
+	if err := validateTableChange(regular, added); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validateTableChange(added, regular); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestTypesEqual(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"INT", "INT8", true},
+		{"integer", "INT8", true},
+		{"text", "STRING", true},
+		{"VARCHAR(10)", "STRING(10)", true},
+		{"decimal(10, 2)", "DECIMAL(10,2)", true},
+		{"int[]", "INT8[]", true},
+		{"timestamp with time zone", "TIMESTAMPTZ", true},
+		{"INT4", "INT8", false},
+		{"STRING(10)", "STRING", false},
+	}
+
+	for _, c := range cases {
+		if actual := typesEqual(c.a, c.b); actual != c.expected {
+			t.Errorf("typesEqual(%q, %q) = %v, expected %v", c.a, c.b, actual, c.expected)
+		}
+	}
+}
+
+func TestCollatedColumns(t *testing.T) {
+	table := tableDefinition{columns: []tableColumn{{name: "name", typ: "STRING", collation: "de"}}}
+	expected := `CREATE TABLE t ("name" STRING COLLATE "de" NOT NULL)`
+	if actual := createTableStatement("t", table); actual != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", actual, expected)
+	}
+
+	changed := tableDefinition{columns: []tableColumn{{name: "name", typ: "STRING", collation: "en-US"}}}
+	expectedStatements := []string{`ALTER TABLE t ALTER COLUMN "name" TYPE STRING COLLATE "en-US"`}
+	if actual := alterTableStatements("t", table, changed); !reflect.DeepEqual(actual, expectedStatements) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expectedStatements)
+	}
+
+	if !collationsEqual("en-us", "en_US") || collationsEqual("en_US", "en_GB") {
+		t.Errorf("unexpected collation comparison")
+	}
+	if actual := collateRegexp.ReplaceAllString("STRING COLLATE en_US", ""); actual != "STRING" {
+		t.Errorf("expected the collation to be removed from the type, got %q", actual)
+	}
+}
+
+func TestExpressionsEqual(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"quantity > 0", "(quantity > 0:::INT8)", true},
+		{"now()", "now():::TIMESTAMPTZ", true},
+		{"'x'", "'x':::STRING", true},
+		{"(a) + (b)", "(a) + (b)", true},
+		{"(a) + (b)", "a + b", false},
+		{"a > 0", "a > 1", false},
+		{"LOWER(name)", "lower(name)", true},
+		// the case of string literals and quoted identifiers matters
+		{"'ABC'", "'abc':::STRING", false},
+		{"'it''s ABC'", "'it''s ABC':::STRING", true},
+		{`"Name" = 'x'`, `"name" = 'x'`, false},
+	}
+
+	for _, c := range cases {
+		if actual := expressionsEqual(c.a, c.b); actual != c.expected {
+			t.Errorf("expressionsEqual(%q, %q) = %v, expected %v", c.a, c.b, actual, c.expected)
+		}
+	}
+}
+
+func TestPrimaryKeyNotNull(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceTable().Schema, map[string]interface{}{
+		"name": "accounts",
+		"column": []interface{}{
+			map[string]interface{}{"name": "id", "type": "UUID"},
+			map[string]interface{}{"name": "balance", "type": "DECIMAL"},
+		},
+		"primary_key": []interface{}{"id"},
+	})
+
+	// the primary key column is NOT NULL as read, nullable defaulting to true
+	columns := tableDefinitionOf(d.Get).columns
+	if columns[0].nullable || !columns[1].nullable {
+		t.Errorf("expected only the primary key column to be NOT NULL, got %+v", columns)
+	}
+	if !suppressPrimaryKeyNullable("column.0.nullable", "false", "true", d) || suppressPrimaryKeyNullable("column.1.nullable", "false", "true", d) {
+		t.Errorf("expected the diff of nullable to be suppressed for the primary key column only")
+	}
+}
+
+func TestNotNullConstraintRegexp(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"2200_104_1_not_null":   true,
+		"balance_not_null":      false,
+		"check_owner_not_null":  false,
+		"104_1_not_null":        false,
+		"2200_104_1_not_null_x": false,
+	} {
+		if actual := notNullConstraintRegexp.MatchString(name); actual != expected {
+			t.Errorf("expected %s matching to be %v", name, expected)
+		}
+	}
+}
+
+func TestMergeTableDefinition(t *testing.T) {
+	configured := tableDefinition{
+		columns: []tableColumn{{name: "b", onUpdate: "now()"}, {name: "a"}, {name: "gone"}},
+	}
+	read := tableDefinition{
+		columns: []tableColumn{{name: "a", typ: "INT8"}, {name: "b", typ: "TIMESTAMPTZ"}, {name: "extra", typ: "STRING"}},
+	}
+
+	merged := mergeTableDefinition(configured, read)
+	expected := []tableColumn{
+		{name: "b", typ: "TIMESTAMPTZ", onUpdate: "now()"},
+		{name: "a", typ: "INT8"},
+		{name: "extra", typ: "STRING"},
+	}
+	if !reflect.DeepEqual(merged.columns, expected) {
+		t.Errorf("unexpected columns %+v", merged.columns)
+	}
+
+	configured = tableDefinition{
+		uniques: []tableUnique{{name: "u"}},
+		indexes: []tableIndex{{name: "unique_idx"}},
+	}
+	read = tableDefinition{
+		uniques: []tableUnique{{name: "u", columns: []string{"a"}}, {name: "unique_idx", columns: []string{"b"}}},
+		indexes: []tableIndex{
+			{name: "u", columns: []string{"a"}, unique: true},
+			{name: "unique_idx", columns: []string{"b"}, unique: true},
+			{name: "extra", columns: []string{"c"}},
+		},
+	}
+	merged = mergeTableDefinition(configured, read)
+	if !reflect.DeepEqual(merged.uniques, []tableUnique{{name: "u", columns: []string{"a"}}}) {
+		t.Errorf("unexpected uniques %+v", merged.uniques)
+	}
+	expectedIndexes := []tableIndex{{name: "unique_idx", columns: []string{"b"}, unique: true}, {name: "extra", columns: []string{"c"}}}
+	if !reflect.DeepEqual(merged.indexes, expectedIndexes) {
+		t.Errorf("unexpected indexes %+v", merged.indexes)
+	}
+}
+
+const testAccResourceTable = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "foo" {
+  database = cockroach_database.foo.name
+  name     = "orders"
+
+  column {
+    name     = "id"
+    type     = "INT8"
+    nullable = false
+  }
+
+  column {
+    name = "quantity"
+    type = "INT"
+  }
+
+  column {
+    name = "price"
+    type = "DECIMAL"
+  }
+
+  column {
+    name     = "total"
+    type     = "DECIMAL"
+    computed = "quantity * price"
+    stored   = true
+  }
+
+  primary_key = ["id"]
+
+  check {
+    name       = "positive_quantity"
+    expression = "quantity > 0"
+  }
+
+  gc_ttl_seconds      = 600
+  drop_gc_ttl_seconds = 60
+  max_drop_size_bytes = 1073741824
+}
+`
@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	triggerNameAttr      = "name"
+	triggerDatabaseAttr  = "database"
+	triggerSchemaAttr    = "schema"
+	triggerTableAttr     = "table"
+	triggerTimingAttr    = "timing"
+	triggerEventsAttr    = "events"
+	triggerForEachAttr   = "for_each"
+	triggerWhenAttr      = "when"
+	triggerFunctionAttr  = "function"
+	triggerArgumentsAttr = "arguments"
+
+	triggerDefaultLocalPort = "26274"
+)
+
+func resourceTrigger() *schema.Resource {
+	return withClusterScopedID(&schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to create a trigger on a table of a CockroachDB cluster, running a trigger function on the changes of its rows. " +
+			"Triggers require CockroachDB v24.3 or later, and are dropped and created again when changed.",
+
+		CreateContext: resourceTriggerCreate,
+		ReadContext:   resourceTriggerRead,
+		DeleteContext: resourceTriggerDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTriggerImporter,
+		},
+		CustomizeDiff: resourceTriggerCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			triggerNameAttr: {
+				Description: "Name of the trigger.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			triggerDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			triggerSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			triggerTableAttr: {
+				Description: "Name of the table.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			triggerTimingAttr: {
+				Description:  "When the function runs, `BEFORE` or `AFTER` the change.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"BEFORE", "AFTER"}, true),
+				StateFunc: func(v interface{}) string {
+					return strings.ToUpper(v.(string))
+				},
+			},
+			triggerEventsAttr: {
+				Description: "Changes running the function, among `INSERT`, `UPDATE` and `DELETE`.",
+				Type:        schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"INSERT", "UPDATE", "DELETE"}, false),
+				},
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+			},
+			triggerForEachAttr: {
+				Description:  "Whether the function runs for each `ROW` or once for each `STATEMENT`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "ROW",
+				ValidateFunc: validation.StringInSlice([]string{"ROW", "STATEMENT"}, false),
+			},
+			triggerWhenAttr: {
+				Description:      "Boolean expression the change must satisfy for the function to run, e.g. `NEW.status <> OLD.status`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentExpression,
+			},
+			triggerFunctionAttr: {
+				Description:      "Name of the trigger function, optionally qualified by its schema.",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentFunctionName,
+			},
+			triggerArgumentsAttr: {
+				Description: "Arguments of the function, available as `TG_ARGV`.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			// triggers are replaced to be changed, like the port
+			argTolerateExistingState: func() *schema.Schema {
+				s := tolerateExistingStateSchema("trigger")
+				s.ForceNew = true
+				return s
+			}(),
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26274), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     triggerDefaultLocalPort,
+			},
+		},
+	}, 4)
+}
+
+func resourceTriggerCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := setDefaultDatabase(d, meta, triggerDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, triggerSchemaAttr)
+}
+
+// suppressEquivalentFunctionName ignores the schema CockroachDB may add to the
+// name of the function.
+func suppressEquivalentFunctionName(k, old, new string, d *schema.ResourceData) bool {
+	unqualified := func(name string) string {
+		name = strings.ToLower(strings.ReplaceAll(name, `"`, ``))
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+
+	return unqualified(old) == unqualified(new)
+}
+
+// trigger is the definition of a trigger.
+type trigger struct {
+	timing    string
+	events    []string
+	forEach   string
+	when      string
+	function  string
+	arguments []string
+}
+
+// eventOrder sorts the events as CockroachDB reports them.
+var eventOrder = map[string]int{"INSERT": 0, "UPDATE": 1, "DELETE": 2}
+
+func sortEvents(events []string) {
+	sort.Slice(events, func(i, j int) bool { return eventOrder[events[i]] < eventOrder[events[j]] })
+}
+
+// createTriggerStatement returns the CREATE TRIGGER statement of the trigger.
+func createTriggerStatement(name string, table string, t trigger) string {
+	events := append([]string{}, t.events...)
+	sortEvents(events)
+
+	statement := `CREATE TRIGGER ` + pq.QuoteIdentifier(name) + ` ` + strings.ToUpper(t.timing) + ` ` + strings.Join(events, ` OR `) +
+		` ON ` + table + ` FOR EACH ` + t.forEach
+	if t.when != "" {
+		statement += ` WHEN (` + t.when + `)`
+	}
+
+	arguments := make([]string, len(t.arguments))
+	for i, a := range t.arguments {
+		arguments[i] = pq.QuoteLiteral(a)
+	}
+
+	return statement + ` EXECUTE FUNCTION ` + t.function + `(` + strings.Join(arguments, `, `) + `)`
+}
+
+var createTriggerRegexp = regexp.MustCompile(`(?is)^CREATE\s+TRIGGER\s+\S+\s+(BEFORE|AFTER)\s+(.+?)\s+ON\s+\S+\s+FOR\s+EACH\s+(ROW|STATEMENT)(?:\s+WHEN\s+\((.*)\))?\s+EXECUTE\s+FUNCTION\s+(\S+?)\s*\((.*)\)\s*;?\s*$`)
+
+var triggerEventSeparatorRegexp = regexp.MustCompile(`(?i)\s+OR\s+`)
+
+var triggerArgumentRegexp = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// parseCreateTrigger parses the definition of a trigger from the statement
+// reported by SHOW CREATE TRIGGER.
+func parseCreateTrigger(statement string) (trigger, error) {
+	match := createTriggerRegexp.FindStringSubmatch(strings.TrimSpace(statement))
+	if match == nil {
+		return trigger{}, fmt.Errorf("unexpected trigger definition %q", statement)
+	}
+
+	t := trigger{
+		timing:   strings.ToUpper(match[1]),
+		forEach:  strings.ToUpper(match[3]),
+		when:     match[4],
+		function: match[5],
+	}
+	for _, event := range triggerEventSeparatorRegexp.Split(match[2], -1) {
+		t.events = append(t.events, strings.ToUpper(event))
+	}
+	for _, a := range triggerArgumentRegexp.FindAllStringSubmatch(match[6], -1) {
+		t.arguments = append(t.arguments, strings.ReplaceAll(a[1], "''", "'"))
+	}
+
+	return t, nil
+}
+
+func triggerTable(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(triggerDatabaseAttr).(string), d.Get(triggerSchemaAttr).(string), []string{d.Get(triggerTableAttr).(string)})
+}
+
+func triggerLockKey(d *schema.ResourceData) string {
+	return objectLockKey("table", d.Get(triggerDatabaseAttr).(string), d.Get(triggerSchemaAttr).(string), d.Get(triggerTableAttr).(string))
+}
+
+func resourceTriggerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	database := d.Get(triggerDatabaseAttr).(string)
+	if database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", triggerDatabaseAttr)
+	}
+
+	// the function of the trigger resolves in the database of the table
+	conn, closeConn, diags := openConnectionIn(ctx, d, meta, database)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(triggerLockKey(d))
+	defer unlock()
+
+	t := trigger{
+		timing:    d.Get(triggerTimingAttr).(string),
+		events:    convertToString(d.Get(triggerEventsAttr).(*schema.Set).List()),
+		forEach:   d.Get(triggerForEachAttr).(string),
+		when:      d.Get(triggerWhenAttr).(string),
+		function:  d.Get(triggerFunctionAttr).(string),
+		arguments: convertToString(d.Get(triggerArgumentsAttr).([]interface{})),
+	}
+	if _, err := conn.Exec(ctx, createTriggerStatement(d.Get(triggerNameAttr).(string), triggerTable(d), t)); err != nil && !toleratedExistingState(d, err, alreadyExistsCodes) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(clusterScopedID("", database, d.Get(triggerSchemaAttr).(string), d.Get(triggerTableAttr).(string), d.Get(triggerNameAttr).(string)))
+
+	return resourceTriggerRead(ctx, d, meta)
+}
+
+// readTrigger reads the definition of the trigger of the table, ok being false
+// when it doesn't exist.
+func readTrigger(ctx context.Context, conn *pgx.Conn, table string, name string) (t trigger, ok bool, err error) {
+	err = conn.QueryRow(ctx, `SELECT count(*) > 0 FROM [SHOW TRIGGERS FROM `+table+`] WHERE trigger_name = $1`, name).Scan(&ok)
+	if err != nil {
+		// the table has been dropped along with its triggers
+		if strings.Contains(err.Error(), "does not exist") {
+			return t, false, nil
+		}
+		return t, false, err
+	}
+	if !ok {
+		return t, false, nil
+	}
+
+	var statement string
+	err = conn.QueryRow(ctx, `SELECT create_statement FROM [SHOW CREATE TRIGGER `+pq.QuoteIdentifier(name)+` ON `+table+`]`).Scan(&statement)
+	if err != nil {
+		return t, false, err
+	}
+
+	t, err = parseCreateTrigger(statement)
+
+	return t, err == nil, err
+}
+
+func resourceTriggerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, d.Get(triggerDatabaseAttr).(string), d.Get(triggerSchemaAttr).(string), d.Get(triggerTableAttr).(string), d.Get(triggerNameAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("trigger %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	t, ok, err := readTrigger(ctx, conn, triggerTable(d), d.Get(triggerNameAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("trigger %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	values := map[string]interface{}{
+		triggerTimingAttr:    t.timing,
+		triggerEventsAttr:    t.events,
+		triggerForEachAttr:   t.forEach,
+		triggerWhenAttr:      t.when,
+		triggerFunctionAttr:  t.function,
+		triggerArgumentsAttr: t.arguments,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceTriggerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(triggerLockKey(d))
+	defer unlock()
+
+	if _, err := conn.Exec(ctx, `DROP TRIGGER IF EXISTS `+pq.QuoteIdentifier(d.Get(triggerNameAttr).(string))+` ON `+triggerTable(d)); err != nil && !toleratedExistingState(d, err, doesNotExistCodes) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceTriggerImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format database/schema/table/name
+	_, parts, err := parseResourceID(d.Id(), 4)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trigger id %q, expected database/schema/table/name: %v", d.Id(), err)
+	}
+
+	values := map[string]interface{}{
+		triggerDatabaseAttr: parts[0],
+		triggerSchemaAttr:   parts[1],
+		triggerTableAttr:    parts[2],
+		triggerNameAttr:     parts[3],
+		argLocalPort:        triggerDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceTrigger(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTrigger,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_trigger.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:foo:public:orders:orders_audit$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_trigger.foo", "events.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestCreateTriggerStatement(t *testing.T) {
+	statement := createTriggerStatement("audit", `"foo"."public"."orders"`, trigger{
+		timing:    "after",
+		events:    []string{"DELETE", "INSERT"},
+		forEach:   "ROW",
+		when:      "NEW.status <> 'draft'",
+		function:  "audit_fn",
+		arguments: []string{"orders", "it's"},
+	})
+
+	expected := `CREATE TRIGGER "audit" AFTER INSERT OR DELETE ON "foo"."public"."orders" FOR EACH ROW ` +
+		`WHEN (NEW.status <> 'draft') EXECUTE FUNCTION audit_fn('orders', 'it''s')`
+	if statement != expected {
+		t.Errorf("unexpected statement\n%s\nexpected\n%s", statement, expected)
+	}
+}
+
+func TestParseCreateTrigger(t *testing.T) {
+	parsed, err := parseCreateTrigger(`CREATE TRIGGER audit AFTER INSERT OR DELETE ON foo.public.orders FOR EACH ROW WHEN (new.status != 'draft':::STRING) EXECUTE FUNCTION public.audit_fn('orders', 'it''s');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := trigger{
+		timing:    "AFTER",
+		events:    []string{"INSERT", "DELETE"},
+		forEach:   "ROW",
+		when:      "new.status != 'draft':::STRING",
+		function:  "public.audit_fn",
+		arguments: []string{"orders", "it's"},
+	}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("unexpected trigger %+v", parsed)
+	}
+
+	if _, err := parseCreateTrigger("CREATE TABLE t ()"); err == nil {
+		t.Errorf("expected an error for a statement which isn't a trigger")
+	}
+}
+
+func TestSuppressEquivalentFunctionName(t *testing.T) {
+	if !suppressEquivalentFunctionName("", "public.audit_fn", "audit_fn", nil) {
+		t.Errorf("expected the schema of the function to be ignored")
+	}
+	if suppressEquivalentFunctionName("", "audit_fn", "other_fn", nil) {
+		t.Errorf("expected different functions to differ")
+	}
+}
+
+// there is no resource for functions yet, audit_fn must exist in the database
+const testAccResourceTrigger = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "orders" {
+  database = cockroach_database.foo.name
+  name     = "orders"
+
+  column {
+    name = "status"
+    type = "STRING"
+  }
+}
+
+resource "cockroach_trigger" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.orders.name
+  name     = "orders_audit"
+  timing   = "AFTER"
+  events   = ["INSERT", "DELETE"]
+  function = "audit_fn"
+}
+`
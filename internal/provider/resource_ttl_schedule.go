@@ -0,0 +1,336 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	ttlScheduleDatabaseAttr       = "database"
+	ttlScheduleSchemaAttr         = "schema"
+	ttlScheduleTableAttr          = "table"
+	ttlSchedulePausedAttr         = "paused"
+	ttlScheduleCronAttr           = "cron"
+	ttlScheduleScheduleIDAttr     = "schedule_id"
+	ttlScheduleScheduleStatusAttr = "schedule_status"
+	ttlScheduleNextRunAttr        = "next_run"
+
+	// ttlJobEnabledSetting pauses the TTL jobs of every table when false
+	ttlJobEnabledSetting = "sql.ttl.job.enabled"
+	ttlClusterID         = "ttl"
+
+	ttlScheduleDefaultLocalPort = "26302"
+)
+
+func resourceTTLSchedule() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to control the row-level TTL jobs of a table of a CockroachDB cluster, or of every table when no table is set, " +
+			"e.g. to pause the TTL deletions during a bulk backfill they would contend with. The schedule of the TTL job of the table is exposed as well.",
+
+		CreateContext: resourceTTLScheduleCreate,
+		ReadContext:   resourceTTLScheduleRead,
+		UpdateContext: resourceTTLScheduleUpdate,
+		DeleteContext: resourceTTLScheduleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTTLScheduleImporter,
+		},
+		CustomizeDiff: resourceTTLScheduleCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			ttlScheduleDatabaseAttr: {
+				Description: "Name of the database of the table, the default database of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			ttlScheduleSchemaAttr: {
+				Description: "Name of the schema of the table, the default schema of the provider is used when not set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			ttlScheduleTableAttr: {
+				Description: "Name of the table with row-level TTL, the TTL jobs of every table being controlled, " +
+					"through the `" + ttlJobEnabledSetting + "` cluster setting, when not set.",
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			ttlSchedulePausedAttr: {
+				Description: "True to pause the TTL deletions, as the `ttl_pause` storage parameter of the table, or by disabling the TTL jobs without table, the running jobs completing.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			ttlScheduleCronAttr: {
+				Description: "Schedule of the TTL job of the table in the cron format, e.g. `@daily`, as the `ttl_job_cron` storage parameter, hourly when not set. Requires `table`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			ttlScheduleScheduleIDAttr: {
+				Description: "Id of the schedule of the TTL job of the table.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			ttlScheduleScheduleStatusAttr: {
+				Description: "Status of the schedule of the TTL job of the table, e.g. `ACTIVE`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			ttlScheduleNextRunAttr: {
+				Description: "Next time the TTL job of the table runs.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + ttlScheduleDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     ttlScheduleDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func resourceTTLScheduleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get(ttlScheduleTableAttr).(string) == "" {
+		if d.Get(ttlScheduleCronAttr).(string) != "" {
+			return fmt.Errorf("%s requires %s, the schedules of the TTL jobs being set per table", ttlScheduleCronAttr, ttlScheduleTableAttr)
+		}
+		return nil
+	}
+
+	if err := setDefaultDatabase(d, meta, ttlScheduleDatabaseAttr); err != nil {
+		return err
+	}
+
+	return setDefaultSchema(d, meta, ttlScheduleSchemaAttr)
+}
+
+// ttlStorageParameters returns the storage parameters of the table for the
+// pause and the cron schedule of its TTL job.
+func ttlStorageParameters(paused bool, cron string) map[string]interface{} {
+	parameters := map[string]interface{}{}
+	if paused {
+		parameters["ttl_pause"] = "true"
+	}
+	if cron != "" {
+		parameters["ttl_job_cron"] = cron
+	}
+
+	return parameters
+}
+
+func ttlScheduleTable(d *schema.ResourceData) string {
+	return qualifiedNames(d.Get(ttlScheduleDatabaseAttr).(string), d.Get(ttlScheduleSchemaAttr).(string), []string{d.Get(ttlScheduleTableAttr).(string)})
+}
+
+// applyTTLSchedule pauses or resumes the TTL jobs and sets the schedule of
+// the one of the table, from the old values to the new ones.
+func applyTTLSchedule(ctx context.Context, d *schema.ResourceData, meta interface{}, oldPaused bool, oldCron string, paused bool, cron string) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	if d.Get(ttlScheduleTableAttr).(string) == "" {
+		if paused == oldPaused {
+			return nil
+		}
+		if paused {
+			if err := setClusterSetting(ctx, conn, ttlJobEnabledSetting, "false"); err != nil {
+				return diag.Errorf("failed to set %s: %v", ttlJobEnabledSetting, err)
+			}
+			return nil
+		}
+		if err := resetClusterSetting(ctx, conn, ttlJobEnabledSetting); err != nil {
+			return diag.Errorf("failed to reset %s: %v", ttlJobEnabledSetting, err)
+		}
+		return nil
+	}
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("table", d.Get(ttlScheduleDatabaseAttr).(string), d.Get(ttlScheduleSchemaAttr).(string), d.Get(ttlScheduleTableAttr).(string)))
+	defer unlock()
+
+	for _, statement := range storageParameterStatements(ttlScheduleTable(d), ttlStorageParameters(oldPaused, oldCron), ttlStorageParameters(paused, cron)) {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return nil
+}
+
+func resourceTTLScheduleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	table := d.Get(ttlScheduleTableAttr).(string)
+	database := d.Get(ttlScheduleDatabaseAttr).(string)
+	if table != "" && database == "" {
+		return diag.Errorf("%s must be set when the provider has no default database", ttlScheduleDatabaseAttr)
+	}
+
+	if diags := applyTTLSchedule(ctx, d, meta, false, "", d.Get(ttlSchedulePausedAttr).(bool), d.Get(ttlScheduleCronAttr).(string)); diags != nil {
+		return diags
+	}
+
+	if table == "" {
+		d.SetId(ttlClusterID)
+	} else {
+		d.SetId(clusterScopedID("", database, d.Get(ttlScheduleSchemaAttr).(string), table))
+	}
+
+	return resourceTTLScheduleRead(ctx, d, meta)
+}
+
+// ttlSchedule is the schedule of the TTL job of a table.
+type ttlSchedule struct {
+	id      int64
+	status  string
+	nextRun string
+}
+
+// readTTLSchedule reads the schedule of the TTL job of the table, labeled
+// with the id of the table, ok being false when the table has no TTL.
+func readTTLSchedule(ctx context.Context, conn *pgx.Conn, tableID int64) (s ttlSchedule, ok bool, err error) {
+	id := strconv.FormatInt(tableID, 10)
+	err = conn.QueryRow(ctx,
+		`SELECT id, schedule_status, coalesce(next_run::STRING, '') FROM [SHOW SCHEDULES] `+
+			`WHERE label = 'row-level-ttl-' || $1 OR (label LIKE 'row-level-ttl%' AND label LIKE '%[' || $1 || ']')`, id).
+		Scan(&s.id, &s.status, &s.nextRun)
+	if err == pgx.ErrNoRows {
+		return s, false, nil
+	}
+	if err != nil {
+		return s, false, err
+	}
+
+	return s, true, nil
+}
+
+func resourceTTLScheduleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	table := d.Get(ttlScheduleTableAttr).(string)
+	if table == "" {
+		current, err := readClusterSettings(ctx, conn)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set(ttlSchedulePausedAttr, settingValuesEqual(current[ttlJobEnabledSetting].value, "false")); err != nil {
+			return diag.FromErr(err)
+		}
+		return diag.Diagnostics{}
+	}
+
+	database, schemaName := d.Get(ttlScheduleDatabaseAttr).(string), d.Get(ttlScheduleSchemaAttr).(string)
+	bound, err := bindClusterScopedID(ctx, conn, d, meta, database, schemaName, table)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !bound {
+		logInfo("table %s belongs to another cluster, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	parameters, ok, err := readStorageParameters(ctx, conn, database, schemaName, table)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("table %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	paused, _ := strconv.ParseBool(fmt.Sprint(parameters["ttl_pause"]))
+	cron, _ := parameters["ttl_job_cron"].(string)
+	if err := d.Set(ttlSchedulePausedAttr, paused); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(ttlScheduleCronAttr, cron); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tableID, err := readTableDescriptorID(ctx, conn, database, schemaName, table)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	schedule, ok, err := readTTLSchedule(ctx, conn, tableID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		return diag.Errorf("table %s has no row-level TTL", ttlScheduleTable(d))
+	}
+
+	values := map[string]interface{}{
+		ttlScheduleScheduleIDAttr:     schedule.id,
+		ttlScheduleScheduleStatusAttr: schedule.status,
+		ttlScheduleNextRunAttr:        schedule.nextRun,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceTTLScheduleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	oldPaused, paused := d.GetChange(ttlSchedulePausedAttr)
+	oldCron, cron := d.GetChange(ttlScheduleCronAttr)
+	if diags := applyTTLSchedule(ctx, d, meta, oldPaused.(bool), oldCron.(string), paused.(bool), cron.(string)); diags != nil {
+		return diags
+	}
+
+	return resourceTTLScheduleRead(ctx, d, meta)
+}
+
+func resourceTTLScheduleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the TTL jobs are resumed, hourly
+	if diags := applyTTLSchedule(ctx, d, meta, d.Get(ttlSchedulePausedAttr).(bool), d.Get(ttlScheduleCronAttr).(string), false, ""); diags != nil {
+		return diags
+	}
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceTTLScheduleImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	values := map[string]interface{}{
+		argLocalPort: ttlScheduleDefaultLocalPort,
+	}
+
+	// id has the format database/schema/table, or is ttl for every table
+	if d.Id() != ttlClusterID {
+		_, parts, err := parseResourceID(d.Id(), 3)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TTL schedule id %q, expected database/schema/table or %s: %v", d.Id(), ttlClusterID, err)
+		}
+		values[ttlScheduleDatabaseAttr] = parts[0]
+		values[ttlScheduleSchemaAttr] = parts[1]
+		values[ttlScheduleTableAttr] = parts[2]
+	}
+
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceTTLSchedule(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTTLSchedule,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"cockroach_ttl_schedule.foo", "id", regexp.MustCompile("^[0-9a-f-]{36}:foo:public:sessions$")),
+					resource.TestCheckResourceAttr(
+						"cockroach_ttl_schedule.foo", "paused", "true"),
+					resource.TestCheckResourceAttr(
+						"cockroach_ttl_schedule.foo", "schedule_status", "ACTIVE"),
+				),
+			},
+		},
+	})
+}
+
+func TestTTLStorageParameters(t *testing.T) {
+	expected := map[string]interface{}{"ttl_pause": "true", "ttl_job_cron": "@daily"}
+	if actual := ttlStorageParameters(true, "@daily"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected storage parameters %v", actual)
+	}
+
+	statements := []string{`ALTER TABLE t RESET (ttl_pause)`}
+	if actual := storageParameterStatements("t", ttlStorageParameters(true, ""), ttlStorageParameters(false, "")); !reflect.DeepEqual(actual, statements) {
+		t.Errorf("unexpected statements %q", actual)
+	}
+}
+
+const testAccResourceTTLSchedule = `
+resource "cockroach_database" "foo" {
+  name = "foo"
+}
+
+resource "cockroach_table" "sessions" {
+  database = cockroach_database.foo.name
+  name     = "sessions"
+
+  column {
+    name = "expires_at"
+    type = "TIMESTAMPTZ"
+  }
+}
+
+resource "cockroach_storage_parameter" "ttl" {
+  database = cockroach_database.foo.name
+  table    = cockroach_table.sessions.name
+
+  parameters = {
+    ttl_expiration_expression = "expires_at"
+  }
+}
+
+resource "cockroach_ttl_schedule" "foo" {
+  database = cockroach_database.foo.name
+  table    = cockroach_storage_parameter.ttl.table
+  paused   = true
+}
+`
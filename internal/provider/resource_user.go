@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -16,12 +19,20 @@ const (
 	dbPasswordAttr = "password"
 	dbRolesAttr    = "roles"
 	dbAdminAttr    = "is_admin"
+	dbSubjectAttr  = "subject"
+
+	dbRoleOptionsAttr = "role_options"
+	dbValidUntilAttr  = "valid_until"
+
+	dbReassignOwnedToAttr = "reassign_owned_to"
+	dbDropOwnedAttr       = "drop_owned"
 )
 
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
 		// This description is used by the documentation generator and the language server.
-		Description: "Resource used to create a new user inside Cockroachdb cluster, and to attach required roles to the user.",
+		Description: "Resource used to create a new user inside Cockroachdb cluster, and to attach required roles to the user. " +
+			"Its id is the name of the user, which isn't scoped to the cluster: the user of the same name is adopted when the configuration is applied to another cluster.",
 
 		CreateContext: resourceUserCreate,
 		ReadContext:   resourceUserRead,
@@ -32,11 +43,18 @@ func resourceUser() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
+			userIDAttr: {
+				Description: "Id of the user in CockroachDB, its OID.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			createdAtAttr: createdAtSchema(),
 			dbUsernameAttr: {
-				Description: "Name of the user to create.",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
+				Description:      "Name of the user to create, lowercased by CockroachDB.",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressRoleNameCase,
 			},
 			dbPasswordAttr: {
 				Description: "Password of the user to create.",
@@ -57,6 +75,43 @@ func resourceUser() *schema.Resource {
 				Optional:    true,
 				Default:     false,
 			},
+			dbSubjectAttr: {
+				Description: "Distinguished name of the subject of the client certificates the user authenticates with, e.g. `CN=app,O=Example`, instead of the user name in the common name. Requires CockroachDB 24.1 or later.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+			dbRoleOptionsAttr: {
+				Description: "Role options of the user, e.g. `CREATEDB` or `NOLOGIN`, the options left out being revoked, e.g. with `NOCREATEDB` or `LOGIN`. " +
+					"Each option changed outside of Terraform is planned and repaired on its own with `ALTER ROLE`. The options also set in `roles` are left to it.",
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(roleOptions, false),
+				},
+			},
+			dbValidUntilAttr: {
+				Description:      "Time after which the password of the user isn't valid anymore, in RFC 3339 format, e.g. `2030-01-01T00:00:00Z`. Never expires when not set.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "",
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: suppressEquivalentTime,
+			},
+			dbReassignOwnedToAttr: {
+				Description: "Role the objects owned by the user are given to, in every database, when the user is destroyed, since a user owning objects can't be dropped.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+			dbDropOwnedAttr: {
+				Description: "True to drop the objects still owned by the user and revoke its privileges, in every database, when the user is destroyed. Runs after `reassign_owned_to`, only revoking the privileges when both are set.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			argTolerateExistingState: tolerateExistingStateSchema("user"),
 			argLocalPort: {
 				Description: "Local port to be used for port-forward. (default is 26257), use different port to avoid same port opening.",
 				Type:        schema.TypeString,
@@ -74,14 +129,9 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	password := d.Get(dbPasswordAttr).(string)
 	roles := d.Get(dbRolesAttr).(string)
 	isAdmin := d.Get(dbAdminAttr).(bool)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
-
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-	defer close(stopCh)
+	subject := d.Get(dbSubjectAttr).(string)
+	options := convertToString(d.Get(dbRoleOptionsAttr).(*schema.Set).List())
+	validUntil := d.Get(dbValidUntilAttr).(string)
 
 	if local_port == "" {
 		return diag.Errorf("local_port can't be an empty string")
@@ -95,56 +145,68 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.Errorf("password can't be an empty string")
 	}
 
-	if err := tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port); err != nil {
-		return err
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
-
-	conn, err := pgx.Connect(ctx, dns)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer func() {
-		if closeErr := conn.Close(ctx); closeErr != nil {
-			logError("failed to close database connection: %v", closeErr)
-		}
-	}()
-
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
-	}
-
-	_, err = conn.Exec(ctx,
-		`CREATE USER `+
-			pq.QuoteIdentifier(name)+
-			` WITH PASSWORD '`+
-			password+
-			`' `+
+	defer closeConn()
+
+	unlock := cockroachClient.locks.lock(roleLockKey(name))
+	defer unlock()
+	defer cockroachClient.cache.invalidate()
+
+	statements := []string{
+		`CREATE USER ` +
+			pq.QuoteIdentifier(name) +
+			` WITH PASSWORD '` +
+			password +
+			`' ` +
 			roles,
-	)
-
-	if err != nil {
-		return diag.FromErr(err)
 	}
 
 	if isAdmin {
-		_, err := conn.Exec(ctx,
+		statements = append(statements,
 			`GRANT admin TO `+
 				pq.QuoteIdentifier(name)+
 				` WITH ADMIN OPTION`,
 		)
+	}
 
-		// _, err = conn.Exec(ctx, fmt.Sprintf("GRANT admin to %s", pq.QuoteIdentifier(name)))
+	if subject != "" {
+		statements = append(statements, userSubjectStatement(name, subject))
+	}
 
-		if err != nil {
-			return diag.FromErr(err)
-		}
+	if statement := roleOptionsStatement(name, nil, options, "", validUntil); statement != "" {
+		statements = append(statements, statement)
+	}
+
+	// the user is only created if it can be made admin and mapped to its
+	// subject as well
+	err := execInTransaction(ctx, conn, statements...)
+	// a tolerated existing user is made admin, mapped and given its options
+	// all the same, its password being left as is
+	if toleratedExistingState(d, err, alreadyExistsCodes) {
+		err = execInTransaction(ctx, conn, statements[1:]...)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids, err := readUserIDs(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	d.SetId(name)
+	if err := setCreatedAt(d); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set(userIDAttr, ids[strings.ToLower(name)])
 	d.Set(dbUsernameAttr, name)
 	d.Set(dbPasswordAttr, password)
 	d.Set(dbRolesAttr, roles)
 	d.Set(dbAdminAttr, isAdmin)
+	d.Set(dbSubjectAttr, subject)
 
 	return diag.Diagnostics{}
 }
@@ -153,76 +215,96 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 	cockroachClient := meta.(*cockroachClient)
 
 	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
 
 	if local_port == "" {
 		return diag.Errorf("local_port can't be an empty string")
 	}
 
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-	defer close(stopCh)
+	// the users are listed once for all the users refreshed together
+	cached, diags := cockroachClient.cache.get("users", func() (interface{}, diag.Diagnostics) {
+		conn, closeConn, diags := openConnection(ctx, d, meta)
+		if diags != nil {
+			return nil, diags
+		}
+		defer closeConn()
 
-	if err := tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port); err != nil {
-		return err
+		users, err := showUsers(ctx, conn)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		return users, nil
+	})
+	if diags != nil {
+		return diags
 	}
 
-	conn, err := pgx.Connect(ctx, dns)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer func() {
-		if closeErr := conn.Close(ctx); closeErr != nil {
-			logError("failed to close database connection: %v", closeErr)
+	roleOptions, diags := cockroachClient.cache.get("user_role_options", func() (interface{}, diag.Diagnostics) {
+		conn, closeConn, diags := openConnection(ctx, d, meta)
+		if diags != nil {
+			return nil, diags
 		}
-	}()
+		defer closeConn()
 
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
+		roleOptions, err := readUserRoleOptions(ctx, conn)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		return roleOptions, nil
+	})
+	if diags != nil {
+		return diags
 	}
 
-	name := d.Id()
+	ids, diags := cockroachClient.cache.get("user_ids", func() (interface{}, diag.Diagnostics) {
+		conn, closeConn, diags := openConnection(ctx, d, meta)
+		if diags != nil {
+			return nil, diags
+		}
+		defer closeConn()
 
-	rows, err := conn.Query(ctx, "SHOW USERS")
-	if err != nil {
-		// handle this error better than this
-		return diag.FromErr(err)
-	}
-	found := false
-	defer rows.Close()
-	for rows.Next() {
-		var (
-			username  string
-			options   string
-			member_of []string
-		)
-		err = rows.Scan(&username, &options, &member_of)
+		ids, err := readUserIDs(ctx, conn)
 		if err != nil {
-			// handle this error
-			return diag.FromErr(err)
+			return nil, diag.FromErr(err)
 		}
+		return ids, nil
+	})
+	if diags != nil {
+		return diags
+	}
 
-		if username == name {
+	name := d.Id()
+
+	found := false
+	for _, user := range cached.([]userRow) {
+		if roleNamesEqual(user.username, name) {
 			// TODO: find a way to read all the roles
 			// if err := d.Set(dbRolesAttr, options); err != nil {
 			// 	return diag.FromErr(err)
 			// }
 
-			if err := d.Set(dbAdminAttr, contains(member_of, "admin")); err != nil {
+			if err := d.Set(dbAdminAttr, contains(user.memberOf, "admin")); err != nil {
+				return diag.FromErr(err)
+			}
+			userOptions := roleOptions.(map[string]map[string]string)[user.username]
+			if err := d.Set(dbSubjectAttr, userOptions[subjectRoleOption]); err != nil {
+				return diag.FromErr(err)
+			}
+			roles := d.Get(dbRolesAttr).(string)
+			if err := d.Set(dbRoleOptionsAttr, managedRoleOptions(userOptions, roles)); err != nil {
+				return diag.FromErr(err)
+			}
+			if !strings.Contains(strings.ToUpper(roles), validUntilRoleOption) {
+				if err := d.Set(dbValidUntilAttr, formatValidUntil(userOptions[validUntilRoleOption])); err != nil {
+					return diag.FromErr(err)
+				}
+			}
+			if err := d.Set(userIDAttr, ids.(map[string]int64)[user.username]); err != nil {
 				return diag.FromErr(err)
 			}
 			found = true
 			break
 		}
 	}
-	// get any error encountered during iteration
-	err = rows.Err()
-	if err != nil {
-		return diag.FromErr(err)
-	}
 
 	if !found {
 		if err := d.Set(dbNameAttr, ""); err != nil {
@@ -233,11 +315,197 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 	return nil
 }
 
+// userRow is a row returned by SHOW USERS.
+type userRow struct {
+	username string
+	options  string
+	memberOf []string
+}
+
+func showUsers(ctx context.Context, conn *pgx.Conn) ([]userRow, error) {
+	rows, err := conn.Query(ctx, "SHOW USERS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []userRow
+	for rows.Next() {
+		var user userRow
+		if err := rows.Scan(&user.username, &user.options, &user.memberOf); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	// get any error encountered during iteration
+	return users, rows.Err()
+}
+
+const (
+	subjectRoleOption    = "SUBJECT"
+	validUntilRoleOption = "VALID UNTIL"
+)
+
+// roleOptions are the role options managed by role_options, the ones without
+// a value. Only the options differing from their default are stored in
+// system.role_options.
+var roleOptions = []string{
+	"CANCELQUERY",
+	"CONTROLCHANGEFEED",
+	"CONTROLJOB",
+	"CREATEDB",
+	"CREATELOGIN",
+	"CREATEROLE",
+	"MODIFYCLUSTERSETTING",
+	"NOLOGIN",
+	"NOSQLLOGIN",
+	"VIEWACTIVITY",
+	"VIEWACTIVITYREDACTED",
+	"VIEWCLUSTERSETTING",
+}
+
+// validUntilLayout is the layout of the VALID UNTIL values in
+// system.role_options.
+const validUntilLayout = "2006-01-02 15:04:05.999999-07:00"
+
+// readUserRoleOptions returns the role options of the users by name, with
+// their value, empty for the options without one. The SUBJECT role option
+// only exists from CockroachDB 24.1, none is read before.
+func readUserRoleOptions(ctx context.Context, conn *pgx.Conn) (map[string]map[string]string, error) {
+	rows, err := conn.Query(ctx, `SELECT username, option, value FROM system.role_options`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	options := map[string]map[string]string{}
+	for rows.Next() {
+		var username, option string
+		var value *string
+		if err := rows.Scan(&username, &option, &value); err != nil {
+			return nil, err
+		}
+		if options[username] == nil {
+			options[username] = map[string]string{}
+		}
+		options[username][option] = ""
+		if value != nil {
+			options[username][option] = *value
+		}
+	}
+
+	return options, rows.Err()
+}
+
+// managedRoleOptions returns the options of role_options the user has, but
+// the ones set in roles, which aren't refreshed.
+func managedRoleOptions(options map[string]string, roles string) []string {
+	words := strings.Fields(strings.ToUpper(roles))
+
+	var managed []string
+	for _, option := range roleOptions {
+		if _, ok := options[option]; ok && !contains(words, option) && !contains(words, revokedRoleOption(option)) {
+			managed = append(managed, option)
+		}
+	}
+
+	return managed
+}
+
+// revokedRoleOption returns the option reverting the role option to its
+// default, e.g. NOCREATEDB for CREATEDB and LOGIN for NOLOGIN.
+func revokedRoleOption(option string) string {
+	if strings.HasPrefix(option, "NO") {
+		return strings.TrimPrefix(option, "NO")
+	}
+
+	return "NO" + option
+}
+
+// roleOptionsStatement returns the ALTER ROLE statement granting the options
+// of n not in o, revoking the ones of o not in n and changing the expiry of
+// the password from oldValidUntil to newValidUntil, none when nothing changes.
+func roleOptionsStatement(name string, o []string, n []string, oldValidUntil string, newValidUntil string) string {
+	var options []string
+	for _, option := range n {
+		if !contains(o, option) {
+			options = append(options, option)
+		}
+	}
+	for _, option := range o {
+		if !contains(n, option) {
+			options = append(options, revokedRoleOption(option))
+		}
+	}
+	sort.Strings(options)
+
+	if !timesEqual(oldValidUntil, newValidUntil) {
+		if newValidUntil == "" {
+			options = append(options, validUntilRoleOption+` NULL`)
+		} else {
+			options = append(options, validUntilRoleOption+` `+pq.QuoteLiteral(newValidUntil))
+		}
+	}
+
+	if len(options) == 0 {
+		return ""
+	}
+
+	return `ALTER ROLE ` + pq.QuoteIdentifier(name) + ` WITH ` + strings.Join(options, ` `)
+}
+
+// formatValidUntil returns the VALID UNTIL value read from
+// system.role_options in RFC 3339 format, as is when it can't be parsed.
+func formatValidUntil(value string) string {
+	t, err := time.Parse(validUntilLayout, value)
+	if err != nil {
+		return value
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// timesEqual returns whether the RFC 3339 times are the same instant, e.g.
+// with different time zones.
+func timesEqual(a string, b string) bool {
+	if a == b {
+		return true
+	}
+
+	ta, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return false
+	}
+	tb, err := time.Parse(time.RFC3339, b)
+	if err != nil {
+		return false
+	}
+
+	return ta.Equal(tb)
+}
+
+// suppressEquivalentTime suppresses the diff between RFC 3339 times being
+// the same instant, e.g. `2030-01-01T01:00:00+01:00` as configured and
+// `2030-01-01T00:00:00Z` as read.
+func suppressEquivalentTime(k, old, new string, d *schema.ResourceData) bool {
+	return timesEqual(old, new)
+}
+
+// userSubjectStatement maps the user to the subject of its certificates, or
+// removes the mapping when the subject is empty.
+func userSubjectStatement(name string, subject string) string {
+	if subject == "" {
+		return `ALTER ROLE ` + pq.QuoteIdentifier(name) + ` WITH SUBJECT NULL`
+	}
+
+	return `ALTER ROLE ` + pq.QuoteIdentifier(name) + ` WITH SUBJECT ` + pq.QuoteLiteral(subject)
+}
+
 func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	cockroachClient := meta.(*cockroachClient)
 
 	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
 
 	if local_port == "" {
 		return diag.Errorf("local_port can't be an empty string")
@@ -245,24 +513,16 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 
 	d.Partial(true)
 
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-	defer close(stopCh)
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
+
+	unlock := cockroachClient.locks.lock(roleLockKey(d.Id()))
+	defer unlock()
+	defer cockroachClient.cache.invalidate()
 
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
-	}
 	if d.HasChange(dbAdminAttr) || d.HasChange(dbRolesAttr) || d.HasChange(dbPasswordAttr) {
 		_, npass := d.GetChange(dbPasswordAttr)
 		oadmin, nadmin := d.GetChange(dbAdminAttr)
@@ -279,43 +539,35 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 		}
 
 		// ALTER user
-		_, err := conn.Exec(ctx,
-			`ALTER USER `+
-				pq.QuoteIdentifier(name)+
-				` WITH PASSWORD '`+
-				password+
-				`' `+
+		statements := []string{
+			`ALTER USER ` +
+				pq.QuoteIdentifier(name) +
+				` WITH PASSWORD '` +
+				password +
+				`' ` +
 				roles,
-		)
-
-		if err != nil {
-			return diag.FromErr(err)
 		}
 
 		// disable or grant admin
 		if oadmin == true && nadmin == false {
 			// revoke admin
-			_, err := conn.Exec(ctx,
+			statements = append(statements,
 				`REVOKE admin from `+
 					pq.QuoteIdentifier(name),
 			)
-
-			if err != nil {
-				return diag.FromErr(err)
-			}
 		}
 
 		if oadmin == false && nadmin == true {
 			// grant admin priviledged
-			_, err := conn.Exec(ctx,
+			statements = append(statements,
 				`GRANT admin to `+
 					pq.QuoteIdentifier(name)+
 					` WITH ADMIN OPTION`,
 			)
+		}
 
-			if err != nil {
-				return diag.FromErr(err)
-			}
+		if err := execInTransaction(ctx, conn, statements...); err != nil {
+			return diag.FromErr(err)
 		}
 
 		d.Set(dbAdminAttr, nadmin)
@@ -323,6 +575,34 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 		d.Set(dbPasswordAttr, npass)
 	}
 
+	if d.HasChange(dbSubjectAttr) {
+		subject := d.Get(dbSubjectAttr).(string)
+		if _, err := conn.Exec(ctx, userSubjectStatement(d.Id(), subject)); err != nil {
+			return diag.FromErr(err)
+		}
+
+		d.Set(dbSubjectAttr, subject)
+	}
+
+	// only the options changed, in the configuration or outside of Terraform,
+	// are altered, the user being kept
+	if d.HasChanges(dbRoleOptionsAttr, dbValidUntilAttr) {
+		oraw, nraw := d.GetChange(dbRoleOptionsAttr)
+		oldValidUntil, newValidUntil := d.GetChange(dbValidUntilAttr)
+		o := convertToString(oraw.(*schema.Set).List())
+		n := convertToString(nraw.(*schema.Set).List())
+
+		statement := roleOptionsStatement(d.Id(), o, n, oldValidUntil.(string), newValidUntil.(string))
+		if statement != "" {
+			if _, err := conn.Exec(ctx, statement); err != nil {
+				return diag.Errorf("failed to run %q: %v", statement, err)
+			}
+		}
+
+		d.Set(dbRoleOptionsAttr, nraw)
+		d.Set(dbValidUntilAttr, newValidUntil)
+	}
+
 	d.Partial(false)
 	return diag.Diagnostics{}
 }
@@ -331,38 +611,47 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interf
 	cockroachClient := meta.(*cockroachClient)
 
 	local_port := d.Get(argLocalPort).(string)
-	dns := strings.Replace(cockroachClient.dns, "<local_port>", local_port, 1)
 
 	if local_port == "" {
 		return diag.Errorf("local_port can't be an empty string")
 	}
 
-	// stopCh control the port forwarding lifecycle. When it gets closed the
-	// port forward will terminate
-	stopCh := make(chan struct{}, 1)
-	// readyCh communicate when the port forward is ready to get traffic
-	readyCh := make(chan struct{})
-	defer close(stopCh)
-
-	tryPortForwardIfNeeded(ctx, d, meta, stopCh, readyCh, local_port)
-
-	conn, err := pgx.Connect(ctx, dns)
-
-	if err != nil {
-		return diag.FromErr(err)
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
 	}
+	defer closeConn()
 
-	if err := conn.Ping(ctx); err != nil {
-		return diag.FromErr(err)
-	}
 	username := d.Get(dbUsernameAttr).(string)
 
 	if username == "" {
 		return diag.Errorf("User name can't be an empty string")
 	}
 
-	_, err = conn.Exec(ctx, `DROP USER `+pq.QuoteIdentifier(username))
-	if err != nil {
+	unlock := cockroachClient.locks.lock(roleLockKey(username))
+	defer unlock()
+	defer cockroachClient.cache.invalidate()
+
+	// a user already dropped has nothing to release, the ownership statements
+	// failing on it before DROP USER would be tolerated
+	if d.Get(argTolerateExistingState).(bool) {
+		exists, err := userExists(ctx, conn, username)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if !exists {
+			logInfo("tolerating the existing state: user %s doesn't exist", username)
+			d.SetId("")
+			return diag.Diagnostics{}
+		}
+	}
+
+	if err := releaseUserOwnership(ctx, conn, username, d.Get(dbReassignOwnedToAttr).(string), d.Get(dbDropOwnedAttr).(bool)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err := conn.Exec(ctx, `DROP USER `+pq.QuoteIdentifier(username))
+	if err != nil && !toleratedExistingState(d, err, doesNotExistCodes) {
 		return diag.FromErr(err)
 	}
 
@@ -387,6 +676,68 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interf
 	return diag.Diagnostics{}
 }
 
+// userExists returns whether the user exists, CockroachDB lowercasing the
+// names of the users.
+func userExists(ctx context.Context, conn *pgx.Conn, username string) (bool, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM [SHOW USERS] WHERE username = lower($1))`, username).Scan(&exists)
+
+	return exists, err
+}
+
+// releaseUserOwnership reassigns the objects owned by the user and drops the
+// remaining ones, with its privileges, so it can be dropped. Both statements
+// only apply to the current database, so they run in every database.
+func releaseUserOwnership(ctx context.Context, conn *pgx.Conn, username string, reassignTo string, dropOwned bool) error {
+	statements := userOwnershipStatements(username, reassignTo, dropOwned)
+	if len(statements) == 0 {
+		return nil
+	}
+
+	rows, err := conn.Query(ctx, `SELECT name FROM crdb_internal.databases WHERE name != 'system' ORDER BY name`)
+	if err != nil {
+		return err
+	}
+	var databases []string
+	for rows.Next() {
+		var database string
+		if err := rows.Scan(&database); err != nil {
+			rows.Close()
+			return err
+		}
+		databases = append(databases, database)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, database := range databases {
+		if err := useDatabase(ctx, conn, database); err != nil {
+			return err
+		}
+		for _, statement := range statements {
+			if _, err := conn.Exec(ctx, statement); err != nil {
+				return fmt.Errorf("failed to release the objects of %s in %s: %v", username, database, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func userOwnershipStatements(username string, reassignTo string, dropOwned bool) []string {
+	var statements []string
+	if reassignTo != "" {
+		statements = append(statements, `REASSIGN OWNED BY `+pq.QuoteIdentifier(username)+` TO `+pq.QuoteIdentifier(reassignTo))
+	}
+	if dropOwned {
+		statements = append(statements, `DROP OWNED BY `+pq.QuoteIdentifier(username))
+	}
+
+	return statements
+}
+
 func resourceUserImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	err := resourceUserRead(ctx, d, meta)
 	if err != nil {
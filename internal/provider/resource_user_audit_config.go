@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	userAuditRuleAttr                 = "rule"
+	userAuditRoleAttr                 = "role"
+	userAuditStatementsAttr           = "statements"
+	userAuditReducedConfigEnabledAttr = "reduced_config_enabled"
+
+	userAuditSetting              = "sql.log.user_audit"
+	userAuditReducedConfigSetting = "sql.log.user_audit.reduced_config.enabled"
+
+	userAuditID               = "user_audit_config"
+	userAuditDefaultLocalPort = "26285"
+)
+
+func resourceUserAuditConfig() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to audit the statements of the users of a CockroachDB cluster by role, logged to the `SENSITIVE_ACCESS` channel. " +
+			"It manages the `sql.log.user_audit` cluster setting as a whole, the first rule matching a role of the user applying. The settings are reset when the resource is destroyed.",
+
+		CreateContext: resourceUserAuditConfigCreate,
+		ReadContext:   resourceUserAuditConfigRead,
+		UpdateContext: resourceUserAuditConfigCreate,
+		DeleteContext: resourceUserAuditConfigDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceUserAuditConfigImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			userAuditRuleAttr: {
+				Description: "Rules of the audit, in order.",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						userAuditRoleAttr: {
+							Description:      "Name of the role, or `ALL` for every user.",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressRoleNameCase,
+						},
+						userAuditStatementsAttr: {
+							Description:  "Statements of the users with the role which are audited, `ALL` or `NONE`.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "ALL",
+							ValidateFunc: validation.StringInSlice([]string{"ALL", "NONE"}, false),
+						},
+					},
+				},
+			},
+			userAuditReducedConfigEnabledAttr: {
+				Description: "Whether the roles of a user are only matched against the rules once per session, which lowers the cost of the audit but delays the changes of roles.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26285), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     userAuditDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// userAuditConfig returns the value of the sql.log.user_audit setting of the
+// rules, a rule per line.
+func userAuditConfig(rules []interface{}) string {
+	lines := make([]string, len(rules))
+	for i, r := range rules {
+		rule := r.(map[string]interface{})
+		lines[i] = rule[userAuditRoleAttr].(string) + " " + rule[userAuditStatementsAttr].(string)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseUserAuditConfig returns the rules of the value of the
+// sql.log.user_audit setting.
+func parseUserAuditConfig(config string) ([]interface{}, error) {
+	var rules []interface{}
+	for _, line := range strings.Split(config, "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected audit rule %q", line)
+		}
+		rules = append(rules, map[string]interface{}{
+			userAuditRoleAttr:       fields[0],
+			userAuditStatementsAttr: strings.ToUpper(fields[1]),
+		})
+	}
+
+	return rules, nil
+}
+
+func resourceUserAuditConfigCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	if err := setClusterSetting(ctx, conn, userAuditSetting, userAuditConfig(d.Get(userAuditRuleAttr).([]interface{}))); err != nil {
+		return diag.Errorf("failed to set %s: %v", userAuditSetting, err)
+	}
+	if err := setClusterSetting(ctx, conn, userAuditReducedConfigSetting, fmt.Sprint(d.Get(userAuditReducedConfigEnabledAttr).(bool))); err != nil {
+		return diag.Errorf("failed to set %s: %v", userAuditReducedConfigSetting, err)
+	}
+
+	d.SetId(userAuditID)
+
+	return resourceUserAuditConfigReadConn(ctx, conn, d)
+}
+
+func resourceUserAuditConfigReadConn(ctx context.Context, conn *pgx.Conn, d *schema.ResourceData) diag.Diagnostics {
+	current, err := readClusterSettings(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rules, err := parseUserAuditConfig(current[userAuditSetting].value)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set(userAuditRuleAttr, rules); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if setting, ok := current[userAuditReducedConfigSetting]; ok {
+		if err := d.Set(userAuditReducedConfigEnabledAttr, settingValuesEqual(setting.value, "true")); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceUserAuditConfigRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	return resourceUserAuditConfigReadConn(ctx, conn, d)
+}
+
+func resourceUserAuditConfigDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	for _, name := range []string{userAuditSetting, userAuditReducedConfigSetting} {
+		if err := resetClusterSetting(ctx, conn, name); err != nil {
+			return diag.Errorf("failed to reset %s: %v", name, err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceUserAuditConfigImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if d.Id() != userAuditID {
+		return nil, fmt.Errorf("invalid user audit config id %q, expected %s", d.Id(), userAuditID)
+	}
+	if err := d.Set(argLocalPort, userAuditDefaultLocalPort); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceUserAuditConfig(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceUserAuditConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_user_audit_config.foo", "rule.#", "2"),
+					resource.TestCheckResourceAttr("cockroach_user_audit_config.foo", "rule.0.role", "auditor"),
+					resource.TestCheckResourceAttr("cockroach_user_audit_config.foo", "rule.1.statements", "NONE"),
+				),
+			},
+		},
+	})
+}
+
+func TestUserAuditConfig(t *testing.T) {
+	rules := []interface{}{
+		map[string]interface{}{"role": "admin", "statements": "ALL"},
+		map[string]interface{}{"role": "ALL", "statements": "NONE"},
+	}
+
+	config := userAuditConfig(rules)
+	if config != "admin ALL\nALL NONE" {
+		t.Errorf("unexpected config %q", config)
+	}
+
+	parsed, err := parseUserAuditConfig("# audit the admins\nadmin ALL\n\nALL none\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, rules) {
+		t.Errorf("got %v, expected %v", parsed, rules)
+	}
+
+	if _, err := parseUserAuditConfig("admin"); err == nil {
+		t.Errorf("expected an error for an invalid rule")
+	}
+}
+
+const testAccResourceUserAuditConfig = `
+resource "cockroach_user_audit_config" "foo" {
+  rule {
+    role = "auditor"
+  }
+
+  rule {
+    role       = "ALL"
+    statements = "NONE"
+  }
+}
+`
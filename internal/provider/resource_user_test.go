@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"reflect"
 	"regexp"
 	"testing"
 
@@ -25,6 +26,71 @@ func TestAccResourceUser(t *testing.T) {
 	})
 }
 
+func TestUserSubjectStatement(t *testing.T) {
+	if s := userSubjectStatement("app", "CN=app,O=Example's"); s != `ALTER ROLE "app" WITH SUBJECT 'CN=app,O=Example''s'` {
+		t.Errorf("unexpected statement %s", s)
+	}
+	if s := userSubjectStatement("app", ""); s != `ALTER ROLE "app" WITH SUBJECT NULL` {
+		t.Errorf("unexpected statement %s", s)
+	}
+}
+
+func TestRoleOptionsStatement(t *testing.T) {
+	cases := []struct {
+		o, n                         []string
+		oldValidUntil, newValidUntil string
+		expected                     string
+	}{
+		{nil, nil, "", "", ""},
+		{[]string{"CREATEDB"}, []string{"CREATEDB"}, "2030-01-01T00:00:00Z", "2030-01-01T01:00:00+01:00", ""},
+		{nil, []string{"CREATEDB", "VIEWACTIVITY"}, "", "", `ALTER ROLE "app" WITH CREATEDB VIEWACTIVITY`},
+		{[]string{"CREATEDB", "NOLOGIN"}, []string{"CREATEDB"}, "", "", `ALTER ROLE "app" WITH LOGIN`},
+		{[]string{"VIEWACTIVITY"}, []string{"CONTROLJOB"}, "", "2030-01-01T00:00:00Z", `ALTER ROLE "app" WITH CONTROLJOB NOVIEWACTIVITY VALID UNTIL '2030-01-01T00:00:00Z'`},
+		{nil, nil, "2030-01-01T00:00:00Z", "", `ALTER ROLE "app" WITH VALID UNTIL NULL`},
+	}
+
+	for _, c := range cases {
+		if s := roleOptionsStatement("app", c.o, c.n, c.oldValidUntil, c.newValidUntil); s != c.expected {
+			t.Errorf("unexpected statement from %v to %v: %q, expected %q", c.o, c.n, s, c.expected)
+		}
+	}
+}
+
+func TestManagedRoleOptions(t *testing.T) {
+	options := map[string]string{"CREATEDB": "", "NOLOGIN": "", "VIEWACTIVITY": "", "SUBJECT": "CN=app", "VALID UNTIL": "2030-01-01 00:00:00+00:00"}
+
+	expected := []string{"CREATEDB", "NOLOGIN", "VIEWACTIVITY"}
+	if actual := managedRoleOptions(options, ""); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// the options set in roles, or their opposite, are left to it
+	expected = []string{"NOLOGIN"}
+	if actual := managedRoleOptions(options, "createdb NOVIEWACTIVITY"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestFormatValidUntil(t *testing.T) {
+	if s := formatValidUntil("2030-01-01 00:00:00+00:00"); s != "2030-01-01T00:00:00Z" {
+		t.Errorf("unexpected time %s", s)
+	}
+	if s := formatValidUntil(""); s != "" {
+		t.Errorf("unexpected time %s", s)
+	}
+}
+
+func TestUserOwnershipStatements(t *testing.T) {
+	if s := userOwnershipStatements("app", "", false); len(s) != 0 {
+		t.Errorf("expected no statement, got %v", s)
+	}
+
+	expected := []string{`REASSIGN OWNED BY "app" TO "owner"`, `DROP OWNED BY "app"`}
+	if s := userOwnershipStatements("app", "owner", true); !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %v, got %v", expected, s)
+	}
+}
+
 const testAccResourceUser = `
 resource "cockroach_user" "foo" {
   username = "bar"
@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	virtualClusterNameAttr            = "name"
+	virtualClusterServiceModeAttr     = "service_mode"
+	virtualClusterDropImmediatelyAttr = "drop_immediately"
+	virtualClusterIDAttr              = "virtual_cluster_id"
+	virtualClusterDataStateAttr       = "data_state"
+
+	virtualClusterServiceNone     = "none"
+	virtualClusterServiceShared   = "shared"
+	virtualClusterServiceExternal = "external"
+
+	virtualClusterDefaultLocalPort = "26280"
+)
+
+var virtualClusterNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,98}[a-z0-9])?$`)
+
+func resourceVirtualCluster() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to create a virtual cluster of a CockroachDB cluster running with cluster virtualization, and start or stop its SQL service. " +
+			"The service of the virtual cluster is stopped before it is dropped when the resource is destroyed.",
+
+		CreateContext: resourceVirtualClusterCreate,
+		ReadContext:   resourceVirtualClusterRead,
+		UpdateContext: resourceVirtualClusterUpdate,
+		DeleteContext: resourceVirtualClusterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVirtualClusterImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			virtualClusterNameAttr: {
+				Description:  "Name of the virtual cluster, renamed in place when changed.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringMatch(virtualClusterNameRegexp, "must contain lowercase letters, digits and hyphens, and start and end with a letter or a digit"),
+			},
+			virtualClusterServiceModeAttr: {
+				Description:  "Mode of the SQL service of the virtual cluster, `none` to stop it, `shared` to run it in the processes of the cluster or `external` to run it in separate processes.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      virtualClusterServiceNone,
+				ValidateFunc: validation.StringInSlice([]string{virtualClusterServiceNone, virtualClusterServiceShared, virtualClusterServiceExternal}, false),
+			},
+			virtualClusterDropImmediatelyAttr: {
+				Description: "Whether the data of the virtual cluster is deleted immediately when it is dropped instead of after the garbage collection window.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			virtualClusterIDAttr: {
+				Description: "Id of the virtual cluster.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			virtualClusterDataStateAttr: {
+				Description: "State of the data of the virtual cluster, e.g. `ready`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26280), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     virtualClusterDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// virtualClusterServiceStatements returns the statements changing the service
+// of the virtual cluster from o to n, the service being stopped before it is
+// started in another mode.
+func virtualClusterServiceStatements(name string, o string, n string) []string {
+	if o == n {
+		return nil
+	}
+
+	var statements []string
+	if o != virtualClusterServiceNone {
+		statements = append(statements, `ALTER VIRTUAL CLUSTER `+pq.QuoteIdentifier(name)+` STOP SERVICE`)
+	}
+	if n != virtualClusterServiceNone {
+		statements = append(statements, `ALTER VIRTUAL CLUSTER `+pq.QuoteIdentifier(name)+` START SERVICE `+strings.ToUpper(n))
+	}
+
+	return statements
+}
+
+// execStatements runs the statements in order, outside of a transaction as
+// the statements changing virtual clusters can't run in one.
+func execStatements(ctx context.Context, conn *pgx.Conn, statements []string) error {
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceVirtualClusterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	name := d.Get(virtualClusterNameAttr).(string)
+	if _, err := conn.Exec(ctx, `CREATE VIRTUAL CLUSTER `+pq.QuoteIdentifier(name)); err != nil {
+		return diag.Errorf("failed to create virtual cluster %s: %v", name, err)
+	}
+	d.SetId(name)
+
+	if err := execStatements(ctx, conn, virtualClusterServiceStatements(name, virtualClusterServiceNone, d.Get(virtualClusterServiceModeAttr).(string))); err != nil {
+		return diag.Errorf("failed to start the service of virtual cluster %s: %v", name, err)
+	}
+
+	return resourceVirtualClusterRead(ctx, d, meta)
+}
+
+// readVirtualCluster reads the id, the data state and the service mode of the
+// virtual cluster, ok being false when it doesn't exist.
+func readVirtualCluster(ctx context.Context, conn *pgx.Conn, name string) (id string, dataState string, serviceMode string, ok bool, err error) {
+	err = conn.QueryRow(ctx,
+		`SELECT id::STRING, data_state, service_mode FROM [SHOW VIRTUAL CLUSTERS] WHERE name = $1`, name).Scan(&id, &dataState, &serviceMode)
+	if err == pgx.ErrNoRows {
+		return "", "", "", false, nil
+	}
+	if err != nil {
+		return "", "", "", false, err
+	}
+
+	return id, dataState, strings.ToLower(serviceMode), true, nil
+}
+
+func resourceVirtualClusterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	id, dataState, serviceMode, ok, err := readVirtualCluster(ctx, conn, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !ok {
+		logInfo("virtual cluster %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	values := map[string]interface{}{
+		virtualClusterNameAttr:        d.Id(),
+		virtualClusterServiceModeAttr: serviceMode,
+		virtualClusterIDAttr:          id,
+		virtualClusterDataStateAttr:   dataState,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceVirtualClusterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChanges(virtualClusterNameAttr, virtualClusterServiceModeAttr) {
+		return resourceVirtualClusterRead(ctx, d, meta)
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	// the service of a virtual cluster must be stopped to rename it
+	o, n := d.GetChange(virtualClusterServiceModeAttr)
+	oldMode, newMode := o.(string), n.(string)
+	if d.HasChange(virtualClusterNameAttr) && oldMode != virtualClusterServiceNone {
+		if err := execStatements(ctx, conn, virtualClusterServiceStatements(d.Id(), oldMode, virtualClusterServiceNone)); err != nil {
+			return diag.Errorf("failed to stop the service of virtual cluster %s: %v", d.Id(), err)
+		}
+		oldMode = virtualClusterServiceNone
+	}
+
+	if d.HasChange(virtualClusterNameAttr) {
+		name := d.Get(virtualClusterNameAttr).(string)
+		if _, err := conn.Exec(ctx, `ALTER VIRTUAL CLUSTER `+pq.QuoteIdentifier(d.Id())+` RENAME TO `+pq.QuoteIdentifier(name)); err != nil {
+			return diag.Errorf("failed to rename virtual cluster %s: %v", d.Id(), err)
+		}
+		d.SetId(name)
+	}
+
+	if err := execStatements(ctx, conn, virtualClusterServiceStatements(d.Id(), oldMode, newMode)); err != nil {
+		return diag.Errorf("failed to change the service of virtual cluster %s: %v", d.Id(), err)
+	}
+
+	return resourceVirtualClusterRead(ctx, d, meta)
+}
+
+func resourceVirtualClusterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	_, _, serviceMode, ok, err := readVirtualCluster(ctx, conn, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if ok {
+		if err := execStatements(ctx, conn, virtualClusterServiceStatements(d.Id(), serviceMode, virtualClusterServiceNone)); err != nil {
+			return diag.Errorf("failed to stop the service of virtual cluster %s: %v", d.Id(), err)
+		}
+
+		statement := `DROP VIRTUAL CLUSTER ` + pq.QuoteIdentifier(d.Id())
+		if d.Get(virtualClusterDropImmediatelyAttr).(bool) {
+			statement += ` IMMEDIATE`
+		}
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to drop virtual cluster %s: %v", d.Id(), err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceVirtualClusterImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id is the name of the virtual cluster
+	if !virtualClusterNameRegexp.MatchString(d.Id()) {
+		return nil, fmt.Errorf("invalid virtual cluster id %q, expected its name", d.Id())
+	}
+
+	values := map[string]interface{}{
+		virtualClusterNameAttr:            d.Id(),
+		virtualClusterDropImmediatelyAttr: false,
+		argLocalPort:                      virtualClusterDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
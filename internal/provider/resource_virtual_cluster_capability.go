@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	capabilityVirtualClusterAttr = "virtual_cluster"
+	capabilityNameAttr           = "capability"
+	capabilityValueAttr          = "value"
+
+	virtualClusterCapabilityDefaultLocalPort = "26281"
+)
+
+var capabilityNameRegexp = regexp.MustCompile(`^[a-z_]+$`)
+
+func resourceVirtualClusterCapability() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to grant a capability to a virtual cluster of a CockroachDB cluster, e.g. `can_admin_split`. The capability is revoked when the resource is destroyed.",
+
+		CreateContext: resourceVirtualClusterCapabilityCreate,
+		ReadContext:   resourceVirtualClusterCapabilityRead,
+		UpdateContext: resourceVirtualClusterCapabilityCreate,
+		DeleteContext: resourceVirtualClusterCapabilityDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVirtualClusterCapabilityImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			capabilityVirtualClusterAttr: {
+				Description: "Name of the virtual cluster.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			capabilityNameAttr: {
+				Description:  "Name of the capability, e.g. `can_admin_split` or `can_view_node_info`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(capabilityNameRegexp, "invalid capability name"),
+			},
+			capabilityValueAttr: {
+				Description:  "Value of the capability, `true` for the boolean capabilities, which are revoked by destroying the resource.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "true",
+				ValidateFunc: validation.StringNotInSlice([]string{"false"}, false),
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26281), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     virtualClusterCapabilityDefaultLocalPort,
+			},
+		},
+	}
+}
+
+// grantCapabilityStatement returns the statement granting the capability, the
+// true value of the boolean capabilities being omitted.
+func grantCapabilityStatement(virtualCluster string, capability string, value string) string {
+	statement := `ALTER VIRTUAL CLUSTER ` + pq.QuoteIdentifier(virtualCluster) + ` GRANT CAPABILITY ` + capability
+	if value != "true" {
+		statement += ` = ` + pq.QuoteLiteral(value)
+	}
+
+	return statement
+}
+
+func resourceVirtualClusterCapabilityCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	virtualCluster, capability := d.Get(capabilityVirtualClusterAttr).(string), d.Get(capabilityNameAttr).(string)
+	if _, err := conn.Exec(ctx, grantCapabilityStatement(virtualCluster, capability, d.Get(capabilityValueAttr).(string))); err != nil {
+		return diag.Errorf("failed to grant %s to virtual cluster %s: %v", capability, virtualCluster, err)
+	}
+	d.SetId(virtualCluster + "/" + capability)
+
+	return resourceVirtualClusterCapabilityRead(ctx, d, meta)
+}
+
+// readCapability reads the value of the capability of the virtual cluster, ok
+// being false when the virtual cluster or the capability doesn't exist.
+func readCapability(ctx context.Context, conn *pgx.Conn, virtualCluster string, capability string) (string, bool, error) {
+	var value string
+	err := conn.QueryRow(ctx,
+		`SELECT capability_value FROM [SHOW VIRTUAL CLUSTERS WITH CAPABILITIES] WHERE name = $1 AND capability_name = $2`,
+		virtualCluster, capability).Scan(&value)
+	if err == pgx.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, true, nil
+}
+
+func resourceVirtualClusterCapabilityRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	value, ok, err := readCapability(ctx, conn, d.Get(capabilityVirtualClusterAttr).(string), d.Get(capabilityNameAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// a revoked boolean capability is shown as false
+	if !ok || value == "false" {
+		logInfo("capability %s not found or revoked, removing it from state", d.Id())
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+
+	if err := d.Set(capabilityValueAttr, value); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceVirtualClusterCapabilityDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	virtualCluster, capability := d.Get(capabilityVirtualClusterAttr).(string), d.Get(capabilityNameAttr).(string)
+	if _, ok, err := readCapability(ctx, conn, virtualCluster, capability); err != nil {
+		return diag.FromErr(err)
+	} else if ok {
+		if _, err := conn.Exec(ctx, `ALTER VIRTUAL CLUSTER `+pq.QuoteIdentifier(virtualCluster)+` REVOKE CAPABILITY `+capability); err != nil {
+			return diag.Errorf("failed to revoke %s from virtual cluster %s: %v", capability, virtualCluster, err)
+		}
+	}
+
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceVirtualClusterCapabilityImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// id has the format virtual_cluster/capability
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 2 || !capabilityNameRegexp.MatchString(parts[1]) {
+		return nil, fmt.Errorf("invalid capability id %q, expected virtual_cluster/capability", d.Id())
+	}
+
+	values := map[string]interface{}{
+		capabilityVirtualClusterAttr: parts[0],
+		capabilityNameAttr:           parts[1],
+		argLocalPort:                 virtualClusterCapabilityDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,14 @@
+package provider
+
+import "testing"
+
+func TestGrantCapabilityStatement(t *testing.T) {
+	if statement := grantCapabilityStatement("app", "can_admin_split", "true"); statement != `ALTER VIRTUAL CLUSTER "app" GRANT CAPABILITY can_admin_split` {
+		t.Errorf("unexpected statement %s", statement)
+	}
+
+	expected := `ALTER VIRTUAL CLUSTER "app" GRANT CAPABILITY span_config_bounds = 'gc.ttlseconds: {start: 3600}'`
+	if statement := grantCapabilityStatement("app", "span_config_bounds", "gc.ttlseconds: {start: 3600}"); statement != expected {
+		t.Errorf("got %s, expected %s", statement, expected)
+	}
+}
@@ -0,0 +1,41 @@
+package provider
+
+import "testing"
+
+func TestStartReplicationStatement(t *testing.T) {
+	cases := []struct {
+		retention          string
+		readVirtualCluster bool
+		failback           bool
+		expected           string
+	}{
+		{"", false, false, `CREATE VIRTUAL CLUSTER "standby" FROM REPLICATION OF "main" ON 'external://primary'`},
+		{"24h", true, false, `CREATE VIRTUAL CLUSTER "standby" FROM REPLICATION OF "main" ON 'external://primary' WITH RETENTION = '24h', READ VIRTUAL CLUSTER`},
+		{"", false, true, `ALTER VIRTUAL CLUSTER "standby" START REPLICATION OF "main" ON 'external://primary'`},
+	}
+
+	for _, c := range cases {
+		if statement := startReplicationStatement("standby", "main", "external://primary", c.retention, c.readVirtualCluster, c.failback); statement != c.expected {
+			t.Errorf("got %s, expected %s", statement, c.expected)
+		}
+	}
+}
+
+func TestCutoverStatement(t *testing.T) {
+	if statement := cutoverStatement("standby", "latest"); statement != `ALTER VIRTUAL CLUSTER "standby" COMPLETE REPLICATION TO LATEST` {
+		t.Errorf("unexpected statement %s", statement)
+	}
+	expected := `ALTER VIRTUAL CLUSTER "standby" COMPLETE REPLICATION TO SYSTEM TIME '2024-05-01T10:00:00Z'`
+	if statement := cutoverStatement("standby", "2024-05-01T10:00:00Z"); statement != expected {
+		t.Errorf("got %s, expected %s", statement, expected)
+	}
+
+	for _, cutoverTo := range []string{"latest", "1714557600000000000.0000000000", "2024-05-01T10:00:00Z"} {
+		if _, errs := validateCutoverTo(cutoverTo, "cutover_to"); len(errs) != 0 {
+			t.Errorf("unexpected errors for %q: %v", cutoverTo, errs)
+		}
+	}
+	if _, errs := validateCutoverTo("now", "cutover_to"); len(errs) == 0 {
+		t.Errorf("expected an error for an invalid time")
+	}
+}
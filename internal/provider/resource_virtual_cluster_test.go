@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// The acceptance test requires a cluster started with
+// --config-profile=virtualization-noapp, the virtual clusters being disabled
+// otherwise.
+func TestAccResourceVirtualCluster(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVirtualCluster("analytics", "none"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_virtual_cluster.foo", "service_mode", "none"),
+					resource.TestCheckResourceAttr("cockroach_virtual_cluster_capability.split", "value", "true"),
+				),
+			},
+			{
+				Config: testAccResourceVirtualCluster("reporting", "shared"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cockroach_virtual_cluster.foo", "name", "reporting"),
+					resource.TestCheckResourceAttr("cockroach_virtual_cluster.foo", "service_mode", "shared"),
+				),
+			},
+		},
+	})
+}
+
+func TestVirtualClusterServiceStatements(t *testing.T) {
+	cases := []struct {
+		o, n     string
+		expected []string
+	}{
+		{"none", "none", nil},
+		{"none", "shared", []string{`ALTER VIRTUAL CLUSTER "app" START SERVICE SHARED`}},
+		{"shared", "none", []string{`ALTER VIRTUAL CLUSTER "app" STOP SERVICE`}},
+		{"shared", "external", []string{`ALTER VIRTUAL CLUSTER "app" STOP SERVICE`, `ALTER VIRTUAL CLUSTER "app" START SERVICE EXTERNAL`}},
+	}
+
+	for _, c := range cases {
+		if statements := virtualClusterServiceStatements("app", c.o, c.n); !reflect.DeepEqual(statements, c.expected) {
+			t.Errorf("%s to %s: got %v, expected %v", c.o, c.n, statements, c.expected)
+		}
+	}
+}
+
+func testAccResourceVirtualCluster(name string, serviceMode string) string {
+	return `
+resource "cockroach_virtual_cluster" "foo" {
+  name             = "` + name + `"
+  service_mode     = "` + serviceMode + `"
+  drop_immediately = true
+}
+
+resource "cockroach_virtual_cluster_capability" "split" {
+  virtual_cluster = cockroach_virtual_cluster.foo.name
+  capability      = "can_admin_split"
+}
+`
+}
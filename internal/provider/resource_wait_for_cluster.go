@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	waitExpectedNodesAttr = "expected_nodes"
+	waitTriggersAttr      = "triggers"
+	waitLiveNodesAttr     = "live_nodes"
+)
+
+func resourceWaitForCluster() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource waiting, when created, until the CockroachDB cluster answers SQL queries and optionally until the expected number of nodes is live. " +
+			"Resources depending on it are only applied once the cluster is up, e.g. right after it has been installed.",
+
+		CreateContext: resourceWaitForClusterCreate,
+		ReadContext:   resourceWaitForClusterRead,
+		DeleteContext: resourceWaitForClusterDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			waitExpectedNodesAttr: {
+				Description:  "Number of nodes which must be live, only the SQL endpoint is checked when not set.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			waitTriggersAttr: {
+				Description: "Arbitrary values which wait for the cluster again when changed, e.g. the version of the Helm release.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				ForceNew: true,
+			},
+			waitLiveNodesAttr: {
+				Description: "Number of live nodes once the cluster was up.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is 26267), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "26267",
+			},
+		},
+	}
+}
+
+func resourceWaitForClusterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	expectedNodes := d.Get(waitExpectedNodesAttr).(int)
+
+	var liveNodes int
+	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		nodes, err := checkCluster(ctx, d, meta)
+		if err != nil {
+			logDebug("cluster is not ready yet: %v", err)
+			return resource.RetryableError(err)
+		}
+
+		if nodes < expectedNodes {
+			return resource.RetryableError(fmt.Errorf("%d nodes live out of %d", nodes, expectedNodes))
+		}
+
+		liveNodes = nodes
+		return nil
+	})
+	if err != nil {
+		return diag.Errorf("cluster not ready: %v", err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err := d.Set(waitLiveNodesAttr, liveNodes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+// checkCluster checks the cluster answers SQL queries and returns its number
+// of live nodes.
+func checkCluster(ctx context.Context, d *schema.ResourceData, meta interface{}) (int, error) {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags.HasError() {
+		return 0, diagnosticsError(diags)
+	}
+	defer closeConn()
+
+	var one int
+	if err := conn.QueryRow(ctx, `SELECT 1`).Scan(&one); err != nil {
+		return 0, err
+	}
+
+	var nodes int
+	if err := conn.QueryRow(ctx, `SELECT count(*) FROM crdb_internal.gossip_nodes WHERE is_live`).Scan(&nodes); err != nil {
+		return 0, err
+	}
+
+	return nodes, nil
+}
+
+func resourceWaitForClusterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// the wait only happens when the resource is created, nothing to refresh
+	return diag.Diagnostics{}
+}
+
+func resourceWaitForClusterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceWaitForCluster(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceWaitForCluster,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_wait_for_cluster.foo", "live_nodes", "1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceWaitForCluster = `
+resource "cockroach_wait_for_cluster" "foo" {
+  expected_nodes = 1
+
+  timeouts {
+    create = "2m"
+  }
+}
+`
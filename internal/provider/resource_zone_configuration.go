@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	zoneConfigurationRangeAttr  = "range"
+	zoneConfigurationConfigAttr = "config"
+
+	zoneConfigurationDefaultLocalPort = "26300"
+)
+
+// zoneConfigurationRanges are the named ranges whose zone configuration can
+// be set, the default one being the parent of every other zone.
+var zoneConfigurationRanges = []string{"default", "liveness", "meta", "system"}
+
+func resourceZoneConfiguration() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Resource used to set the zone configuration of a named range of a CockroachDB cluster, e.g. the GC TTL or the replication of the system ranges. " +
+			"Only the variables of the resource are managed, the other ones are left untouched. The variables inherited from the parent zone aren't considered set, " +
+			"a variable of the resource reset with `COPY FROM PARENT` being set again.",
+
+		CreateContext: resourceZoneConfigurationCreate,
+		ReadContext:   resourceZoneConfigurationRead,
+		UpdateContext: resourceZoneConfigurationUpdate,
+		DeleteContext: resourceZoneConfigurationDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceZoneConfigurationImporter,
+		},
+		CustomizeDiff: resourceZoneConfigurationCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			zoneConfigurationRangeAttr: {
+				Description:  "Named range of the zone configuration, one of `default`, `liveness`, `meta` or `system`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(zoneConfigurationRanges, false),
+			},
+			zoneConfigurationConfigAttr: func() *schema.Schema {
+				s := zoneConfigSchema("Zone configuration of the range. The removed variables are inherited from the `default` range again, " +
+					"the ones of the `default` range being left as is, as when the resource is destroyed, since it has no parent zone to inherit them from.")
+				s.Optional = false
+				s.Required = true
+				return s
+			}(),
+			argLocalPort: {
+				Description: "Local port to be used for port-forward. (default is " + zoneConfigurationDefaultLocalPort + "), use different port to avoid same port opening.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     zoneConfigurationDefaultLocalPort,
+			},
+		},
+	}
+}
+
+func resourceZoneConfigurationCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChange(zoneConfigurationConfigAttr) || !d.NewValueKnown(zoneConfigurationConfigAttr) {
+		return nil
+	}
+
+	return validateZoneConfigLocalities(ctx, d, meta, []map[string]interface{}{d.Get(zoneConfigurationConfigAttr).(map[string]interface{})})
+}
+
+// zoneConfigurationTarget returns the target of the statements changing the
+// zone configuration of the range.
+func zoneConfigurationTarget(rangeName string) string {
+	return `RANGE ` + rangeName
+}
+
+// zoneConfigurationStatements returns the statements changing the zone
+// configuration of the range from o to n, the variables of the default range
+// being left as is when they are removed.
+func zoneConfigurationStatements(rangeName string, o map[string]interface{}, n map[string]interface{}) []string {
+	if rangeName == "default" {
+		kept := map[string]interface{}{}
+		for name, value := range o {
+			if _, ok := n[name]; ok {
+				kept[name] = value
+			}
+		}
+		o = kept
+	}
+
+	return configureZoneStatements(zoneConfigurationTarget(rangeName), o, n)
+}
+
+// readZoneConfiguration reads the variables set on the zone configuration of
+// the range, leaving out the ones inherited from its parent zone, which
+// SHOW ZONE CONFIGURATION reports as well.
+func readZoneConfiguration(ctx context.Context, conn *pgx.Conn, rangeName string) (map[string]interface{}, error) {
+	var sql string
+	err := conn.QueryRow(ctx,
+		`SELECT coalesce(raw_config_sql, '') FROM crdb_internal.zones WHERE target = $1`, zoneConfigurationTarget(rangeName)).Scan(&sql)
+	if err == pgx.ErrNoRows {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseZoneConfig(sql), nil
+}
+
+// applyZoneConfiguration runs the statements changing the zone configuration
+// of the range from o to n.
+func applyZoneConfiguration(ctx context.Context, d *schema.ResourceData, meta interface{}, o map[string]interface{}, n map[string]interface{}) diag.Diagnostics {
+	rangeName := d.Get(zoneConfigurationRangeAttr).(string)
+	statements := zoneConfigurationStatements(rangeName, o, n)
+	if len(statements) == 0 {
+		return nil
+	}
+
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	unlock := meta.(*cockroachClient).locks.lock(objectLockKey("range", "", "", rangeName))
+	defer unlock()
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(ctx, statement); err != nil {
+			return diag.Errorf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return nil
+}
+
+func resourceZoneConfigurationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyZoneConfiguration(ctx, d, meta, nil, d.Get(zoneConfigurationConfigAttr).(map[string]interface{})); diags != nil {
+		return diags
+	}
+	d.SetId(d.Get(zoneConfigurationRangeAttr).(string))
+
+	return resourceZoneConfigurationRead(ctx, d, meta)
+}
+
+func resourceZoneConfigurationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	current, err := readZoneConfiguration(ctx, conn, d.Get(zoneConfigurationRangeAttr).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// only the variables of the resource are tracked, the inherited ones
+	// being set again
+	config := d.Get(zoneConfigurationConfigAttr).(map[string]interface{})
+	for name := range config {
+		value, ok := current[name]
+		if !ok {
+			delete(config, name)
+			continue
+		}
+		config[name] = value
+	}
+
+	if err := d.Set(zoneConfigurationConfigAttr, config); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{}
+}
+
+func resourceZoneConfigurationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	o, n := d.GetChange(zoneConfigurationConfigAttr)
+	if diags := applyZoneConfiguration(ctx, d, meta, o.(map[string]interface{}), n.(map[string]interface{})); diags != nil {
+		return diags
+	}
+
+	return resourceZoneConfigurationRead(ctx, d, meta)
+}
+
+func resourceZoneConfigurationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyZoneConfiguration(ctx, d, meta, d.Get(zoneConfigurationConfigAttr).(map[string]interface{}), nil); diags != nil {
+		return diags
+	}
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceZoneConfigurationImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// the id is the name of the range
+	rangeName := d.Id()
+	if !contains(zoneConfigurationRanges, rangeName) {
+		return nil, fmt.Errorf("invalid zone configuration id %q, expected one of %v", d.Id(), zoneConfigurationRanges)
+	}
+
+	values := map[string]interface{}{
+		zoneConfigurationRangeAttr: rangeName,
+		argLocalPort:               zoneConfigurationDefaultLocalPort,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	// every variable set on the range is imported
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return nil, fmt.Errorf("failed to connect to the cluster: %v", diagnosticsError(diags))
+	}
+	defer closeConn()
+
+	config, err := readZoneConfiguration(ctx, conn, rangeName)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Set(zoneConfigurationConfigAttr, config); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
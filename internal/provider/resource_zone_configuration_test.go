@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceZoneConfiguration(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceZoneConfiguration,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cockroach_zone_configuration.meta", "id", "meta"),
+					resource.TestCheckResourceAttr(
+						"cockroach_zone_configuration.meta", "config.gc.ttlseconds", "3600"),
+				),
+			},
+		},
+	})
+}
+
+func TestZoneConfigurationStatements(t *testing.T) {
+	o := map[string]interface{}{"num_replicas": "5", "gc.ttlseconds": "3600"}
+	n := map[string]interface{}{"num_replicas": "3"}
+
+	expected := []string{`ALTER RANGE meta CONFIGURE ZONE USING num_replicas = 3, gc.ttlseconds = COPY FROM PARENT`}
+	if actual := zoneConfigurationStatements("meta", o, n); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expected)
+	}
+	expected = []string{`ALTER RANGE meta CONFIGURE ZONE DISCARD`}
+	if actual := zoneConfigurationStatements("meta", o, nil); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expected)
+	}
+
+	// the default range has no parent zone
+	expected = []string{`ALTER RANGE default CONFIGURE ZONE USING num_replicas = 3`}
+	if actual := zoneConfigurationStatements("default", o, n); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statements\n%q\nexpected\n%q", actual, expected)
+	}
+	if actual := zoneConfigurationStatements("default", o, nil); len(actual) != 0 {
+		t.Errorf("expected no statements destroying the default range, got %q", actual)
+	}
+}
+
+const testAccResourceZoneConfiguration = `
+resource "cockroach_zone_configuration" "meta" {
+  range = "meta"
+
+  config = {
+    "gc.ttlseconds" = "3600"
+  }
+}
+`
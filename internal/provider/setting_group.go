@@ -0,0 +1,368 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+// settingGroup describes a resource managing a group of related cluster
+// settings through typed attributes. The group is managed as a whole: the
+// settings of the attributes which aren't set go back to their default value,
+// so a change made outside of Terraform to any of them shows up in the plan.
+type settingGroup struct {
+	// id is the id of the resource, there is a single group per cluster
+	id          string
+	description string
+	localPort   string
+	// attributes are the settings of the group, keyed by attribute
+	attributes map[string]groupedSetting
+}
+
+// redactedSettingValue is the value of the sensitive settings shown to the
+// users who can't see them.
+const redactedSettingValue = "<redacted>"
+
+// groupedSetting is a cluster setting of a group, its schema being made
+// optional.
+type groupedSetting struct {
+	name   string
+	schema *schema.Schema
+	// roleDefault is true when the setting is the session variable name set
+	// for every role with ALTER ROLE ALL SET, e.g. for the defaults of the
+	// transactions, which aren't cluster settings
+	roleDefault bool
+}
+
+var roleDefaultNameRegexp = regexp.MustCompile(`^[a-z_]+$`)
+
+func resourceSettingGroup(g settingGroup) *schema.Resource {
+	s := map[string]*schema.Schema{
+		argLocalPort: {
+			Description: fmt.Sprintf("Local port to be used for port-forward. (default is %s), use different port to avoid same port opening.", g.localPort),
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     g.localPort,
+		},
+	}
+	for attr, setting := range g.attributes {
+		attrSchema := setting.schema
+		attrSchema.Optional = true
+		if setting.roleDefault {
+			attrSchema.Description += fmt.Sprintf(" Session variable `%s`, set for every role.", setting.name)
+		} else {
+			attrSchema.Description += fmt.Sprintf(" Cluster setting `%s`.", setting.name)
+		}
+		if attrSchema.Type == schema.TypeString && attrSchema.DiffSuppressFunc == nil {
+			attrSchema.DiffSuppressFunc = suppressEquivalentSettingValue
+		}
+		s[attr] = attrSchema
+	}
+
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: g.description + " The settings of the attributes which aren't set are reset to their default value.",
+
+		CreateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			if diags := applySettingGroup(ctx, d, meta, g, false); diags != nil {
+				return diags
+			}
+			d.SetId(g.id)
+
+			return readSettingGroup(ctx, d, meta, g)
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return readSettingGroup(ctx, d, meta, g)
+		},
+		UpdateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			if diags := applySettingGroup(ctx, d, meta, g, true); diags != nil {
+				return diags
+			}
+
+			return readSettingGroup(ctx, d, meta, g)
+		},
+		DeleteContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			if diags := resetSettingGroup(ctx, d, meta, g); diags != nil {
+				return diags
+			}
+			d.SetId("")
+
+			return diag.Diagnostics{}
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+				if d.Id() != g.id {
+					return nil, fmt.Errorf("invalid id %q, expected %s", d.Id(), g.id)
+				}
+				if err := d.Set(argLocalPort, g.localPort); err != nil {
+					return nil, err
+				}
+
+				return []*schema.ResourceData{d}, nil
+			},
+		},
+
+		Schema: s,
+	}
+}
+
+// sortedAttributes returns the attributes of the group, sorted so the
+// settings are always changed in the same order.
+func (g settingGroup) sortedAttributes() []string {
+	attrs := make([]string, 0, len(g.attributes))
+	for attr := range g.attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	return attrs
+}
+
+// attributeConfigured returns whether the attribute is set in the
+// configuration, which d.GetOk can't tell for false and 0.
+func attributeConfigured(d *schema.ResourceData, attr string) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		_, ok := d.GetOk(attr)
+		return ok
+	}
+
+	return !config.GetAttr(attr).IsNull()
+}
+
+// settingValueOf returns the value of the cluster setting of an attribute.
+func settingValueOf(attrType schema.ValueType, v interface{}) string {
+	switch attrType {
+	case schema.TypeFloat:
+		return strconv.FormatFloat(v.(float64), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// attributeValueOf returns the value of an attribute from the value of its
+// cluster setting.
+func attributeValueOf(attrType schema.ValueType, value string) (interface{}, error) {
+	switch attrType {
+	case schema.TypeBool:
+		return strconv.ParseBool(value)
+	case schema.TypeInt:
+		return strconv.Atoi(value)
+	case schema.TypeFloat:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+func isZeroValue(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	default:
+		return v == nil
+	}
+}
+
+// applySettingGroup sets the settings of the configured attributes and resets
+// the other ones, only the changed attributes being applied on update.
+func applySettingGroup(ctx context.Context, d *schema.ResourceData, meta interface{}, g settingGroup, update bool) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	current, err := readGroupedSettings(ctx, conn, g)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, attr := range g.sortedAttributes() {
+		name := g.attributes[attr].name
+		if update && !d.HasChange(attr) {
+			continue
+		}
+
+		if attributeConfigured(d, attr) {
+			if err := setGroupedSetting(ctx, conn, g.attributes[attr], settingValueOf(g.attributes[attr].schema.Type, d.Get(attr))); err != nil {
+				return diag.Errorf("failed to set %s: %v", name, err)
+			}
+			continue
+		}
+
+		// settings unknown to the version of the cluster can't be reset
+		if setting, ok := current[name]; ok && !settingValuesEqual(setting.value, setting.defaultValue) {
+			if err := resetGroupedSetting(ctx, conn, g.attributes[attr]); err != nil {
+				return diag.Errorf("failed to reset %s: %v", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// readGroupedSettings reads the cluster settings and the role defaults of the
+// group, the default value of the role defaults being empty, as well as their
+// value when they aren't set.
+func readGroupedSettings(ctx context.Context, conn *pgx.Conn, g settingGroup) (map[string]clusterSetting, error) {
+	current, err := readClusterSettings(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults map[string]string
+	for _, attr := range g.sortedAttributes() {
+		setting := g.attributes[attr]
+		if !setting.roleDefault {
+			continue
+		}
+		if defaults == nil {
+			if defaults, err = readRoleDefaults(ctx, conn); err != nil {
+				return nil, err
+			}
+		}
+		current[setting.name] = clusterSetting{value: defaults[setting.name]}
+	}
+
+	return current, nil
+}
+
+// readRoleDefaults reads the session variables set for every role, by name.
+func readRoleDefaults(ctx context.Context, conn *pgx.Conn) (map[string]string, error) {
+	var config []string
+	err := conn.QueryRow(ctx,
+		`SELECT coalesce(setconfig, ARRAY[]::STRING[]) FROM pg_catalog.pg_db_role_setting WHERE setdatabase = 0 AND setrole = 0`).Scan(&config)
+	if err == pgx.ErrNoRows {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRoleDefaults(config), nil
+}
+
+// parseRoleDefaults parses the settings of pg_db_role_setting, e.g.
+// default_transaction_isolation=read committed.
+func parseRoleDefaults(config []string) map[string]string {
+	defaults := map[string]string{}
+	for _, setting := range config {
+		if parts := strings.SplitN(setting, "=", 2); len(parts) == 2 {
+			defaults[parts[0]] = parts[1]
+		}
+	}
+
+	return defaults
+}
+
+func setGroupedSetting(ctx context.Context, conn *pgx.Conn, setting groupedSetting, value string) error {
+	if !setting.roleDefault {
+		return setClusterSetting(ctx, conn, setting.name, value)
+	}
+	if !roleDefaultNameRegexp.MatchString(setting.name) {
+		return fmt.Errorf("invalid session variable name %q", setting.name)
+	}
+
+	_, err := conn.Exec(ctx, `ALTER ROLE ALL SET `+setting.name+` = `+pq.QuoteLiteral(value))
+	return err
+}
+
+func resetGroupedSetting(ctx context.Context, conn *pgx.Conn, setting groupedSetting) error {
+	if !setting.roleDefault {
+		return resetClusterSetting(ctx, conn, setting.name)
+	}
+	if !roleDefaultNameRegexp.MatchString(setting.name) {
+		return fmt.Errorf("invalid session variable name %q", setting.name)
+	}
+
+	_, err := conn.Exec(ctx, `ALTER ROLE ALL RESET `+setting.name)
+	return err
+}
+
+// readSettingGroupConn reads the settings of the group. The attributes which
+// aren't set are left unset as long as their setting has its default value.
+func readSettingGroupConn(ctx context.Context, conn *pgx.Conn, d *schema.ResourceData, g settingGroup) diag.Diagnostics {
+	current, err := readGroupedSettings(ctx, conn, g)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, attr := range g.sortedAttributes() {
+		name := g.attributes[attr].name
+		setting, ok := current[name]
+		// the configured value of a redacted setting is kept
+		if !ok || setting.value == redactedSettingValue {
+			continue
+		}
+
+		attrType := g.attributes[attr].schema.Type
+		if settingValuesEqual(setting.value, setting.defaultValue) && isZeroValue(d.Get(attr)) {
+			continue
+		}
+		// the configured value is kept when CockroachDB reports it differently
+		if settingValuesEqual(setting.value, settingValueOf(attrType, d.Get(attr))) {
+			continue
+		}
+
+		value, err := attributeValueOf(attrType, setting.value)
+		if err != nil {
+			return diag.Errorf("unexpected value %q of %s: %v", setting.value, name, err)
+		}
+		if err := d.Set(attr, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diag.Diagnostics{}
+}
+
+func readSettingGroup(ctx context.Context, d *schema.ResourceData, meta interface{}, g settingGroup) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	return readSettingGroupConn(ctx, conn, d, g)
+}
+
+// resetSettingGroup resets every setting of the group.
+func resetSettingGroup(ctx context.Context, d *schema.ResourceData, meta interface{}, g settingGroup) diag.Diagnostics {
+	conn, closeConn, diags := openConnection(ctx, d, meta)
+	if diags != nil {
+		return diags
+	}
+	defer closeConn()
+
+	current, err := readGroupedSettings(ctx, conn, g)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, attr := range g.sortedAttributes() {
+		name := g.attributes[attr].name
+		if setting, ok := current[name]; !ok || settingValuesEqual(setting.value, setting.defaultValue) {
+			continue
+		}
+		if err := resetGroupedSetting(ctx, conn, g.attributes[attr]); err != nil {
+			return diag.Errorf("failed to reset %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSettingValues(t *testing.T) {
+	cases := []struct {
+		attrType schema.ValueType
+		value    interface{}
+		setting  string
+	}{
+		{schema.TypeBool, true, "true"},
+		{schema.TypeInt, 200, "200"},
+		{schema.TypeFloat, 0.2, "0.2"},
+		{schema.TypeString, "10m0s", "10m0s"},
+	}
+
+	for _, c := range cases {
+		if setting := settingValueOf(c.attrType, c.value); setting != c.setting {
+			t.Errorf("settingValueOf(%v) = %q, expected %q", c.value, setting, c.setting)
+		}
+		value, err := attributeValueOf(c.attrType, c.setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != c.value {
+			t.Errorf("attributeValueOf(%q) = %v, expected %v", c.setting, value, c.value)
+		}
+	}
+
+	if _, err := attributeValueOf(schema.TypeInt, "abc"); err == nil {
+		t.Errorf("expected an error for an invalid integer")
+	}
+}
+
+func TestSettingGroupSchema(t *testing.T) {
+	r := resourceSettingGroup(settingGroup{
+		id:        "test",
+		localPort: "26257",
+		attributes: map[string]groupedSetting{
+			"enabled":  {name: "test.enabled", schema: &schema.Schema{Description: "Whether it is enabled.", Type: schema.TypeBool}},
+			"interval": {name: "test.interval", schema: &schema.Schema{Description: "Interval.", Type: schema.TypeString}},
+		},
+	})
+
+	if err := r.InternalValidate(nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if description := r.Schema["enabled"].Description; description != "Whether it is enabled. Cluster setting `test.enabled`." {
+		t.Errorf("unexpected description %q", description)
+	}
+	if !r.Schema["interval"].Optional || r.Schema["interval"].DiffSuppressFunc == nil {
+		t.Errorf("expected an optional attribute comparing equivalent values")
+	}
+}
+
+func TestParseRoleDefaults(t *testing.T) {
+	defaults := parseRoleDefaults([]string{"default_transaction_isolation=read committed", "statement_timeout=30000", "invalid"})
+	if len(defaults) != 2 || defaults["default_transaction_isolation"] != "read committed" || defaults["statement_timeout"] != "30000" {
+		t.Errorf("unexpected role defaults %v", defaults)
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"os"
+	"sync"
+)
+
+// Terraform starts the provider for a command and shuts it down once it is
+// done with it, interrupted or not, the plugin server returning then. What
+// the provider leaves behind is torn down by Shutdown, called by main once
+// the plugin is served, the stop context of the provider only being
+// cancelled when Terraform is interrupted.
+
+// Shutdown tears down what the provider started for the Terraform command,
+// it must be called before the process exits.
+func Shutdown() {
+	logInfo("provider activity: %s", activity.summary())
+	logInfo("provider shut down, stopping the forward processes...")
+	portForwards.stopAll()
+	devClusters.stop()
+	tempDirs.remove()
+	traces.shutdown()
+}
+
+// tempDirs holds the files the provider writes for the Terraform command, e.g.
+// the client certificates, under a single directory removed on shutdown.
+var tempDirs = &tempDir{}
+
+type tempDir struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// create creates a directory only readable by the current user in the
+// directory of the provider, itself created on first call.
+func (t *tempDir) create(pattern string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dir == "" {
+		dir, err := os.MkdirTemp("", "terraform-provider-cockroach-")
+		if err != nil {
+			return "", err
+		}
+		t.dir = dir
+	}
+
+	return os.MkdirTemp(t.dir, pattern)
+}
+
+func (t *tempDir) remove() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dir == "" {
+		return
+	}
+	if err := os.RemoveAll(t.dir); err != nil {
+		logError("failed to remove %s: %v", t.dir, err)
+	}
+	t.dir = ""
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// The SQL written by the users refers to variables the way psql does, :'name'
+// being replaced with the value of the variable quoted as a string literal and
+// :"name" with the value quoted as an identifier, so the values never need to
+// be interpolated into the SQL by hand. The references within string
+// literals, escape strings (E'...'), dollar-quoted strings, e.g. the bodies of
+// functions, quoted identifiers and comments are left as is.
+
+var sqlVariableNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// sqlDollarQuoteRegexp matches the delimiter of a dollar-quoted string, $$ or
+// $tag$, $1 being a placeholder.
+var sqlDollarQuoteRegexp = regexp.MustCompile(`^\$([a-zA-Z_][a-zA-Z0-9_]*)?\$`)
+
+// isSQLIdentifierByte returns whether the byte can be part of an identifier,
+// E or $ following one being part of it rather than starting a string.
+func isSQLIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b >= 0x80
+}
+
+// renderSQL replaces the references to the variables in the SQL with their
+// quoted values, failing when a referenced variable isn't set.
+func renderSQL(sql string, variables map[string]string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(sql); {
+		switch {
+		case sql[i] == ':' && i+1 < len(sql) && (sql[i+1] == '\'' || sql[i+1] == '"') && (i == 0 || sql[i-1] != ':'):
+			quote := sql[i+1]
+			end := strings.IndexByte(sql[i+2:], quote)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference at offset %d", i)
+			}
+			name := sql[i+2 : i+2+end]
+			if !sqlVariableNameRegexp.MatchString(name) {
+				return "", fmt.Errorf("invalid variable name %q at offset %d", name, i)
+			}
+			value, ok := variables[name]
+			if !ok {
+				return "", fmt.Errorf("variable %s is not set", name)
+			}
+			if quote == '\'' {
+				out.WriteString(pq.QuoteLiteral(value))
+			} else {
+				out.WriteString(pq.QuoteIdentifier(value))
+			}
+			i += end + 3
+		case (sql[i] == 'e' || sql[i] == 'E') && i+1 < len(sql) && sql[i+1] == '\'' && (i == 0 || !isSQLIdentifierByte(sql[i-1])):
+			// a backslash escapes the next character, quotes included
+			end := i + 2
+			for end < len(sql) && sql[end] != '\'' {
+				if sql[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(sql) {
+				out.WriteString(sql[i:])
+				i = len(sql)
+				continue
+			}
+			out.WriteString(sql[i : end+1])
+			i = end + 1
+		case sql[i] == '$' && (i == 0 || !isSQLIdentifierByte(sql[i-1])) && sqlDollarQuoteRegexp.MatchString(sql[i:]):
+			delimiter := sqlDollarQuoteRegexp.FindString(sql[i:])
+			end := strings.Index(sql[i+len(delimiter):], delimiter)
+			if end < 0 {
+				out.WriteString(sql[i:])
+				i = len(sql)
+				continue
+			}
+			out.WriteString(sql[i : i+2*len(delimiter)+end])
+			i += 2*len(delimiter) + end
+		case sql[i] == '\'' || sql[i] == '"':
+			// a doubled quote within a literal or identifier is an escaped one,
+			// the scan resumes on it as the start of the rest of the literal
+			end := strings.IndexByte(sql[i+1:], sql[i])
+			if end < 0 {
+				out.WriteString(sql[i:])
+				i = len(sql)
+				continue
+			}
+			out.WriteString(sql[i : i+end+2])
+			i += end + 2
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			out.WriteString(sql[i : i+end])
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 4
+			}
+			out.WriteString(sql[i : i+end+4])
+			i += end + 4
+		default:
+			out.WriteByte(sql[i])
+			i++
+		}
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestRenderSQL(t *testing.T) {
+	variables := map[string]string{
+		"owner": "app owner",
+		"name":  "O'Brien",
+	}
+
+	cases := []struct {
+		sql      string
+		expected string
+	}{
+		{`ALTER TABLE t OWNER TO :"owner"`, `ALTER TABLE t OWNER TO "app owner"`},
+		{`INSERT INTO t VALUES (:'name')`, `INSERT INTO t VALUES ('O''Brien')`},
+		{`SELECT 'it''s :''name''', ":'name'"`, `SELECT 'it''s :''name''', ":'name'"`},
+		{"SELECT 1 -- :'name'\n, :'name'", "SELECT 1 -- :'name'\n, 'O''Brien'"},
+		{`SELECT /* :"owner" */ 'a'::STRING`, `SELECT /* :"owner" */ 'a'::STRING`},
+		{`SELECT E'it\'s :\'name\'', :'name'`, `SELECT E'it\'s :\'name\'', 'O''Brien'`},
+		{`SELECT e'\\', :'name'`, `SELECT e'\\', 'O''Brien'`},
+		{"CREATE FUNCTION f() RETURNS STRING AS $$ SELECT ':''name''' $$ LANGUAGE SQL; ALTER FUNCTION f OWNER TO :\"owner\"",
+			"CREATE FUNCTION f() RETURNS STRING AS $$ SELECT ':''name''' $$ LANGUAGE SQL; ALTER FUNCTION f OWNER TO \"app owner\""},
+		{"SELECT $body$ :'name' $$ :'name' $body$, :'name'", "SELECT $body$ :'name' $$ :'name' $body$, 'O''Brien'"},
+		{`SELECT $1, :'name'`, `SELECT $1, 'O''Brien'`},
+	}
+
+	for _, c := range cases {
+		rendered, err := renderSQL(c.sql, variables)
+		if err != nil {
+			t.Errorf("renderSQL(%q): %v", c.sql, err)
+			continue
+		}
+		if rendered != c.expected {
+			t.Errorf("renderSQL(%q) = %q, expected %q", c.sql, rendered, c.expected)
+		}
+	}
+
+	for _, sql := range []string{`SELECT :'missing'`, `SELECT :'na me'`, `SELECT :'name`} {
+		if _, err := renderSQL(sql, variables); err == nil {
+			t.Errorf("expected an error for %q", sql)
+		}
+	}
+}
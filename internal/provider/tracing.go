@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The provider traces its configuration, the port-forwards it waits for and
+// the statements it runs, exported with OTLP over HTTP when the
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment
+// variable is set, the other OTEL_EXPORTER_OTLP_ variables configuring the
+// exporter. The spans of the statements are children of the spans of the
+// context they are run with.
+const (
+	tracerName = "github.com/irinelbogdan92/terraform-provider-cockroach"
+
+	// tracingShutdownTimeout bounds the export of the remaining spans on
+	// shutdown, Terraform killing the provider shortly after shutting it down
+	tracingShutdownTimeout = time.Second
+)
+
+// traces is the tracing of the provider process, of all its clusters.
+var traces = &tracing{}
+
+type tracing struct {
+	once sync.Once
+
+	mu       sync.RWMutex
+	provider *sdktrace.TracerProvider
+}
+
+// start starts exporting the spans when an OTLP endpoint is set, once per
+// process.
+func (t *tracing) start(version string) {
+	t.once.Do(func() {
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+			return
+		}
+
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			logError("failed to create the OTLP trace exporter: %v", err)
+			return
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.provider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+				semconv.ServiceNameKey.String("terraform-provider-cockroach"),
+				semconv.ServiceVersionKey.String(version),
+			)),
+		)
+	})
+}
+
+// tracer returns the tracer of the provider, not recording anything when the
+// spans aren't exported.
+func (t *tracing) tracer() trace.Tracer {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.provider == nil {
+		return trace.NewNoopTracerProvider().Tracer(tracerName)
+	}
+	return t.provider.Tracer(tracerName)
+}
+
+// shutdown exports the remaining spans.
+func (t *tracing) shutdown() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.provider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := t.provider.Shutdown(ctx); err != nil {
+		logError("failed to export the traces: %v", err)
+	}
+	t.provider = nil
+}
+
+// endSpan ends the span, with the error as its status when not nil.
+func endSpan(span trace.Span, err error, options ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(options...)
+}
+
+// endSpanWithDiagnostics ends the span, recording the error diagnostics.
+func endSpanWithDiagnostics(span trace.Span, diags diag.Diagnostics) {
+	var summaries []string
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+		summary := d.Summary
+		if d.Detail != "" {
+			summary += ": " + d.Detail
+		}
+		span.RecordError(errors.New(summary))
+		summaries = append(summaries, summary)
+	}
+	if len(summaries) > 0 {
+		span.SetStatus(codes.Error, strings.Join(summaries, "; "))
+	}
+	span.End()
+}
+
+// traceStatement records the span of the statement logged by pgx. Neither the
+// arguments nor the string literals of the statement are recorded, they may
+// be passwords, e.g. of CREATE USER ... WITH PASSWORD.
+func traceStatement(ctx context.Context, msg string, data map[string]interface{}) {
+	end := time.Now()
+	start := end
+	if elapsed, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-elapsed)
+	}
+	sql, _ := data["sql"].(string)
+
+	_, span := traces.tracer().Start(ctx, msg,
+		trace.WithTimestamp(start),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemCockroachdb,
+			semconv.DBStatementKey.String(redactStringLiterals(sql)),
+		),
+	)
+	if rows, ok := data["rowCount"].(int); ok {
+		span.SetAttributes(attribute.Int("db.row_count", rows))
+	}
+	err, _ := data["err"].(error)
+	endSpan(span, err, trace.WithTimestamp(end))
+}
+
+// redactStringLiterals replaces the string literals of the statement, escape
+// strings included, by '***', leaving the quoted identifiers alone.
+func redactStringLiterals(sql string) string {
+	var b strings.Builder
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '"':
+			end := strings.IndexByte(sql[i+1:], '"')
+			if end < 0 {
+				b.WriteString(sql[i:])
+				return b.String()
+			}
+			b.WriteString(sql[i : i+end+2])
+			i += end + 1
+		case c == '\'':
+			escape := i > 0 && (sql[i-1] == 'e' || sql[i-1] == 'E') && (i < 2 || !isIdentifierByte(sql[i-2]))
+			j := i + 1
+			for ; j < len(sql); j++ {
+				if escape && sql[j] == '\\' {
+					j++
+					continue
+				}
+				if sql[j] == '\'' {
+					if j+1 < len(sql) && sql[j+1] == '\'' {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteString("'***'")
+			i = j
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
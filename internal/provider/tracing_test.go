@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+func TestTraceStatement(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	traces.mu.Lock()
+	traces.provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	traces.mu.Unlock()
+	defer traces.shutdown()
+
+	ctx, parent := traces.tracer().Start(context.Background(), "Create")
+	activity.Log(ctx, pgx.LogLevelInfo, "Exec", map[string]interface{}{
+		"sql":  "CREATE USER \"it's\" WITH PASSWORD 'p4ss''word' VALID UNTIL E'2030\\'s'",
+		"args": []interface{}{"secret"},
+		"time": 2 * time.Second,
+	})
+	activity.Log(ctx, pgx.LogLevelError, "Query", map[string]interface{}{
+		"sql": "SELECT 1",
+		"err": errors.New("connection reset"),
+	})
+	activity.Log(ctx, pgx.LogLevelInfo, "closed connection", nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	exec := spans[0]
+	if exec.Name() != "Exec" || exec.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("unexpected span %q of parent %s", exec.Name(), exec.Parent().SpanID())
+	}
+	if d := exec.EndTime().Sub(exec.StartTime()); d != 2*time.Second {
+		t.Errorf("expected the span to last 2s, got %s", d)
+	}
+	attrs := map[string]string{}
+	for _, a := range exec.Attributes() {
+		attrs[string(a.Key)] = a.Value.Emit()
+	}
+	expected := "CREATE USER \"it's\" WITH PASSWORD '***' VALID UNTIL E'***'"
+	if attrs[string(semconv.DBStatementKey)] != expected || attrs[string(semconv.DBSystemKey)] != "cockroachdb" {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	for _, v := range attrs {
+		if strings.Contains(v, "secret") || strings.Contains(v, "p4ss") {
+			t.Errorf("the arguments and literals of the statement must not be recorded: %v", attrs)
+		}
+	}
+
+	if query := spans[1]; query.Name() != "Query" || query.Status().Code != codes.Error || query.Status().Description != "connection reset" {
+		t.Errorf("unexpected span %q with status %+v", query.Name(), query.Status())
+	}
+}
+
+func TestRedactStringLiterals(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"ALTER USER foo WITH PASSWORD 'bar'", "ALTER USER foo WITH PASSWORD '***'"},
+		{"ALTER USER foo WITH PASSWORD NULL", "ALTER USER foo WITH PASSWORD NULL"},
+		{"SELECT 'a''b', e'c\\'d', 1", "SELECT '***', e'***', 1"},
+		{`SELECT "col'umn" FROM t WHERE name = 'x'`, `SELECT "col'umn" FROM t WHERE name = '***'`},
+		{"SELECT 'unterminated", "SELECT '***'"},
+	}
+
+	for _, test := range tests {
+		if actual := redactStringLiterals(test.sql); actual != test.expected {
+			t.Errorf("redactStringLiterals(%q) = %q, expected %q", test.sql, actual, test.expected)
+		}
+	}
+}
+
+func TestTracingNotStartedWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	tr := &tracing{}
+	tr.start("test")
+	if tr.provider != nil {
+		t.Errorf("expected no tracer provider without an OTLP endpoint")
+	}
+	if _, span := tr.tracer().Start(context.Background(), "Configure"); span.IsRecording() {
+		t.Errorf("expected the spans not to be recorded")
+	}
+}
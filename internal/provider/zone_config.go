@@ -0,0 +1,279 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jackc/pgx/v4"
+	"github.com/lib/pq"
+)
+
+// zoneConfigVariables are the variables of a zone configuration, true for the
+// ones holding a string, e.g. constraints.
+var zoneConfigVariables = map[string]bool{
+	"constraints":       true,
+	"gc.ttlseconds":     false,
+	"global_reads":      false,
+	"lease_preferences": true,
+	"num_replicas":      false,
+	"num_voters":        false,
+	"range_max_bytes":   false,
+	"range_min_bytes":   false,
+	"voter_constraints": true,
+}
+
+// zoneConfigSchema returns the schema of a zone configuration attribute.
+func zoneConfigSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: description + " Keyed by variable, e.g. `num_replicas` or `constraints`, the string variables being given without quotes, e.g. `[+region=us-east1]`. " +
+			"The locality tiers of the constraints and lease preferences are checked against the ones of the nodes of the cluster while planning, when it can be reached.",
+		Type: schema.TypeMap,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		Optional:     true,
+		ValidateFunc: validateZoneConfig,
+	}
+}
+
+func validateZoneConfig(v interface{}, k string) ([]string, []error) {
+	var errs []error
+	for name, value := range v.(map[string]interface{}) {
+		isString, ok := zoneConfigVariables[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown zone configuration variable %s", k, name))
+			continue
+		}
+		if isString {
+			if _, err := zoneConstraints(value.(string)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid %s: %v", k, name, err))
+			}
+		}
+	}
+
+	return nil, errs
+}
+
+var zoneConstraintRegexp = regexp.MustCompile(`^[+-][^=\s]+(=[^=\s]+)?$`)
+
+// zoneConstraints returns the constraints of the value of constraints,
+// voter_constraints or lease_preferences, given as a list, e.g.
+// [+region=us-east1, -zone=us-east1-b], as per-replica constraints, e.g.
+// {"+region=us-east1": 2, "+region=us-west1": 1}, or as lists of lists for
+// the lease preferences, e.g. [[+region=us-east1], [+region=us-west1]].
+func zoneConstraints(value string) ([]string, error) {
+	var constraints []string
+	stripped := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]{}"'`, r) {
+			return -1
+		}
+		return r
+	}, value)
+	for _, item := range strings.Split(stripped, ",") {
+		// the number of replicas of the per-replica constraints
+		if i := strings.Index(item, ":"); i >= 0 {
+			if _, err := strconv.Atoi(strings.TrimSpace(item[i+1:])); err != nil {
+				return nil, fmt.Errorf("unexpected number of replicas in %q", strings.TrimSpace(item))
+			}
+			item = item[:i]
+		}
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !zoneConstraintRegexp.MatchString(item) {
+			return nil, fmt.Errorf("unexpected constraint %q, expected +key=value, -key=value or +attribute", item)
+		}
+		constraints = append(constraints, item)
+	}
+
+	return constraints, nil
+}
+
+// checkZoneConfigLocalities returns an error when a constraint of the zone
+// configuration refers to a locality tier the nodes of the cluster don't
+// have, e.g. because of a typo, CockroachDB accepting it and leaving the
+// replicas it can't place under-replicated. The tiers are the values of the
+// nodes by key, the constraints on the attributes of the nodes and stores,
+// e.g. +ssd, being left out.
+func checkZoneConfigLocalities(config map[string]interface{}, tiers map[string][]string) error {
+	for _, name := range sortedKeys(config) {
+		if !zoneConfigVariables[name] {
+			continue
+		}
+
+		constraints, err := zoneConstraints(config[name].(string))
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+		for _, constraint := range constraints {
+			parts := strings.SplitN(constraint[1:], "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			values, ok := tiers[parts[0]]
+			if !ok {
+				keys := make([]string, 0, len(tiers))
+				for key := range tiers {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				return fmt.Errorf("%s: no node of the cluster has a locality tier %s, the tiers are %s", name, parts[0], strings.Join(keys, ", "))
+			}
+			if !contains(values, parts[1]) {
+				return fmt.Errorf("%s: no node of the cluster has the locality tier %s=%s, the values of %s are %s", name, parts[0], parts[1], parts[0], strings.Join(values, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// readLocalityTiers reads the values of the locality tiers of the nodes of
+// the cluster by key, sorted.
+func readLocalityTiers(ctx context.Context, conn *pgx.Conn) (map[string][]string, error) {
+	rows, err := conn.Query(ctx, `SELECT DISTINCT locality FROM crdb_internal.gossip_nodes`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := map[string][]string{}
+	for rows.Next() {
+		var locality string
+		if err := rows.Scan(&locality); err != nil {
+			return nil, err
+		}
+		parsed, err := parseLocality(locality)
+		if err != nil {
+			return nil, err
+		}
+		for _, tier := range parsed {
+			if !contains(tiers[tier.key], tier.value) {
+				tiers[tier.key] = append(tiers[tier.key], tier.value)
+			}
+		}
+	}
+	for _, values := range tiers {
+		sort.Strings(values)
+	}
+
+	return tiers, rows.Err()
+}
+
+// validateZoneConfigLocalities checks the constraints of the zone
+// configurations against the locality tiers of the nodes of the cluster
+// while planning, the configurations with unknown values being left out. The
+// check is skipped when the cluster can't be reached yet, e.g. when it is
+// created by the same apply, the connection of the provider being unknown.
+func validateZoneConfigLocalities(ctx context.Context, d *schema.ResourceDiff, meta interface{}, configs []map[string]interface{}) error {
+	var constrained []map[string]interface{}
+	for _, config := range configs {
+		for name, value := range config {
+			if zoneConfigVariables[name] && strings.Contains(value.(string), "=") {
+				constrained = append(constrained, config)
+				break
+			}
+		}
+	}
+	if len(constrained) == 0 {
+		return nil
+	}
+
+	if !meta.(*cockroachClient).hasConnection() {
+		logWarn("skipping the check of the zone configuration localities, the connection to the cluster is unknown")
+		return nil
+	}
+	conn, closeConn, diags := openDiffConnection(ctx, d, meta)
+	if diags != nil {
+		logWarn("skipping the check of the zone configuration localities, failed to connect to the cluster: %v", diagnosticsError(diags))
+		return nil
+	}
+	defer closeConn()
+
+	tiers, diags := meta.(*cockroachClient).cache.get("locality_tiers", func() (interface{}, diag.Diagnostics) {
+		tiers, err := readLocalityTiers(ctx, conn)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		return tiers, nil
+	})
+	if diags.HasError() {
+		return fmt.Errorf("failed to read the localities of the nodes: %v", diagnosticsError(diags))
+	}
+
+	for _, config := range constrained {
+		if err := checkZoneConfigLocalities(config, tiers.(map[string][]string)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// zoneConfigValue returns the SQL value of the variable.
+func zoneConfigValue(name string, value string) string {
+	if zoneConfigVariables[name] {
+		return pq.QuoteLiteral(value)
+	}
+
+	return value
+}
+
+// configureZoneStatements returns the statements changing the zone
+// configuration of the target, e.g. `PARTITION p OF TABLE t`, from o to n.
+// The removed variables are inherited from the parent zone again.
+func configureZoneStatements(target string, o map[string]interface{}, n map[string]interface{}) []string {
+	if len(n) == 0 {
+		if len(o) == 0 {
+			return nil
+		}
+		return []string{`ALTER ` + target + ` CONFIGURE ZONE DISCARD`}
+	}
+
+	var assignments []string
+	for _, name := range sortedKeys(n) {
+		value := n[name].(string)
+		if old, ok := o[name]; ok && old.(string) == value {
+			continue
+		}
+		assignments = append(assignments, name+` = `+zoneConfigValue(name, value))
+	}
+	for _, name := range sortedKeys(o) {
+		if _, ok := n[name]; !ok {
+			assignments = append(assignments, name+` = COPY FROM PARENT`)
+		}
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	return []string{`ALTER ` + target + ` CONFIGURE ZONE USING ` + strings.Join(assignments, `, `)}
+}
+
+// parseZoneConfig parses the variables of a zone configuration as reported
+// by SHOW ZONE CONFIGURATION, e.g. "num_replicas = 3,\n\tconstraints = '[+region=us-east1]'".
+func parseZoneConfig(sql string) map[string]interface{} {
+	config := map[string]interface{}{}
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+		parts := strings.SplitN(line, " = ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) >= 2 {
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+		config[name] = value
+	}
+
+	return config
+}
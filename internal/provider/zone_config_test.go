@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestConfigureZoneStatements(t *testing.T) {
+	cases := []struct {
+		o, n     map[string]interface{}
+		expected []string
+	}{
+		{nil, nil, nil},
+		{
+			nil,
+			map[string]interface{}{"num_replicas": "5", "constraints": "[+region=us-east1]"},
+			[]string{`ALTER PARTITION p OF TABLE t CONFIGURE ZONE USING constraints = '[+region=us-east1]', num_replicas = 5`},
+		},
+		{
+			map[string]interface{}{"num_replicas": "5", "gc.ttlseconds": "600"},
+			map[string]interface{}{"num_replicas": "3"},
+			[]string{`ALTER PARTITION p OF TABLE t CONFIGURE ZONE USING num_replicas = 3, gc.ttlseconds = COPY FROM PARENT`},
+		},
+		{
+			map[string]interface{}{"num_replicas": "5"},
+			map[string]interface{}{"num_replicas": "5"},
+			nil,
+		},
+		{
+			map[string]interface{}{"num_replicas": "5"},
+			nil,
+			[]string{`ALTER PARTITION p OF TABLE t CONFIGURE ZONE DISCARD`},
+		},
+	}
+
+	for _, c := range cases {
+		if actual := configureZoneStatements("PARTITION p OF TABLE t", c.o, c.n); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("configureZoneStatements(%v, %v) = %q, expected %q", c.o, c.n, actual, c.expected)
+		}
+	}
+}
+
+func TestParseZoneConfig(t *testing.T) {
+	config := parseZoneConfig("range_min_bytes = 134217728,\n\tnum_replicas = 3,\n\tconstraints = '[+region=us-east1]',\n\tlease_preferences = '[[+region=us-east1]]'")
+	expected := map[string]interface{}{
+		"range_min_bytes":   "134217728",
+		"num_replicas":      "3",
+		"constraints":       "[+region=us-east1]",
+		"lease_preferences": "[[+region=us-east1]]",
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("unexpected zone configuration %v", config)
+	}
+
+	// the raw configuration of crdb_internal.zones starts with the statement
+	config = parseZoneConfig("ALTER RANGE meta CONFIGURE ZONE USING\n\tgc.ttlseconds = 3600")
+	if !reflect.DeepEqual(config, map[string]interface{}{"gc.ttlseconds": "3600"}) {
+		t.Errorf("unexpected zone configuration %v", config)
+	}
+
+	if config := parseZoneConfig(""); len(config) != 0 {
+		t.Errorf("expected an empty zone configuration, got %v", config)
+	}
+}
+
+func TestZoneConstraints(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected []string
+	}{
+		{"[+region=us-east1, -zone=us-east1-b]", []string{"+region=us-east1", "-zone=us-east1-b"}},
+		{`{"+region=us-east1,+zone=us-east1-b": 2, "+region=us-west1": 1}`, []string{"+region=us-east1", "+zone=us-east1-b", "+region=us-west1"}},
+		{"[[+region=us-east1], [+region=us-west1]]", []string{"+region=us-east1", "+region=us-west1"}},
+		{"[+ssd]", []string{"+ssd"}},
+		{"[]", nil},
+	}
+	for _, c := range cases {
+		actual, err := zoneConstraints(c.value)
+		if err != nil {
+			t.Errorf("zoneConstraints(%q): %v", c.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("zoneConstraints(%q) = %q, expected %q", c.value, actual, c.expected)
+		}
+	}
+
+	for _, value := range []string{"[region=us-east1]", "[+region=us=east1]", `{"+region=us-east1": two}`} {
+		if _, err := zoneConstraints(value); err == nil {
+			t.Errorf("expected zoneConstraints(%q) to fail", value)
+		}
+	}
+}
+
+func TestCheckZoneConfigLocalities(t *testing.T) {
+	tiers := map[string][]string{"region": {"us-east1", "us-west1"}, "zone": {"us-east1-b", "us-west1-a"}}
+
+	valid := map[string]interface{}{
+		"num_replicas":      "5",
+		"constraints":       `{"+region=us-east1": 2, "+region=us-west1": 1}`,
+		"lease_preferences": "[[+region=us-east1, +ssd]]",
+	}
+	if err := checkZoneConfigLocalities(valid, tiers); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for _, config := range []map[string]interface{}{
+		{"constraints": "[+region=us-east]"},
+		{"voter_constraints": "[+datacenter=dc1]"},
+	} {
+		if err := checkZoneConfigLocalities(config, tiers); err == nil {
+			t.Errorf("expected %v to fail", config)
+		}
+	}
+}
+
+func TestValidateZoneConfigLocalitiesUnreachable(t *testing.T) {
+	raw := map[string]interface{}{"range": "liveness", "config": map[string]interface{}{"constraints": "[+region=us-east1]"}}
+
+	// the connection of a cluster created by the same apply is unknown, and
+	// the cluster may not be reachable yet
+	for _, meta := range []*cockroachClient{
+		{},
+		{dns: "postgresql://root@127.0.0.1:1/defaultdb?sslmode=disable&connect_timeout=1"},
+	} {
+		if _, err := resourceZoneConfiguration().SimpleDiff(context.Background(), &terraform.InstanceState{}, terraform.NewResourceConfigRaw(raw), meta); err != nil {
+			t.Errorf("expected the check to be skipped, got %v", err)
+		}
+	}
+}
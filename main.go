@@ -39,11 +39,14 @@ func main() {
 	if debugMode {
 		// TODO: update this string with the full name of your provider as used in your configs
 		err := plugin.Debug(context.Background(), "registry.terraform.io/irinelbogdan92/cockroach", opts)
+		provider.Shutdown()
 		if err != nil {
 			log.Fatal(err.Error())
 		}
 		return
 	}
 
+	// Terraform shuts the plugin down once done with it, which returns then
 	plugin.Serve(opts)
+	provider.Shutdown()
 }